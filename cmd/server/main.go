@@ -2,24 +2,31 @@ package main
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // alert schedules name IANA time zones
 
 	"github.com/AnjuRKrishnan/fleet-tracker/internal/auth"
 	"github.com/AnjuRKrishnan/fleet-tracker/internal/config"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/grpcapi"
 	handlers "github.com/AnjuRKrishnan/fleet-tracker/internal/handler"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/ingest"
 	"github.com/AnjuRKrishnan/fleet-tracker/internal/middleware"
 	"github.com/AnjuRKrishnan/fleet-tracker/internal/services"
 	"github.com/AnjuRKrishnan/fleet-tracker/internal/store/postgres"
 	"github.com/AnjuRKrishnan/fleet-tracker/internal/store/redis"
 	"github.com/AnjuRKrishnan/fleet-tracker/pkg/logger"
 	"github.com/AnjuRKrishnan/fleet-tracker/pkg/utils"
+	"github.com/AnjuRKrishnan/fleet-tracker/pkg/webhook"
 	"github.com/go-chi/chi/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -48,15 +55,84 @@ func main() {
 	// Setup Repositories
 	vehicleRepo := postgres.NewVehicleRepository(dbpool)
 	vehicleCache := redis.NewVehicleCache(cache)
+	eventBus := redis.NewEventBus(cache)
+	geofenceRepo := postgres.NewGeofenceRepository(dbpool)
+	alertRepo := postgres.NewAlertRepository(dbpool)
+	webhookRepo := postgres.NewWebhookRepository(dbpool)
+	deviceRepo := postgres.NewDeviceRepository(dbpool)
 
 	// Setup Services
-	vehicleService := services.NewVehicleService(vehicleRepo, vehicleCache)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	webhookService := services.NewWebhookService(webhookRepo, zapLogger)
+	webhookDispatcher, err := services.NewWebhookDispatcher(webhookRepo,
+		webhook.NewClient(cfg.WebhookTimeout),
+		services.WebhookDeliveryPolicy{
+			PollInterval: cfg.WebhookPollInterval,
+			MaxAttempts:  cfg.WebhookMaxAttempts,
+			DisableAfter: cfg.WebhookDisableAfter,
+			Retention:    cfg.WebhookRetention,
+		},
+		zapLogger,
+	)
+	if err != nil {
+		zapLogger.Fatal("Invalid webhook configuration", zap.Error(err))
+	}
+	utils.SafeGo(func() { webhookDispatcher.Run(ctx) }, "WebhookDispatcher")
+
+	liveHub := services.NewLiveHub(eventBus, zapLogger)
+	utils.SafeGo(func() { liveHub.Run(ctx) }, "LiveHub")
+
+	events := services.MultiPublisher(webhookService, liveHub)
+
+	tripDetector := services.NewTripDetector(vehicleRepo, cfg.TripStopWindow, cfg.TripMinSpeed, zapLogger,
+		services.WithTripEvents(events),
+	)
+	// Pick up the trips a previous run left open before any reading arrives.
+	if err := tripDetector.Restore(ctx); err != nil {
+		zapLogger.Fatal("Could not restore open trips", zap.Error(err))
+	}
+	utils.SafeGo(func() { tripDetector.Run(ctx) }, "TripDetector")
+
+	geofenceService := services.NewGeofenceService(geofenceRepo,
+		services.WithGeofenceEvents(events),
+	)
+
+	alertService := services.NewAlertService(alertRepo, cfg.AlertSweepInterval, zapLogger,
+		services.WithAlertEvents(events),
+	)
+	utils.SafeGo(func() { alertService.Run(ctx) }, "AlertService")
+
+	vehicleOpts := []services.VehicleServiceOption{
+		services.WithStatusProcessors(tripDetector, geofenceService, alertService, services.StatusEvents(events)),
+		services.WithDedupWindow(cfg.DedupWindow),
+		services.WithLogger(zapLogger),
+	}
+	if cfg.RejectUnregisteredVehicles {
+		vehicleOpts = append(vehicleOpts, services.WithRegisteredVehiclesOnly(vehicleRepo))
+	}
+	vehicleService := services.NewVehicleService(vehicleRepo, vehicleCache, vehicleOpts...)
+	vehicleRegistry := services.NewVehicleRegistry(vehicleRepo, vehicleCache,
+		services.WithArchiveHandlers(alertService),
+	)
+
+	deviceService := services.NewDeviceService(deviceRepo)
 
 	// Setup JWT Auth
 	jwtAuth := auth.NewJWTAuth(cfg.JWTSecret)
 
 	// Setup Handlers
 	vehicleHandler := handlers.NewVehicleHandler(vehicleService, zapLogger)
+	geofenceHandler := handlers.NewGeofenceHandler(geofenceService, zapLogger)
+	alertHandler := handlers.NewAlertHandler(alertService, zapLogger)
+	webhookHandler := handlers.NewWebhookHandler(webhookService, zapLogger)
+	vehicleRegistryHandler := handlers.NewVehicleRegistryHandler(vehicleRegistry, zapLogger)
+	deviceHandler := handlers.NewDeviceHandler(deviceService, zapLogger)
+	osmAndHandler := handlers.NewOsmAndHandler(deviceService, vehicleService, zapLogger)
+	loRaWANHandler := handlers.NewLoRaWANHandler(deviceService, vehicleService, ingest.NewLoRaWANDecoders(), zapLogger)
+	streamHandler := handlers.NewStreamHandler(liveHub, zapLogger)
+	socketHandler := handlers.NewSocketHandler(liveHub, zapLogger)
 
 	// Setup Router
 	r := chi.NewRouter()
@@ -69,12 +145,96 @@ func main() {
 		w.Write([]byte("OK"))
 	})
 
+	// Streaming routes also accept the token as the access_token query
+	// parameter, since browsers cannot set headers on EventSource and
+	// WebSocket requests.
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.TokenFromQuery("access_token"))
+		r.Use(middleware.JWTAuthenticator(jwtAuth))
+		r.Get("/api/vehicle/stream", streamHandler.StreamPositions)
+		r.Get("/api/ws", socketHandler.Subscribe)
+	})
+
+	// Phone tracking apps authenticate with their device token rather than
+	// a JWT.
+	r.Get("/api/osmand", osmAndHandler.Ingest)
+	r.Post("/api/osmand", osmAndHandler.Ingest)
+
+	// Network server webhooks authenticate with a shared token.
+	if cfg.LoRaWANWebhookToken != "" {
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.StaticToken(cfg.LoRaWANWebhookToken))
+			r.Post("/api/lorawan/tts", loRaWANHandler.TTSUplink)
+			r.Post("/api/lorawan/chirpstack", loRaWANHandler.ChirpStackUplink)
+		})
+	}
+
 	// Private (authenticated) routes
 	r.Route("/api", func(r chi.Router) {
 		r.Use(middleware.JWTAuthenticator(jwtAuth))
 		r.Post("/vehicle/ingest", vehicleHandler.IngestData)
+		r.Post("/vehicle/ingest/batch", vehicleHandler.IngestBatch)
 		r.Get("/vehicle/status", vehicleHandler.GetStatus)
+		r.Get("/fleet/status", vehicleHandler.GetFleetStatus)
+		r.Get("/fleet/nearby", vehicleHandler.FindNearby)
 		r.Get("/vehicle/trips", vehicleHandler.GetTrips)
+		r.Get("/vehicle/trips/export", vehicleHandler.ExportTrips)
+		r.Get("/vehicle/trips/{id}", vehicleHandler.GetTrip)
+		r.Get("/vehicle/trips/{id}/route", vehicleHandler.GetTripRoute)
+		r.Get("/vehicle/positions", vehicleHandler.GetPositions)
+		r.Get("/vehicle/positions/export", vehicleHandler.ExportPositions)
+		r.Get("/vehicle/geofence-events", geofenceHandler.GetVehicleEvents)
+
+		r.Route("/vehicles", func(r chi.Router) {
+			r.Post("/", vehicleRegistryHandler.Create)
+			r.Get("/", vehicleRegistryHandler.List)
+			r.Get("/plate/{plate}", vehicleRegistryHandler.GetByPlate)
+			r.Get("/{id}", vehicleRegistryHandler.Get)
+			r.Put("/{id}", vehicleRegistryHandler.Update)
+			r.Post("/{id}/archive", vehicleRegistryHandler.Archive)
+		})
+
+		r.Route("/geofences", func(r chi.Router) {
+			r.Post("/", geofenceHandler.Create)
+			r.Get("/", geofenceHandler.List)
+			r.Get("/{id}", geofenceHandler.Get)
+			r.Put("/{id}", geofenceHandler.Update)
+			r.Delete("/{id}", geofenceHandler.Delete)
+			r.Get("/{id}/events", geofenceHandler.GetFenceEvents)
+		})
+
+		r.Route("/alert-rules", func(r chi.Router) {
+			r.Post("/", alertHandler.CreateRule)
+			r.Get("/", alertHandler.ListRules)
+			r.Get("/{id}", alertHandler.GetRule)
+			r.Put("/{id}", alertHandler.UpdateRule)
+			r.Delete("/{id}", alertHandler.DeleteRule)
+		})
+
+		r.Route("/alerts", func(r chi.Router) {
+			r.Get("/", alertHandler.ListAlerts)
+			r.Get("/{id}", alertHandler.GetAlert)
+			r.Post("/{id}/acknowledge", alertHandler.AcknowledgeAlert)
+			r.Post("/{id}/resolve", alertHandler.ResolveAlert)
+		})
+
+		r.Route("/webhooks", func(r chi.Router) {
+			r.Post("/", webhookHandler.Create)
+			r.Get("/", webhookHandler.List)
+			r.Get("/{id}", webhookHandler.Get)
+			r.Put("/{id}", webhookHandler.Update)
+			r.Delete("/{id}", webhookHandler.Delete)
+			r.Get("/{id}/deliveries", webhookHandler.ListDeliveries)
+		})
+
+		r.Route("/devices", func(r chi.Router) {
+			r.Post("/", deviceHandler.Create)
+			r.Get("/", deviceHandler.List)
+			r.Get("/{id}", deviceHandler.Get)
+			r.Put("/{id}", deviceHandler.Update)
+			r.Delete("/{id}", deviceHandler.Delete)
+			r.Post("/{id}/token", deviceHandler.RotateToken)
+		})
 	})
 
 	// Start server
@@ -83,6 +243,10 @@ func main() {
 		Handler: r,
 	}
 
+	// Ending the background work on shutdown also closes the open event
+	// streams, which would otherwise hold the server open.
+	server.RegisterOnShutdown(cancel)
+
 	go func() {
 		zapLogger.Info("Starting server on port 8080")
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -90,13 +254,55 @@ func main() {
 		}
 	}()
 
-	// Start the simulated data stream and worker pool
+	var grpcServer *grpc.Server
+	if cfg.GRPCListenAddr != "" {
+		lis, err := net.Listen("tcp", cfg.GRPCListenAddr)
+		if err != nil {
+			zapLogger.Fatal("Could not start gRPC server", zap.Error(err))
+		}
+		grpcServer = grpcapi.NewServer(jwtAuth, grpcapi.NewVehicleServer(vehicleService, liveHub, zapLogger))
+		utils.SafeGo(func() {
+			zapLogger.Info("Starting gRPC server", zap.String("addr", cfg.GRPCListenAddr))
+			if err := grpcServer.Serve(lis); err != nil {
+				zapLogger.Fatal("Could not start gRPC server", zap.Error(err))
+			}
+		}, "GRPCServer")
+	}
+
+	// Start the worker pool and the sources that feed it
+	ingestQueue := make(chan domain.IngestRequest, services.IngestQueueSize)
+	workerPool := services.NewWorkerPool(5, ingestQueue, vehicleService, zapLogger)
+	utils.SafeGo(workerPool.Run, "WorkerPool")
+
 	if cfg.SimulatorEnabled {
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
-		dataChannel := services.StartDataSimulator(ctx)
-		workerPool := services.NewWorkerPool(5, dataChannel, vehicleService, zapLogger)
-		utils.SafeGo(workerPool.Run, "WorkerPool")
+		services.StartDataSimulator(ctx, ingestQueue)
+	}
+	if cfg.MQTTBrokerURL != "" {
+		mqttSource := ingest.NewMQTTSource(ingest.MQTTConfig{
+			BrokerURL: cfg.MQTTBrokerURL,
+			ClientID:  cfg.MQTTClientID,
+			Username:  cfg.MQTTUsername,
+			Password:  cfg.MQTTPassword,
+			Topics:    cfg.MQTTTopics,
+			QoS:       byte(cfg.MQTTQoS),
+		}, vehicleService, zapLogger)
+		utils.SafeGo(func() { mqttSource.Run(ctx) }, "MQTTSource")
+	}
+	if cfg.NMEAListenAddr != "" {
+		nmeaServer := ingest.NewNMEAServer(cfg.NMEAListenAddr, cfg.NMEAIdleTimeout, deviceService, ingestQueue, zapLogger)
+		utils.SafeGo(func() {
+			if err := nmeaServer.ListenAndServe(ctx); err != nil {
+				zapLogger.Fatal("Could not start NMEA server", zap.Error(err))
+			}
+		}, "NMEAServer")
+	}
+	if cfg.TeltonikaListenAddr != "" {
+		teltonikaServer := ingest.NewTeltonikaServer(cfg.TeltonikaListenAddr, cfg.TeltonikaIdleTimeout, deviceService, vehicleService, zapLogger)
+		utils.SafeGo(func() {
+			if err := teltonikaServer.ListenAndServe(ctx); err != nil {
+				zapLogger.Fatal("Could not start Teltonika server", zap.Error(err))
+			}
+		}, "TeltonikaServer")
 	}
 
 	// Graceful shutdown
@@ -106,11 +312,25 @@ func main() {
 
 	zapLogger.Info("Shutting down server...")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	if err := server.Shutdown(ctx); err != nil {
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
 		zapLogger.Fatal("Server shutdown failed", zap.Error(err))
 	}
+	if grpcServer != nil {
+		// Shutting down the HTTP server ended the live position watches;
+		// ingest streams get until the deadline to finish.
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-shutdownCtx.Done():
+			grpcServer.Stop()
+		}
+	}
 	zapLogger.Info("Server stopped gracefully")
 
 }
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_vehicle_positions_vehicle_recorded_at;
+
+DROP TABLE IF EXISTS vehicle_positions;
@@ -0,0 +1,13 @@
+CREATE TABLE vehicle_positions (
+    id BIGSERIAL PRIMARY KEY,
+    vehicle_id UUID NOT NULL REFERENCES vehicle(id) ON DELETE CASCADE,
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    longitude FLOAT,
+    latitude FLOAT,
+    speed FLOAT NOT NULL DEFAULT 0,
+    status JSONB NOT NULL DEFAULT '{}'
+);
+
+--indexes
+
+CREATE INDEX idx_vehicle_positions_vehicle_recorded_at ON vehicle_positions(vehicle_id, recorded_at);
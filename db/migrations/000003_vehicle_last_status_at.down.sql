@@ -0,0 +1 @@
+ALTER TABLE vehicle DROP COLUMN IF EXISTS last_status_at;
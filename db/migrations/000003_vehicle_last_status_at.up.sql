@@ -0,0 +1,5 @@
+ALTER TABLE vehicle ADD COLUMN last_status_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE vehicle
+SET last_status_at = (last_status->>'timestamp')::timestamptz
+WHERE last_status ? 'timestamp';
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_geofence_events_geofence_id;
+DROP INDEX IF EXISTS idx_geofence_events_vehicle_id;
+
+DROP TABLE IF EXISTS geofence_events;
+DROP TABLE IF EXISTS geofence_presence;
+DROP TABLE IF EXISTS geofences;
@@ -0,0 +1,37 @@
+CREATE TABLE geofences (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('circle', 'polygon')),
+    center_lon FLOAT,
+    center_lat FLOAT,
+    radius FLOAT, -- metres, circles only
+    polygon JSONB NOT NULL DEFAULT '[]', -- [[lon, lat], ...], polygons only
+    dwell_seconds INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+-- Which vehicles are currently inside which fences.
+CREATE TABLE geofence_presence (
+    vehicle_id UUID NOT NULL REFERENCES vehicle(id) ON DELETE CASCADE,
+    geofence_id UUID NOT NULL REFERENCES geofences(id) ON DELETE CASCADE,
+    entered_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    dwell_reported BOOLEAN NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (vehicle_id, geofence_id)
+);
+
+CREATE TABLE geofence_events (
+    id BIGSERIAL PRIMARY KEY,
+    geofence_id UUID NOT NULL REFERENCES geofences(id) ON DELETE CASCADE,
+    vehicle_id UUID NOT NULL REFERENCES vehicle(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('enter', 'exit', 'dwell')),
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    longitude FLOAT,
+    latitude FLOAT
+);
+
+--indexes
+
+CREATE INDEX idx_geofence_events_vehicle_id ON geofence_events(vehicle_id, occurred_at DESC);
+
+CREATE INDEX idx_geofence_events_geofence_id ON geofence_events(geofence_id, occurred_at DESC);
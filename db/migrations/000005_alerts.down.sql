@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_alerts_opened_at;
+DROP INDEX IF EXISTS idx_alerts_vehicle_id;
+DROP INDEX IF EXISTS idx_alerts_unresolved;
+
+DROP TABLE IF EXISTS alerts;
+DROP TABLE IF EXISTS alert_rules;
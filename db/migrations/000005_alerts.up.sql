@@ -0,0 +1,36 @@
+CREATE TABLE alert_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('speeding', 'no_update', 'outside_hours')),
+    vehicle_id UUID, -- NULL applies the rule fleet-wide
+    speed_limit FLOAT NOT NULL DEFAULT 0, -- km/h
+    duration_seconds INT NOT NULL DEFAULT 0,
+    schedule JSONB NOT NULL DEFAULT 'null', -- allowed hours, outside_hours only
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE TABLE alerts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    rule_id UUID NOT NULL REFERENCES alert_rules(id) ON DELETE CASCADE,
+    vehicle_id UUID NOT NULL REFERENCES vehicle(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    state TEXT NOT NULL DEFAULT 'open' CHECK (state IN ('open', 'acknowledged', 'resolved')),
+    message TEXT NOT NULL,
+    value FLOAT NOT NULL DEFAULT 0,
+    longitude FLOAT,
+    latitude FLOAT,
+    opened_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    acknowledged_at TIMESTAMP WITH TIME ZONE,
+    resolved_at TIMESTAMP WITH TIME ZONE
+);
+
+--indexes
+
+-- At most one unresolved alert per rule and vehicle.
+CREATE UNIQUE INDEX idx_alerts_unresolved ON alerts(rule_id, vehicle_id) WHERE state <> 'resolved';
+
+CREATE INDEX idx_alerts_vehicle_id ON alerts(vehicle_id, opened_at DESC);
+
+CREATE INDEX idx_alerts_opened_at ON alerts(opened_at DESC);
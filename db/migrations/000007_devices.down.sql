@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_devices_vehicle_id;
+DROP INDEX IF EXISTS idx_devices_identifier;
+
+DROP TABLE IF EXISTS devices;
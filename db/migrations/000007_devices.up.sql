@@ -0,0 +1,15 @@
+CREATE TABLE devices (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    identifier TEXT NOT NULL, -- what the device reports itself as, e.g. its IMEI
+    vehicle_id UUID NOT NULL, -- the vehicle its readings are ingested for
+    plate_number TEXT NOT NULL DEFAULT '',
+    name TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+--indexes
+
+CREATE UNIQUE INDEX idx_devices_identifier ON devices(identifier);
+
+CREATE INDEX idx_devices_vehicle_id ON devices(vehicle_id);
@@ -0,0 +1,3 @@
+-- SHA-256 of the token devices authenticate HTTP ingest with. Devices
+-- registered before tokens existed have none until one is issued.
+ALTER TABLE devices ADD COLUMN token_hash BYTEA;
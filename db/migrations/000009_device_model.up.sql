@@ -0,0 +1,3 @@
+-- The tracker model, which selects the decoder of LoRaWAN payloads. Empty
+-- uses the payload the network server decoded.
+ALTER TABLE devices ADD COLUMN model TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_vehicle_created_at;
+DROP INDEX IF EXISTS idx_vehicle_plate_number;
+
+ALTER TABLE vehicle
+    DROP COLUMN IF EXISTS archived_at,
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS name;
@@ -0,0 +1,14 @@
+-- Vehicles can be registered ahead of their first reading. Archived vehicles
+-- keep their history but are hidden from the registry and, when unregistered
+-- vehicles are rejected, no longer accept readings.
+ALTER TABLE vehicle
+    ADD COLUMN name TEXT NOT NULL DEFAULT '',
+    ADD COLUMN created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    ADD COLUMN archived_at TIMESTAMP WITH TIME ZONE;
+
+--indexes
+
+CREATE INDEX idx_vehicle_plate_number ON vehicle(plate_number);
+
+CREATE INDEX idx_vehicle_created_at ON vehicle(created_at, id);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_trips_vehicle_start_time;
@@ -0,0 +1,2 @@
+-- Trip history is paged per vehicle by (start_time, id) in either direction.
+CREATE INDEX idx_trips_vehicle_start_time ON trips(vehicle_id, start_time, id);
@@ -0,0 +1,2 @@
+-- Open trips are reloaded by the trip detector on startup.
+CREATE INDEX idx_trips_open ON trips(start_time) WHERE end_time IS NULL;
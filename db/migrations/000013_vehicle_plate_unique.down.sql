@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_vehicle_active_plate_number;
@@ -0,0 +1,20 @@
+-- Plate numbers are unique among active vehicles. Readings could set any
+-- plate number before, so of the active vehicles sharing one only the
+-- earliest registered keeps it.
+UPDATE vehicle v
+SET plate_number = '',
+    updated_at = now()
+WHERE v.archived_at IS NULL
+  AND v.plate_number <> ''
+  AND EXISTS (
+      SELECT 1
+      FROM vehicle o
+      WHERE o.plate_number = v.plate_number
+        AND o.archived_at IS NULL
+        AND (o.created_at, o.id) < (v.created_at, v.id)
+  );
+
+--indexes
+
+CREATE UNIQUE INDEX idx_vehicle_active_plate_number ON vehicle(plate_number)
+WHERE archived_at IS NULL AND plate_number <> '';
@@ -0,0 +1,116 @@
+-- name: CreateAlertRule :one
+INSERT INTO alert_rules (name, kind, vehicle_id, speed_limit, duration_seconds, schedule, enabled)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetAlertRule :one
+SELECT *
+FROM alert_rules
+WHERE id = $1;
+
+-- name: ListAlertRules :many
+SELECT *
+FROM alert_rules
+ORDER BY name, id;
+
+-- name: UpdateAlertRule :one
+UPDATE alert_rules
+SET name = $2,
+    kind = $3,
+    vehicle_id = $4,
+    speed_limit = $5,
+    duration_seconds = $6,
+    schedule = $7,
+    enabled = $8,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteAlertRule :execrows
+DELETE FROM alert_rules
+WHERE id = $1;
+
+-- name: OpenAlert :one
+INSERT INTO alerts (rule_id, vehicle_id, kind, message, value, longitude, latitude, opened_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (rule_id, vehicle_id) WHERE state <> 'resolved' DO NOTHING
+RETURNING *;
+
+-- name: OpenSilenceAlerts :many
+INSERT INTO alerts (rule_id, vehicle_id, kind, message, value, opened_at)
+SELECT @rule_id::uuid, v.id, 'no_update', @message::text,
+       EXTRACT(EPOCH FROM @opened_at::timestamptz - v.last_status_at)::float8, @opened_at::timestamptz
+FROM vehicle v
+WHERE v.last_status_at < @silent_since::timestamptz
+  AND v.archived_at IS NULL
+  AND (sqlc.narg('vehicle_id')::uuid IS NULL OR v.id = sqlc.narg('vehicle_id'))
+ON CONFLICT (rule_id, vehicle_id) WHERE state <> 'resolved' DO NOTHING
+RETURNING *;
+
+-- name: ResolveOpenAlerts :many
+UPDATE alerts
+SET state = 'resolved',
+    resolved_at = $3
+WHERE rule_id = $1
+  AND vehicle_id = $2
+  AND state <> 'resolved'
+RETURNING *;
+
+-- name: ResolveRuleAlerts :many
+UPDATE alerts
+SET state = 'resolved',
+    resolved_at = $2
+WHERE rule_id = $1
+  AND state <> 'resolved'
+RETURNING *;
+
+-- name: ResolveSilenceAlerts :many
+UPDATE alerts a
+SET state = 'resolved',
+    resolved_at = @resolved_at::timestamptz
+FROM vehicle v
+WHERE a.vehicle_id = v.id
+  AND a.rule_id = @rule_id::uuid
+  AND a.state <> 'resolved'
+  AND v.last_status_at >= @silent_since::timestamptz
+RETURNING a.*;
+
+-- name: ResolveVehicleAlerts :many
+UPDATE alerts
+SET state = 'resolved',
+    resolved_at = $2
+WHERE vehicle_id = $1
+  AND state <> 'resolved'
+RETURNING *;
+
+-- name: AcknowledgeAlert :one
+UPDATE alerts
+SET state = 'acknowledged',
+    acknowledged_at = $2
+WHERE id = $1
+  AND state = 'open'
+RETURNING *;
+
+-- name: ResolveAlert :one
+UPDATE alerts
+SET state = 'resolved',
+    resolved_at = $2
+WHERE id = $1
+  AND state <> 'resolved'
+RETURNING *;
+
+-- name: GetAlert :one
+SELECT *
+FROM alerts
+WHERE id = $1;
+
+-- name: ListAlerts :many
+SELECT *
+FROM alerts
+WHERE (sqlc.narg('vehicle_id')::uuid IS NULL OR vehicle_id = sqlc.narg('vehicle_id'))
+  AND (sqlc.narg('rule_id')::uuid IS NULL OR rule_id = sqlc.narg('rule_id'))
+  AND (sqlc.narg('state')::text IS NULL OR state = sqlc.narg('state'))
+  AND opened_at >= @from_time
+  AND opened_at <= @to_time
+ORDER BY opened_at DESC, id
+LIMIT @row_limit OFFSET @row_offset;
@@ -0,0 +1,41 @@
+-- name: CreateDevice :one
+INSERT INTO devices (identifier, vehicle_id, plate_number, name, model, token_hash)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetDevice :one
+SELECT *
+FROM devices
+WHERE id = $1;
+
+-- name: GetDeviceByIdentifier :one
+SELECT *
+FROM devices
+WHERE identifier = $1;
+
+-- name: ListDevices :many
+SELECT *
+FROM devices
+ORDER BY created_at, id;
+
+-- name: UpdateDevice :one
+UPDATE devices
+SET identifier = @identifier,
+    vehicle_id = @vehicle_id,
+    plate_number = @plate_number,
+    name = @name,
+    model = @model,
+    updated_at = now()
+WHERE id = @id
+RETURNING *;
+
+-- name: SetDeviceTokenHash :one
+UPDATE devices
+SET token_hash = @token_hash,
+    updated_at = now()
+WHERE id = @id
+RETURNING *;
+
+-- name: DeleteDevice :execrows
+DELETE FROM devices
+WHERE id = $1;
@@ -0,0 +1,68 @@
+-- name: CreateGeofence :one
+INSERT INTO geofences (name, kind, center_lon, center_lat, radius, polygon, dwell_seconds)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetGeofence :one
+SELECT *
+FROM geofences
+WHERE id = $1;
+
+-- name: ListGeofences :many
+SELECT *
+FROM geofences
+ORDER BY name, id;
+
+-- name: UpdateGeofence :one
+UPDATE geofences
+SET name = $2,
+    kind = $3,
+    center_lon = $4,
+    center_lat = $5,
+    radius = $6,
+    polygon = $7,
+    dwell_seconds = $8,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteGeofence :execrows
+DELETE FROM geofences
+WHERE id = $1;
+
+-- name: ListGeofencePresence :many
+SELECT *
+FROM geofence_presence
+WHERE vehicle_id = $1;
+
+-- name: InsertGeofencePresence :execrows
+INSERT INTO geofence_presence (vehicle_id, geofence_id, entered_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (vehicle_id, geofence_id) DO NOTHING;
+
+-- name: MarkGeofenceDwellReported :execrows
+UPDATE geofence_presence
+SET dwell_reported = TRUE
+WHERE vehicle_id = $1
+  AND geofence_id = $2
+  AND NOT dwell_reported;
+
+-- name: DeleteGeofencePresence :execrows
+DELETE FROM geofence_presence
+WHERE vehicle_id = $1
+  AND geofence_id = $2;
+
+-- name: InsertGeofenceEvent :one
+INSERT INTO geofence_events (geofence_id, vehicle_id, kind, occurred_at, longitude, latitude)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: ListGeofenceEvents :many
+SELECT *
+FROM geofence_events
+WHERE (sqlc.narg('vehicle_id')::uuid IS NULL OR vehicle_id = sqlc.narg('vehicle_id'))
+  AND (sqlc.narg('geofence_id')::uuid IS NULL OR geofence_id = sqlc.narg('geofence_id'))
+  AND occurred_at >= @from_time
+  AND occurred_at <= @to_time
+ORDER BY occurred_at DESC, id DESC
+LIMIT @row_limit OFFSET @row_offset;
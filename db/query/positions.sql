@@ -0,0 +1,41 @@
+-- name: InsertPosition :exec
+INSERT INTO vehicle_positions (vehicle_id, recorded_at, longitude, latitude, speed, status)
+VALUES ($1, $2, $3, $4, $5, $6::JSONB);
+
+-- name: ListPositionsByVehicle :many
+SELECT id, vehicle_id, recorded_at, longitude, latitude, speed, status
+FROM vehicle_positions
+WHERE vehicle_id = $1
+  AND recorded_at >= $2
+  AND recorded_at <= $3
+ORDER BY recorded_at ASC, id ASC
+LIMIT $4 OFFSET $5;
+
+-- name: CountPositionsByVehicle :one
+SELECT COUNT(*)
+FROM vehicle_positions
+WHERE vehicle_id = $1
+  AND recorded_at >= $2
+  AND recorded_at <= $3;
+
+-- name: ListPositionsByVehicleAfter :many
+-- Chronological, continuing after the position given by after_recorded_at and
+-- after_id.
+SELECT id, vehicle_id, recorded_at, longitude, latitude, speed, status
+FROM vehicle_positions
+WHERE vehicle_id = @vehicle_id
+  AND recorded_at >= sqlc.arg('after_recorded_at')
+  AND recorded_at <= sqlc.arg('to')
+  AND (recorded_at, id) > (sqlc.arg('after_recorded_at')::timestamptz, sqlc.arg('after_id')::bigint)
+ORDER BY recorded_at ASC, id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: InsertPositions :exec
+INSERT INTO vehicle_positions (vehicle_id, recorded_at, longitude, latitude, speed, status)
+SELECT p.vehicle_id,
+       (p.status->>'timestamp')::timestamptz,
+       (p.status->'location'->>0)::float8,
+       (p.status->'location'->>1)::float8,
+       COALESCE((p.status->>'speed')::float8, 0),
+       p.status
+FROM unnest(@vehicle_ids::uuid[], @statuses::jsonb[]) AS p(vehicle_id, status);
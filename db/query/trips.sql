@@ -2,21 +2,49 @@
 INSERT INTO trips (id, vehicle_id, start_time, end_time, mileage, avg_speed)
 VALUES ($1, $2, $3, $4, $5, $6);
 
--- name: GetTripsLast24Hours :many
-SELECT id, vehicle_id, start_time, end_time, mileage, avg_speed
-FROM trips
-WHERE vehicle_id = $1
-  AND start_time >= now() - interval '24 hours'
-ORDER BY start_time DESC;
-
 -- name: GetTripByID :one
 SELECT *
 FROM trips
 WHERE id = $1;
 
 -- name: ListTripsByVehicle :many
+-- Newest first. A page continues after the trip given by after_start_time and
+-- after_id.
+SELECT *
+FROM trips
+WHERE vehicle_id = @vehicle_id
+  AND start_time >= sqlc.arg('from')
+  AND start_time <= sqlc.arg('to')
+  AND COALESCE(mileage, 0) >= @min_mileage
+  AND (sqlc.narg('after_start_time')::timestamptz IS NULL
+       OR (start_time, id) < (sqlc.narg('after_start_time'), sqlc.narg('after_id')::uuid))
+ORDER BY start_time DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListTripsByVehicleAsc :many
+-- Oldest first. A page continues after the trip given by after_start_time and
+-- after_id.
 SELECT *
 FROM trips
-WHERE vehicle_id = $1
-AND start_time >= $2 
-ORDER BY start_time DESC;
+WHERE vehicle_id = @vehicle_id
+  AND start_time >= sqlc.arg('from')
+  AND start_time <= sqlc.arg('to')
+  AND COALESCE(mileage, 0) >= @min_mileage
+  AND (sqlc.narg('after_start_time')::timestamptz IS NULL
+       OR (start_time, id) > (sqlc.narg('after_start_time'), sqlc.narg('after_id')::uuid))
+ORDER BY start_time ASC, id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: ListOpenTrips :many
+-- Trips that were started but never closed, oldest first.
+SELECT *
+FROM trips
+WHERE end_time IS NULL
+ORDER BY start_time ASC, id ASC;
+
+-- name: CloseTrip :exec
+UPDATE trips
+SET end_time = $2,
+    mileage = $3,
+    avg_speed = $4
+WHERE id = $1;
@@ -1,31 +1,77 @@
--- name: UpsertVehicleStatus :exec
-INSERT INTO vehicle (id, plate_number, last_status)
-VALUES ($1, $2, $3::JSONB)
+-- name: UpsertVehicleStatus :execrows
+-- Only a reading at least as new as the stored one replaces it. A reading
+-- without a plate number, or with one another active vehicle has, keeps the
+-- registered one.
+INSERT INTO vehicle (id, plate_number, last_status, last_status_at)
+VALUES (
+    $1,
+    CASE WHEN EXISTS (
+        SELECT 1
+        FROM vehicle
+        WHERE plate_number = $2
+          AND archived_at IS NULL
+          AND id <> $1
+    ) THEN '' ELSE $2 END,
+    $3::JSONB,
+    $4
+)
 ON CONFLICT (id) DO UPDATE
-SET plate_number = EXCLUDED.plate_number,
-    last_status  = EXCLUDED.last_status;
-
+SET plate_number   = COALESCE(NULLIF(EXCLUDED.plate_number, ''), vehicle.plate_number),
+    last_status    = EXCLUDED.last_status,
+    last_status_at = EXCLUDED.last_status_at
+WHERE vehicle.last_status_at IS NULL
+   OR vehicle.last_status_at <= EXCLUDED.last_status_at;
 
 -- name: GetVehicleStatus :one
-SELECT id, plate_number, last_status
+SELECT last_status, last_status_at
 FROM vehicle
 WHERE id = $1;
 
 -- name: CreateVehicle :one
-INSERT INTO vehicle (id, plate_number, last_status)
+INSERT INTO vehicle (id, plate_number, name)
 VALUES ($1, $2, $3)
-ON CONFLICT (id) DO UPDATE
-SET last_status = EXCLUDED.last_status
 RETURNING *;
 
+-- name: GetVehicle :one
+SELECT *
+FROM vehicle
+WHERE id = $1;
+
 -- name: GetVehicleByPlate :one
+-- Prefers the active vehicle with the plate over archived ones.
 SELECT *
 FROM vehicle
-WHERE plate_number = $1;
+WHERE plate_number = $1
+ORDER BY archived_at IS NOT NULL, archived_at DESC
+LIMIT 1;
+
+-- name: IsVehicleActive :one
+SELECT EXISTS (
+    SELECT 1
+    FROM vehicle
+    WHERE id = $1
+      AND archived_at IS NULL
+);
 
 -- name: ListVehicles :many
 SELECT *
 FROM vehicle
-ORDER BY ID DESC
-LIMIT $1 OFFSET $2;
+WHERE @include_archived::boolean OR archived_at IS NULL
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
 
+-- name: UpdateVehicle :one
+UPDATE vehicle
+SET plate_number = @plate_number,
+    name = @name,
+    updated_at = now()
+WHERE id = @id
+RETURNING *;
+
+-- name: ArchiveVehicle :one
+-- Archiving an archived vehicle keeps its original archive time.
+UPDATE vehicle
+SET archived_at = COALESCE(archived_at, now()),
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,102 @@
+-- name: CreateWebhook :one
+INSERT INTO webhook_subscriptions (url, secret, event_types, enabled)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetWebhook :one
+SELECT *
+FROM webhook_subscriptions
+WHERE id = $1;
+
+-- name: ListWebhooks :many
+SELECT *
+FROM webhook_subscriptions
+ORDER BY created_at, id;
+
+-- name: UpdateWebhook :one
+UPDATE webhook_subscriptions
+SET url = @url,
+    secret = COALESCE(NULLIF(@secret::text, ''), secret),
+    event_types = @event_types,
+    enabled = @enabled,
+    failing_since = CASE WHEN @enabled::boolean AND enabled THEN failing_since ELSE NULL END,
+    disabled_at = CASE WHEN @enabled::boolean THEN NULL ELSE COALESCE(disabled_at, now()) END,
+    updated_at = now()
+WHERE id = @id
+RETURNING *;
+
+-- name: DeleteWebhook :execrows
+DELETE FROM webhook_subscriptions
+WHERE id = $1;
+
+-- name: RecordWebhookSuccess :exec
+UPDATE webhook_subscriptions
+SET failing_since = NULL
+WHERE id = $1
+  AND failing_since IS NOT NULL;
+
+-- name: RecordWebhookFailure :one
+UPDATE webhook_subscriptions
+SET failing_since = COALESCE(failing_since, @failed_at::timestamptz),
+    enabled = enabled AND COALESCE(failing_since, @failed_at::timestamptz) > @disable_before::timestamptz,
+    disabled_at = CASE
+        WHEN enabled AND COALESCE(failing_since, @failed_at::timestamptz) <= @disable_before::timestamptz THEN @failed_at::timestamptz
+        ELSE disabled_at
+    END
+WHERE id = @id
+RETURNING *;
+
+-- name: EnqueueWebhookDeliveries :exec
+INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, next_attempt_at)
+SELECT unnest(@subscription_ids::uuid[]), @event_id::uuid, @event_type::text, @payload::jsonb, @next_attempt_at::timestamptz;
+
+-- name: ClaimWebhookDeliveries :many
+WITH due AS (
+    SELECT d.id
+    FROM webhook_deliveries d
+    JOIN webhook_subscriptions s ON s.id = d.subscription_id
+    WHERE d.state = 'pending'
+      AND d.next_attempt_at <= @now::timestamptz
+      AND s.enabled
+    ORDER BY d.next_attempt_at
+    LIMIT @row_limit
+    FOR UPDATE OF d SKIP LOCKED
+)
+UPDATE webhook_deliveries d
+SET next_attempt_at = @lease_until::timestamptz
+FROM due, webhook_subscriptions s
+WHERE d.id = due.id
+  AND s.id = d.subscription_id
+RETURNING d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.state, d.attempts, d.next_attempt_at, d.response_status, d.last_error, d.created_at, d.completed_at, s.url, s.secret;
+
+-- name: RetryWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET attempts = attempts + 1,
+    response_status = @response_status,
+    last_error = @last_error,
+    next_attempt_at = @next_attempt_at
+WHERE id = @id;
+
+-- name: CompleteWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET state = @state,
+    attempts = attempts + 1,
+    response_status = @response_status,
+    last_error = @last_error,
+    completed_at = @completed_at
+WHERE id = @id;
+
+-- name: ListWebhookDeliveries :many
+SELECT *
+FROM webhook_deliveries
+WHERE subscription_id = @subscription_id
+  AND (sqlc.narg('state')::text IS NULL OR state = sqlc.narg('state'))
+  AND created_at >= @from_time
+  AND created_at <= @to_time
+ORDER BY created_at DESC, id
+LIMIT @row_limit OFFSET @row_offset;
+
+-- name: DeleteWebhookDeliveriesBefore :execrows
+DELETE FROM webhook_deliveries
+WHERE state <> 'pending'
+  AND created_at < $1;
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/caarlos0/env"
 	"github.com/joho/godotenv"
 )
@@ -11,6 +13,60 @@ type Config struct {
 	RedisURL         string `env:"REDIS_URL,required"`
 	JWTSecret        string `env:"JWT_SECRET,required"`
 	SimulatorEnabled bool   `env:"SIMULATOR_ENABLED" envDefault:"true"`
+
+	// Trip detection: a trip closes once the vehicle has been slower than
+	// TripMinSpeed (km/h), or silent, for TripStopWindow.
+	TripStopWindow time.Duration `env:"TRIP_STOP_WINDOW" envDefault:"5m"`
+	TripMinSpeed   float64       `env:"TRIP_MIN_SPEED" envDefault:"5"`
+
+	// DedupWindow is how long device message IDs are remembered to drop
+	// retried ingests. Zero disables deduplication.
+	DedupWindow time.Duration `env:"DEDUP_WINDOW" envDefault:"10m"`
+
+	// RejectUnregisteredVehicles rejects readings for vehicles that are not
+	// in the registry, or are archived, instead of registering vehicles on
+	// their first reading.
+	RejectUnregisteredVehicles bool `env:"REJECT_UNREGISTERED_VEHICLES" envDefault:"false"`
+
+	// AlertSweepInterval is how often no_update alert rules are checked.
+	AlertSweepInterval time.Duration `env:"ALERT_SWEEP_INTERVAL" envDefault:"30s"`
+
+	// Webhook delivery: attempts time out after WebhookTimeout and a delivery
+	// fails after WebhookMaxAttempts. A subscription is disabled once it has
+	// been failing for WebhookDisableAfter, and completed deliveries are kept
+	// in the log for WebhookRetention.
+	WebhookPollInterval time.Duration `env:"WEBHOOK_POLL_INTERVAL" envDefault:"1s"`
+	WebhookTimeout      time.Duration `env:"WEBHOOK_TIMEOUT" envDefault:"10s"`
+	WebhookMaxAttempts  int           `env:"WEBHOOK_MAX_ATTEMPTS" envDefault:"10"`
+	WebhookDisableAfter time.Duration `env:"WEBHOOK_DISABLE_AFTER" envDefault:"24h"`
+	WebhookRetention    time.Duration `env:"WEBHOOK_RETENTION" envDefault:"168h"`
+
+	// MQTT ingest is enabled by setting MQTTBrokerURL, e.g. tcp://broker:1883.
+	// Each instance needs its own MQTTClientID unless the topics are shared
+	// subscriptions.
+	MQTTBrokerURL string   `env:"MQTT_BROKER_URL"`
+	MQTTClientID  string   `env:"MQTT_CLIENT_ID" envDefault:"fleet-tracker"`
+	MQTTUsername  string   `env:"MQTT_USERNAME"`
+	MQTTPassword  string   `env:"MQTT_PASSWORD"`
+	MQTTTopics    []string `env:"MQTT_TOPICS" envSeparator:"," envDefault:"fleet/+/telemetry"`
+	MQTTQoS       int      `env:"MQTT_QOS" envDefault:"1"`
+
+	// NMEA over TCP is enabled by setting NMEAListenAddr, e.g. :5010.
+	// Connections that stay silent for NMEAIdleTimeout are closed.
+	NMEAListenAddr  string        `env:"NMEA_LISTEN_ADDR"`
+	NMEAIdleTimeout time.Duration `env:"NMEA_IDLE_TIMEOUT" envDefault:"5m"`
+
+	// The Teltonika Codec 8/8E server is enabled by setting
+	// TeltonikaListenAddr, e.g. :5027.
+	TeltonikaListenAddr  string        `env:"TELTONIKA_LISTEN_ADDR"`
+	TeltonikaIdleTimeout time.Duration `env:"TELTONIKA_IDLE_TIMEOUT" envDefault:"5m"`
+
+	// The LoRaWAN uplink webhooks are enabled by setting
+	// LoRaWANWebhookToken, the bearer token network servers send.
+	LoRaWANWebhookToken string `env:"LORAWAN_WEBHOOK_TOKEN"`
+
+	// The gRPC API is enabled by setting GRPCListenAddr, e.g. :9090.
+	GRPCListenAddr string `env:"GRPC_LISTEN_ADDR"`
 }
 
 // Load reads configuration from a .env file and environment variables.
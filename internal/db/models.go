@@ -8,6 +8,76 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type Alert struct {
+	ID             pgtype.UUID        `json:"id"`
+	RuleID         pgtype.UUID        `json:"rule_id"`
+	VehicleID      pgtype.UUID        `json:"vehicle_id"`
+	Kind           string             `json:"kind"`
+	State          string             `json:"state"`
+	Message        string             `json:"message"`
+	Value          float64            `json:"value"`
+	Longitude      pgtype.Float8      `json:"longitude"`
+	Latitude       pgtype.Float8      `json:"latitude"`
+	OpenedAt       pgtype.Timestamptz `json:"opened_at"`
+	AcknowledgedAt pgtype.Timestamptz `json:"acknowledged_at"`
+	ResolvedAt     pgtype.Timestamptz `json:"resolved_at"`
+}
+
+type AlertRule struct {
+	ID              pgtype.UUID        `json:"id"`
+	Name            string             `json:"name"`
+	Kind            string             `json:"kind"`
+	VehicleID       pgtype.UUID        `json:"vehicle_id"`
+	SpeedLimit      float64            `json:"speed_limit"`
+	DurationSeconds int64              `json:"duration_seconds"`
+	Schedule        string             `json:"schedule"`
+	Enabled         bool               `json:"enabled"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+}
+
+type Device struct {
+	ID          pgtype.UUID        `json:"id"`
+	Identifier  string             `json:"identifier"`
+	VehicleID   pgtype.UUID        `json:"vehicle_id"`
+	PlateNumber string             `json:"plate_number"`
+	Name        string             `json:"name"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	TokenHash   []byte             `json:"token_hash"`
+	Model       string             `json:"model"`
+}
+
+type Geofence struct {
+	ID           pgtype.UUID        `json:"id"`
+	Name         string             `json:"name"`
+	Kind         string             `json:"kind"`
+	CenterLon    pgtype.Float8      `json:"center_lon"`
+	CenterLat    pgtype.Float8      `json:"center_lat"`
+	Radius       pgtype.Float8      `json:"radius"`
+	Polygon      string             `json:"polygon"`
+	DwellSeconds int64              `json:"dwell_seconds"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+}
+
+type GeofenceEvent struct {
+	ID         int64              `json:"id"`
+	GeofenceID pgtype.UUID        `json:"geofence_id"`
+	VehicleID  pgtype.UUID        `json:"vehicle_id"`
+	Kind       string             `json:"kind"`
+	OccurredAt pgtype.Timestamptz `json:"occurred_at"`
+	Longitude  pgtype.Float8      `json:"longitude"`
+	Latitude   pgtype.Float8      `json:"latitude"`
+}
+
+type GeofencePresence struct {
+	VehicleID     pgtype.UUID        `json:"vehicle_id"`
+	GeofenceID    pgtype.UUID        `json:"geofence_id"`
+	EnteredAt     pgtype.Timestamptz `json:"entered_at"`
+	DwellReported bool               `json:"dwell_reported"`
+}
+
 type Trip struct {
 	ID        pgtype.UUID        `json:"id"`
 	VehicleID pgtype.UUID        `json:"vehicle_id"`
@@ -18,7 +88,49 @@ type Trip struct {
 }
 
 type Vehicle struct {
-	ID          pgtype.UUID `json:"id"`
-	PlateNumber string      `json:"plate_number"`
-	LastStatus  string      `json:"last_status"`
+	ID           pgtype.UUID        `json:"id"`
+	PlateNumber  string             `json:"plate_number"`
+	LastStatus   string             `json:"last_status"`
+	LastStatusAt pgtype.Timestamptz `json:"last_status_at"`
+	Name         string             `json:"name"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+	ArchivedAt   pgtype.Timestamptz `json:"archived_at"`
+}
+
+type VehiclePosition struct {
+	ID         int64              `json:"id"`
+	VehicleID  pgtype.UUID        `json:"vehicle_id"`
+	RecordedAt pgtype.Timestamptz `json:"recorded_at"`
+	Longitude  pgtype.Float8      `json:"longitude"`
+	Latitude   pgtype.Float8      `json:"latitude"`
+	Speed      float64            `json:"speed"`
+	Status     string             `json:"status"`
+}
+
+type WebhookDelivery struct {
+	ID             pgtype.UUID        `json:"id"`
+	SubscriptionID pgtype.UUID        `json:"subscription_id"`
+	EventID        pgtype.UUID        `json:"event_id"`
+	EventType      string             `json:"event_type"`
+	Payload        string             `json:"payload"`
+	State          string             `json:"state"`
+	Attempts       int64              `json:"attempts"`
+	NextAttemptAt  pgtype.Timestamptz `json:"next_attempt_at"`
+	ResponseStatus pgtype.Int4        `json:"response_status"`
+	LastError      string             `json:"last_error"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	CompletedAt    pgtype.Timestamptz `json:"completed_at"`
+}
+
+type WebhookSubscription struct {
+	ID           pgtype.UUID        `json:"id"`
+	Url          string             `json:"url"`
+	Secret       string             `json:"secret"`
+	EventTypes   []string           `json:"event_types"`
+	Enabled      bool               `json:"enabled"`
+	FailingSince pgtype.Timestamptz `json:"failing_since"`
+	DisabledAt   pgtype.Timestamptz `json:"disabled_at"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
 }
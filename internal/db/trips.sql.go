@@ -11,6 +11,31 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const closeTrip = `-- name: CloseTrip :exec
+UPDATE trips
+SET end_time = $2,
+    mileage = $3,
+    avg_speed = $4
+WHERE id = $1
+`
+
+type CloseTripParams struct {
+	ID       pgtype.UUID        `json:"id"`
+	EndTime  pgtype.Timestamptz `json:"end_time"`
+	Mileage  pgtype.Float8      `json:"mileage"`
+	AvgSpeed pgtype.Float8      `json:"avg_speed"`
+}
+
+func (q *Queries) CloseTrip(ctx context.Context, arg CloseTripParams) error {
+	_, err := q.db.Exec(ctx, closeTrip,
+		arg.ID,
+		arg.EndTime,
+		arg.Mileage,
+		arg.AvgSpeed,
+	)
+	return err
+}
+
 const getTripByID = `-- name: GetTripByID :one
 SELECT id, vehicle_id, start_time, end_time, mileage, avg_speed
 FROM trips
@@ -31,16 +56,42 @@ func (q *Queries) GetTripByID(ctx context.Context, id pgtype.UUID) (Trip, error)
 	return i, err
 }
 
-const getTripsLast24Hours = `-- name: GetTripsLast24Hours :many
+const insertTrip = `-- name: InsertTrip :exec
+INSERT INTO trips (id, vehicle_id, start_time, end_time, mileage, avg_speed)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+type InsertTripParams struct {
+	ID        pgtype.UUID        `json:"id"`
+	VehicleID pgtype.UUID        `json:"vehicle_id"`
+	StartTime pgtype.Timestamptz `json:"start_time"`
+	EndTime   pgtype.Timestamptz `json:"end_time"`
+	Mileage   pgtype.Float8      `json:"mileage"`
+	AvgSpeed  pgtype.Float8      `json:"avg_speed"`
+}
+
+func (q *Queries) InsertTrip(ctx context.Context, arg InsertTripParams) error {
+	_, err := q.db.Exec(ctx, insertTrip,
+		arg.ID,
+		arg.VehicleID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.Mileage,
+		arg.AvgSpeed,
+	)
+	return err
+}
+
+const listOpenTrips = `-- name: ListOpenTrips :many
 SELECT id, vehicle_id, start_time, end_time, mileage, avg_speed
 FROM trips
-WHERE vehicle_id = $1
-  AND start_time >= now() - interval '24 hours'
-ORDER BY start_time DESC
+WHERE end_time IS NULL
+ORDER BY start_time ASC, id ASC
 `
 
-func (q *Queries) GetTripsLast24Hours(ctx context.Context, vehicleID pgtype.UUID) ([]Trip, error) {
-	rows, err := q.db.Query(ctx, getTripsLast24Hours, vehicleID)
+// Trips that were started but never closed, oldest first.
+func (q *Queries) ListOpenTrips(ctx context.Context) ([]Trip, error) {
+	rows, err := q.db.Query(ctx, listOpenTrips)
 	if err != nil {
 		return nil, err
 	}
@@ -66,47 +117,101 @@ func (q *Queries) GetTripsLast24Hours(ctx context.Context, vehicleID pgtype.UUID
 	return items, nil
 }
 
-const insertTrip = `-- name: InsertTrip :exec
-INSERT INTO trips (id, vehicle_id, start_time, end_time, mileage, avg_speed)
-VALUES ($1, $2, $3, $4, $5, $6)
+const listTripsByVehicle = `-- name: ListTripsByVehicle :many
+SELECT id, vehicle_id, start_time, end_time, mileage, avg_speed
+FROM trips
+WHERE vehicle_id = $1
+  AND start_time >= $2
+  AND start_time <= $3
+  AND COALESCE(mileage, 0) >= $4
+  AND ($5::timestamptz IS NULL
+       OR (start_time, id) < ($5, $6::uuid))
+ORDER BY start_time DESC, id DESC
+LIMIT $7
 `
 
-type InsertTripParams struct {
-	ID        pgtype.UUID        `json:"id"`
-	VehicleID pgtype.UUID        `json:"vehicle_id"`
-	StartTime pgtype.Timestamptz `json:"start_time"`
-	EndTime   pgtype.Timestamptz `json:"end_time"`
-	Mileage   pgtype.Float8      `json:"mileage"`
-	AvgSpeed  pgtype.Float8      `json:"avg_speed"`
+type ListTripsByVehicleParams struct {
+	VehicleID      pgtype.UUID        `json:"vehicle_id"`
+	From           pgtype.Timestamptz `json:"from"`
+	To             pgtype.Timestamptz `json:"to"`
+	MinMileage     float64            `json:"min_mileage"`
+	AfterStartTime pgtype.Timestamptz `json:"after_start_time"`
+	AfterID        pgtype.UUID        `json:"after_id"`
+	Limit          int32              `json:"limit"`
 }
 
-func (q *Queries) InsertTrip(ctx context.Context, arg InsertTripParams) error {
-	_, err := q.db.Exec(ctx, insertTrip,
-		arg.ID,
+// Newest first. A page continues after the trip given by after_start_time and
+// after_id.
+func (q *Queries) ListTripsByVehicle(ctx context.Context, arg ListTripsByVehicleParams) ([]Trip, error) {
+	rows, err := q.db.Query(ctx, listTripsByVehicle,
 		arg.VehicleID,
-		arg.StartTime,
-		arg.EndTime,
-		arg.Mileage,
-		arg.AvgSpeed,
+		arg.From,
+		arg.To,
+		arg.MinMileage,
+		arg.AfterStartTime,
+		arg.AfterID,
+		arg.Limit,
 	)
-	return err
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Trip
+	for rows.Next() {
+		var i Trip
+		if err := rows.Scan(
+			&i.ID,
+			&i.VehicleID,
+			&i.StartTime,
+			&i.EndTime,
+			&i.Mileage,
+			&i.AvgSpeed,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const listTripsByVehicle = `-- name: ListTripsByVehicle :many
+const listTripsByVehicleAsc = `-- name: ListTripsByVehicleAsc :many
 SELECT id, vehicle_id, start_time, end_time, mileage, avg_speed
 FROM trips
 WHERE vehicle_id = $1
-AND start_time >= $2 
-ORDER BY start_time DESC
+  AND start_time >= $2
+  AND start_time <= $3
+  AND COALESCE(mileage, 0) >= $4
+  AND ($5::timestamptz IS NULL
+       OR (start_time, id) > ($5, $6::uuid))
+ORDER BY start_time ASC, id ASC
+LIMIT $7
 `
 
-type ListTripsByVehicleParams struct {
-	VehicleID pgtype.UUID        `json:"vehicle_id"`
-	StartTime pgtype.Timestamptz `json:"start_time"`
+type ListTripsByVehicleAscParams struct {
+	VehicleID      pgtype.UUID        `json:"vehicle_id"`
+	From           pgtype.Timestamptz `json:"from"`
+	To             pgtype.Timestamptz `json:"to"`
+	MinMileage     float64            `json:"min_mileage"`
+	AfterStartTime pgtype.Timestamptz `json:"after_start_time"`
+	AfterID        pgtype.UUID        `json:"after_id"`
+	Limit          int32              `json:"limit"`
 }
 
-func (q *Queries) ListTripsByVehicle(ctx context.Context, arg ListTripsByVehicleParams) ([]Trip, error) {
-	rows, err := q.db.Query(ctx, listTripsByVehicle, arg.VehicleID, arg.StartTime)
+// Oldest first. A page continues after the trip given by after_start_time and
+// after_id.
+func (q *Queries) ListTripsByVehicleAsc(ctx context.Context, arg ListTripsByVehicleAscParams) ([]Trip, error) {
+	rows, err := q.db.Query(ctx, listTripsByVehicleAsc,
+		arg.VehicleID,
+		arg.From,
+		arg.To,
+		arg.MinMileage,
+		arg.AfterStartTime,
+		arg.AfterID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
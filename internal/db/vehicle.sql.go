@@ -11,67 +11,156 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const archiveVehicle = `-- name: ArchiveVehicle :one
+UPDATE vehicle
+SET archived_at = COALESCE(archived_at, now()),
+    updated_at = now()
+WHERE id = $1
+RETURNING id, plate_number, last_status, last_status_at, name, created_at, updated_at, archived_at
+`
+
+// Archiving an archived vehicle keeps its original archive time.
+func (q *Queries) ArchiveVehicle(ctx context.Context, id pgtype.UUID) (Vehicle, error) {
+	row := q.db.QueryRow(ctx, archiveVehicle, id)
+	var i Vehicle
+	err := row.Scan(
+		&i.ID,
+		&i.PlateNumber,
+		&i.LastStatus,
+		&i.LastStatusAt,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ArchivedAt,
+	)
+	return i, err
+}
+
 const createVehicle = `-- name: CreateVehicle :one
-INSERT INTO vehicle (id, plate_number, last_status)
+INSERT INTO vehicle (id, plate_number, name)
 VALUES ($1, $2, $3)
-ON CONFLICT (id) DO UPDATE
-SET last_status = EXCLUDED.last_status
-RETURNING id, plate_number, last_status
+RETURNING id, plate_number, last_status, last_status_at, name, created_at, updated_at, archived_at
 `
 
 type CreateVehicleParams struct {
 	ID          pgtype.UUID `json:"id"`
 	PlateNumber string      `json:"plate_number"`
-	LastStatus  string      `json:"last_status"`
+	Name        string      `json:"name"`
 }
 
 func (q *Queries) CreateVehicle(ctx context.Context, arg CreateVehicleParams) (Vehicle, error) {
-	row := q.db.QueryRow(ctx, createVehicle, arg.ID, arg.PlateNumber, arg.LastStatus)
+	row := q.db.QueryRow(ctx, createVehicle, arg.ID, arg.PlateNumber, arg.Name)
+	var i Vehicle
+	err := row.Scan(
+		&i.ID,
+		&i.PlateNumber,
+		&i.LastStatus,
+		&i.LastStatusAt,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ArchivedAt,
+	)
+	return i, err
+}
+
+const getVehicle = `-- name: GetVehicle :one
+SELECT id, plate_number, last_status, last_status_at, name, created_at, updated_at, archived_at
+FROM vehicle
+WHERE id = $1
+`
+
+func (q *Queries) GetVehicle(ctx context.Context, id pgtype.UUID) (Vehicle, error) {
+	row := q.db.QueryRow(ctx, getVehicle, id)
 	var i Vehicle
-	err := row.Scan(&i.ID, &i.PlateNumber, &i.LastStatus)
+	err := row.Scan(
+		&i.ID,
+		&i.PlateNumber,
+		&i.LastStatus,
+		&i.LastStatusAt,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ArchivedAt,
+	)
 	return i, err
 }
 
 const getVehicleByPlate = `-- name: GetVehicleByPlate :one
-SELECT id, plate_number, last_status
+SELECT id, plate_number, last_status, last_status_at, name, created_at, updated_at, archived_at
 FROM vehicle
 WHERE plate_number = $1
+ORDER BY archived_at IS NOT NULL, archived_at DESC
+LIMIT 1
 `
 
+// Prefers the active vehicle with the plate over archived ones.
 func (q *Queries) GetVehicleByPlate(ctx context.Context, plateNumber string) (Vehicle, error) {
 	row := q.db.QueryRow(ctx, getVehicleByPlate, plateNumber)
 	var i Vehicle
-	err := row.Scan(&i.ID, &i.PlateNumber, &i.LastStatus)
+	err := row.Scan(
+		&i.ID,
+		&i.PlateNumber,
+		&i.LastStatus,
+		&i.LastStatusAt,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ArchivedAt,
+	)
 	return i, err
 }
 
 const getVehicleStatus = `-- name: GetVehicleStatus :one
-SELECT last_status
+SELECT last_status, last_status_at
 FROM vehicle
 WHERE id = $1
 `
 
-func (q *Queries) GetVehicleStatus(ctx context.Context, id pgtype.UUID) (string, error) {
+type GetVehicleStatusRow struct {
+	LastStatus   string             `json:"last_status"`
+	LastStatusAt pgtype.Timestamptz `json:"last_status_at"`
+}
+
+func (q *Queries) GetVehicleStatus(ctx context.Context, id pgtype.UUID) (GetVehicleStatusRow, error) {
 	row := q.db.QueryRow(ctx, getVehicleStatus, id)
-	var last_status string
-	err := row.Scan(&last_status)
-	return last_status, err
+	var i GetVehicleStatusRow
+	err := row.Scan(&i.LastStatus, &i.LastStatusAt)
+	return i, err
+}
+
+const isVehicleActive = `-- name: IsVehicleActive :one
+SELECT EXISTS (
+    SELECT 1
+    FROM vehicle
+    WHERE id = $1
+      AND archived_at IS NULL
+)
+`
+
+func (q *Queries) IsVehicleActive(ctx context.Context, id pgtype.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, isVehicleActive, id)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
 }
 
 const listVehicles = `-- name: ListVehicles :many
-SELECT id, plate_number, last_status
+SELECT id, plate_number, last_status, last_status_at, name, created_at, updated_at, archived_at
 FROM vehicle
-ORDER BY ID DESC
-LIMIT $1 OFFSET $2
+WHERE $1::boolean OR archived_at IS NULL
+ORDER BY created_at, id
+LIMIT $2 OFFSET $3
 `
 
 type ListVehiclesParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+	IncludeArchived bool  `json:"include_archived"`
+	Limit           int32 `json:"limit"`
+	Offset          int32 `json:"offset"`
 }
 
 func (q *Queries) ListVehicles(ctx context.Context, arg ListVehiclesParams) ([]Vehicle, error) {
-	rows, err := q.db.Query(ctx, listVehicles, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listVehicles, arg.IncludeArchived, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -79,7 +168,16 @@ func (q *Queries) ListVehicles(ctx context.Context, arg ListVehiclesParams) ([]V
 	var items []Vehicle
 	for rows.Next() {
 		var i Vehicle
-		if err := rows.Scan(&i.ID, &i.PlateNumber, &i.LastStatus); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.PlateNumber,
+			&i.LastStatus,
+			&i.LastStatusAt,
+			&i.Name,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ArchivedAt,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -90,21 +188,78 @@ func (q *Queries) ListVehicles(ctx context.Context, arg ListVehiclesParams) ([]V
 	return items, nil
 }
 
-const upsertVehicleStatus = `-- name: UpsertVehicleStatus :exec
-INSERT INTO vehicle (id, plate_number, last_status)
-VALUES ($1, $2, $3::JSONB)
+const updateVehicle = `-- name: UpdateVehicle :one
+UPDATE vehicle
+SET plate_number = $1,
+    name = $2,
+    updated_at = now()
+WHERE id = $3
+RETURNING id, plate_number, last_status, last_status_at, name, created_at, updated_at, archived_at
+`
+
+type UpdateVehicleParams struct {
+	PlateNumber string      `json:"plate_number"`
+	Name        string      `json:"name"`
+	ID          pgtype.UUID `json:"id"`
+}
+
+func (q *Queries) UpdateVehicle(ctx context.Context, arg UpdateVehicleParams) (Vehicle, error) {
+	row := q.db.QueryRow(ctx, updateVehicle, arg.PlateNumber, arg.Name, arg.ID)
+	var i Vehicle
+	err := row.Scan(
+		&i.ID,
+		&i.PlateNumber,
+		&i.LastStatus,
+		&i.LastStatusAt,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ArchivedAt,
+	)
+	return i, err
+}
+
+const upsertVehicleStatus = `-- name: UpsertVehicleStatus :execrows
+INSERT INTO vehicle (id, plate_number, last_status, last_status_at)
+VALUES (
+    $1,
+    CASE WHEN EXISTS (
+        SELECT 1
+        FROM vehicle
+        WHERE plate_number = $2
+          AND archived_at IS NULL
+          AND id <> $1
+    ) THEN '' ELSE $2 END,
+    $3::JSONB,
+    $4
+)
 ON CONFLICT (id) DO UPDATE
-SET plate_number = EXCLUDED.plate_number,
-    last_status  = EXCLUDED.last_status
+SET plate_number   = COALESCE(NULLIF(EXCLUDED.plate_number, ''), vehicle.plate_number),
+    last_status    = EXCLUDED.last_status,
+    last_status_at = EXCLUDED.last_status_at
+WHERE vehicle.last_status_at IS NULL
+   OR vehicle.last_status_at <= EXCLUDED.last_status_at
 `
 
 type UpsertVehicleStatusParams struct {
-	ID          pgtype.UUID `json:"id"`
-	PlateNumber string      `json:"plate_number"`
-	Column3     string      `json:"column_3"`
+	ID           pgtype.UUID        `json:"id"`
+	PlateNumber  string             `json:"plate_number"`
+	Column3      string             `json:"column_3"`
+	LastStatusAt pgtype.Timestamptz `json:"last_status_at"`
 }
 
-func (q *Queries) UpsertVehicleStatus(ctx context.Context, arg UpsertVehicleStatusParams) error {
-	_, err := q.db.Exec(ctx, upsertVehicleStatus, arg.ID, arg.PlateNumber, arg.Column3)
-	return err
+// Only a reading at least as new as the stored one replaces it. A reading
+// without a plate number, or with one another active vehicle has, keeps the
+// registered one.
+func (q *Queries) UpsertVehicleStatus(ctx context.Context, arg UpsertVehicleStatusParams) (int64, error) {
+	result, err := q.db.Exec(ctx, upsertVehicleStatus,
+		arg.ID,
+		arg.PlateNumber,
+		arg.Column3,
+		arg.LastStatusAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
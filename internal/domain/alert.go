@@ -0,0 +1,259 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AlertRuleKind is the condition an alert rule watches for.
+type AlertRuleKind string
+
+const (
+	// AlertSpeeding fires when a vehicle reports a speed above SpeedLimit for
+	// at least DurationSeconds.
+	AlertSpeeding AlertRuleKind = "speeding"
+	// AlertNoUpdate fires when a vehicle has not reported for DurationSeconds.
+	AlertNoUpdate AlertRuleKind = "no_update"
+	// AlertOutsideHours fires when a vehicle moves faster than SpeedLimit
+	// outside the hours its Schedule allows.
+	AlertOutsideHours AlertRuleKind = "outside_hours"
+)
+
+// Valid reports whether k is a known rule kind.
+func (k AlertRuleKind) Valid() bool {
+	switch k {
+	case AlertSpeeding, AlertNoUpdate, AlertOutsideHours:
+		return true
+	}
+	return false
+}
+
+// AlertRule is a condition evaluated over the telemetry stream of one vehicle,
+// or of the whole fleet if VehicleID is nil.
+type AlertRule struct {
+	ID              uuid.UUID      `json:"id"`
+	Name            string         `json:"name"`
+	Kind            AlertRuleKind  `json:"kind"`
+	VehicleID       *uuid.UUID     `json:"vehicle_id,omitempty"`
+	SpeedLimit      float64        `json:"speed_limit,omitempty"`      // km/h
+	DurationSeconds int            `json:"duration_seconds,omitempty"` // how long the condition must hold
+	Schedule        *AlertSchedule `json:"schedule,omitempty"`
+	Enabled         bool           `json:"enabled"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+}
+
+// AppliesTo reports whether the rule is enabled for the vehicle.
+func (r AlertRule) AppliesTo(vehicleID uuid.UUID) bool {
+	return r.Enabled && (r.VehicleID == nil || *r.VehicleID == vehicleID)
+}
+
+// Duration is how long the rule's condition must hold before it fires.
+func (r AlertRule) Duration() time.Duration {
+	return time.Duration(r.DurationSeconds) * time.Second
+}
+
+// Validate checks that the rule can be evaluated. It returns a
+// *ValidationError listing every problem, or nil.
+func (r AlertRule) Validate() error {
+	verr := &ValidationError{}
+	if strings.TrimSpace(r.Name) == "" {
+		verr.add("name", "is required")
+	}
+	if r.VehicleID != nil && *r.VehicleID == uuid.Nil {
+		verr.add("vehicle_id", "must not be the nil UUID")
+	}
+	if !isFinite(r.SpeedLimit) || r.SpeedLimit < 0 {
+		verr.add("speed_limit", "must not be negative")
+	}
+	if r.DurationSeconds < 0 {
+		verr.add("duration_seconds", "must not be negative")
+	}
+
+	switch r.Kind {
+	case AlertSpeeding:
+		if r.SpeedLimit <= 0 {
+			verr.add("speed_limit", "must be greater than 0")
+		}
+	case AlertNoUpdate:
+		if r.DurationSeconds <= 0 {
+			verr.add("duration_seconds", "must be greater than 0")
+		}
+	case AlertOutsideHours:
+		if r.Schedule == nil {
+			verr.add("schedule", "is required")
+		} else {
+			r.Schedule.validate("schedule.", verr)
+		}
+	default:
+		verr.add("kind", "must be speeding, no_update or outside_hours")
+	}
+
+	if len(verr.Fields) > 0 {
+		return verr
+	}
+	return nil
+}
+
+// AlertSchedule is a daily window of allowed hours. Start and End are
+// "HH:MM" in Timezone (default UTC); an End before Start spans midnight.
+// Days restricts the window to the listed weekdays (0 is Sunday) of the
+// reading's local date; empty means every day.
+type AlertSchedule struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Days     []int  `json:"days,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// Allows reports whether t falls inside the allowed hours. A schedule that
+// fails validation allows nothing. It parses the schedule on every call;
+// schedules checked against many readings are compiled once instead.
+func (s AlertSchedule) Allows(t time.Time) bool {
+	c, err := s.Compile()
+	if err != nil {
+		return false
+	}
+	return c.Allows(t)
+}
+
+// Compile parses the schedule and loads its time zone. It fails for a
+// schedule that does not validate.
+func (s AlertSchedule) Compile() (*CompiledSchedule, error) {
+	start, err := parseClock(s.Start)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseClock(s.End)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := s.location()
+	if err != nil {
+		return nil, err
+	}
+	return &CompiledSchedule{start: start, end: end, days: s.Days, loc: loc}, nil
+}
+
+func (s AlertSchedule) location() (*time.Location, error) {
+	if s.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(s.Timezone)
+}
+
+// CompiledSchedule is an AlertSchedule ready to check readings against. A nil
+// CompiledSchedule, standing for a schedule that failed to compile, allows
+// nothing.
+type CompiledSchedule struct {
+	start, end int // minutes since midnight
+	days       []int
+	loc        *time.Location
+}
+
+// Allows reports whether t falls inside the allowed hours.
+func (c *CompiledSchedule) Allows(t time.Time) bool {
+	if c == nil {
+		return false
+	}
+
+	t = t.In(c.loc)
+	if len(c.days) > 0 && !containsInt(c.days, int(t.Weekday())) {
+		return false
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	if c.start <= c.end {
+		return minute >= c.start && minute < c.end
+	}
+	return minute >= c.start || minute < c.end
+}
+
+func (s AlertSchedule) validate(prefix string, verr *ValidationError) {
+	if _, err := parseClock(s.Start); err != nil {
+		verr.add(prefix+"start", "must be HH:MM")
+	}
+	if _, err := parseClock(s.End); err != nil {
+		verr.add(prefix+"end", "must be HH:MM")
+	}
+	for i, d := range s.Days {
+		if d < 0 || d > 6 {
+			verr.add(indexField(prefix+"days", i), "must be between 0 (Sunday) and 6 (Saturday)")
+		}
+	}
+	if _, err := s.location(); err != nil {
+		verr.add(prefix+"timezone", "must be an IANA time zone")
+	}
+}
+
+// parseClock turns "HH:MM" into minutes since midnight. "24:00" is accepted
+// as the end of the day.
+func parseClock(v string) (int, error) {
+	h, m, ok := strings.Cut(v, ":")
+	if !ok || len(h) != 2 || len(m) != 2 {
+		return 0, strconv.ErrSyntax
+	}
+	hour, err := strconv.Atoi(h)
+	if err != nil {
+		return 0, err
+	}
+	minute, err := strconv.Atoi(m)
+	if err != nil {
+		return 0, err
+	}
+	if hour < 0 || minute < 0 || minute > 59 || hour > 24 || (hour == 24 && minute != 0) {
+		return 0, strconv.ErrRange
+	}
+	return hour*60 + minute, nil
+}
+
+func containsInt(values []int, v int) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// AlertState is the lifecycle state of an alert.
+type AlertState string
+
+const (
+	AlertOpen         AlertState = "open"
+	AlertAcknowledged AlertState = "acknowledged"
+	AlertResolved     AlertState = "resolved"
+)
+
+// Alert is a firing of an alert rule for one vehicle. It opens when the
+// rule's condition has held long enough, may be acknowledged by an operator,
+// and is resolved once the condition clears or by hand. Value is the observed
+// speed in km/h, or for no_update alerts the seconds since the last report.
+type Alert struct {
+	ID             uuid.UUID     `json:"id"`
+	RuleID         uuid.UUID     `json:"rule_id"`
+	VehicleID      uuid.UUID     `json:"vehicle_id"`
+	Kind           AlertRuleKind `json:"kind"`
+	State          AlertState    `json:"state"`
+	Message        string        `json:"message"`
+	Value          float64       `json:"value"`
+	Location       []float64     `json:"location,omitempty"` // [longitude, latitude]
+	OpenedAt       time.Time     `json:"opened_at"`
+	AcknowledgedAt *time.Time    `json:"acknowledged_at,omitempty"`
+	ResolvedAt     *time.Time    `json:"resolved_at,omitempty"`
+}
+
+// AlertQuery selects a page of alerts opened between From and To, newest
+// first. Nil IDs and an empty State do not filter.
+type AlertQuery struct {
+	VehicleID *uuid.UUID
+	RuleID    *uuid.UUID
+	State     AlertState
+	From      time.Time
+	To        time.Time
+	Limit     int32
+	Offset    int32
+}
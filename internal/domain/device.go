@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxDeviceIdentifierLength bounds the identifier a device reports.
+const MaxDeviceIdentifierLength = 64
+
+// Device is a tracker that reports over one of the device protocols, such as
+// NMEA over TCP or LoRaWAN, rather than through the ingest API. Its readings
+// are ingested for VehicleID with PlateNumber.
+type Device struct {
+	ID uuid.UUID `json:"id"`
+	// Identifier is what the device reports itself as, e.g. its IMEI or
+	// DevEUI.
+	Identifier  string    `json:"identifier"`
+	VehicleID   uuid.UUID `json:"vehicle_id"`
+	PlateNumber string    `json:"plate_number"`
+	Name        string    `json:"name"`
+	// Model is the tracker model. It selects the decoder of LoRaWAN
+	// payloads; empty uses the payload the network server decoded.
+	Model     string    `json:"model"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Token authenticates HTTP ingest by the device. It is only set when
+	// the token is issued; TokenHash, its SHA-256, is what is stored.
+	Token     string `json:"token,omitempty"`
+	TokenHash []byte `json:"-"`
+}
+
+// Validate checks that readings of the device can be ingested. It returns a
+// *ValidationError listing every problem, or nil.
+func (d Device) Validate() error {
+	verr := &ValidationError{}
+	if d.Identifier == "" {
+		verr.add("identifier", "is required")
+	} else if len(d.Identifier) > MaxDeviceIdentifierLength {
+		verr.add("identifier", "must be at most 64 characters")
+	}
+	if d.VehicleID == uuid.Nil {
+		verr.add("vehicle_id", "is required")
+	}
+
+	if len(verr.Fields) > 0 {
+		return verr
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "errors"
+
+// ErrDuplicateMessage is returned when an ingest request repeats a message ID
+// that was already processed within the deduplication window.
+var ErrDuplicateMessage = errors.New("duplicate message")
+
+// ErrNotFound is returned when a requested record does not exist.
+var ErrNotFound = errors.New("not found")
+
+// ErrAlertResolved is returned when acknowledging an alert that is already
+// resolved.
+var ErrAlertResolved = errors.New("alert is already resolved")
+
+// ErrDeviceExists is returned when registering a device identifier that
+// another device already uses.
+var ErrDeviceExists = errors.New("device identifier is already registered")
+
+// ErrInvalidDeviceToken is returned when a device authenticates with an
+// unknown identifier or a token that does not match.
+var ErrInvalidDeviceToken = errors.New("invalid device token")
+
+// ErrVehicleExists is returned when registering a vehicle ID that is already
+// in use, or a plate number that an active vehicle already has.
+var ErrVehicleExists = errors.New("vehicle is already registered")
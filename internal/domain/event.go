@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType names a kind of fleet event.
+type EventType string
+
+const (
+	// EventStatusUpdated carries a VehicleStatus that became a vehicle's last
+	// status.
+	EventStatusUpdated EventType = "status.updated"
+	// EventTripStarted and EventTripEnded carry the Trip.
+	EventTripStarted EventType = "trip.started"
+	EventTripEnded   EventType = "trip.ended"
+	// EventAlertOpened, EventAlertAcknowledged and EventAlertResolved carry
+	// the Alert after the transition.
+	EventAlertOpened       EventType = "alert.opened"
+	EventAlertAcknowledged EventType = "alert.acknowledged"
+	EventAlertResolved     EventType = "alert.resolved"
+	// EventGeofenceEntered, EventGeofenceExited and EventGeofenceDwell carry
+	// the GeofenceEvent.
+	EventGeofenceEntered EventType = "geofence.entered"
+	EventGeofenceExited  EventType = "geofence.exited"
+	EventGeofenceDwell   EventType = "geofence.dwell"
+)
+
+// EventTypes lists every event type.
+var EventTypes = []EventType{
+	EventStatusUpdated,
+	EventTripStarted,
+	EventTripEnded,
+	EventAlertOpened,
+	EventAlertAcknowledged,
+	EventAlertResolved,
+	EventGeofenceEntered,
+	EventGeofenceExited,
+	EventGeofenceDwell,
+}
+
+// GeofenceEventTypes maps the kinds of geofence transitions to their event
+// types.
+var GeofenceEventTypes = map[GeofenceEventKind]EventType{
+	GeofenceEnter: EventGeofenceEntered,
+	GeofenceExit:  EventGeofenceExited,
+	GeofenceDwell: EventGeofenceDwell,
+}
+
+// Valid reports whether t is a known event type.
+func (t EventType) Valid() bool {
+	for _, known := range EventTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is something that happened to a vehicle, as delivered to external
+// subscribers. Data holds the record the event is about. GeofenceID and
+// AlertKind repeat the fence of geofence events and the rule kind of alert
+// events, so subscribers can route them without decoding Data.
+type Event struct {
+	ID         uuid.UUID     `json:"id"`
+	Type       EventType     `json:"type"`
+	VehicleID  uuid.UUID     `json:"vehicle_id"`
+	GeofenceID *uuid.UUID    `json:"geofence_id,omitempty"`
+	AlertKind  AlertRuleKind `json:"alert_kind,omitempty"`
+	OccurredAt time.Time     `json:"occurred_at"`
+	Data       any           `json:"data"`
+}
+
+// NewEvent creates an event with a fresh ID.
+func NewEvent(eventType EventType, vehicleID uuid.UUID, occurredAt time.Time, data any) Event {
+	return Event{
+		ID:         uuid.New(),
+		Type:       eventType,
+		VehicleID:  vehicleID,
+		OccurredAt: occurredAt,
+		Data:       data,
+	}
+}
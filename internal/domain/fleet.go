@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BoundingBox is a rectangle of longitudes and latitudes. A box whose MinLon
+// is greater than its MaxLon crosses the antimeridian.
+type BoundingBox struct {
+	MinLon float64
+	MinLat float64
+	MaxLon float64
+	MaxLat float64
+}
+
+// Validate checks that the corners are valid coordinates and the box is not
+// upside down.
+func (b BoundingBox) Validate() error {
+	verr := &ValidationError{}
+	for _, lon := range []float64{b.MinLon, b.MaxLon} {
+		if !(lon >= -180 && lon <= 180) {
+			verr.add("bbox", "longitudes must be within [-180, 180]")
+			break
+		}
+	}
+	for _, lat := range []float64{b.MinLat, b.MaxLat} {
+		if !(lat >= -90 && lat <= 90) {
+			verr.add("bbox", "latitudes must be within [-90, 90]")
+			break
+		}
+	}
+	if b.MinLat > b.MaxLat {
+		verr.add("bbox", "min latitude must not be above max latitude")
+	}
+
+	if len(verr.Fields) > 0 {
+		return verr
+	}
+	return nil
+}
+
+// Contains reports whether the point lies in the box, edges included.
+func (b BoundingBox) Contains(lon, lat float64) bool {
+	if lat < b.MinLat || lat > b.MaxLat {
+		return false
+	}
+	if b.MinLon <= b.MaxLon {
+		return lon >= b.MinLon && lon <= b.MaxLon
+	}
+	return lon >= b.MinLon || lon <= b.MaxLon
+}
+
+// VehicleSnapshot is the latest status of one vehicle of the fleet.
+type VehicleSnapshot struct {
+	VehicleID uuid.UUID     `json:"vehicle_id"`
+	Status    VehicleStatus `json:"status"`
+}
+
+// FleetQuery selects a page of the fleet's latest statuses. Empty fields match
+// every vehicle; a BBox only matches vehicles with a location.
+type FleetQuery struct {
+	VehicleIDs   []uuid.UUID
+	BBox         *BoundingBox
+	UpdatedSince time.Time
+	Limit        int32
+	Offset       int32
+}
+
+// MaxNearbyLatitude is the highest latitude, north or south, the position
+// index covers. Nearby searches around points closer to the poles are
+// rejected, as the index cannot search from there.
+const MaxNearbyLatitude = 85.05112878
+
+// NearbyQuery selects the vehicles whose latest position is closest to a
+// point, nearest first.
+type NearbyQuery struct {
+	Longitude float64
+	Latitude  float64
+	// Radius limits the search to this many metres around the point. Zero
+	// searches the whole fleet.
+	Radius       float64
+	Limit        int32
+	UpdatedSince time.Time
+}
+
+// Validate checks the point and radius of the query. It returns a
+// *ValidationError listing every problem, or nil.
+func (q NearbyQuery) Validate() error {
+	verr := &ValidationError{}
+	if !isFinite(q.Longitude) || q.Longitude < -180 || q.Longitude > 180 {
+		verr.add("lon", "must be between -180 and 180")
+	}
+	if !isFinite(q.Latitude) || q.Latitude < -MaxNearbyLatitude || q.Latitude > MaxNearbyLatitude {
+		verr.add("lat", "must be between -85.05112878 and 85.05112878")
+	}
+	if !isFinite(q.Radius) || q.Radius < 0 {
+		verr.add("radius", "must not be negative")
+	}
+
+	if len(verr.Fields) > 0 {
+		return verr
+	}
+	return nil
+}
+
+// NearbyVehicle is a vehicle found by a NearbyQuery.
+type NearbyVehicle struct {
+	VehicleID uuid.UUID     `json:"vehicle_id"`
+	Distance  float64       `json:"distance"` // metres from the query point
+	Status    VehicleStatus `json:"status"`
+}
@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/pkg/geo"
+	"github.com/google/uuid"
+)
+
+// GeofenceKind is the shape of a geofence.
+type GeofenceKind string
+
+const (
+	GeofenceCircle  GeofenceKind = "circle"
+	GeofencePolygon GeofenceKind = "polygon"
+)
+
+// DefaultDwellSeconds is used when a geofence is created without dwell_seconds.
+const DefaultDwellSeconds = 600
+
+// Geofence is a zone vehicles are tracked against. Circles use Center and
+// Radius (metres); polygons use Polygon, a ring of [longitude, latitude]
+// vertices. A dwell event fires once a vehicle has stayed inside for
+// DwellSeconds; zero disables dwell events.
+type Geofence struct {
+	ID           uuid.UUID    `json:"id"`
+	Name         string       `json:"name"`
+	Kind         GeofenceKind `json:"kind"`
+	Center       []float64    `json:"center,omitempty"`
+	Radius       float64      `json:"radius,omitempty"`
+	Polygon      [][]float64  `json:"polygon,omitempty"`
+	DwellSeconds int          `json:"dwell_seconds"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+// Contains reports whether the point lies inside the geofence.
+func (g Geofence) Contains(lon, lat float64) bool {
+	switch g.Kind {
+	case GeofenceCircle:
+		if len(g.Center) != 2 {
+			return false
+		}
+		return geo.DistanceKm(g.Center[0], g.Center[1], lon, lat)*1000 <= g.Radius
+	case GeofencePolygon:
+		return geo.PointInPolygon(lon, lat, g.Polygon)
+	}
+	return false
+}
+
+// DwellTime is how long a vehicle must stay inside before a dwell event fires.
+func (g Geofence) DwellTime() time.Duration {
+	return time.Duration(g.DwellSeconds) * time.Second
+}
+
+// Validate checks that the geofence describes a usable zone. It returns a
+// *ValidationError listing every problem, or nil.
+func (g Geofence) Validate() error {
+	verr := &ValidationError{}
+	if strings.TrimSpace(g.Name) == "" {
+		verr.add("name", "is required")
+	}
+
+	switch g.Kind {
+	case GeofenceCircle:
+		if len(g.Center) != 2 {
+			verr.add("center", "must be [longitude, latitude]")
+		} else {
+			validateCoordinates("center", g.Center[0], g.Center[1], verr)
+		}
+		if !isFinite(g.Radius) || g.Radius <= 0 {
+			verr.add("radius", "must be greater than 0")
+		}
+	case GeofencePolygon:
+		if len(g.Polygon) < 3 {
+			verr.add("polygon", "must have at least 3 vertices")
+		}
+		for i, v := range g.Polygon {
+			if len(v) != 2 {
+				verr.add(indexField("polygon", i), "must be [longitude, latitude]")
+				continue
+			}
+			validateCoordinates(indexField("polygon", i), v[0], v[1], verr)
+		}
+	default:
+		verr.add("kind", "must be circle or polygon")
+	}
+
+	if g.DwellSeconds < 0 {
+		verr.add("dwell_seconds", "must not be negative")
+	}
+
+	if len(verr.Fields) > 0 {
+		return verr
+	}
+	return nil
+}
+
+// GeofenceEventKind is the transition a geofence event records.
+type GeofenceEventKind string
+
+const (
+	GeofenceEnter GeofenceEventKind = "enter"
+	GeofenceExit  GeofenceEventKind = "exit"
+	GeofenceDwell GeofenceEventKind = "dwell"
+)
+
+// GeofenceEvent records a vehicle entering, leaving or dwelling in a geofence.
+type GeofenceEvent struct {
+	ID         int64             `json:"id"`
+	GeofenceID uuid.UUID         `json:"geofence_id"`
+	VehicleID  uuid.UUID         `json:"vehicle_id"`
+	Kind       GeofenceEventKind `json:"kind"`
+	OccurredAt time.Time         `json:"occurred_at"`
+	Location   []float64         `json:"location,omitempty"` // [longitude, latitude]
+}
+
+// GeofencePresence records that a vehicle is currently inside a geofence.
+type GeofencePresence struct {
+	VehicleID     uuid.UUID
+	GeofenceID    uuid.UUID
+	EnteredAt     time.Time
+	DwellReported bool
+}
+
+// GeofenceEventQuery selects a page of geofence events between From and To,
+// newest first. Nil IDs do not filter.
+type GeofenceEventQuery struct {
+	VehicleID  *uuid.UUID
+	GeofenceID *uuid.UUID
+	From       time.Time
+	To         time.Time
+	Limit      int32
+	Offset     int32
+}
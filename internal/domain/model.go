@@ -1,24 +1,67 @@
 package domain
 
 import (
+	"encoding/base64"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-// Vehicle represents a vehicle in the system.
-type Vehicle struct {
-	ID          uuid.UUID      `json:"id"`
-	PlateNumber string         `json:"plate_number"`
-	LastStatus  *VehicleStatus `json:"last_status,omitempty"`
-}
-
-// VehicleStatus represents the real-time status of a vehicle.
+// VehicleStatus represents the real-time status of a vehicle. The telemetry
+// fields below Timestamp are optional: nil means the device did not report
+// them.
 type VehicleStatus struct {
 	Location  []float64 `json:"location"` // [longitude, latitude]
 	Speed     float64   `json:"speed"`
 	Timestamp time.Time `json:"timestamp"`
+
+	Heading     *float64 `json:"heading,omitempty"`      // degrees clockwise from north, [0, 360)
+	Altitude    *float64 `json:"altitude,omitempty"`     // metres above sea level
+	Accuracy    *float64 `json:"accuracy,omitempty"`     // horizontal accuracy in metres
+	HDOP        *float64 `json:"hdop,omitempty"`         // horizontal dilution of precision
+	Satellites  *int     `json:"satellites,omitempty"`   // satellites in use
+	Ignition    *bool    `json:"ignition,omitempty"`     // engine ignition on
+	Odometer    *float64 `json:"odometer,omitempty"`     // total distance in kilometres
+	FuelLevel   *float64 `json:"fuel_level,omitempty"`   // percent, [0, 100]
+	EngineHours *float64 `json:"engine_hours,omitempty"` // total engine running time in hours
+}
+
+// Coordinates returns the longitude and latitude of the status, and false
+// when no location was reported.
+func (s VehicleStatus) Coordinates() (lon, lat float64, ok bool) {
+	if len(s.Location) != 2 {
+		return 0, 0, false
+	}
+	return s.Location[0], s.Location[1], true
+}
+
+// Position is a single historical status reading of a vehicle.
+type Position struct {
+	ID        int64     `json:"id"`
+	VehicleID uuid.UUID `json:"vehicle_id"`
+	VehicleStatus
+}
+
+// PositionQuery selects a page of a vehicle's position history between From
+// and To, inclusive, in chronological order. After, if set, continues the
+// listing after that position and takes the place of Offset.
+type PositionQuery struct {
+	VehicleID uuid.UUID
+	From      time.Time
+	To        time.Time
+	After     *PositionCursor
+	Limit     int32
+	Offset    int32
+}
+
+// PositionCursor is the position of a reading in a listing, used to fetch the
+// page that follows it.
+type PositionCursor struct {
+	RecordedAt time.Time
+	ID         int64
 }
 
 // Trip represents a single journey made by a vehicle.
@@ -31,9 +74,96 @@ type Trip struct {
 	AvgSpeed  float64            `json:"avg_speed"`
 }
 
+// SortOrder is the order of a listing by time.
+type SortOrder string
+
+const (
+	SortDescending SortOrder = "desc"
+	SortAscending  SortOrder = "asc"
+)
+
+// TripQuery selects a page of a vehicle's trips that started between From and
+// To, inclusive. After, if set, continues the listing after that trip.
+type TripQuery struct {
+	VehicleID  uuid.UUID
+	From       time.Time
+	To         time.Time
+	MinMileage float64
+	Order      SortOrder
+	After      *TripCursor
+	Limit      int32
+}
+
+// TripCursor is the position of a trip in a listing, used to fetch the page
+// that follows it.
+type TripCursor struct {
+	StartTime time.Time
+	ID        uuid.UUID
+}
+
+// String encodes the cursor as an opaque, URL-safe token.
+func (c TripCursor) String() string {
+	raw := c.StartTime.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseTripCursor decodes a token produced by TripCursor.String.
+func ParseTripCursor(token string) (*TripCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, errors.New("malformed trip cursor")
+	}
+	startTime, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return nil, err
+	}
+	tripID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, err
+	}
+	return &TripCursor{StartTime: startTime, ID: tripID}, nil
+}
+
+// TripPage is a page of trips. Next is set when more trips may follow.
+type TripPage struct {
+	Trips []Trip
+	Next  *TripCursor
+}
+
+// TripPositions are the positions recorded during a trip in chronological
+// order. Truncated is set when only the first of them were read.
+type TripPositions struct {
+	Positions []Position
+	Truncated bool
+}
+
 // IngestRequest is the structure for incoming data from the /ingest endpoint.
+// MessageID is an optional device-assigned message or sequence ID; a message
+// seen again within the deduplication window is acknowledged but not
+// processed twice.
 type IngestRequest struct {
 	VehicleID   pgtype.UUID   `json:"vehicle_id"`
 	Status      VehicleStatus `json:"status"`
 	PlateNumber string        `json:"plate_number"`
+	MessageID   string        `json:"message_id,omitempty"`
+}
+
+// Outcomes of a single item of a batch ingest.
+const (
+	IngestAccepted  = "accepted"
+	IngestRejected  = "rejected"
+	IngestDuplicate = "duplicate"
+)
+
+// IngestResult reports the outcome of one item of a batch ingest. Index is the
+// position of the item in the uploaded batch.
+type IngestResult struct {
+	Index  int          `json:"index"`
+	Status string       `json:"status"`
+	Error  string       `json:"error,omitempty"`
+	Fields []FieldError `json:"fields,omitempty"`
 }
@@ -9,13 +9,171 @@ import (
 
 // VehicleRepository defines the interface for database operations related to vehicles and trips.
 type VehicleRepository interface {
-	UpdateVehicleStatus(ctx context.Context, vehicleID uuid.UUID, plateNumber string, status VehicleStatus) error
-	FindTripsByVehicleID(ctx context.Context, vehicleID uuid.UUID, since time.Time) ([]Trip, error)
+	// UpdateVehicleStatus stores status as the vehicle's last status unless a
+	// newer one is already stored, and reports whether it was applied.
+	UpdateVehicleStatus(ctx context.Context, vehicleID uuid.UUID, plateNumber string, status VehicleStatus) (bool, error)
+	ListTrips(ctx context.Context, query TripQuery) ([]Trip, error)
+	// GetTrip returns ErrNotFound for an unknown trip.
+	GetTrip(ctx context.Context, id uuid.UUID) (*Trip, error)
 	GetVehicleStatus(ctx context.Context, vehicleID uuid.UUID) (*VehicleStatus, error) // <-- new
+	CreateTrip(ctx context.Context, trip Trip) error
+	CloseTrip(ctx context.Context, trip Trip) error
+	// ListOpenTrips returns the trips that have no end time yet.
+	ListOpenTrips(ctx context.Context) ([]Trip, error)
+	InsertPosition(ctx context.Context, vehicleID uuid.UUID, status VehicleStatus) error
+	InsertPositions(ctx context.Context, positions []Position) error
+	ListPositions(ctx context.Context, query PositionQuery) ([]Position, error)
+	// CountPositions counts the positions between query.From and query.To;
+	// the rest of the query is ignored.
+	CountPositions(ctx context.Context, query PositionQuery) (int64, error)
+}
+
+// VehicleRegistryRepository defines the interface for database operations on
+// the vehicle registry. Create and update return ErrVehicleExists if the ID
+// or, among active vehicles, the plate number is taken; the lookups and
+// updates return ErrNotFound for unknown vehicles.
+type VehicleRegistryRepository interface {
+	CreateVehicle(ctx context.Context, vehicle Vehicle) (*Vehicle, error)
+	GetVehicle(ctx context.Context, id uuid.UUID) (*Vehicle, error)
+	// GetVehicleByPlate prefers the active vehicle with the plate number
+	// over archived ones.
+	GetVehicleByPlate(ctx context.Context, plateNumber string) (*Vehicle, error)
+	// IsVehicleActive reports whether the vehicle is registered and not
+	// archived.
+	IsVehicleActive(ctx context.Context, id uuid.UUID) (bool, error)
+	ListVehicles(ctx context.Context, query VehicleQuery) ([]Vehicle, error)
+	UpdateVehicle(ctx context.Context, vehicle Vehicle) (*Vehicle, error)
+	ArchiveVehicle(ctx context.Context, id uuid.UUID) (*Vehicle, error)
 }
 
 // VehicleCache defines the interface for caching vehicle status.
 type VehicleCache interface {
+	// SetStatus caches status unless a newer status is already cached.
 	SetStatus(ctx context.Context, vehicleID uuid.UUID, status *VehicleStatus, expiration time.Duration) error
 	GetStatus(ctx context.Context, vehicleID uuid.UUID) (*VehicleStatus, error)
+	// ListStatuses returns the latest status of the given vehicles, or of
+	// every vehicle if vehicleIDs is empty, taken at or after since.
+	ListStatuses(ctx context.Context, vehicleIDs []uuid.UUID, since time.Time) ([]VehicleSnapshot, error)
+	// ListStatusesInBox returns the latest status, taken at or after since,
+	// of the vehicles located in the box. Vehicles just outside it may be
+	// included.
+	ListStatusesInBox(ctx context.Context, box BoundingBox, since time.Time) ([]VehicleSnapshot, error)
+	// RemoveFromFleet drops a vehicle from the fleet snapshot and nearby
+	// searches.
+	RemoveFromFleet(ctx context.Context, vehicleID uuid.UUID) error
+	// FindNearby returns the vehicles within radius metres of the point,
+	// nearest first. A zero radius searches everywhere and a zero count
+	// returns every match.
+	FindNearby(ctx context.Context, lon, lat, radius float64, count int) ([]NearbyVehicle, error)
+	// MarkMessageSeen records a device message ID for window and reports
+	// whether it is the first time the message was seen.
+	MarkMessageSeen(ctx context.Context, vehicleID uuid.UUID, messageID string, window time.Duration) (bool, error)
+	// ForgetMessage drops a recorded message ID so the message can be retried.
+	ForgetMessage(ctx context.Context, vehicleID uuid.UUID, messageID string) error
+}
+
+// DeviceRepository defines the interface for database operations related to
+// the trackers that report over device protocols. Create and update return
+// ErrDeviceExists if another device has the identifier.
+type DeviceRepository interface {
+	CreateDevice(ctx context.Context, device Device) (*Device, error)
+	GetDevice(ctx context.Context, id uuid.UUID) (*Device, error)
+	GetDeviceByIdentifier(ctx context.Context, identifier string) (*Device, error)
+	ListDevices(ctx context.Context) ([]Device, error)
+	UpdateDevice(ctx context.Context, device Device) (*Device, error)
+	SetDeviceTokenHash(ctx context.Context, id uuid.UUID, tokenHash []byte) (*Device, error)
+	DeleteDevice(ctx context.Context, id uuid.UUID) error
+}
+
+// EventBus fans events out to every server instance, e.g. to feed the live
+// streams of the clients connected to each of them.
+type EventBus interface {
+	Publish(ctx context.Context, event Event) error
+	// Subscribe passes every event published by any instance to handle until
+	// ctx is cancelled or the subscription fails.
+	Subscribe(ctx context.Context, handle func(Event)) error
+}
+
+// GeofenceRepository defines the interface for database operations related to
+// geofences, vehicle presence in them and the resulting events.
+type GeofenceRepository interface {
+	CreateGeofence(ctx context.Context, fence Geofence) (*Geofence, error)
+	GetGeofence(ctx context.Context, id uuid.UUID) (*Geofence, error)
+	ListGeofences(ctx context.Context) ([]Geofence, error)
+	UpdateGeofence(ctx context.Context, fence Geofence) (*Geofence, error)
+	DeleteGeofence(ctx context.Context, id uuid.UUID) error
+
+	ListPresence(ctx context.Context, vehicleID uuid.UUID) ([]GeofencePresence, error)
+	// EnterGeofence, MarkDwellReported and ExitGeofence report whether they
+	// changed anything, so concurrent writers emit each event once.
+	EnterGeofence(ctx context.Context, presence GeofencePresence) (bool, error)
+	MarkDwellReported(ctx context.Context, vehicleID, geofenceID uuid.UUID) (bool, error)
+	ExitGeofence(ctx context.Context, vehicleID, geofenceID uuid.UUID) (bool, error)
+
+	InsertGeofenceEvent(ctx context.Context, event GeofenceEvent) (*GeofenceEvent, error)
+	ListGeofenceEvents(ctx context.Context, query GeofenceEventQuery) ([]GeofenceEvent, error)
+}
+
+// AlertRepository defines the interface for database operations related to
+// alert rules and the alerts they raise.
+type AlertRepository interface {
+	CreateAlertRule(ctx context.Context, rule AlertRule) (*AlertRule, error)
+	GetAlertRule(ctx context.Context, id uuid.UUID) (*AlertRule, error)
+	ListAlertRules(ctx context.Context) ([]AlertRule, error)
+	UpdateAlertRule(ctx context.Context, rule AlertRule) (*AlertRule, error)
+	DeleteAlertRule(ctx context.Context, id uuid.UUID) error
+
+	// OpenAlert returns nil if the rule already has an unresolved alert for
+	// the vehicle.
+	OpenAlert(ctx context.Context, alert Alert) (*Alert, error)
+	// ResolveOpenAlerts resolves the unresolved alert of the rule for the
+	// vehicle, if any.
+	ResolveOpenAlerts(ctx context.Context, ruleID, vehicleID uuid.UUID, at time.Time) ([]Alert, error)
+	// ResolveRuleAlerts resolves every unresolved alert of the rule.
+	ResolveRuleAlerts(ctx context.Context, ruleID uuid.UUID, at time.Time) ([]Alert, error)
+	// ResolveVehicleAlerts resolves every unresolved alert of the vehicle.
+	ResolveVehicleAlerts(ctx context.Context, vehicleID uuid.UUID, at time.Time) ([]Alert, error)
+	// OpenSilenceAlerts opens an alert of a no_update rule for every vehicle
+	// it applies to that has not reported since silentSince.
+	OpenSilenceAlerts(ctx context.Context, rule AlertRule, message string, silentSince, at time.Time) ([]Alert, error)
+	// ResolveSilenceAlerts resolves the alerts of a no_update rule whose
+	// vehicle has reported since silentSince.
+	ResolveSilenceAlerts(ctx context.Context, ruleID uuid.UUID, silentSince, at time.Time) ([]Alert, error)
+
+	GetAlert(ctx context.Context, id uuid.UUID) (*Alert, error)
+	ListAlerts(ctx context.Context, query AlertQuery) ([]Alert, error)
+	// AcknowledgeAlert and ResolveAlert return ErrNotFound unless the alert
+	// exists and is open, respectively unresolved.
+	AcknowledgeAlert(ctx context.Context, id uuid.UUID, at time.Time) (*Alert, error)
+	ResolveAlert(ctx context.Context, id uuid.UUID, at time.Time) (*Alert, error)
+}
+
+// WebhookRepository defines the interface for database operations related to
+// webhook subscriptions and their delivery log, which doubles as the queue of
+// the dispatcher.
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, sub WebhookSubscription) (*WebhookSubscription, error)
+	GetWebhook(ctx context.Context, id uuid.UUID) (*WebhookSubscription, error)
+	ListWebhooks(ctx context.Context) ([]WebhookSubscription, error)
+	// UpdateWebhook keeps the current secret if sub.Secret is empty.
+	UpdateWebhook(ctx context.Context, sub WebhookSubscription) (*WebhookSubscription, error)
+	DeleteWebhook(ctx context.Context, id uuid.UUID) error
+
+	// EnqueueDeliveries schedules the event for every listed subscription.
+	EnqueueDeliveries(ctx context.Context, subscriptionIDs []uuid.UUID, event Event, payload []byte) error
+	// ClaimDeliveries returns up to limit pending deliveries due at now and
+	// postpones them to leaseUntil, so that no other dispatcher picks them up
+	// while they are being attempted.
+	ClaimDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int32) ([]PendingDelivery, error)
+	RetryDelivery(ctx context.Context, id uuid.UUID, attempt WebhookAttempt, nextAttemptAt time.Time) error
+	CompleteDelivery(ctx context.Context, id uuid.UUID, state WebhookDeliveryState, attempt WebhookAttempt) error
+	ListDeliveries(ctx context.Context, query WebhookDeliveryQuery) ([]WebhookDelivery, error)
+	// DeleteDeliveriesBefore drops the completed deliveries created before t.
+	DeleteDeliveriesBefore(ctx context.Context, t time.Time) (int64, error)
+
+	RecordWebhookSuccess(ctx context.Context, id uuid.UUID) error
+	// RecordWebhookFailure marks the subscription as failing since at, unless
+	// it already is, and disables it if it has been failing since
+	// disableBefore or earlier.
+	RecordWebhookFailure(ctx context.Context, id uuid.UUID, at, disableBefore time.Time) (*WebhookSubscription, error)
 }
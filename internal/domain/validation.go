@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxClockSkew is how far in the future a reading's timestamp may lie before
+// it is rejected.
+const MaxClockSkew = 5 * time.Minute
+
+// MaxMessageIDLength bounds the device message ID of an ingest request.
+const MaxMessageIDLength = 128
+
+// FieldError describes why a single field of a request is invalid. Field is
+// the JSON path of the field, e.g. "status.location".
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every invalid field of a request.
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Field + ": " + f.Message
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+// Validate checks that the request describes a plausible reading. It returns
+// a *ValidationError listing every problem, or nil.
+func (r IngestRequest) Validate() error {
+	verr := &ValidationError{}
+	if !r.VehicleID.Valid || uuid.UUID(r.VehicleID.Bytes) == uuid.Nil {
+		verr.add("vehicle_id", "is required")
+	}
+	if len(r.MessageID) > MaxMessageIDLength {
+		verr.add("message_id", "must be at most 128 characters")
+	}
+	r.Status.validate("status.", verr)
+
+	if len(verr.Fields) > 0 {
+		return verr
+	}
+	return nil
+}
+
+func (s VehicleStatus) validate(prefix string, verr *ValidationError) {
+	if lon, lat, ok := s.Coordinates(); ok {
+		validateCoordinates(prefix+"location", lon, lat, verr)
+	} else {
+		verr.add(prefix+"location", "must be [longitude, latitude]")
+	}
+
+	if !isFinite(s.Speed) || s.Speed < 0 {
+		verr.add(prefix+"speed", "must not be negative")
+	}
+
+	switch {
+	case s.Timestamp.IsZero():
+		verr.add(prefix+"timestamp", "is required")
+	case s.Timestamp.After(time.Now().Add(MaxClockSkew)):
+		verr.add(prefix+"timestamp", "must not be in the future")
+	}
+
+	if v := s.Heading; v != nil && (!isFinite(*v) || *v < 0 || *v >= 360) {
+		verr.add(prefix+"heading", "must be between 0 and 360")
+	}
+	if v := s.Altitude; v != nil && !isFinite(*v) {
+		verr.add(prefix+"altitude", "must be a number")
+	}
+	if v := s.Accuracy; v != nil && (!isFinite(*v) || *v < 0) {
+		verr.add(prefix+"accuracy", "must not be negative")
+	}
+	if v := s.HDOP; v != nil && (!isFinite(*v) || *v < 0) {
+		verr.add(prefix+"hdop", "must not be negative")
+	}
+	if v := s.Satellites; v != nil && *v < 0 {
+		verr.add(prefix+"satellites", "must not be negative")
+	}
+	if v := s.Odometer; v != nil && (!isFinite(*v) || *v < 0) {
+		verr.add(prefix+"odometer", "must not be negative")
+	}
+	if v := s.FuelLevel; v != nil && (!isFinite(*v) || *v < 0 || *v > 100) {
+		verr.add(prefix+"fuel_level", "must be between 0 and 100")
+	}
+	if v := s.EngineHours; v != nil && (!isFinite(*v) || *v < 0) {
+		verr.add(prefix+"engine_hours", "must not be negative")
+	}
+}
+
+// validateCoordinates checks a [longitude, latitude] pair found at field.
+func validateCoordinates(field string, lon, lat float64, verr *ValidationError) {
+	if !isFinite(lon) || lon < -180 || lon > 180 {
+		verr.add(indexField(field, 0), "longitude must be between -180 and 180")
+	}
+	if !isFinite(lat) || lat < -90 || lat > 90 {
+		verr.add(indexField(field, 1), "latitude must be between -90 and 90")
+	}
+}
+
+func indexField(field string, i int) string {
+	return field + "[" + strconv.Itoa(i) + "]"
+}
+
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxPlateNumberLength bounds the plate number of a registered vehicle.
+const MaxPlateNumberLength = 32
+
+// Vehicle represents a vehicle in the system. Vehicles are registered through
+// the registry or, unless unregistered vehicles are rejected, on their first
+// reading.
+type Vehicle struct {
+	ID          uuid.UUID      `json:"id"`
+	PlateNumber string         `json:"plate_number"`
+	Name        string         `json:"name"`
+	LastStatus  *VehicleStatus `json:"last_status,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	// ArchivedAt is set once the vehicle is retired. Archived vehicles keep
+	// their history but are left out of the registry listing.
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+}
+
+// Validate checks the registration of the vehicle. It returns a
+// *ValidationError listing every problem, or nil.
+func (v Vehicle) Validate() error {
+	verr := &ValidationError{}
+	if v.PlateNumber == "" {
+		verr.add("plate_number", "is required")
+	} else if len(v.PlateNumber) > MaxPlateNumberLength {
+		verr.add("plate_number", "must be at most 32 characters")
+	}
+
+	if len(verr.Fields) > 0 {
+		return verr
+	}
+	return nil
+}
+
+// VehicleQuery selects a page of the vehicle registry, oldest registration
+// first.
+type VehicleQuery struct {
+	IncludeArchived bool
+	Limit           int32
+	Offset          int32
+}
+
+// UnregisteredVehicleError rejects a reading for a vehicle that is not in the
+// registry, or is archived, when unregistered vehicles are not accepted.
+func UnregisteredVehicleError() *ValidationError {
+	return &ValidationError{Fields: []FieldError{{Field: "vehicle_id", Message: "is not a registered vehicle"}}}
+}
@@ -0,0 +1,131 @@
+package domain
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/pkg/webhook"
+	"github.com/google/uuid"
+)
+
+// MinWebhookSecretLength is the shortest secret accepted for signing
+// webhook payloads.
+const MinWebhookSecretLength = 16
+
+// WebhookSubscription registers an HTTP endpoint for fleet events. Payloads
+// are signed with Secret, which is only returned when the subscription is
+// created. An empty EventTypes subscribes to every event type.
+type WebhookSubscription struct {
+	ID         uuid.UUID   `json:"id"`
+	URL        string      `json:"url"`
+	Secret     string      `json:"secret,omitempty"`
+	EventTypes []EventType `json:"event_types"`
+	Enabled    bool        `json:"enabled"`
+	// FailingSince is the first failed attempt since the endpoint last
+	// accepted a delivery.
+	FailingSince *time.Time `json:"failing_since,omitempty"`
+	DisabledAt   *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// Wants reports whether the subscription is enabled for events of type t.
+func (s WebhookSubscription) Wants(t EventType) bool {
+	if !s.Enabled {
+		return false
+	}
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+	for _, want := range s.EventTypes {
+		if want == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that events can be delivered to the subscription and that
+// its URL does not name a host inside the network. An empty Secret is
+// allowed, as one is generated on create and kept on update. It returns a
+// *ValidationError listing every problem, or nil.
+func (s WebhookSubscription) Validate() error {
+	verr := &ValidationError{}
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		verr.add("url", "must be an absolute http or https URL")
+	} else if !webhook.IsPublicHost(u.Hostname()) {
+		verr.add("url", "must not point to a loopback, private or link-local address")
+	}
+	if s.Secret != "" && len(s.Secret) < MinWebhookSecretLength {
+		verr.add("secret", "must be at least 16 characters")
+	}
+	for i, t := range s.EventTypes {
+		if !t.Valid() {
+			verr.add("event_types["+strconv.Itoa(i)+"]", "is not a known event type")
+		}
+	}
+
+	if len(verr.Fields) > 0 {
+		return verr
+	}
+	return nil
+}
+
+// WebhookDeliveryState is the progress of a delivery.
+type WebhookDeliveryState string
+
+const (
+	// WebhookPending deliveries are waiting for their next attempt.
+	WebhookPending WebhookDeliveryState = "pending"
+	// WebhookDelivered deliveries were accepted with a 2xx response.
+	WebhookDelivered WebhookDeliveryState = "delivered"
+	// WebhookFailed deliveries ran out of attempts.
+	WebhookFailed WebhookDeliveryState = "failed"
+)
+
+// WebhookDelivery is one event sent to one subscription, together with the
+// outcome of its latest attempt.
+type WebhookDelivery struct {
+	ID             uuid.UUID            `json:"id"`
+	SubscriptionID uuid.UUID            `json:"subscription_id"`
+	EventID        uuid.UUID            `json:"event_id"`
+	EventType      EventType            `json:"event_type"`
+	Payload        json.RawMessage      `json:"payload"`
+	State          WebhookDeliveryState `json:"state"`
+	Attempts       int                  `json:"attempts"`
+	NextAttemptAt  time.Time            `json:"next_attempt_at"`
+	ResponseStatus *int                 `json:"response_status,omitempty"`
+	LastError      string               `json:"last_error,omitempty"`
+	CreatedAt      time.Time            `json:"created_at"`
+	CompletedAt    *time.Time           `json:"completed_at,omitempty"`
+}
+
+// PendingDelivery is a delivery claimed for an attempt, together with the
+// endpoint it goes to.
+type PendingDelivery struct {
+	WebhookDelivery
+	URL    string
+	Secret string
+}
+
+// WebhookAttempt is the outcome of one delivery attempt. ResponseStatus is
+// nil if the endpoint could not be reached.
+type WebhookAttempt struct {
+	ResponseStatus *int
+	Error          string
+	At             time.Time
+}
+
+// WebhookDeliveryQuery selects a page of the deliveries of one subscription
+// created within [From, To], optionally in a single State.
+type WebhookDeliveryQuery struct {
+	SubscriptionID uuid.UUID
+	State          WebhookDeliveryState
+	From           time.Time
+	To             time.Time
+	Limit          int32
+	Offset         int32
+}
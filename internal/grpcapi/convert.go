@@ -0,0 +1,165 @@
+package grpcapi
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	fleetv1 "github.com/AnjuRKrishnan/fleet-tracker/pkg/api/fleet/v1"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func statusToProto(s domain.VehicleStatus) *fleetv1.VehicleStatus {
+	msg := &fleetv1.VehicleStatus{
+		Speed:       s.Speed,
+		Timestamp:   timestamppb.New(s.Timestamp),
+		Heading:     s.Heading,
+		Altitude:    s.Altitude,
+		Accuracy:    s.Accuracy,
+		Hdop:        s.HDOP,
+		Ignition:    s.Ignition,
+		Odometer:    s.Odometer,
+		FuelLevel:   s.FuelLevel,
+		EngineHours: s.EngineHours,
+	}
+	if lon, lat, ok := s.Coordinates(); ok {
+		msg.Longitude, msg.Latitude = &lon, &lat
+	}
+	if s.Satellites != nil {
+		n := int32(*s.Satellites)
+		msg.Satellites = &n
+	}
+	return msg
+}
+
+// statusFromProto converts a status message. A missing message, longitude,
+// latitude or timestamp leaves the location, respectively the timestamp,
+// empty so validation reports it.
+func statusFromProto(msg *fleetv1.VehicleStatus) domain.VehicleStatus {
+	if msg == nil {
+		return domain.VehicleStatus{}
+	}
+	s := domain.VehicleStatus{
+		Speed:       msg.GetSpeed(),
+		Heading:     msg.Heading,
+		Altitude:    msg.Altitude,
+		Accuracy:    msg.Accuracy,
+		HDOP:        msg.Hdop,
+		Ignition:    msg.Ignition,
+		Odometer:    msg.Odometer,
+		FuelLevel:   msg.FuelLevel,
+		EngineHours: msg.EngineHours,
+	}
+	if msg.Longitude != nil && msg.Latitude != nil {
+		s.Location = []float64{*msg.Longitude, *msg.Latitude}
+	}
+	if msg.Timestamp != nil {
+		s.Timestamp = msg.Timestamp.AsTime()
+	}
+	if msg.Satellites != nil {
+		n := int(*msg.Satellites)
+		s.Satellites = &n
+	}
+	return s
+}
+
+// ingestRequestFromProto converts an ingest message, rejecting a vehicle ID
+// that is not a UUID. The request itself is validated by the service.
+func ingestRequestFromProto(msg *fleetv1.IngestRequest) (domain.IngestRequest, *domain.ValidationError) {
+	req := domain.IngestRequest{
+		Status:      statusFromProto(msg.GetStatus()),
+		PlateNumber: msg.GetPlateNumber(),
+		MessageID:   msg.GetMessageId(),
+	}
+	if v := msg.GetVehicleId(); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return req, &domain.ValidationError{Fields: []domain.FieldError{{Field: "vehicle_id", Message: "must be a UUID"}}}
+		}
+		req.VehicleID = pgtype.UUID{Bytes: id, Valid: true}
+	}
+	return req, nil
+}
+
+func rejectionToProto(index int64, res domain.IngestResult) *fleetv1.IngestRejection {
+	msg := &fleetv1.IngestRejection{Index: index, Error: res.Error}
+	for _, f := range res.Fields {
+		msg.Fields = append(msg.Fields, &fleetv1.FieldError{Field: f.Field, Message: f.Message})
+	}
+	return msg
+}
+
+// tripQueryFromProto checks and converts a trip listing request. The error
+// message is meant for the client.
+func tripQueryFromProto(req *fleetv1.GetVehicleTripsRequest) (domain.TripQuery, error) {
+	vehicleID, err := uuid.Parse(req.GetVehicleId())
+	if err != nil {
+		return domain.TripQuery{}, errors.New("Invalid vehicle_id")
+	}
+	query := domain.TripQuery{
+		VehicleID:  vehicleID,
+		MinMileage: req.GetMinMileage(),
+		Order:      domain.SortDescending,
+		Limit:      req.GetPageSize(),
+	}
+	if req.From != nil {
+		query.From = req.GetFrom().AsTime()
+	}
+	if req.To != nil {
+		query.To = req.GetTo().AsTime()
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && query.To.Before(query.From) {
+		return domain.TripQuery{}, errors.New("to must not be before from")
+	}
+	if query.MinMileage < 0 {
+		return domain.TripQuery{}, errors.New("Invalid min_mileage")
+	}
+	if query.Limit < 0 {
+		return domain.TripQuery{}, errors.New("Invalid page_size")
+	}
+	if req.GetAscending() {
+		query.Order = domain.SortAscending
+	}
+	if token := req.GetPageToken(); token != "" {
+		if query.After, err = domain.ParseTripCursor(token); err != nil {
+			return domain.TripQuery{}, errors.New("Invalid page_token")
+		}
+	}
+	return query, nil
+}
+
+func tripToProto(t domain.Trip) *fleetv1.Trip {
+	msg := &fleetv1.Trip{
+		Id:        uuid.UUID(t.ID.Bytes).String(),
+		VehicleId: uuid.UUID(t.VehicleID.Bytes).String(),
+		StartTime: timestamppb.New(t.StartTime.Time),
+		Mileage:   t.Mileage,
+		AvgSpeed:  t.AvgSpeed,
+	}
+	if t.EndTime != nil {
+		msg.EndTime = timestamppb.New(*t.EndTime)
+	}
+	return msg
+}
+
+// positionUpdateFromEvent converts a status.updated event. Events that came
+// through the event bus carry their status as raw JSON.
+func positionUpdateFromEvent(event domain.Event) (*fleetv1.PositionUpdate, error) {
+	s, ok := event.Data.(domain.VehicleStatus)
+	if !ok {
+		data, err := json.Marshal(event.Data)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+	}
+	return &fleetv1.PositionUpdate{
+		EventId:   event.ID.String(),
+		VehicleId: event.VehicleID.String(),
+		Status:    statusToProto(s),
+	}, nil
+}
@@ -0,0 +1,250 @@
+// Package grpcapi serves the gRPC API defined in api/proto. It translates
+// between the protobuf messages and the domain types and delegates to the
+// same services as the REST handlers.
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/auth"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/middleware"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/services"
+	fleetv1 "github.com/AnjuRKrishnan/fleet-tracker/pkg/api/fleet/v1"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// MaxIngestRejections caps the rejections an Ingest response lists.
+	// Rejected readings beyond it are still counted.
+	MaxIngestRejections = 100
+	// IngestBatchSize caps the readings of an Ingest stream stored at once.
+	IngestBatchSize = 500
+)
+
+// NewServer returns a gRPC server that requires a user JWT on every call and
+// serves the vehicle API. A panicking call fails with codes.Internal instead
+// of taking the process down.
+func NewServer(jwtAuth *auth.JWTAuth, vehicles *VehicleServer, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(
+			middleware.UnaryRecoverer(vehicles.logger),
+			middleware.UnaryJWTAuthenticator(jwtAuth),
+		),
+		grpc.ChainStreamInterceptor(
+			middleware.StreamRecoverer(vehicles.logger),
+			middleware.StreamJWTAuthenticator(jwtAuth),
+		),
+	)
+	s := grpc.NewServer(opts...)
+	fleetv1.RegisterVehicleServiceServer(s, vehicles)
+	return s
+}
+
+// VehicleServer implements fleetv1.VehicleServiceServer.
+type VehicleServer struct {
+	fleetv1.UnimplementedVehicleServiceServer
+
+	service services.VehicleServiceAPI
+	feed    services.LiveFeedAPI
+	logger  *zap.Logger
+}
+
+func NewVehicleServer(s services.VehicleServiceAPI, f services.LiveFeedAPI, l *zap.Logger) *VehicleServer {
+	return &VehicleServer{service: s, feed: f, logger: l}
+}
+
+func (s *VehicleServer) GetVehicleStatus(ctx context.Context, req *fleetv1.GetVehicleStatusRequest) (*fleetv1.VehicleStatus, error) {
+	vehicleID, err := uuid.Parse(req.GetVehicleId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid vehicle_id")
+	}
+
+	vehicleStatus, err := s.service.GetVehicleStatus(ctx, vehicleID)
+	if errors.Is(err, domain.ErrNotFound) || (err == nil && vehicleStatus == nil) {
+		return nil, status.Error(codes.NotFound, "Vehicle not found")
+	}
+	if err != nil {
+		s.logger.Error("Failed to get status", zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to retrieve status")
+	}
+	return statusToProto(*vehicleStatus), nil
+}
+
+func (s *VehicleServer) GetVehicleTrips(ctx context.Context, req *fleetv1.GetVehicleTripsRequest) (*fleetv1.GetVehicleTripsResponse, error) {
+	query, err := tripQueryFromProto(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	page, err := s.service.GetVehicleTrips(ctx, query)
+	if err != nil {
+		s.logger.Error("Failed to get trips", zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to retrieve trips")
+	}
+
+	resp := &fleetv1.GetVehicleTripsResponse{Trips: make([]*fleetv1.Trip, len(page.Trips))}
+	for i, trip := range page.Trips {
+		resp.Trips[i] = tripToProto(trip)
+	}
+	if page.Next != nil {
+		resp.NextPageToken = page.Next.String()
+	}
+	return resp, nil
+}
+
+// Ingest stores the readings of the stream in batches: the readings that
+// arrived while the previous batch was being stored, up to IngestBatchSize,
+// go to IngestBatch together, so a busy stream takes few round trips and a
+// quiet one is stored without delay. If a batch cannot be stored the call
+// fails with codes.Internal; the details of the status carry the summary up
+// to the first reading of that batch, so the client knows where to resume.
+func (s *VehicleServer) Ingest(stream fleetv1.VehicleService_IngestServer) error {
+	ctx := stream.Context()
+	msgs := make(chan *fleetv1.IngestRequest, IngestBatchSize)
+	done := make(chan error, 1)
+	go func() {
+		defer close(msgs)
+		for {
+			msg, err := stream.Recv()
+			if err != nil {
+				done <- err
+				return
+			}
+			select {
+			case msgs <- msg:
+			case <-ctx.Done():
+				done <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	resp := &fleetv1.IngestResponse{}
+	var index int64
+	for msg := range msgs {
+		batch := []*fleetv1.IngestRequest{msg}
+	fill:
+		for len(batch) < IngestBatchSize {
+			select {
+			case msg, ok := <-msgs:
+				if !ok {
+					break fill
+				}
+				batch = append(batch, msg)
+			default:
+				break fill
+			}
+		}
+		if err := s.ingestBatch(ctx, resp, index, batch); err != nil {
+			return err
+		}
+		index += int64(len(batch))
+	}
+	if err := <-done; err != io.EOF {
+		return err
+	}
+	return stream.SendAndClose(resp)
+}
+
+// ingestBatch stores a batch of an Ingest stream whose first reading has the
+// given index in the stream, and adds the outcomes to resp. If the batch
+// cannot be stored resp is left as it was.
+func (s *VehicleServer) ingestBatch(ctx context.Context, resp *fleetv1.IngestResponse, first int64, batch []*fleetv1.IngestRequest) error {
+	// Readings that fail to convert are rejected here; the rest go to the
+	// service, remembering where they sat in the batch.
+	results := make([]domain.IngestResult, len(batch))
+	items := make([]domain.IngestRequest, 0, len(batch))
+	indexes := make([]int, 0, len(batch))
+	for i, msg := range batch {
+		req, verr := ingestRequestFromProto(msg)
+		if verr != nil {
+			results[i] = domain.IngestResult{Status: domain.IngestRejected, Error: verr.Error(), Fields: verr.Fields}
+			continue
+		}
+		items = append(items, req)
+		indexes = append(indexes, i)
+	}
+
+	if len(items) > 0 {
+		itemResults, err := s.service.IngestBatch(ctx, items)
+		if err != nil {
+			s.logger.Error("Failed to ingest data", zap.Int64("index", first), zap.Int("readings", len(items)), zap.Error(err))
+		}
+		// Results that come with an error mean the batch was stored anyway.
+		if itemResults == nil {
+			st, _ := status.New(codes.Internal, "Failed to ingest readings from "+strconv.FormatInt(first, 10)).WithDetails(resp)
+			return st.Err()
+		}
+		for j, res := range itemResults {
+			results[indexes[j]] = res
+		}
+	}
+
+	for i, res := range results {
+		switch res.Status {
+		case domain.IngestAccepted:
+			resp.Accepted++
+		case domain.IngestDuplicate:
+			resp.Duplicates++
+		case domain.IngestRejected:
+			resp.Rejected++
+			if len(resp.Rejections) < MaxIngestRejections {
+				resp.Rejections = append(resp.Rejections, rejectionToProto(first+int64(i), res))
+			}
+		}
+	}
+	return nil
+}
+
+// WatchPositions streams the accepted statuses of the requested vehicles
+// until the client cancels the call or the server shuts down.
+func (s *VehicleServer) WatchPositions(req *fleetv1.WatchPositionsRequest, stream fleetv1.VehicleService_WatchPositionsServer) error {
+	vehicleIDs := make([]uuid.UUID, len(req.GetVehicleIds()))
+	for i, v := range req.GetVehicleIds() {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, "Invalid vehicle_ids")
+		}
+		vehicleIDs[i] = id
+	}
+
+	sub := s.feed.Subscribe(services.LiveFilter{
+		Types:      []domain.EventType{domain.EventStatusUpdated},
+		VehicleIDs: vehicleIDs,
+	})
+	defer sub.Close()
+
+	// Sending the headers tells the client the watch is established.
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				return status.Error(codes.Unavailable, "Server is shutting down")
+			}
+			update, err := positionUpdateFromEvent(event)
+			if err != nil {
+				s.logger.Error("Failed to decode live event", zap.Error(err))
+				continue
+			}
+			update.Dropped = sub.TakeDropped()
+			if err := stream.Send(update); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
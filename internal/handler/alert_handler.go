@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/services"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type AlertHandler struct {
+	service services.AlertServiceAPI
+	logger  *zap.Logger
+}
+
+func NewAlertHandler(s services.AlertServiceAPI, l *zap.Logger) *AlertHandler {
+	return &AlertHandler{service: s, logger: l}
+}
+
+// CreateRule stores a new alert rule. Rules are enabled unless the body says
+// otherwise.
+func (h *AlertHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
+	rule := domain.AlertRule{Enabled: true}
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.service.CreateRule(r.Context(), rule)
+	var verr *domain.ValidationError
+	if errors.As(err, &verr) {
+		writeValidationError(w, verr)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to create alert rule", zap.Error(err))
+		http.Error(w, "Failed to create alert rule", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func (h *AlertHandler) ListRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.service.ListRules(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to list alert rules", zap.Error(err))
+		http.Error(w, "Failed to retrieve alert rules", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+func (h *AlertHandler) GetRule(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid alert rule id", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.service.GetRule(r.Context(), id)
+	if errors.Is(err, domain.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to get alert rule", zap.Error(err))
+		http.Error(w, "Failed to retrieve alert rule", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// UpdateRule replaces an alert rule. Omitted fields are reset, except enabled
+// which defaults to true as on create.
+func (h *AlertHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid alert rule id", http.StatusBadRequest)
+		return
+	}
+
+	rule := domain.AlertRule{Enabled: true}
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	rule.ID = id
+
+	updated, err := h.service.UpdateRule(r.Context(), rule)
+	var verr *domain.ValidationError
+	if errors.As(err, &verr) {
+		writeValidationError(w, verr)
+		return
+	}
+	if errors.Is(err, domain.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to update alert rule", zap.Error(err))
+		http.Error(w, "Failed to update alert rule", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+func (h *AlertHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid alert rule id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.DeleteRule(r.Context(), id)
+	if errors.Is(err, domain.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to delete alert rule", zap.Error(err))
+		http.Error(w, "Failed to delete alert rule", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListAlerts lists alerts, optionally filtered by vehicle_id, rule_id, state
+// and the from/to range of their opening time.
+func (h *AlertHandler) ListAlerts(w http.ResponseWriter, r *http.Request) {
+	var query domain.AlertQuery
+	var err error
+	if query.VehicleID, err = parseOptionalUUID(r, "vehicle_id"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if query.RuleID, err = parseOptionalUUID(r, "rule_id"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query.State = domain.AlertState(r.URL.Query().Get("state"))
+	switch query.State {
+	case "", domain.AlertOpen, domain.AlertAcknowledged, domain.AlertResolved:
+	default:
+		http.Error(w, "Invalid state", http.StatusBadRequest)
+		return
+	}
+
+	if query.From, query.To, err = parseTimeRange(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if query.Limit, err = parseInt32(r, "limit"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if query.Offset, err = parseInt32(r, "offset"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	alerts, err := h.service.ListAlerts(r.Context(), query)
+	if err != nil {
+		h.logger.Error("Failed to list alerts", zap.Error(err))
+		http.Error(w, "Failed to retrieve alerts", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(alerts)
+}
+
+func (h *AlertHandler) GetAlert(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid alert id", http.StatusBadRequest)
+		return
+	}
+
+	alert, err := h.service.GetAlert(r.Context(), id)
+	h.writeAlert(w, r, alert, err, "Failed to retrieve alert")
+}
+
+func (h *AlertHandler) AcknowledgeAlert(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid alert id", http.StatusBadRequest)
+		return
+	}
+
+	alert, err := h.service.AcknowledgeAlert(r.Context(), id)
+	if errors.Is(err, domain.ErrAlertResolved) {
+		http.Error(w, "Alert is already resolved", http.StatusConflict)
+		return
+	}
+	h.writeAlert(w, r, alert, err, "Failed to acknowledge alert")
+}
+
+func (h *AlertHandler) ResolveAlert(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid alert id", http.StatusBadRequest)
+		return
+	}
+
+	alert, err := h.service.ResolveAlert(r.Context(), id)
+	h.writeAlert(w, r, alert, err, "Failed to resolve alert")
+}
+
+func (h *AlertHandler) writeAlert(w http.ResponseWriter, r *http.Request, alert *domain.Alert, err error, failure string) {
+	if errors.Is(err, domain.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		h.logger.Error(failure, zap.Error(err))
+		http.Error(w, failure, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(alert)
+}
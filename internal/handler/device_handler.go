@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/services"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type DeviceHandler struct {
+	service services.DeviceServiceAPI
+	logger  *zap.Logger
+}
+
+func NewDeviceHandler(s services.DeviceServiceAPI, l *zap.Logger) *DeviceHandler {
+	return &DeviceHandler{service: s, logger: l}
+}
+
+// Create registers a device for a vehicle.
+func (h *DeviceHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var device domain.Device
+	if err := json.NewDecoder(r.Body).Decode(&device); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.service.CreateDevice(r.Context(), device)
+	if h.writeSaveError(w, r, err, "Failed to create device") {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func (h *DeviceHandler) List(w http.ResponseWriter, r *http.Request) {
+	devices, err := h.service.ListDevices(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to list devices", zap.Error(err))
+		http.Error(w, "Failed to retrieve devices", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(devices)
+}
+
+func (h *DeviceHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid device id", http.StatusBadRequest)
+		return
+	}
+
+	device, err := h.service.GetDevice(r.Context(), id)
+	if errors.Is(err, domain.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to get device", zap.Error(err))
+		http.Error(w, "Failed to retrieve device", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(device)
+}
+
+// Update replaces a device. Omitted fields are reset.
+func (h *DeviceHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid device id", http.StatusBadRequest)
+		return
+	}
+
+	var device domain.Device
+	if err := json.NewDecoder(r.Body).Decode(&device); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	device.ID = id
+
+	updated, err := h.service.UpdateDevice(r.Context(), device)
+	if h.writeSaveError(w, r, err, "Failed to update device") {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+// RotateToken issues a new token for the device. The old token stops working
+// immediately.
+func (h *DeviceHandler) RotateToken(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid device id", http.StatusBadRequest)
+		return
+	}
+
+	device, err := h.service.RotateDeviceToken(r.Context(), id)
+	if errors.Is(err, domain.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to rotate device token", zap.Error(err))
+		http.Error(w, "Failed to rotate device token", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(device)
+}
+
+func (h *DeviceHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid device id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.DeleteDevice(r.Context(), id)
+	if errors.Is(err, domain.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to delete device", zap.Error(err))
+		http.Error(w, "Failed to delete device", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeSaveError replies to a failed create or update and reports whether
+// there was an error.
+func (h *DeviceHandler) writeSaveError(w http.ResponseWriter, r *http.Request, err error, message string) bool {
+	var verr *domain.ValidationError
+	switch {
+	case err == nil:
+		return false
+	case errors.As(err, &verr):
+		writeValidationError(w, verr)
+	case errors.Is(err, domain.ErrNotFound):
+		http.NotFound(w, r)
+	case errors.Is(err, domain.ErrDeviceExists):
+		http.Error(w, "Device identifier is already registered", http.StatusConflict)
+	default:
+		h.logger.Error(message, zap.Error(err))
+		http.Error(w, message, http.StatusInternalServerError)
+	}
+	return true
+}
@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/pkg/gpx"
+	"github.com/AnjuRKrishnan/fleet-tracker/pkg/kml"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Export formats, chosen with the format query parameter.
+const (
+	exportCSV = "csv"
+	exportGPX = "gpx"
+	exportKML = "kml"
+)
+
+// exportCreator names the application in GPX headers.
+const exportCreator = "fleet-tracker"
+
+var exportMediaTypes = map[string]string{
+	exportCSV: "text/csv; charset=utf-8",
+	exportGPX: gpx.MediaType,
+	exportKML: kml.MediaType,
+}
+
+// parseExportFormat reads the optional format query parameter, csv by
+// default.
+func parseExportFormat(r *http.Request) (string, error) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		return exportCSV, nil
+	}
+	if _, ok := exportMediaTypes[format]; !ok {
+		return "", errors.New("Invalid format")
+	}
+	return format, nil
+}
+
+// exportStream passes an export through to the client and remembers whether
+// any of it was sent, after which an error can no longer change the status.
+type exportStream struct {
+	w    http.ResponseWriter
+	sent bool
+}
+
+func (s *exportStream) Write(p []byte) (int, error) {
+	s.sent = true
+	return s.w.Write(p)
+}
+
+// startExport sets the headers of a download named filename in the format and
+// returns the buffered writer the export goes through.
+func startExport(w http.ResponseWriter, format, filename string) (*exportStream, *bufio.Writer) {
+	w.Header().Set("Content-Type", exportMediaTypes[format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+"."+format))
+	stream := &exportStream{w: w}
+	return stream, bufio.NewWriter(stream)
+}
+
+// failExport reports an export that failed. Before anything was sent it
+// answers with an error status; after that it aborts the response, so that
+// the client sees a broken transfer rather than a complete but truncated file.
+func (h *VehicleHandler) failExport(w http.ResponseWriter, stream *exportStream, msg string, err error) {
+	h.logger.Error(msg, zap.Error(err), zap.Bool("partial", stream.sent))
+	if stream.sent {
+		panic(http.ErrAbortHandler)
+	}
+	w.Header().Del("Content-Disposition")
+	http.Error(w, msg, http.StatusInternalServerError)
+}
+
+// ExportTrips downloads every trip matched by the trip listing parameters,
+// without paging. format=csv (the default) writes one row per trip, gpx and
+// kml one track per trip through the positions recorded during it.
+func (h *VehicleHandler) ExportTrips(w http.ResponseWriter, r *http.Request) {
+	query, err := parseTripQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	format, err := parseExportFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stream, bw := startExport(w, format, "trips-"+query.VehicleID.String())
+	switch format {
+	case exportCSV:
+		err = h.exportTripsCSV(r.Context(), bw, query)
+	case exportGPX:
+		err = h.exportTripsGPX(r.Context(), bw, query)
+	case exportKML:
+		err = h.exportTripsKML(r.Context(), bw, query)
+	}
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err != nil {
+		h.failExport(w, stream, "Failed to export trips", err)
+	}
+}
+
+var tripCSVHeader = []string{"id", "vehicle_id", "start_time", "end_time", "mileage", "avg_speed"}
+
+func (h *VehicleHandler) exportTripsCSV(ctx context.Context, w io.Writer, query domain.TripQuery) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(tripCSVHeader); err != nil {
+		return err
+	}
+	err := h.service.ExportTrips(ctx, query, func(trip domain.Trip) error {
+		var endTime string
+		if trip.EndTime != nil {
+			endTime = formatExportTime(*trip.EndTime)
+		}
+		return cw.Write([]string{
+			uuid.UUID(trip.ID.Bytes).String(),
+			uuid.UUID(trip.VehicleID.Bytes).String(),
+			formatExportTime(trip.StartTime.Time),
+			endTime,
+			formatExportFloat(trip.Mileage),
+			formatExportFloat(trip.AvgSpeed),
+		})
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (h *VehicleHandler) exportTripsGPX(ctx context.Context, w io.Writer, query domain.TripQuery) error {
+	gw := gpx.NewWriter(w, exportCreator)
+	err := h.service.ExportTrips(ctx, query, func(trip domain.Trip) error {
+		if err := gw.StartTrack(gpx.Track{Name: tripName(trip), Description: tripDescription(trip)}); err != nil {
+			return err
+		}
+		return h.service.ExportPositions(ctx, tripPositionQuery(trip), func(p domain.Position) error {
+			return writeGPXPoint(gw, p)
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+func (h *VehicleHandler) exportTripsKML(ctx context.Context, w io.Writer, query domain.TripQuery) error {
+	kw := kml.NewWriter(w, "Trips of "+query.VehicleID.String())
+	err := h.service.ExportTrips(ctx, query, func(trip domain.Trip) error {
+		placemark := kml.Placemark{
+			Name:        tripName(trip),
+			Description: tripDescription(trip),
+			Begin:       trip.StartTime.Time,
+		}
+		if trip.EndTime != nil {
+			placemark.End = *trip.EndTime
+		}
+		if err := kw.StartPlacemark(placemark); err != nil {
+			return err
+		}
+		return h.service.ExportPositions(ctx, tripPositionQuery(trip), func(p domain.Position) error {
+			return writeKMLCoordinate(kw, p)
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return kw.Close()
+}
+
+// tripPositionQuery selects the positions recorded during a trip. An open
+// trip extends to now.
+func tripPositionQuery(trip domain.Trip) domain.PositionQuery {
+	query := domain.PositionQuery{
+		VehicleID: uuid.UUID(trip.VehicleID.Bytes),
+		From:      trip.StartTime.Time,
+	}
+	if trip.EndTime != nil {
+		query.To = *trip.EndTime
+	}
+	return query
+}
+
+func tripName(trip domain.Trip) string {
+	return "Trip " + uuid.UUID(trip.ID.Bytes).String()
+}
+
+func tripDescription(trip domain.Trip) string {
+	return fmt.Sprintf("%.2f km, average %.1f km/h", trip.Mileage, trip.AvgSpeed)
+}
+
+// ExportPositions downloads a vehicle's position history between from and to
+// (default: the 24 hours before now), without paging. format=csv (the
+// default) writes one row per reading, gpx and kml a single track.
+func (h *VehicleHandler) ExportPositions(w http.ResponseWriter, r *http.Request) {
+	vehicleID, err := uuid.Parse(r.URL.Query().Get("vehicle_id"))
+	if err != nil {
+		http.Error(w, "Invalid vehicle_id", http.StatusBadRequest)
+		return
+	}
+	from, to, err := parseTimeRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	format, err := parseExportFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query := domain.PositionQuery{VehicleID: vehicleID, From: from, To: to}
+	name := "positions-" + vehicleID.String()
+	stream, bw := startExport(w, format, name)
+	switch format {
+	case exportCSV:
+		err = h.exportPositionsCSV(r.Context(), bw, query)
+	case exportGPX:
+		gw := gpx.NewWriter(bw, exportCreator)
+		if err = gw.StartTrack(gpx.Track{Name: vehicleID.String()}); err == nil {
+			err = h.service.ExportPositions(r.Context(), query, func(p domain.Position) error {
+				return writeGPXPoint(gw, p)
+			})
+		}
+		if err == nil {
+			err = gw.Close()
+		}
+	case exportKML:
+		kw := kml.NewWriter(bw, "Positions of "+vehicleID.String())
+		if err = kw.StartPlacemark(kml.Placemark{Name: vehicleID.String()}); err == nil {
+			err = h.service.ExportPositions(r.Context(), query, func(p domain.Position) error {
+				return writeKMLCoordinate(kw, p)
+			})
+		}
+		if err == nil {
+			err = kw.Close()
+		}
+	}
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err != nil {
+		h.failExport(w, stream, "Failed to export positions", err)
+	}
+}
+
+var positionCSVHeader = []string{
+	"id", "vehicle_id", "timestamp", "longitude", "latitude", "speed",
+	"heading", "altitude", "accuracy", "hdop", "satellites", "ignition",
+	"odometer", "fuel_level", "engine_hours",
+}
+
+func (h *VehicleHandler) exportPositionsCSV(ctx context.Context, w io.Writer, query domain.PositionQuery) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(positionCSVHeader); err != nil {
+		return err
+	}
+	err := h.service.ExportPositions(ctx, query, func(p domain.Position) error {
+		var lon, lat string
+		if x, y, ok := p.Coordinates(); ok {
+			lon, lat = formatExportFloat(x), formatExportFloat(y)
+		}
+		var satellites, ignition string
+		if p.Satellites != nil {
+			satellites = strconv.Itoa(*p.Satellites)
+		}
+		if p.Ignition != nil {
+			ignition = strconv.FormatBool(*p.Ignition)
+		}
+		return cw.Write([]string{
+			strconv.FormatInt(p.ID, 10),
+			p.VehicleID.String(),
+			formatExportTime(p.Timestamp),
+			lon,
+			lat,
+			formatExportFloat(p.Speed),
+			formatOptionalFloat(p.Heading),
+			formatOptionalFloat(p.Altitude),
+			formatOptionalFloat(p.Accuracy),
+			formatOptionalFloat(p.HDOP),
+			satellites,
+			ignition,
+			formatOptionalFloat(p.Odometer),
+			formatOptionalFloat(p.FuelLevel),
+			formatOptionalFloat(p.EngineHours),
+		})
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeGPXPoint adds a position to the current track. Readings without a
+// location are left out.
+func writeGPXPoint(gw *gpx.Writer, p domain.Position) error {
+	lon, lat, ok := p.Coordinates()
+	if !ok {
+		return nil
+	}
+	return gw.WritePoint(gpx.Point{Longitude: lon, Latitude: lat, Elevation: p.Altitude, Time: p.Timestamp})
+}
+
+// writeKMLCoordinate adds a position to the current line. Readings without a
+// location are left out.
+func writeKMLCoordinate(kw *kml.Writer, p domain.Position) error {
+	lon, lat, ok := p.Coordinates()
+	if !ok {
+		return nil
+	}
+	return kw.WriteCoordinate(lon, lat)
+}
+
+func formatExportTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func formatExportFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func formatOptionalFloat(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return formatExportFloat(*v)
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/services"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type GeofenceHandler struct {
+	service services.GeofenceServiceAPI
+	logger  *zap.Logger
+}
+
+func NewGeofenceHandler(s services.GeofenceServiceAPI, l *zap.Logger) *GeofenceHandler {
+	return &GeofenceHandler{service: s, logger: l}
+}
+
+func (h *GeofenceHandler) Create(w http.ResponseWriter, r *http.Request) {
+	fence := domain.Geofence{DwellSeconds: domain.DefaultDwellSeconds}
+	if err := json.NewDecoder(r.Body).Decode(&fence); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.service.CreateGeofence(r.Context(), fence)
+	var verr *domain.ValidationError
+	if errors.As(err, &verr) {
+		writeValidationError(w, verr)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to create geofence", zap.Error(err))
+		http.Error(w, "Failed to create geofence", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func (h *GeofenceHandler) List(w http.ResponseWriter, r *http.Request) {
+	fences, err := h.service.ListGeofences(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to list geofences", zap.Error(err))
+		http.Error(w, "Failed to retrieve geofences", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(fences)
+}
+
+func (h *GeofenceHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid geofence id", http.StatusBadRequest)
+		return
+	}
+
+	fence, err := h.service.GetGeofence(r.Context(), id)
+	if errors.Is(err, domain.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to get geofence", zap.Error(err))
+		http.Error(w, "Failed to retrieve geofence", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(fence)
+}
+
+// Update replaces a geofence. Omitted fields are reset, except dwell_seconds
+// which falls back to domain.DefaultDwellSeconds as on create.
+func (h *GeofenceHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid geofence id", http.StatusBadRequest)
+		return
+	}
+
+	fence := domain.Geofence{DwellSeconds: domain.DefaultDwellSeconds}
+	if err := json.NewDecoder(r.Body).Decode(&fence); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	fence.ID = id
+
+	updated, err := h.service.UpdateGeofence(r.Context(), fence)
+	var verr *domain.ValidationError
+	if errors.As(err, &verr) {
+		writeValidationError(w, verr)
+		return
+	}
+	if errors.Is(err, domain.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to update geofence", zap.Error(err))
+		http.Error(w, "Failed to update geofence", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+func (h *GeofenceHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid geofence id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.DeleteGeofence(r.Context(), id)
+	if errors.Is(err, domain.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to delete geofence", zap.Error(err))
+		http.Error(w, "Failed to delete geofence", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetFenceEvents lists the events of one geofence.
+func (h *GeofenceHandler) GetFenceEvents(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid geofence id", http.StatusBadRequest)
+		return
+	}
+	h.listEvents(w, r, domain.GeofenceEventQuery{GeofenceID: &id})
+}
+
+// GetVehicleEvents lists the geofence events of one vehicle, optionally
+// narrowed to a single geofence with geofence_id.
+func (h *GeofenceHandler) GetVehicleEvents(w http.ResponseWriter, r *http.Request) {
+	vehicleID, err := uuid.Parse(r.URL.Query().Get("vehicle_id"))
+	if err != nil {
+		http.Error(w, "Invalid vehicle_id", http.StatusBadRequest)
+		return
+	}
+	geofenceID, err := parseOptionalUUID(r, "geofence_id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.listEvents(w, r, domain.GeofenceEventQuery{VehicleID: &vehicleID, GeofenceID: geofenceID})
+}
+
+func (h *GeofenceHandler) listEvents(w http.ResponseWriter, r *http.Request, query domain.GeofenceEventQuery) {
+	var err error
+	query.From, query.To, err = parseTimeRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if query.Limit, err = parseInt32(r, "limit"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if query.Offset, err = parseInt32(r, "offset"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	events, err := h.service.ListEvents(r.Context(), query)
+	if err != nil {
+		h.logger.Error("Failed to list geofence events", zap.Error(err))
+		http.Error(w, "Failed to retrieve geofence events", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(events)
+}
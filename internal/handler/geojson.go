@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/pkg/geojson"
+	"github.com/google/uuid"
+)
+
+// wantsGeoJSON reports whether the client asked for GeoJSON, either with
+// ?format=geojson or with an Accept header naming application/geo+json.
+func wantsGeoJSON(r *http.Request) bool {
+	if r.URL.Query().Get("format") == "geojson" {
+		return true
+	}
+	return strings.Contains(r.Header.Get("Accept"), geojson.MediaType)
+}
+
+func writeGeoJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", geojson.MediaType)
+	json.NewEncoder(w).Encode(v)
+}
+
+// toProperties flattens the JSON form of v into a GeoJSON properties object,
+// leaving out the omitted keys.
+func toProperties(v any, omit ...string) map[string]any {
+	props := map[string]any{}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return props
+	}
+	_ = json.Unmarshal(b, &props)
+	for _, k := range omit {
+		delete(props, k)
+	}
+	return props
+}
+
+// statusFeature turns a status into a Point feature whose properties carry
+// the rest of the reading.
+func statusFeature(id any, status domain.VehicleStatus) geojson.Feature {
+	var geometry *geojson.Geometry
+	if lon, lat, ok := status.Coordinates(); ok {
+		geometry = geojson.NewPoint(lon, lat)
+	}
+	return geojson.NewFeature(id, geometry, toProperties(status, "location"))
+}
+
+// positionsFeatureCollection turns a page of position history into a
+// collection of Point features.
+func positionsFeatureCollection(positions []domain.Position) geojson.FeatureCollection {
+	features := make([]geojson.Feature, 0, len(positions))
+	for _, p := range positions {
+		f := statusFeature(p.ID, p.VehicleStatus)
+		f.Properties["vehicle_id"] = p.VehicleID.String()
+		features = append(features, f)
+	}
+	return geojson.NewFeatureCollection(features)
+}
+
+// tripFeature turns a trip into a LineString feature through the positions
+// recorded during it. Trips with fewer than two positions degrade to a Point
+// or a null geometry.
+func tripFeature(trip domain.Trip, positions []domain.Position) geojson.Feature {
+	coords := make([][]float64, 0, len(positions))
+	for _, p := range positions {
+		if lon, lat, ok := p.Coordinates(); ok {
+			coords = append(coords, []float64{lon, lat})
+		}
+	}
+
+	var geometry *geojson.Geometry
+	switch len(coords) {
+	case 0:
+	case 1:
+		geometry = geojson.NewPoint(coords[0][0], coords[0][1])
+	default:
+		geometry = geojson.NewLineString(coords)
+	}
+
+	id := uuid.UUID(trip.ID.Bytes).String()
+	return geojson.NewFeature(id, geometry, toProperties(trip, "id"))
+}
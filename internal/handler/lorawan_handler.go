@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/ingest"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/services"
+	"go.uber.org/zap"
+)
+
+// maxUplinkBodyBytes bounds the JSON of a network server webhook.
+const maxUplinkBodyBytes = 1 << 20
+
+// LoRaWANHandler accepts uplinks that LoRaWAN network servers forward by
+// webhook. The DevEUI is looked up in the device registry and the payload is
+// decoded by the decoder of the device's model.
+type LoRaWANHandler struct {
+	devices  services.DeviceResolver
+	vehicles services.VehicleServiceAPI
+	decoders *ingest.LoRaWANDecoders
+	logger   *zap.Logger
+}
+
+func NewLoRaWANHandler(d services.DeviceResolver, v services.VehicleServiceAPI, decoders *ingest.LoRaWANDecoders, l *zap.Logger) *LoRaWANHandler {
+	return &LoRaWANHandler{devices: d, vehicles: v, decoders: decoders, logger: l}
+}
+
+// TTSUplink handles the uplink message webhook of The Things Stack.
+func (h *LoRaWANHandler) TTSUplink(w http.ResponseWriter, r *http.Request) {
+	h.handleUplink(w, r, ingest.ParseTTSUplink)
+}
+
+// ChirpStackUplink handles the ChirpStack HTTP integration, which posts every
+// event type to the same URL. Events other than uplinks are acknowledged and
+// ignored.
+func (h *LoRaWANHandler) ChirpStackUplink(w http.ResponseWriter, r *http.Request) {
+	if event := r.URL.Query().Get("event"); event != "" && event != "up" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	h.handleUplink(w, r, ingest.ParseChirpStackUplink)
+}
+
+func (h *LoRaWANHandler) handleUplink(w http.ResponseWriter, r *http.Request, parse func([]byte) (ingest.LoRaWANUplink, error)) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxUplinkBodyBytes))
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	uplink, err := parse(body)
+	if err != nil {
+		http.Error(w, "Invalid uplink: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	device, err := h.devices.ResolveDevice(r.Context(), uplink.DevEUI)
+	if errors.Is(err, domain.ErrNotFound) {
+		http.Error(w, "Device not registered", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to resolve LoRaWAN device", zap.String("dev_eui", uplink.DevEUI), zap.Error(err))
+		http.Error(w, "Failed to ingest data", http.StatusInternalServerError)
+		return
+	}
+
+	decoder, ok := h.decoders.Decoder(device.Model)
+	if !ok {
+		h.logger.Warn("No decoder for LoRaWAN device model", zap.String("dev_eui", uplink.DevEUI), zap.String("model", device.Model))
+		http.Error(w, "No decoder for device model", http.StatusUnprocessableEntity)
+		return
+	}
+	status, err := decoder.Decode(uplink)
+	// Heartbeats and other uplinks without a position are acknowledged.
+	if errors.Is(err, ingest.ErrNoPosition) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Invalid payload: "+err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	err = h.vehicles.IngestData(r.Context(), uplink.IngestRequest(device, status))
+	var verr *domain.ValidationError
+	if errors.As(err, &verr) {
+		writeValidationError(w, verr)
+		return
+	}
+	// A retried webhook was already processed; acknowledge it again.
+	if errors.Is(err, domain.ErrDuplicateMessage) {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to ingest data", zap.String("dev_eui", uplink.DevEUI), zap.Error(err))
+		http.Error(w, "Failed to ingest data", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
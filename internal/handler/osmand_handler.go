@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/ingest"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/services"
+	"go.uber.org/zap"
+)
+
+// maxOsmAndBodyBytes bounds the form body of an OsmAnd POST.
+const maxOsmAndBodyBytes = 64 << 10
+
+// OsmAndHandler accepts positions from phone tracking apps that speak the
+// OsmAnd protocol. Apps authenticate with the token of their registered
+// device instead of a user JWT.
+type OsmAndHandler struct {
+	devices  services.DeviceAuthenticator
+	vehicles services.VehicleServiceAPI
+	logger   *zap.Logger
+}
+
+func NewOsmAndHandler(d services.DeviceAuthenticator, v services.VehicleServiceAPI, l *zap.Logger) *OsmAndHandler {
+	return &OsmAndHandler{devices: d, vehicles: v, logger: l}
+}
+
+// Ingest stores one position given as query or form parameters. The device
+// token is read from the token parameter or a bearer Authorization header.
+// Apps such as Traccar Client only treat 200 as success, so accepted and
+// repeated positions both get 200.
+func (h *OsmAndHandler) Ingest(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxOsmAndBodyBytes)
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	reading, err := ingest.ParseOsmAnd(r.Form)
+	if err != nil {
+		http.Error(w, "Invalid position: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	device, err := h.devices.AuthenticateDevice(r.Context(), reading.DeviceID, osmAndToken(r))
+	if errors.Is(err, domain.ErrInvalidDeviceToken) {
+		http.Error(w, "Invalid device token", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to authenticate device", zap.String("device", reading.DeviceID), zap.Error(err))
+		http.Error(w, "Failed to ingest data", http.StatusInternalServerError)
+		return
+	}
+	// A position without a fix is acknowledged but not stored.
+	if !reading.Valid {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	err = h.vehicles.IngestData(r.Context(), reading.IngestRequest(device))
+	var verr *domain.ValidationError
+	if errors.As(err, &verr) {
+		writeValidationError(w, verr)
+		return
+	}
+	if err != nil && !errors.Is(err, domain.ErrDuplicateMessage) {
+		h.logger.Error("Failed to ingest data", zap.String("device", reading.DeviceID), zap.Error(err))
+		http.Error(w, "Failed to ingest data", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func osmAndToken(r *http.Request) string {
+	if token := r.Form.Get("token"); token != "" {
+		return token
+	}
+	return strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
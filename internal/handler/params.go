@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/google/uuid"
+)
+
+// parseTime reads an optional RFC 3339 timestamp from the query string. A
+// missing parameter yields the zero time.
+func parseTime(r *http.Request, name string) (time.Time, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+// parseTimeRange reads the optional from/to query parameters.
+func parseTimeRange(r *http.Request) (from, to time.Time, err error) {
+	if from, err = parseTime(r, "from"); err != nil {
+		return time.Time{}, time.Time{}, errors.New("Invalid from")
+	}
+	if to, err = parseTime(r, "to"); err != nil {
+		return time.Time{}, time.Time{}, errors.New("Invalid to")
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return time.Time{}, time.Time{}, errors.New("to must not be before from")
+	}
+	return from, to, nil
+}
+
+// parseInt32 reads an optional non-negative integer from the query string. A
+// missing parameter yields zero.
+func parseInt32(r *http.Request, name string) (int32, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 32)
+	if err != nil || n < 0 {
+		return 0, errors.New("Invalid " + name)
+	}
+	return int32(n), nil
+}
+
+// parseFloat reads an optional number from the query string. A missing
+// parameter yields zero.
+func parseFloat(r *http.Request, name string) (float64, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, errors.New("Invalid " + name)
+	}
+	return f, nil
+}
+
+// parseSortOrder reads an optional asc/desc sort order from the query string.
+// A missing parameter yields the empty order, leaving the default to the
+// service.
+func parseSortOrder(r *http.Request, name string) (domain.SortOrder, error) {
+	switch order := domain.SortOrder(r.URL.Query().Get(name)); order {
+	case "", domain.SortAscending, domain.SortDescending:
+		return order, nil
+	default:
+		return "", errors.New("Invalid " + name)
+	}
+}
+
+// parseOptionalUUID reads an optional UUID from the query string. A missing
+// parameter yields nil.
+func parseOptionalUUID(r *http.Request, name string) (*uuid.UUID, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(v)
+	if err != nil {
+		return nil, errors.New("Invalid " + name)
+	}
+	return &id, nil
+}
+
+// parseUUIDList reads UUIDs from a query parameter that may be repeated and
+// may hold comma-separated values. A missing parameter yields nil.
+func parseUUIDList(r *http.Request, name string) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	for _, v := range r.URL.Query()[name] {
+		for _, s := range strings.Split(v, ",") {
+			id, err := uuid.Parse(strings.TrimSpace(s))
+			if err != nil {
+				return nil, errors.New("Invalid " + name)
+			}
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// parseBoundingBox reads an optional bounding box given as
+// min_lon,min_lat,max_lon,max_lat. A missing parameter yields nil. The
+// coordinates are range checked by domain.BoundingBox.Validate.
+func parseBoundingBox(r *http.Request, name string) (*domain.BoundingBox, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	parts := strings.Split(v, ",")
+	if len(parts) != 4 {
+		return nil, errors.New("Invalid " + name)
+	}
+	var coords [4]float64
+	for i, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, errors.New("Invalid " + name)
+		}
+		coords[i] = f
+	}
+	return &domain.BoundingBox{MinLon: coords[0], MinLat: coords[1], MaxLon: coords[2], MaxLat: coords[3]}, nil
+}
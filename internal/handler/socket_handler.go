@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/services"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+const (
+	// SocketPingInterval is how often the server pings an open socket. A
+	// client that has not answered for SocketPongTimeout is disconnected.
+	SocketPingInterval = 30 * time.Second
+	SocketPongTimeout  = 60 * time.Second
+	// SocketWriteTimeout bounds every write, so a client that stops reading
+	// is disconnected instead of holding on to its events.
+	SocketWriteTimeout = 10 * time.Second
+	// socketReadLimit bounds the size of a client message.
+	socketReadLimit = 64 << 10
+)
+
+// Actions a client can send.
+const (
+	socketSubscribe   = "subscribe"
+	socketUnsubscribe = "unsubscribe"
+)
+
+// Types of the messages the server sends.
+const (
+	socketEvent         = "event"
+	socketSubscriptions = "subscriptions"
+	socketDropped       = "dropped"
+	socketError         = "error"
+)
+
+// socketRequest is a message from the client. Subscribe adds the listed
+// vehicles, geofences and alert types to the session, unsubscribe removes
+// them.
+type socketRequest struct {
+	Action     string                 `json:"action"`
+	Vehicles   []uuid.UUID            `json:"vehicles"`
+	Geofences  []uuid.UUID            `json:"geofences"`
+	AlertTypes []domain.AlertRuleKind `json:"alert_types"`
+
+	// malformed is set for messages that could not be decoded.
+	malformed bool
+}
+
+// socketMessage is a message to the client.
+type socketMessage struct {
+	Type       string                 `json:"type"`
+	Event      *domain.Event          `json:"event,omitempty"`
+	Vehicles   []uuid.UUID            `json:"vehicles,omitempty"`
+	Geofences  []uuid.UUID            `json:"geofences,omitempty"`
+	AlertTypes []domain.AlertRuleKind `json:"alert_types,omitempty"`
+	Count      uint64                 `json:"count,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+// socketTopics is what a session is subscribed to.
+type socketTopics struct {
+	vehicles   []uuid.UUID
+	geofences  []uuid.UUID
+	alertTypes []domain.AlertRuleKind
+}
+
+type SocketHandler struct {
+	feed     services.LiveFeedAPI
+	logger   *zap.Logger
+	upgrader websocket.Upgrader
+}
+
+func NewSocketHandler(f services.LiveFeedAPI, l *zap.Logger) *SocketHandler {
+	return &SocketHandler{
+		feed:   f,
+		logger: l,
+		upgrader: websocket.Upgrader{
+			// Sockets are authenticated with a bearer token rather than
+			// cookies, so other origins cannot ride on a user's session.
+			CheckOrigin: func(*http.Request) bool { return true },
+		},
+	}
+}
+
+// Subscribe upgrades the request to a WebSocket that streams the fleet
+// events of the vehicles, geofences and alert types the client subscribes
+// to. A vehicle subscription receives every event of the vehicle, a geofence
+// subscription its enter, exit and dwell events and an alert type
+// subscription the alert events of rules of that kind.
+//
+// Every change of the subscriptions is answered with the full set. Events a
+// slow client missed are reported as a dropped message with their count.
+func (h *SocketHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader has already replied with an error.
+		return
+	}
+	defer conn.Close()
+
+	sub := h.feed.Subscribe()
+	defer sub.Close()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	requests := make(chan socketRequest)
+	go h.read(ctx, cancel, conn, requests)
+
+	ping := time.NewTicker(SocketPingInterval)
+	defer ping.Stop()
+
+	var topics socketTopics
+	for {
+		var msg socketMessage
+		select {
+		case req := <-requests:
+			if err := topics.apply(req); err != nil {
+				msg = socketMessage{Type: socketError, Error: err.Error()}
+				break
+			}
+			sub.SetFilters(topics.filters()...)
+			msg = topics.message()
+		case event, ok := <-sub.Events():
+			if !ok {
+				conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseGoingAway, ""),
+					time.Now().Add(SocketWriteTimeout))
+				return
+			}
+			if dropped := sub.TakeDropped(); dropped > 0 {
+				if err := h.write(conn, socketMessage{Type: socketDropped, Count: dropped}); err != nil {
+					return
+				}
+			}
+			msg = socketMessage{Type: socketEvent, Event: &event}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(SocketWriteTimeout)); err != nil {
+				return
+			}
+			continue
+		case <-ctx.Done():
+			return
+		}
+
+		if err := h.write(conn, msg); err != nil {
+			return
+		}
+	}
+}
+
+// read passes the client's requests to the session until the connection
+// fails, then cancels it. Malformed messages are passed on as well, so the
+// session can answer them.
+func (h *SocketHandler) read(ctx context.Context, cancel context.CancelFunc, conn *websocket.Conn, requests chan<- socketRequest) {
+	defer cancel()
+
+	conn.SetReadLimit(socketReadLimit)
+	conn.SetReadDeadline(time.Now().Add(SocketPongTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(SocketPongTimeout))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var req socketRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			req = socketRequest{malformed: true}
+		}
+		select {
+		case requests <- req:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (h *SocketHandler) write(conn *websocket.Conn, msg socketMessage) error {
+	conn.SetWriteDeadline(time.Now().Add(SocketWriteTimeout))
+	if err := conn.WriteJSON(msg); err != nil {
+		h.logger.Debug("Closing live socket", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// apply adds or removes the topics of req.
+func (t *socketTopics) apply(req socketRequest) error {
+	if req.malformed {
+		return errors.New("Invalid message")
+	}
+	for _, kind := range req.AlertTypes {
+		if !kind.Valid() {
+			return errors.New("Invalid alert_types")
+		}
+	}
+
+	switch req.Action {
+	case socketSubscribe:
+		t.vehicles = addAll(t.vehicles, req.Vehicles)
+		t.geofences = addAll(t.geofences, req.Geofences)
+		t.alertTypes = addAll(t.alertTypes, req.AlertTypes)
+	case socketUnsubscribe:
+		t.vehicles = removeAll(t.vehicles, req.Vehicles)
+		t.geofences = removeAll(t.geofences, req.Geofences)
+		t.alertTypes = removeAll(t.alertTypes, req.AlertTypes)
+	default:
+		return errors.New("Invalid action")
+	}
+	return nil
+}
+
+// filters returns the live filters of the topics; an event passes if it
+// matches any of them.
+func (t *socketTopics) filters() []services.LiveFilter {
+	var filters []services.LiveFilter
+	if len(t.vehicles) > 0 {
+		filters = append(filters, services.LiveFilter{VehicleIDs: t.vehicles})
+	}
+	if len(t.geofences) > 0 {
+		filters = append(filters, services.LiveFilter{GeofenceIDs: t.geofences})
+	}
+	if len(t.alertTypes) > 0 {
+		filters = append(filters, services.LiveFilter{AlertKinds: t.alertTypes})
+	}
+	return filters
+}
+
+func (t *socketTopics) message() socketMessage {
+	return socketMessage{
+		Type:       socketSubscriptions,
+		Vehicles:   t.vehicles,
+		Geofences:  t.geofences,
+		AlertTypes: t.alertTypes,
+	}
+}
+
+// addAll returns set with the missing items appended. The result is a new
+// slice, since filters handed to the hub must not change underneath it.
+func addAll[T comparable](set, items []T) []T {
+	out := slices.Clone(set)
+	for _, item := range items {
+		if !slices.Contains(out, item) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// removeAll returns a new slice of the items of set that are not in items.
+func removeAll[T comparable](set, items []T) []T {
+	var out []T
+	for _, item := range set {
+		if !slices.Contains(items, item) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
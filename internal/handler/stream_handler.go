@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/services"
+	"go.uber.org/zap"
+)
+
+// SSEHeartbeatInterval is how often an idle event stream sends a comment, so
+// proxies and load balancers do not close it.
+const SSEHeartbeatInterval = 15 * time.Second
+
+type StreamHandler struct {
+	feed   services.LiveFeedAPI
+	logger *zap.Logger
+}
+
+func NewStreamHandler(f services.LiveFeedAPI, l *zap.Logger) *StreamHandler {
+	return &StreamHandler{feed: f, logger: l}
+}
+
+// StreamPositions pushes every accepted vehicle status as a Server-Sent
+// Event until the client disconnects. The stream can be narrowed to the
+// vehicles listed in vehicle_id, which may be repeated or comma-separated.
+func (h *StreamHandler) StreamPositions(w http.ResponseWriter, r *http.Request) {
+	vehicleIDs, err := parseUUIDList(r, "vehicle_id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sub := h.feed.Subscribe(services.LiveFilter{
+		Types:      []domain.EventType{domain.EventStatusUpdated},
+		VehicleIDs: vehicleIDs,
+	})
+	defer sub.Close()
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	if err := rc.Flush(); err != nil {
+		h.logger.Error("Streaming is not supported by the response writer", zap.Error(err))
+		return
+	}
+
+	heartbeat := time.NewTicker(SSEHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				h.logger.Error("Failed to encode live event", zap.Error(err))
+				continue
+			}
+			fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case <-r.Context().Done():
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
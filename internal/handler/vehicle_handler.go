@@ -1,11 +1,19 @@
 package handlers
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
 	"github.com/AnjuRKrishnan/fleet-tracker/internal/services"
+	"github.com/AnjuRKrishnan/fleet-tracker/pkg/geojson"
+	"github.com/AnjuRKrishnan/fleet-tracker/pkg/polyline"
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
@@ -26,7 +34,18 @@ func (h *VehicleHandler) IngestData(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.IngestData(r.Context(), req); err != nil {
+	err := h.service.IngestData(r.Context(), req)
+	var verr *domain.ValidationError
+	if errors.As(err, &verr) {
+		writeValidationError(w, verr)
+		return
+	}
+	// A retried message was already processed; acknowledge it again.
+	if errors.Is(err, domain.ErrDuplicateMessage) {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if err != nil {
 		h.logger.Error("Failed to ingest data", zap.Error(err))
 		http.Error(w, "Failed to ingest data", http.StatusInternalServerError)
 		return
@@ -35,6 +54,146 @@ func (h *VehicleHandler) IngestData(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusAccepted)
 }
 
+type validationErrorResponse struct {
+	Error  string              `json:"error"`
+	Fields []domain.FieldError `json:"fields"`
+}
+
+// writeValidationError replies 422 with the machine-readable list of invalid
+// fields.
+func writeValidationError(w http.ResponseWriter, verr *domain.ValidationError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(validationErrorResponse{
+		Error:  "validation failed",
+		Fields: verr.Fields,
+	})
+}
+
+// maxBatchBodyBytes bounds the size of a batch ingest upload.
+const maxBatchBodyBytes = 16 << 20
+
+type batchIngestResponse struct {
+	Accepted  int                   `json:"accepted"`
+	Rejected  int                   `json:"rejected"`
+	Duplicate int                   `json:"duplicate"`
+	Results   []domain.IngestResult `json:"results"`
+}
+
+// IngestBatch accepts a JSON array or an NDJSON stream of ingest requests and
+// reports the outcome of every item.
+func (h *VehicleHandler) IngestBatch(w http.ResponseWriter, r *http.Request) {
+	raw, err := readBatch(http.MaxBytesReader(w, r.Body, maxBatchBodyBytes))
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		http.Error(w, "Batch too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(raw) == 0 {
+		http.Error(w, "Empty batch", http.StatusBadRequest)
+		return
+	}
+	if len(raw) > services.MaxBatchSize {
+		http.Error(w, "Batch too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	// Items that fail to decode are rejected here; the rest go to the
+	// service, remembering where they sat in the upload.
+	results := make([]domain.IngestResult, len(raw))
+	items := make([]domain.IngestRequest, 0, len(raw))
+	indexes := make([]int, 0, len(raw))
+	for i, msg := range raw {
+		var req domain.IngestRequest
+		if err := json.Unmarshal(msg, &req); err != nil {
+			results[i] = domain.IngestResult{Index: i, Status: domain.IngestRejected, Error: "invalid JSON"}
+			continue
+		}
+		items = append(items, req)
+		indexes = append(indexes, i)
+	}
+
+	if len(items) > 0 {
+		itemResults, err := h.service.IngestBatch(r.Context(), items)
+		if err != nil {
+			h.logger.Error("Failed to ingest batch", zap.Int("items", len(items)), zap.Error(err))
+		}
+		// Results that come with an error mean the batch was stored anyway;
+		// failing the request would only make the device upload it again.
+		if itemResults == nil {
+			http.Error(w, "Failed to ingest data", http.StatusInternalServerError)
+			return
+		}
+		for j, res := range itemResults {
+			res.Index = indexes[j]
+			results[indexes[j]] = res
+		}
+	}
+
+	resp := batchIngestResponse{Results: results}
+	for _, res := range results {
+		switch res.Status {
+		case domain.IngestAccepted:
+			resp.Accepted++
+		case domain.IngestDuplicate:
+			resp.Duplicate++
+		default:
+			resp.Rejected++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// readBatch splits a batch upload into its items. A body starting with '[' is
+// read as a JSON array, anything else as newline-delimited JSON.
+func readBatch(body io.Reader) ([]json.RawMessage, error) {
+	br := bufio.NewReader(body)
+	for {
+		b, err := br.ReadByte()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if b == ' ' || b == '\t' || b == '\r' || b == '\n' {
+			continue
+		}
+		if err := br.UnreadByte(); err != nil {
+			return nil, err
+		}
+		if b == '[' {
+			var items []json.RawMessage
+			if err := json.NewDecoder(br).Decode(&items); err != nil {
+				return nil, err
+			}
+			return items, nil
+		}
+		break
+	}
+
+	var items []json.RawMessage
+	scanner := bufio.NewScanner(br)
+	scanner.Buffer(make([]byte, 64*1024), maxBatchBodyBytes)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		items = append(items, json.RawMessage(bytes.Clone(line)))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 func (h *VehicleHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
 	vehicleIDStr := r.URL.Query().Get("vehicle_id")
 	vehicleID, err := uuid.Parse(vehicleIDStr)
@@ -44,13 +203,20 @@ func (h *VehicleHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
 	}
 
 	status, err := h.service.GetVehicleStatus(r.Context(), vehicleID)
+	if errors.Is(err, domain.ErrNotFound) || (err == nil && status == nil) {
+		http.NotFound(w, r)
+		return
+	}
 	if err != nil {
 		h.logger.Error("Failed to get status", zap.Error(err))
 		http.Error(w, "Failed to retrieve status", http.StatusInternalServerError)
 		return
 	}
-	if status == nil {
-		http.NotFound(w, r)
+
+	if wantsGeoJSON(r) {
+		f := statusFeature(vehicleID.String(), *status)
+		f.Properties["vehicle_id"] = vehicleID.String()
+		writeGeoJSON(w, f)
 		return
 	}
 
@@ -58,7 +224,288 @@ func (h *VehicleHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(status)
 }
 
+// GetFleetStatus returns a page of the latest statuses of the fleet, optionally
+// narrowed to some vehicles, a bounding box or statuses updated since a time.
+func (h *VehicleHandler) GetFleetStatus(w http.ResponseWriter, r *http.Request) {
+	vehicleIDs, err := parseUUIDList(r, "vehicle_id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	bbox, err := parseBoundingBox(r, "bbox")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	since, err := parseTime(r, "updated_since")
+	if err != nil {
+		http.Error(w, "Invalid updated_since", http.StatusBadRequest)
+		return
+	}
+	limit, err := parseInt32(r, "limit")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	offset, err := parseInt32(r, "offset")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	snapshots, err := h.service.GetFleetStatus(r.Context(), domain.FleetQuery{
+		VehicleIDs:   vehicleIDs,
+		BBox:         bbox,
+		UpdatedSince: since,
+		Limit:        limit,
+		Offset:       offset,
+	})
+	var verr *domain.ValidationError
+	if errors.As(err, &verr) {
+		writeValidationError(w, verr)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to get fleet status", zap.Error(err))
+		http.Error(w, "Failed to retrieve fleet status", http.StatusInternalServerError)
+		return
+	}
+
+	if wantsGeoJSON(r) {
+		features := make([]geojson.Feature, 0, len(snapshots))
+		for _, v := range snapshots {
+			f := statusFeature(v.VehicleID.String(), v.Status)
+			f.Properties["vehicle_id"] = v.VehicleID.String()
+			features = append(features, f)
+		}
+		writeGeoJSON(w, geojson.NewFeatureCollection(features))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(snapshots)
+}
+
+// FindNearby returns the vehicles closest to lon/lat, nearest first, optionally
+// within a radius in metres.
+func (h *VehicleHandler) FindNearby(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("lon") == "" || q.Get("lat") == "" {
+		http.Error(w, "lon and lat are required", http.StatusBadRequest)
+		return
+	}
+	var query domain.NearbyQuery
+	var err error
+	if query.Longitude, err = parseFloat(r, "lon"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if query.Latitude, err = parseFloat(r, "lat"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if query.Radius, err = parseFloat(r, "radius"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if query.Limit, err = parseInt32(r, "limit"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if query.UpdatedSince, err = parseTime(r, "updated_since"); err != nil {
+		http.Error(w, "Invalid updated_since", http.StatusBadRequest)
+		return
+	}
+
+	vehicles, err := h.service.FindNearbyVehicles(r.Context(), query)
+	var verr *domain.ValidationError
+	if errors.As(err, &verr) {
+		writeValidationError(w, verr)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to find nearby vehicles", zap.Error(err))
+		http.Error(w, "Failed to find nearby vehicles", http.StatusInternalServerError)
+		return
+	}
+
+	if wantsGeoJSON(r) {
+		features := make([]geojson.Feature, 0, len(vehicles))
+		for _, v := range vehicles {
+			f := statusFeature(v.VehicleID.String(), v.Status)
+			f.Properties["vehicle_id"] = v.VehicleID.String()
+			f.Properties["distance"] = v.Distance
+			features = append(features, f)
+		}
+		writeGeoJSON(w, geojson.NewFeatureCollection(features))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(vehicles)
+}
+
+// nextCursorHeader carries the cursor of the next page of a trip listing. It
+// is absent on the last page.
+const nextCursorHeader = "X-Next-Cursor"
+
+// GetTrips returns a page of a vehicle's trips. The cursor of the next page,
+// if any, is sent in the X-Next-Cursor header.
 func (h *VehicleHandler) GetTrips(w http.ResponseWriter, r *http.Request) {
+	query, err := parseTripQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.service.GetVehicleTrips(r.Context(), query)
+	if err != nil {
+		h.logger.Error("Failed to get trips", zap.Error(err))
+		http.Error(w, "Failed to retrieve trips", http.StatusInternalServerError)
+		return
+	}
+	if page.Next != nil {
+		w.Header().Set(nextCursorHeader, page.Next.String())
+	}
+
+	if wantsGeoJSON(r) {
+		routes, err := h.service.GetTripsPositions(r.Context(), page.Trips)
+		if err != nil {
+			h.logger.Error("Failed to get trip positions", zap.Error(err))
+			http.Error(w, "Failed to retrieve trips", http.StatusInternalServerError)
+			return
+		}
+		features := make([]geojson.Feature, 0, len(page.Trips))
+		for i, trip := range page.Trips {
+			f := tripFeature(trip, routes[i].Positions)
+			f.Properties["truncated"] = routes[i].Truncated
+			features = append(features, f)
+		}
+		writeGeoJSON(w, geojson.NewFeatureCollection(features))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page.Trips)
+}
+
+// parseTripQuery reads the vehicle_id, from/to, min_mileage, sort, cursor and
+// limit query parameters of a trip listing.
+func parseTripQuery(r *http.Request) (domain.TripQuery, error) {
+	vehicleID, err := uuid.Parse(r.URL.Query().Get("vehicle_id"))
+	if err != nil {
+		return domain.TripQuery{}, errors.New("Invalid vehicle_id")
+	}
+	query := domain.TripQuery{VehicleID: vehicleID}
+
+	if query.From, query.To, err = parseTimeRange(r); err != nil {
+		return domain.TripQuery{}, err
+	}
+	if query.MinMileage, err = parseFloat(r, "min_mileage"); err != nil || query.MinMileage < 0 {
+		return domain.TripQuery{}, errors.New("Invalid min_mileage")
+	}
+	if query.Order, err = parseSortOrder(r, "sort"); err != nil {
+		return domain.TripQuery{}, err
+	}
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		if query.After, err = domain.ParseTripCursor(cursor); err != nil {
+			return domain.TripQuery{}, errors.New("Invalid cursor")
+		}
+	}
+	if query.Limit, err = parseInt32(r, "limit"); err != nil {
+		return domain.TripQuery{}, err
+	}
+	return query, nil
+}
+
+// GetTrip returns a single trip.
+func (h *VehicleHandler) GetTrip(w http.ResponseWriter, r *http.Request) {
+	tripID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid trip id", http.StatusBadRequest)
+		return
+	}
+
+	trip, err := h.service.GetTrip(r.Context(), tripID)
+	if errors.Is(err, domain.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to get trip", zap.Error(err))
+		http.Error(w, "Failed to retrieve trip", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(trip)
+}
+
+type tripRouteResponse struct {
+	Trip      domain.Trip       `json:"trip"`
+	Positions []domain.Position `json:"positions"`
+}
+
+// tripPolylineResponse carries the route as an encoded polyline, with the
+// time of every point of the line.
+type tripPolylineResponse struct {
+	Trip       domain.Trip `json:"trip"`
+	Polyline   string      `json:"polyline"`
+	Timestamps []time.Time `json:"timestamps"`
+}
+
+// GetTripRoute returns the positions recorded during a trip in chronological
+// order, optionally thinned out to max_points. With format=polyline the
+// route is an encoded polyline, with format=geojson a LineString feature.
+func (h *VehicleHandler) GetTripRoute(w http.ResponseWriter, r *http.Request) {
+	tripID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid trip id", http.StatusBadRequest)
+		return
+	}
+	maxPoints, err := parseInt32(r, "max_points")
+	if err != nil || maxPoints == 1 {
+		http.Error(w, "Invalid max_points", http.StatusBadRequest)
+		return
+	}
+
+	trip, positions, err := h.service.GetTripRoute(r.Context(), tripID, int(maxPoints))
+	if errors.Is(err, domain.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to get trip route", zap.Error(err))
+		http.Error(w, "Failed to retrieve trip route", http.StatusInternalServerError)
+		return
+	}
+
+	if wantsGeoJSON(r) {
+		writeGeoJSON(w, tripFeature(*trip, positions))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if r.URL.Query().Get("format") == "polyline" {
+		resp := tripPolylineResponse{Trip: *trip, Timestamps: []time.Time{}}
+		coords := make([][]float64, 0, len(positions))
+		for _, p := range positions {
+			if lon, lat, ok := p.Coordinates(); ok {
+				coords = append(coords, []float64{lon, lat})
+				resp.Timestamps = append(resp.Timestamps, p.Timestamp)
+			}
+		}
+		resp.Polyline = polyline.Encode(coords)
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
+	if positions == nil {
+		positions = []domain.Position{}
+	}
+	json.NewEncoder(w).Encode(tripRouteResponse{Trip: *trip, Positions: positions})
+}
+
+func (h *VehicleHandler) GetPositions(w http.ResponseWriter, r *http.Request) {
 	vehicleIDStr := r.URL.Query().Get("vehicle_id")
 	vehicleID, err := uuid.Parse(vehicleIDStr)
 	if err != nil {
@@ -66,13 +513,40 @@ func (h *VehicleHandler) GetTrips(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	trips, err := h.service.GetVehicleTrips(r.Context(), vehicleID)
+	from, to, err := parseTimeRange(r)
 	if err != nil {
-		h.logger.Error("Failed to get trips", zap.Error(err))
-		http.Error(w, "Failed to retrieve trips", http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, err := parseInt32(r, "limit")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	offset, err := parseInt32(r, "offset")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	positions, err := h.service.GetVehiclePositions(r.Context(), domain.PositionQuery{
+		VehicleID: vehicleID,
+		From:      from,
+		To:        to,
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		h.logger.Error("Failed to get positions", zap.Error(err))
+		http.Error(w, "Failed to retrieve positions", http.StatusInternalServerError)
+		return
+	}
+
+	if wantsGeoJSON(r) {
+		writeGeoJSON(w, positionsFeatureCollection(positions))
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(trips)
+	json.NewEncoder(w).Encode(positions)
 }
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/services"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type VehicleRegistryHandler struct {
+	service services.VehicleRegistryAPI
+	logger  *zap.Logger
+}
+
+func NewVehicleRegistryHandler(s services.VehicleRegistryAPI, l *zap.Logger) *VehicleRegistryHandler {
+	return &VehicleRegistryHandler{service: s, logger: l}
+}
+
+// Create registers a vehicle. The id may be given to register a vehicle that
+// is already reporting.
+func (h *VehicleRegistryHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var vehicle domain.Vehicle
+	if err := json.NewDecoder(r.Body).Decode(&vehicle); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.service.CreateVehicle(r.Context(), vehicle)
+	if h.writeSaveError(w, r, err, "Failed to create vehicle") {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// List returns a page of the registry, oldest registration first. Archived
+// vehicles are only included with include_archived=true.
+func (h *VehicleRegistryHandler) List(w http.ResponseWriter, r *http.Request) {
+	limit, err := parseInt32(r, "limit")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	offset, err := parseInt32(r, "offset")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var includeArchived bool
+	if v := r.URL.Query().Get("include_archived"); v != "" {
+		if includeArchived, err = strconv.ParseBool(v); err != nil {
+			http.Error(w, "Invalid include_archived", http.StatusBadRequest)
+			return
+		}
+	}
+
+	vehicles, err := h.service.ListVehicles(r.Context(), domain.VehicleQuery{
+		IncludeArchived: includeArchived,
+		Limit:           limit,
+		Offset:          offset,
+	})
+	if err != nil {
+		h.logger.Error("Failed to list vehicles", zap.Error(err))
+		http.Error(w, "Failed to retrieve vehicles", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(vehicles)
+}
+
+func (h *VehicleRegistryHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid vehicle id", http.StatusBadRequest)
+		return
+	}
+
+	vehicle, err := h.service.GetVehicle(r.Context(), id)
+	h.writeVehicle(w, r, vehicle, err, "Failed to retrieve vehicle")
+}
+
+// GetByPlate looks a vehicle up by its plate number.
+func (h *VehicleRegistryHandler) GetByPlate(w http.ResponseWriter, r *http.Request) {
+	vehicle, err := h.service.GetVehicleByPlate(r.Context(), chi.URLParam(r, "plate"))
+	h.writeVehicle(w, r, vehicle, err, "Failed to retrieve vehicle")
+}
+
+// Update replaces the plate number and name of a vehicle. Omitted fields are
+// reset.
+func (h *VehicleRegistryHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid vehicle id", http.StatusBadRequest)
+		return
+	}
+
+	var vehicle domain.Vehicle
+	if err := json.NewDecoder(r.Body).Decode(&vehicle); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	vehicle.ID = id
+
+	updated, err := h.service.UpdateVehicle(r.Context(), vehicle)
+	if h.writeSaveError(w, r, err, "Failed to update vehicle") {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+// Archive retires a vehicle. Its history is kept.
+func (h *VehicleRegistryHandler) Archive(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid vehicle id", http.StatusBadRequest)
+		return
+	}
+
+	vehicle, err := h.service.ArchiveVehicle(r.Context(), id)
+	h.writeVehicle(w, r, vehicle, err, "Failed to archive vehicle")
+}
+
+// writeVehicle replies with the vehicle a lookup or archive returned.
+func (h *VehicleRegistryHandler) writeVehicle(w http.ResponseWriter, r *http.Request, vehicle *domain.Vehicle, err error, message string) {
+	if errors.Is(err, domain.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		h.logger.Error(message, zap.Error(err))
+		http.Error(w, message, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(vehicle)
+}
+
+// writeSaveError replies to a failed create or update and reports whether
+// there was an error.
+func (h *VehicleRegistryHandler) writeSaveError(w http.ResponseWriter, r *http.Request, err error, message string) bool {
+	var verr *domain.ValidationError
+	switch {
+	case err == nil:
+		return false
+	case errors.As(err, &verr):
+		writeValidationError(w, verr)
+	case errors.Is(err, domain.ErrNotFound):
+		http.NotFound(w, r)
+	case errors.Is(err, domain.ErrVehicleExists):
+		http.Error(w, "Vehicle ID or plate number is already registered", http.StatusConflict)
+	default:
+		h.logger.Error(message, zap.Error(err))
+		http.Error(w, message, http.StatusInternalServerError)
+	}
+	return true
+}
@@ -0,0 +1,160 @@
+package ingest
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ErrNoPosition is returned by a LoRaWANDecoder for uplinks that do not carry
+// a position, such as heartbeats and battery reports.
+var ErrNoPosition = errors.New("uplink carries no position")
+
+// LoRaWANUplink is an uplink forwarded by a LoRaWAN network server.
+type LoRaWANUplink struct {
+	// DevEUI is the device EUI in upper-case hex.
+	DevEUI  string
+	FPort   uint8
+	FCnt    uint32
+	Payload []byte
+	// Decoded is the payload as decoded by the network server's payload
+	// formatter or codec, if one is configured.
+	Decoded    json.RawMessage
+	ReceivedAt time.Time
+}
+
+// IngestRequest converts a status decoded from the uplink to an ingest
+// request of the device. A status without a timestamp gets the time the
+// uplink was received. The DevEUI and frame counter make up the message ID,
+// so a webhook the network server retries is processed once even when it
+// carries no receive time.
+func (u LoRaWANUplink) IngestRequest(device *domain.Device, status domain.VehicleStatus) domain.IngestRequest {
+	if status.Timestamp.IsZero() {
+		status.Timestamp = u.ReceivedAt
+	}
+	return domain.IngestRequest{
+		VehicleID:   pgtype.UUID{Bytes: device.VehicleID, Valid: true},
+		Status:      status,
+		PlateNumber: device.PlateNumber,
+		MessageID:   "lorawan:" + u.DevEUI + ":" + strconv.FormatUint(uint64(u.FCnt), 10),
+	}
+}
+
+type ttsUplink struct {
+	EndDeviceIDs struct {
+		DevEUI string `json:"dev_eui"`
+	} `json:"end_device_ids"`
+	ReceivedAt    time.Time `json:"received_at"`
+	UplinkMessage *struct {
+		FPort          uint8           `json:"f_port"`
+		FCnt           uint32          `json:"f_cnt"`
+		FRMPayload     []byte          `json:"frm_payload"`
+		DecodedPayload json.RawMessage `json:"decoded_payload"`
+		ReceivedAt     time.Time       `json:"received_at"`
+	} `json:"uplink_message"`
+}
+
+// ParseTTSUplink reads the uplink message of a The Things Stack webhook.
+func ParseTTSUplink(body []byte) (LoRaWANUplink, error) {
+	var msg ttsUplink
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return LoRaWANUplink{}, errors.New("invalid uplink message")
+	}
+	if msg.UplinkMessage == nil {
+		return LoRaWANUplink{}, errors.New("not an uplink message")
+	}
+	up := msg.UplinkMessage
+	receivedAt := up.ReceivedAt
+	if receivedAt.IsZero() {
+		receivedAt = msg.ReceivedAt
+	}
+	return newLoRaWANUplink(msg.EndDeviceIDs.DevEUI, up.FPort, up.FCnt, up.FRMPayload, up.DecodedPayload, receivedAt)
+}
+
+type chirpStackUplink struct {
+	Time       time.Time `json:"time"`
+	DeviceInfo struct {
+		DevEUI string `json:"devEui"`
+	} `json:"deviceInfo"`
+	FPort  uint8           `json:"fPort"`
+	FCnt   uint32          `json:"fCnt"`
+	Data   []byte          `json:"data"`
+	Object json.RawMessage `json:"object"`
+}
+
+// ParseChirpStackUplink reads the "up" event of the ChirpStack v4 HTTP
+// integration.
+func ParseChirpStackUplink(body []byte) (LoRaWANUplink, error) {
+	var msg chirpStackUplink
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return LoRaWANUplink{}, errors.New("invalid uplink event")
+	}
+	return newLoRaWANUplink(msg.DeviceInfo.DevEUI, msg.FPort, msg.FCnt, msg.Data, msg.Object, msg.Time)
+}
+
+func newLoRaWANUplink(devEUI string, fPort uint8, fCnt uint32, payload []byte, decoded json.RawMessage, receivedAt time.Time) (LoRaWANUplink, error) {
+	if len(devEUI) != 16 || strings.Trim(strings.ToUpper(devEUI), "0123456789ABCDEF") != "" {
+		return LoRaWANUplink{}, errors.New("invalid DevEUI")
+	}
+	if string(decoded) == "null" {
+		decoded = nil
+	}
+	if receivedAt.IsZero() {
+		receivedAt = time.Now()
+	}
+	return LoRaWANUplink{
+		DevEUI:     strings.ToUpper(devEUI),
+		FPort:      fPort,
+		FCnt:       fCnt,
+		Payload:    payload,
+		Decoded:    decoded,
+		ReceivedAt: receivedAt.UTC(),
+	}, nil
+}
+
+// LoRaWANDecoder extracts a position from the uplinks of one tracker model.
+// It returns ErrNoPosition for uplinks without one.
+type LoRaWANDecoder interface {
+	Decode(uplink LoRaWANUplink) (domain.VehicleStatus, error)
+}
+
+// LoRaWANDecoderFunc adapts a function to a LoRaWANDecoder.
+type LoRaWANDecoderFunc func(uplink LoRaWANUplink) (domain.VehicleStatus, error)
+
+func (f LoRaWANDecoderFunc) Decode(uplink LoRaWANUplink) (domain.VehicleStatus, error) {
+	return f(uplink)
+}
+
+// LoRaWANDecoders selects the decoder of an uplink by the model of the device
+// that sent it. Decoders are registered at startup, before uplinks are
+// decoded.
+type LoRaWANDecoders struct {
+	decoders map[string]LoRaWANDecoder
+}
+
+// NewLoRaWANDecoders returns the built-in decoders: the payload decoded by
+// the network server for devices without a model, and "cayenne-lpp".
+func NewLoRaWANDecoders() *LoRaWANDecoders {
+	return &LoRaWANDecoders{
+		decoders: map[string]LoRaWANDecoder{
+			"":            LoRaWANDecoderFunc(DecodeNetworkServerPayload),
+			"cayenne-lpp": LoRaWANDecoderFunc(DecodeCayenneLPP),
+		},
+	}
+}
+
+// Register adds or replaces the decoder of a model.
+func (d *LoRaWANDecoders) Register(model string, decoder LoRaWANDecoder) {
+	d.decoders[model] = decoder
+}
+
+// Decoder returns the decoder of a model, and false if there is none.
+func (d *LoRaWANDecoders) Decoder(model string) (LoRaWANDecoder, bool) {
+	decoder, ok := d.decoders[model]
+	return decoder, ok
+}
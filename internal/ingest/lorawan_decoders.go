@@ -0,0 +1,109 @@
+package ingest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+)
+
+// DecodeNetworkServerPayload reads the position from the payload the network
+// server's formatter decoded. It understands the field names common
+// formatters use: latitude/lat, longitude/lon/lng, altitude/alt, speed in
+// km/h, heading/course/bearing, accuracy, hdop and satellites/sats.
+func DecodeNetworkServerPayload(uplink LoRaWANUplink) (domain.VehicleStatus, error) {
+	if uplink.Decoded == nil {
+		return domain.VehicleStatus{}, ErrNoPosition
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(uplink.Decoded, &fields); err != nil {
+		return domain.VehicleStatus{}, errors.New("decoded payload is not an object")
+	}
+
+	lat := decodedNumber(fields, "latitude", "lat")
+	lon := decodedNumber(fields, "longitude", "lon", "lng")
+	if lat == nil || lon == nil {
+		return domain.VehicleStatus{}, ErrNoPosition
+	}
+	status := domain.VehicleStatus{
+		Location: []float64{*lon, *lat},
+		Altitude: decodedNumber(fields, "altitude", "alt"),
+		Heading:  decodedNumber(fields, "heading", "course", "bearing"),
+		Accuracy: decodedNumber(fields, "accuracy"),
+		HDOP:     decodedNumber(fields, "hdop"),
+	}
+	if speed := decodedNumber(fields, "speed"); speed != nil {
+		status.Speed = *speed
+	}
+	if sats := decodedNumber(fields, "satellites", "sats"); sats != nil {
+		n := int(*sats)
+		status.Satellites = &n
+	}
+	return status, nil
+}
+
+func decodedNumber(fields map[string]any, keys ...string) *float64 {
+	for _, key := range keys {
+		if v, ok := fields[key].(float64); ok {
+			return &v
+		}
+	}
+	return nil
+}
+
+// cayenneGPS is the Cayenne LPP type of a GPS location.
+const cayenneGPS = 0x88
+
+// cayenneSizes is the size in bytes of the value of every Cayenne LPP type.
+var cayenneSizes = map[byte]int{
+	0x00: 1, // digital input
+	0x01: 1, // digital output
+	0x02: 2, // analog input
+	0x03: 2, // analog output
+	0x65: 2, // illuminance
+	0x66: 1, // presence
+	0x67: 2, // temperature
+	0x68: 1, // relative humidity
+	0x71: 6, // accelerometer
+	0x73: 2, // barometer
+	0x86: 6, // gyrometer
+	0x88: 9, // GPS location
+}
+
+// DecodeCayenneLPP reads the first GPS location of a Cayenne Low Power
+// Payload: latitude and longitude in 0.0001° and altitude in 0.01 m, each as
+// a signed 24-bit integer.
+func DecodeCayenneLPP(uplink LoRaWANUplink) (domain.VehicleStatus, error) {
+	b := uplink.Payload
+	for len(b) > 0 {
+		if len(b) < 2 {
+			return domain.VehicleStatus{}, errors.New("truncated Cayenne LPP payload")
+		}
+		typ := b[1]
+		size, ok := cayenneSizes[typ]
+		if !ok {
+			return domain.VehicleStatus{}, fmt.Errorf("unknown Cayenne LPP type 0x%02X", typ)
+		}
+		if len(b) < 2+size {
+			return domain.VehicleStatus{}, errors.New("truncated Cayenne LPP payload")
+		}
+		value := b[2 : 2+size]
+		b = b[2+size:]
+		if typ != cayenneGPS {
+			continue
+		}
+
+		altitude := float64(int24(value[6:9])) / 100
+		return domain.VehicleStatus{
+			Location: []float64{float64(int24(value[3:6])) / 1e4, float64(int24(value[0:3])) / 1e4},
+			Altitude: &altitude,
+		}, nil
+	}
+	return domain.VehicleStatus{}, ErrNoPosition
+}
+
+// int24 reads a big-endian signed 24-bit integer.
+func int24(b []byte) int32 {
+	return int32(uint32(b[0])<<24|uint32(b[1])<<16|uint32(b[2])<<8) >> 8
+}
@@ -0,0 +1,245 @@
+// Package ingest holds the sources that receive telemetry over device
+// protocols and feed it into the ingest pipeline.
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/services"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+const (
+	// mqttReconnectDelay is the longest pause between reconnection attempts.
+	mqttReconnectDelay = 30 * time.Second
+	// mqttDisconnectQuiesce is how long in-flight work may take when the
+	// client disconnects, in milliseconds.
+	mqttDisconnectQuiesce = 250
+	// mqttRetryDelay is the first pause before a failed ingest is retried.
+	// It doubles up to mqttReconnectDelay.
+	mqttRetryDelay = time.Second
+	// mqttMaxIngests caps the messages that are ingested at the same time.
+	mqttMaxIngests = 5
+)
+
+// MQTTConfig configures an MQTTSource.
+//
+// Topics are subscription filters. The level matched by the first "+" of a
+// filter is taken as the vehicle ID, so "fleet/+/telemetry" receives the
+// readings of vehicle <id> on fleet/<id>/telemetry. Filters without "+" need
+// the vehicle_id in the payload. Shared subscriptions ("$share/<group>/...")
+// let several instances split the messages of one filter.
+type MQTTConfig struct {
+	BrokerURL string
+	ClientID  string
+	Username  string
+	Password  string
+	Topics    []string
+	QoS       byte
+}
+
+// MQTTSource subscribes to telemetry topics on an MQTT broker and ingests
+// every reading.
+//
+// With QoS 1 a message is only acknowledged once it has been stored, so the
+// broker redelivers what was not stored before a disconnect or crash. Failed
+// ingests are retried until they succeed or the source stops. The session is
+// persistent, so messages published while the client was reconnecting are
+// delivered too. Payloads that cannot be decoded and readings the ingest
+// rejects are acknowledged and dropped, since redelivering them would not
+// help.
+type MQTTSource struct {
+	cfg      MQTTConfig
+	ingester services.Ingester
+	slots    chan struct{}
+	logger   *zap.Logger
+}
+
+// NewMQTTSource creates an MQTTSource that stores readings with ingester.
+func NewMQTTSource(cfg MQTTConfig, ingester services.Ingester, logger *zap.Logger) *MQTTSource {
+	return &MQTTSource{
+		cfg:      cfg,
+		ingester: ingester,
+		slots:    make(chan struct{}, mqttMaxIngests),
+		logger:   logger,
+	}
+}
+
+// Run connects to the broker and receives messages until ctx is cancelled.
+// Lost connections are re-established and the topics subscribed again.
+func (s *MQTTSource) Run(ctx context.Context) {
+	opts := mqtt.NewClientOptions().
+		AddBroker(s.cfg.BrokerURL).
+		SetClientID(s.cfg.ClientID).
+		SetUsername(s.cfg.Username).
+		SetPassword(s.cfg.Password).
+		SetCleanSession(false).
+		SetOrderMatters(false).
+		SetAutoAckDisabled(true).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetMaxReconnectInterval(mqttReconnectDelay).
+		SetConnectRetryInterval(mqttReconnectDelay).
+		SetOnConnectHandler(func(c mqtt.Client) {
+			s.logger.Info("Connected to MQTT broker", zap.String("broker", s.cfg.BrokerURL))
+			s.subscribe(ctx, c)
+		}).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			s.logger.Warn("Lost connection to MQTT broker", zap.Error(err))
+		})
+
+	client := mqtt.NewClient(opts)
+	// With ConnectRetry the token only completes once connected or when the
+	// client is disconnected.
+	client.Connect()
+	<-ctx.Done()
+	client.Disconnect(mqttDisconnectQuiesce)
+}
+
+// subscribe (re)subscribes every topic. It runs on each connect, since the
+// broker may have lost the session.
+func (s *MQTTSource) subscribe(ctx context.Context, c mqtt.Client) {
+	for _, topic := range s.cfg.Topics {
+		filter := topic
+		token := c.Subscribe(filter, s.cfg.QoS, func(_ mqtt.Client, msg mqtt.Message) {
+			s.handle(ctx, filter, msg)
+		})
+		go func() {
+			if token.Wait(); token.Error() != nil {
+				s.logger.Error("Failed to subscribe to MQTT topic",
+					zap.String("topic", filter),
+					zap.Error(token.Error()),
+				)
+			}
+		}()
+	}
+}
+
+// handle ingests one message and acknowledges it once it is stored. Only
+// mqttMaxIngests messages are ingested at a time; the others wait, which holds
+// back further deliveries from the broker.
+func (s *MQTTSource) handle(ctx context.Context, filter string, msg mqtt.Message) {
+	req, err := ParseMQTTMessage(filter, msg.Topic(), msg.Payload())
+	if err != nil {
+		s.logger.Warn("Dropping invalid MQTT message",
+			zap.String("topic", msg.Topic()),
+			zap.Error(err),
+		)
+		msg.Ack()
+		return
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-ctx.Done():
+		return
+	}
+
+	delay := mqttRetryDelay
+	for {
+		err := s.ingester.IngestData(ctx, req)
+		var verr *domain.ValidationError
+		switch {
+		case err == nil, errors.Is(err, domain.ErrDuplicateMessage):
+			msg.Ack()
+			return
+		case errors.As(err, &verr):
+			s.logger.Warn("Dropping rejected MQTT message",
+				zap.String("topic", msg.Topic()),
+				zap.Error(err),
+			)
+			msg.Ack()
+			return
+		}
+
+		s.logger.Error("Failed to ingest MQTT message",
+			zap.String("topic", msg.Topic()),
+			zap.Duration("retry_in", delay),
+			zap.Error(err),
+		)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			// Not acknowledged, so the broker redelivers it after a restart.
+			return
+		}
+		delay = min(2*delay, mqttReconnectDelay)
+	}
+}
+
+// mqttPayload is a reading published over MQTT: an ingest request without
+// the vehicle ID, which the topic carries, or just the status.
+type mqttPayload struct {
+	VehicleID   pgtype.UUID           `json:"vehicle_id"`
+	Status      *domain.VehicleStatus `json:"status"`
+	PlateNumber string                `json:"plate_number"`
+	MessageID   string                `json:"message_id"`
+}
+
+// ParseMQTTMessage maps a message received on topic through the
+// subscription filter to an ingest request. The payload is either an ingest
+// request, whose vehicle_id may be omitted, or a bare VehicleStatus. The
+// request is not validated; that is left to the ingest pipeline.
+func ParseMQTTMessage(filter, topic string, payload []byte) (domain.IngestRequest, error) {
+	var p mqttPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return domain.IngestRequest{}, errors.New("invalid payload")
+	}
+	if p.Status == nil {
+		p.Status = &domain.VehicleStatus{}
+		if err := json.Unmarshal(payload, p.Status); err != nil {
+			return domain.IngestRequest{}, errors.New("invalid payload")
+		}
+	}
+
+	if level, ok := vehicleLevel(filter, topic); ok {
+		vehicleID, err := uuid.Parse(level)
+		if err != nil {
+			return domain.IngestRequest{}, errors.New("invalid vehicle ID in topic")
+		}
+		if p.VehicleID.Valid && uuid.UUID(p.VehicleID.Bytes) != vehicleID {
+			return domain.IngestRequest{}, errors.New("vehicle_id does not match the topic")
+		}
+		p.VehicleID = pgtype.UUID{Bytes: vehicleID, Valid: true}
+	}
+
+	return domain.IngestRequest{
+		VehicleID:   p.VehicleID,
+		Status:      *p.Status,
+		PlateNumber: p.PlateNumber,
+		MessageID:   p.MessageID,
+	}, nil
+}
+
+// vehicleLevel returns the level of topic matched by the first "+" of
+// filter.
+func vehicleLevel(filter, topic string) (string, bool) {
+	if strings.HasPrefix(filter, "$share/") {
+		parts := strings.SplitN(filter, "/", 3)
+		if len(parts) < 3 {
+			return "", false
+		}
+		filter = parts[2]
+	}
+
+	filterLevels := strings.Split(filter, "/")
+	topicLevels := strings.Split(topic, "/")
+	for i, level := range filterLevels {
+		if level == "#" || i >= len(topicLevels) {
+			return "", false
+		}
+		if level == "+" {
+			return topicLevels[i], true
+		}
+	}
+	return "", false
+}
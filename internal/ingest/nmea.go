@@ -0,0 +1,176 @@
+package ingest
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/services"
+	"github.com/AnjuRKrishnan/fleet-tracker/pkg/nmea"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+const (
+	// nmeaMaxLineLength bounds a line; NMEA sentences are at most 82
+	// characters.
+	nmeaMaxLineLength = 1024
+	// nmeaMaxBadLines is how many invalid lines in a row a connection may
+	// send before it is dropped.
+	nmeaMaxBadLines = 20
+)
+
+// NMEAServer receives NMEA 0183 sentences from GPS units over TCP.
+//
+// A unit first sends a line with its device identifier, followed by one
+// sentence per line. Every valid RMC sentence becomes a reading of the
+// vehicle the identifier is registered for; a GGA sentence of the same fix
+// adds satellites, HDOP and altitude if it comes first. Other sentence types
+// are ignored. Connections of unregistered devices, connections that keep
+// sending garbage and connections that stay silent for the idle timeout are
+// closed.
+type NMEAServer struct {
+	addr        string
+	idleTimeout time.Duration
+	devices     services.DeviceResolver
+	out         chan<- domain.IngestRequest
+	logger      *zap.Logger
+}
+
+// NewNMEAServer creates an NMEAServer that listens on addr and sends to out.
+func NewNMEAServer(addr string, idleTimeout time.Duration, devices services.DeviceResolver, out chan<- domain.IngestRequest, logger *zap.Logger) *NMEAServer {
+	return &NMEAServer{
+		addr:        addr,
+		idleTimeout: idleTimeout,
+		devices:     devices,
+		out:         out,
+		logger:      logger,
+	}
+}
+
+// ListenAndServe listens on the server's address and serves connections
+// until ctx is cancelled.
+func (s *NMEAServer) ListenAndServe(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve serves connections accepted on ln until ctx is cancelled.
+func (s *NMEAServer) Serve(ctx context.Context, ln net.Listener) error {
+	return serveTCP(ctx, ln, "NMEA", s.logger, s.handle)
+}
+
+func (s *NMEAServer) handle(ctx context.Context, conn net.Conn) {
+	logger := s.logger.With(zap.String("remote_addr", conn.RemoteAddr().String()))
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 128), nmeaMaxLineLength)
+
+	var (
+		device   *domain.Device
+		lastFix  *nmea.GGA
+		badLines int
+	)
+	for {
+		conn.SetReadDeadline(time.Now().Add(s.idleTimeout))
+		if !scanner.Scan() {
+			s.logClosed(logger, scanner.Err())
+			return
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(line, "$") {
+			var err error
+			if device, err = s.devices.ResolveDevice(ctx, line); err != nil {
+				if errors.Is(err, domain.ErrNotFound) {
+					logger.Warn("Closing NMEA connection of unregistered device", zap.String("device", line))
+				} else if ctx.Err() == nil {
+					logger.Error("Failed to resolve NMEA device", zap.String("device", line), zap.Error(err))
+				}
+				return
+			}
+			logger = logger.With(zap.String("device", line))
+			continue
+		}
+		if device == nil {
+			logger.Warn("Closing NMEA connection that sent sentences before its device identifier")
+			return
+		}
+
+		sentence, err := nmea.Parse(line)
+		if errors.Is(err, nmea.ErrUnsupported) {
+			continue
+		}
+		if err != nil {
+			badLines++
+			logger.Debug("Skipping invalid NMEA sentence", zap.String("line", line), zap.Error(err))
+			if badLines >= nmeaMaxBadLines {
+				logger.Warn("Closing NMEA connection after repeated invalid sentences", zap.Error(err))
+				return
+			}
+			continue
+		}
+		badLines = 0
+
+		switch v := sentence.(type) {
+		case nmea.GGA:
+			lastFix = &v
+		case nmea.RMC:
+			if !v.Valid {
+				continue
+			}
+			select {
+			case s.out <- nmeaReading(device, v, lastFix):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+func (s *NMEAServer) logClosed(logger *zap.Logger, err error) {
+	var ne net.Error
+	switch {
+	case err == nil, errors.Is(err, net.ErrClosed):
+		// The device hung up or the server is shutting down.
+	case errors.As(err, &ne) && ne.Timeout():
+		logger.Info("Closing idle NMEA connection")
+	case errors.Is(err, bufio.ErrTooLong):
+		logger.Warn("Closing NMEA connection that sent an overlong line")
+	default:
+		logger.Warn("NMEA connection failed", zap.Error(err))
+	}
+}
+
+// nmeaReading converts an RMC fix to an ingest request of the device. The GGA
+// fix is merged in if it was taken at the same time.
+func nmeaReading(device *domain.Device, rmc nmea.RMC, gga *nmea.GGA) domain.IngestRequest {
+	status := domain.VehicleStatus{
+		Location:  []float64{rmc.Longitude, rmc.Latitude},
+		Speed:     rmc.SpeedKnots * nmea.KnotsToKmh,
+		Timestamp: rmc.Time,
+		Heading:   rmc.Course,
+	}
+	midnight := rmc.Time.Truncate(24 * time.Hour)
+	if gga != nil && gga.Quality > 0 && rmc.Time.Sub(midnight) == gga.TimeOfDay {
+		status.Satellites = gga.Satellites
+		status.HDOP = gga.HDOP
+		status.Altitude = gga.Altitude
+	}
+
+	return domain.IngestRequest{
+		VehicleID:   pgtype.UUID{Bytes: device.VehicleID, Valid: true},
+		Status:      status,
+		PlateNumber: device.PlateNumber,
+	}
+}
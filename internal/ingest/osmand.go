@@ -0,0 +1,151 @@
+package ingest
+
+import (
+	"errors"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/pkg/nmea"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// OsmAndReading is a position reported over the OsmAnd protocol that phone
+// trackers such as OsmAnd, Traccar Client and GPSLogger speak.
+type OsmAndReading struct {
+	// DeviceID is the identifier the app is configured with.
+	DeviceID string
+	Status   domain.VehicleStatus
+	// Valid is false when the app reports that it has no fix.
+	Valid bool
+}
+
+// ParseOsmAnd reads a position from the query or form parameters of an
+// OsmAnd request, e.g.
+// "?id=phone-7&lat=25.2769&lon=55.2962&timestamp=1750148316&speed=32.4&bearing=87.5".
+//
+// As in Traccar, speed is in knots. The timestamp may be Unix seconds or
+// milliseconds, RFC 3339 or "2006-01-02 15:04:05" in UTC, and defaults to
+// now. The position may also be given as location=<lat>,<lon>.
+func ParseOsmAnd(form url.Values) (OsmAndReading, error) {
+	reading := OsmAndReading{
+		DeviceID: firstValue(form, "id", "deviceid"),
+		Valid:    true,
+	}
+	if reading.DeviceID == "" {
+		return OsmAndReading{}, errors.New("missing id")
+	}
+
+	lat, lon := form.Get("lat"), form.Get("lon")
+	if location := form.Get("location"); location != "" && lat == "" && lon == "" {
+		lat, lon, _ = strings.Cut(location, ",")
+	}
+	latitude, err := optionalFloat(lat, "lat")
+	if err != nil || latitude == nil {
+		return OsmAndReading{}, errors.New("invalid lat")
+	}
+	longitude, err := optionalFloat(lon, "lon")
+	if err != nil || longitude == nil {
+		return OsmAndReading{}, errors.New("invalid lon")
+	}
+	reading.Status.Location = []float64{*longitude, *latitude}
+
+	if reading.Status.Timestamp, err = parseOsmAndTime(form.Get("timestamp")); err != nil {
+		return OsmAndReading{}, err
+	}
+
+	speed, err := optionalFloat(form.Get("speed"), "speed")
+	if err != nil {
+		return OsmAndReading{}, err
+	}
+	if speed != nil {
+		reading.Status.Speed = *speed * nmea.KnotsToKmh
+	}
+	if reading.Status.Heading, err = optionalFloat(firstValue(form, "bearing", "heading"), "bearing"); err != nil {
+		return OsmAndReading{}, err
+	}
+	if reading.Status.Altitude, err = optionalFloat(form.Get("altitude"), "altitude"); err != nil {
+		return OsmAndReading{}, err
+	}
+	if reading.Status.Accuracy, err = optionalFloat(form.Get("accuracy"), "accuracy"); err != nil {
+		return OsmAndReading{}, err
+	}
+	if reading.Status.HDOP, err = optionalFloat(form.Get("hdop"), "hdop"); err != nil {
+		return OsmAndReading{}, err
+	}
+
+	if v := form.Get("ignition"); v != "" {
+		ignition, err := strconv.ParseBool(v)
+		if err != nil {
+			return OsmAndReading{}, errors.New("invalid ignition")
+		}
+		reading.Status.Ignition = &ignition
+	}
+	if v := form.Get("valid"); v != "" {
+		if reading.Valid, err = strconv.ParseBool(v); err != nil {
+			return OsmAndReading{}, errors.New("invalid valid")
+		}
+	}
+	return reading, nil
+}
+
+// IngestRequest converts the reading to an ingest request of the device. The
+// timestamp doubles as message ID, so readings an app resends after a failed
+// upload are only processed once.
+func (r OsmAndReading) IngestRequest(device *domain.Device) domain.IngestRequest {
+	return domain.IngestRequest{
+		VehicleID:   pgtype.UUID{Bytes: device.VehicleID, Valid: true},
+		Status:      r.Status,
+		PlateNumber: device.PlateNumber,
+		MessageID:   "osmand:" + strconv.FormatInt(r.Status.Timestamp.UnixMilli(), 10),
+	}
+}
+
+// osmAndMillisThreshold separates Unix timestamps in seconds from ones in
+// milliseconds; as seconds it is in the year 33658.
+const osmAndMillisThreshold = 1e12
+
+func parseOsmAndTime(v string) (time.Time, error) {
+	if v == "" {
+		return time.Now().UTC(), nil
+	}
+	if n, err := strconv.ParseFloat(v, 64); err == nil {
+		if math.IsNaN(n) || math.IsInf(n, 0) {
+			return time.Time{}, errors.New("invalid timestamp")
+		}
+		if n >= osmAndMillisThreshold {
+			return time.UnixMilli(int64(n)).UTC(), nil
+		}
+		sec, frac := math.Modf(n)
+		return time.Unix(int64(sec), int64(frac*1e9)).UTC().Round(time.Millisecond), nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, errors.New("invalid timestamp")
+}
+
+func optionalFloat(v, name string) (*float64, error) {
+	if v == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, errors.New("invalid " + name)
+	}
+	return &f, nil
+}
+
+func firstValue(form url.Values, keys ...string) string {
+	for _, key := range keys {
+		if v := form.Get(key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
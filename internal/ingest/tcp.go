@@ -0,0 +1,80 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// tcpAcceptRetryDelay is the pause after a failed accept, e.g. when the
+// process runs out of file descriptors.
+const tcpAcceptRetryDelay = 100 * time.Millisecond
+
+// serveTCP accepts connections on ln until ctx is cancelled and handles each
+// in its own goroutine, so a failing device only affects its own connection.
+// On return the listener and every open connection are closed and their
+// handlers have finished.
+func serveTCP(ctx context.Context, ln net.Listener, name string, logger *zap.Logger, handle func(ctx context.Context, conn net.Conn)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu    sync.Mutex
+		conns = make(map[net.Conn]struct{})
+		wg    sync.WaitGroup
+	)
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+		mu.Lock()
+		for conn := range conns {
+			conn.Close()
+		}
+		mu.Unlock()
+	}()
+	defer wg.Wait()
+
+	logger.Info("Listening for device connections", zap.String("protocol", name), zap.String("addr", ln.Addr().String()))
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return err
+			}
+			logger.Warn("Failed to accept device connection", zap.String("protocol", name), zap.Error(err))
+			time.Sleep(tcpAcceptRetryDelay)
+			continue
+		}
+
+		mu.Lock()
+		if ctx.Err() != nil {
+			// Accepted while shutting down, after the open connections
+			// were closed.
+			mu.Unlock()
+			conn.Close()
+			return nil
+		}
+		conns[conn] = struct{}{}
+		mu.Unlock()
+
+		wg.Add(1)
+		utils.SafeGo(func() {
+			defer wg.Done()
+			defer func() {
+				mu.Lock()
+				delete(conns, conn)
+				mu.Unlock()
+				conn.Close()
+			}()
+			handle(ctx, conn)
+		}, name, conn.RemoteAddr().String())
+	}
+}
@@ -0,0 +1,216 @@
+package ingest
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/services"
+	"github.com/AnjuRKrishnan/fleet-tracker/pkg/teltonika"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// teltonikaWriteTimeout bounds the handshake reply and packet ACKs.
+const teltonikaWriteTimeout = 10 * time.Second
+
+// IO element IDs of FMB devices that map to status fields.
+const (
+	teltonikaIOOdometer = 16  // total odometer in metres
+	teltonikaIOHDOP     = 182 // HDOP in tenths
+	teltonikaIOIgnition = 239 // 1 while the ignition is on
+)
+
+// TeltonikaServer receives AVL data from Teltonika trackers over TCP in
+// Codec 8 and Codec 8 Extended.
+//
+// A device is accepted in the IMEI handshake if its IMEI is registered as a
+// device identifier. Every record of a packet with a GPS position becomes a
+// reading of the device's vehicle; records without a fix are counted but
+// skipped. A packet is acknowledged once its records are stored, so the
+// device resends packets the server did not store, and readings carry their
+// timestamp as message ID so resent records are deduplicated. Connections of
+// unregistered devices, connections with unreadable packets, connections whose
+// packets could not be stored and connections that stay silent for the idle
+// timeout are closed.
+type TeltonikaServer struct {
+	addr        string
+	idleTimeout time.Duration
+	devices     services.DeviceResolver
+	ingester    services.Ingester
+	logger      *zap.Logger
+}
+
+// NewTeltonikaServer creates a TeltonikaServer that listens on addr and stores
+// readings with ingester.
+func NewTeltonikaServer(addr string, idleTimeout time.Duration, devices services.DeviceResolver, ingester services.Ingester, logger *zap.Logger) *TeltonikaServer {
+	return &TeltonikaServer{
+		addr:        addr,
+		idleTimeout: idleTimeout,
+		devices:     devices,
+		ingester:    ingester,
+		logger:      logger,
+	}
+}
+
+// ListenAndServe listens on the server's address and serves connections
+// until ctx is cancelled.
+func (s *TeltonikaServer) ListenAndServe(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve serves connections accepted on ln until ctx is cancelled.
+func (s *TeltonikaServer) Serve(ctx context.Context, ln net.Listener) error {
+	return serveTCP(ctx, ln, "Teltonika", s.logger, s.handle)
+}
+
+func (s *TeltonikaServer) handle(ctx context.Context, conn net.Conn) {
+	logger := s.logger.With(zap.String("remote_addr", conn.RemoteAddr().String()))
+	r := bufio.NewReader(conn)
+
+	conn.SetReadDeadline(time.Now().Add(s.idleTimeout))
+	imei, err := teltonika.ReadIMEI(r)
+	if err != nil {
+		s.logClosed(logger, err)
+		return
+	}
+	device, err := s.devices.ResolveDevice(ctx, imei)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			logger.Warn("Rejecting Teltonika device that is not registered", zap.String("imei", imei))
+			s.write(conn, []byte{teltonika.RejectIMEI})
+		} else if ctx.Err() == nil {
+			logger.Error("Failed to resolve Teltonika device", zap.String("imei", imei), zap.Error(err))
+		}
+		return
+	}
+	logger = logger.With(zap.String("imei", imei))
+	if err := s.write(conn, []byte{teltonika.AcceptIMEI}); err != nil {
+		s.logClosed(logger, err)
+		return
+	}
+
+	for {
+		conn.SetReadDeadline(time.Now().Add(s.idleTimeout))
+		packet, err := teltonika.ReadPacket(r)
+		if errors.Is(err, teltonika.ErrCRC) {
+			// Taking no records makes the device send the packet again.
+			logger.Warn("Rejecting Teltonika packet with a bad CRC")
+			if err := s.write(conn, teltonika.Ack(0)); err != nil {
+				s.logClosed(logger, err)
+				return
+			}
+			continue
+		}
+		if err != nil {
+			s.logClosed(logger, err)
+			return
+		}
+
+		var readings []domain.IngestRequest
+		for _, record := range packet.Records {
+			if record.Longitude == 0 && record.Latitude == 0 {
+				continue
+			}
+			readings = append(readings, teltonikaReading(device, record))
+		}
+		if !s.ingest(ctx, logger, readings) {
+			// Without an ACK the device sends the packet again once it has
+			// reconnected.
+			return
+		}
+		if err := s.write(conn, teltonika.Ack(len(packet.Records))); err != nil {
+			s.logClosed(logger, err)
+			return
+		}
+	}
+}
+
+// ingest stores the readings of a packet and reports whether the packet can
+// be acknowledged. Readings the ingest rejects are acknowledged with the rest,
+// since sending them again would not help.
+func (s *TeltonikaServer) ingest(ctx context.Context, logger *zap.Logger, readings []domain.IngestRequest) bool {
+	if len(readings) == 0 {
+		return true
+	}
+	results, err := s.ingester.IngestBatch(ctx, readings)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Error("Failed to ingest Teltonika packet", zap.Bool("stored", results != nil), zap.Error(err))
+		}
+		if results == nil {
+			return false
+		}
+	}
+	for _, result := range results {
+		if result.Status == domain.IngestRejected {
+			logger.Warn("Dropping rejected Teltonika record",
+				zap.String("message_id", readings[result.Index].MessageID),
+				zap.String("error", result.Error),
+			)
+		}
+	}
+	return true
+}
+
+func (s *TeltonikaServer) logClosed(logger *zap.Logger, err error) {
+	var ne net.Error
+	switch {
+	case err == nil, errors.Is(err, io.EOF), errors.Is(err, net.ErrClosed):
+		// The device hung up or the server is shutting down.
+	case errors.As(err, &ne) && ne.Timeout():
+		logger.Info("Closing idle Teltonika connection")
+	default:
+		logger.Warn("Teltonika connection failed", zap.Error(err))
+	}
+}
+
+func (s *TeltonikaServer) write(conn net.Conn, b []byte) error {
+	conn.SetWriteDeadline(time.Now().Add(teltonikaWriteTimeout))
+	_, err := conn.Write(b)
+	return err
+}
+
+// teltonikaReading converts an AVL record to an ingest request of the
+// device.
+func teltonikaReading(device *domain.Device, record teltonika.Record) domain.IngestRequest {
+	heading := float64(record.Angle % 360)
+	altitude := float64(record.Altitude)
+	satellites := int(record.Satellites)
+	status := domain.VehicleStatus{
+		Location:   []float64{record.Longitude, record.Latitude},
+		Speed:      float64(record.Speed),
+		Timestamp:  record.Timestamp,
+		Heading:    &heading,
+		Altitude:   &altitude,
+		Satellites: &satellites,
+	}
+	if v, ok := record.IOUint(teltonikaIOIgnition); ok {
+		ignition := v != 0
+		status.Ignition = &ignition
+	}
+	if v, ok := record.IOUint(teltonikaIOOdometer); ok {
+		odometer := float64(v) / 1000
+		status.Odometer = &odometer
+	}
+	if v, ok := record.IOUint(teltonikaIOHDOP); ok {
+		hdop := float64(v) / 10
+		status.HDOP = &hdop
+	}
+
+	return domain.IngestRequest{
+		VehicleID:   pgtype.UUID{Bytes: device.VehicleID, Valid: true},
+		Status:      status,
+		PlateNumber: device.PlateNumber,
+		MessageID:   "teltonika:" + strconv.FormatInt(record.Timestamp.UnixMilli(), 10),
+	}
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"crypto/subtle"
 	"net/http"
 	"strings"
 
@@ -40,3 +41,39 @@ func JWTAuthenticator(jwtAuth *auth.JWTAuth) func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// TokenFromQuery lets requests without an Authorization header pass their
+// bearer token in the named query parameter instead. Browsers cannot set
+// headers on EventSource and WebSocket requests, so streaming routes need it;
+// it must run before JWTAuthenticator.
+func TokenFromQuery(param string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token := r.URL.Query().Get(param); token != "" && r.Header.Get("Authorization") == "" {
+				r.Header.Set("Authorization", "Bearer "+token)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// StaticToken is a middleware for integrations, such as network server
+// webhooks, that are configured with a fixed bearer token instead of a user
+// JWT.
+func StaticToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authHeader := r.Header.Get("Authorization")
+			if authHeader == "" {
+				http.Error(w, "Authorization header required", http.StatusUnauthorized)
+				return
+			}
+			given := strings.TrimPrefix(authHeader, "Bearer ")
+			if given == authHeader || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				http.Error(w, "Invalid token", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"runtime/debug"
+	"strings"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/auth"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryJWTAuthenticator is the gRPC counterpart of JWTAuthenticator for unary
+// calls. The token is read from the "authorization" metadata.
+func UnaryJWTAuthenticator(jwtAuth *auth.JWTAuth) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticateRPC(ctx, jwtAuth)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamJWTAuthenticator is the gRPC counterpart of JWTAuthenticator for
+// streaming calls.
+func StreamJWTAuthenticator(jwtAuth *auth.JWTAuth) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticateRPC(ss.Context(), jwtAuth)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func authenticateRPC(ctx context.Context, jwtAuth *auth.JWTAuth) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "Authorization metadata required")
+	}
+
+	tokenString := strings.TrimPrefix(values[0], "Bearer ")
+	if tokenString == values[0] {
+		return nil, status.Error(codes.Unauthenticated, "Invalid token format")
+	}
+
+	claims, err := jwtAuth.ValidateToken(tokenString)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "Invalid token")
+	}
+	return context.WithValue(ctx, UserIDContextKey, claims.UserID), nil
+}
+
+// authenticatedStream carries the context with the user claims to the stream
+// handler.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// UnaryRecoverer turns a panic of a unary call into a codes.Internal error
+// and logs it with the stack.
+func UnaryRecoverer(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer recoverRPC(logger, info.FullMethod, &err)
+		return handler(ctx, req)
+	}
+}
+
+// StreamRecoverer is the UnaryRecoverer for streaming calls.
+func StreamRecoverer(logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer recoverRPC(logger, info.FullMethod, &err)
+		return handler(srv, ss)
+	}
+}
+
+func recoverRPC(logger *zap.Logger, method string, err *error) {
+	if r := recover(); r != nil {
+		logger.Error("Panic in gRPC call",
+			zap.String("method", method),
+			zap.Any("panic", r),
+			zap.ByteString("stack", debug.Stack()),
+		)
+		*err = status.Error(codes.Internal, "Internal error")
+	}
+}
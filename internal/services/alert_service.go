@@ -0,0 +1,437 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultAlertLimit and MaxAlertLimit bound a page of alerts.
+	DefaultAlertLimit = 100
+	MaxAlertLimit     = 1000
+)
+
+// AlertServiceAPI defines the interface for alert rule and alert operations.
+type AlertServiceAPI interface {
+	CreateRule(ctx context.Context, rule domain.AlertRule) (*domain.AlertRule, error)
+	GetRule(ctx context.Context, id uuid.UUID) (*domain.AlertRule, error)
+	ListRules(ctx context.Context) ([]domain.AlertRule, error)
+	UpdateRule(ctx context.Context, rule domain.AlertRule) (*domain.AlertRule, error)
+	DeleteRule(ctx context.Context, id uuid.UUID) error
+
+	GetAlert(ctx context.Context, id uuid.UUID) (*domain.Alert, error)
+	ListAlerts(ctx context.Context, query domain.AlertQuery) ([]domain.Alert, error)
+	AcknowledgeAlert(ctx context.Context, id uuid.UUID) (*domain.Alert, error)
+	ResolveAlert(ctx context.Context, id uuid.UUID) (*domain.Alert, error)
+}
+
+// AlertService manages alert rules and evaluates them.
+//
+// As a StatusProcessor it evaluates the speeding and outside_hours rules
+// against every accepted reading: an alert opens once the condition has held
+// for the rule's duration and resolves with the first reading that no longer
+// meets it. no_update rules cannot be detected from readings, so a periodic
+// sweep opens their alerts and resolves them once the vehicle reports again.
+// A rule has at most one unresolved alert per vehicle at a time.
+type AlertService struct {
+	repo          domain.AlertRepository
+	sweepInterval time.Duration
+	logger        *zap.Logger
+	events        EventPublisher
+	rules         *listCache[alertRule]
+
+	mu         sync.Mutex // guards conditions
+	conditions map[conditionKey]*conditionState
+}
+
+// alertRule is a rule as the rule cache holds it. Its schedule is compiled
+// once, so that readings are checked without loading the time zone.
+type alertRule struct {
+	domain.AlertRule
+	schedule *domain.CompiledSchedule
+}
+
+type conditionKey struct {
+	ruleID    uuid.UUID
+	vehicleID uuid.UUID
+}
+
+// conditionState tracks a rule's condition for one vehicle. since is nil
+// while the condition does not hold; known is false until the first reading
+// since the state was created has been evaluated.
+type conditionState struct {
+	mu      sync.Mutex
+	known   bool
+	since   *time.Time
+	alerted bool
+}
+
+// AlertServiceOption configures optional behaviour of an AlertService.
+type AlertServiceOption func(*AlertService)
+
+// WithAlertEvents publishes an event for every alert that is opened,
+// acknowledged or resolved to p.
+func WithAlertEvents(p EventPublisher) AlertServiceOption {
+	return func(s *AlertService) {
+		s.events = p
+	}
+}
+
+// NewAlertService creates an AlertService whose no_update sweep runs every
+// sweepInterval.
+func NewAlertService(repo domain.AlertRepository, sweepInterval time.Duration, logger *zap.Logger, opts ...AlertServiceOption) *AlertService {
+	s := &AlertService{
+		repo:          repo,
+		sweepInterval: sweepInterval,
+		logger:        logger,
+		events:        noopPublisher{},
+		rules:         newListCache(ListCacheTTL, loadAlertRules(repo)),
+		conditions:    make(map[conditionKey]*conditionState),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// CreateRule stores a new alert rule. An invalid rule is rejected with a
+// *domain.ValidationError.
+func (s *AlertService) CreateRule(ctx context.Context, rule domain.AlertRule) (*domain.AlertRule, error) {
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+	created, err := s.repo.CreateAlertRule(ctx, rule)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return created, nil
+}
+
+// GetRule returns domain.ErrNotFound if the rule does not exist.
+func (s *AlertService) GetRule(ctx context.Context, id uuid.UUID) (*domain.AlertRule, error) {
+	return s.repo.GetAlertRule(ctx, id)
+}
+
+// ListRules returns every alert rule.
+func (s *AlertService) ListRules(ctx context.Context) ([]domain.AlertRule, error) {
+	return s.repo.ListAlertRules(ctx)
+}
+
+// UpdateRule replaces the rule with rule.ID. Disabling a rule resolves its
+// unresolved alerts, since nothing would resolve them otherwise. It returns a
+// *domain.ValidationError for an invalid rule and domain.ErrNotFound if the
+// rule does not exist.
+func (s *AlertService) UpdateRule(ctx context.Context, rule domain.AlertRule) (*domain.AlertRule, error) {
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+	updated, err := s.repo.UpdateAlertRule(ctx, rule)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate()
+
+	if !updated.Enabled {
+		resolved, err := s.repo.ResolveRuleAlerts(ctx, updated.ID, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		s.publishAll(ctx, domain.EventAlertResolved, resolved)
+	}
+	return updated, nil
+}
+
+// DeleteRule deletes a rule together with its alerts. Its unresolved alerts
+// are resolved first, so that subscribers learn that they ended. It returns
+// domain.ErrNotFound if the rule does not exist.
+func (s *AlertService) DeleteRule(ctx context.Context, id uuid.UUID) error {
+	resolved, err := s.repo.ResolveRuleAlerts(ctx, id, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteAlertRule(ctx, id); err != nil {
+		return err
+	}
+	s.invalidate()
+	s.publishAll(ctx, domain.EventAlertResolved, resolved)
+	return nil
+}
+
+// VehicleArchived resolves the unresolved alerts of an archived vehicle, which
+// no longer reports and would otherwise keep them open for good.
+func (s *AlertService) VehicleArchived(ctx context.Context, vehicleID uuid.UUID) error {
+	resolved, err := s.repo.ResolveVehicleAlerts(ctx, vehicleID, time.Now())
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	for key := range s.conditions {
+		if key.vehicleID == vehicleID {
+			delete(s.conditions, key)
+		}
+	}
+	s.mu.Unlock()
+	s.publishAll(ctx, domain.EventAlertResolved, resolved)
+	return nil
+}
+
+// GetAlert returns domain.ErrNotFound if the alert does not exist.
+func (s *AlertService) GetAlert(ctx context.Context, id uuid.UUID) (*domain.Alert, error) {
+	return s.repo.GetAlert(ctx, id)
+}
+
+// ListAlerts retrieves a page of alerts. A zero To defaults to now; a zero
+// From leaves the range open so long-standing alerts are not hidden.
+func (s *AlertService) ListAlerts(ctx context.Context, query domain.AlertQuery) ([]domain.Alert, error) {
+	if query.To.IsZero() {
+		query.To = time.Now()
+	}
+	if query.Limit <= 0 {
+		query.Limit = DefaultAlertLimit
+	}
+	if query.Limit > MaxAlertLimit {
+		query.Limit = MaxAlertLimit
+	}
+	return s.repo.ListAlerts(ctx, query)
+}
+
+// AcknowledgeAlert marks an open alert as seen by an operator. Acknowledging
+// it again is a no-op; a resolved alert yields domain.ErrAlertResolved.
+func (s *AlertService) AcknowledgeAlert(ctx context.Context, id uuid.UUID) (*domain.Alert, error) {
+	now := time.Now()
+	alert, err := s.repo.AcknowledgeAlert(ctx, id, now)
+	if err == nil {
+		s.publish(ctx, domain.EventAlertAcknowledged, now, *alert)
+		return alert, nil
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+
+	alert, err = s.repo.GetAlert(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if alert.State == domain.AlertResolved {
+		return nil, domain.ErrAlertResolved
+	}
+	return alert, nil
+}
+
+// ResolveAlert closes an alert by hand. Resolving it again is a no-op.
+func (s *AlertService) ResolveAlert(ctx context.Context, id uuid.UUID) (*domain.Alert, error) {
+	now := time.Now()
+	alert, err := s.repo.ResolveAlert(ctx, id, now)
+	if errors.Is(err, domain.ErrNotFound) {
+		return s.repo.GetAlert(ctx, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.publish(ctx, domain.EventAlertResolved, now, *alert)
+	return alert, nil
+}
+
+// Process evaluates one status reading of a vehicle against every rule that
+// applies to it.
+func (s *AlertService) Process(ctx context.Context, vehicleID uuid.UUID, status domain.VehicleStatus) error {
+	rules, err := s.rules.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, rule := range rules {
+		if !rule.AppliesTo(vehicleID) {
+			continue
+		}
+
+		switch rule.Kind {
+		case domain.AlertSpeeding:
+			err = s.evaluate(ctx, rule.AlertRule, vehicleID, status, status.Speed > rule.SpeedLimit)
+		case domain.AlertOutsideHours:
+			moving := status.Speed > rule.SpeedLimit
+			outside := rule.Schedule != nil && !rule.schedule.Allows(status.Timestamp)
+			err = s.evaluate(ctx, rule.AlertRule, vehicleID, status, moving && outside)
+		default:
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// evaluate holds the lock of the rule's condition for the vehicle only, so
+// that readings of other vehicles are evaluated meanwhile. The first reading
+// of a condition after a restart always tries to resolve, so alerts opened
+// before the restart do not stay open forever.
+func (s *AlertService) evaluate(ctx context.Context, rule domain.AlertRule, vehicleID uuid.UUID, status domain.VehicleStatus, active bool) error {
+	st := s.condition(conditionKey{ruleID: rule.ID, vehicleID: vehicleID})
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if !active {
+		if st.known && st.since == nil {
+			return nil
+		}
+		st.known, st.since, st.alerted = true, nil, false
+		resolved, err := s.repo.ResolveOpenAlerts(ctx, rule.ID, vehicleID, status.Timestamp)
+		s.publishAll(ctx, domain.EventAlertResolved, resolved)
+		return err
+	}
+
+	if !st.known || st.since == nil {
+		since := status.Timestamp
+		st.known, st.since, st.alerted = true, &since, false
+	}
+	held := status.Timestamp.Sub(*st.since)
+	if st.alerted || held < rule.Duration() {
+		return nil
+	}
+
+	opened, err := s.repo.OpenAlert(ctx, domain.Alert{
+		RuleID:    rule.ID,
+		VehicleID: vehicleID,
+		Kind:      rule.Kind,
+		Message:   alertMessage(rule, status, held),
+		Value:     status.Speed,
+		Location:  status.Location,
+		OpenedAt:  status.Timestamp,
+	})
+	if err != nil {
+		return err
+	}
+	st.alerted = true
+	if opened != nil {
+		s.publish(ctx, domain.EventAlertOpened, opened.OpenedAt, *opened)
+	}
+	return nil
+}
+
+// loadAlertRules returns a loader of the rule cache that compiles the
+// schedules of the rules it lists. A schedule that fails to compile, which
+// validation keeps from being stored, allows nothing.
+func loadAlertRules(repo domain.AlertRepository) func(ctx context.Context) ([]alertRule, error) {
+	return func(ctx context.Context) ([]alertRule, error) {
+		rules, err := repo.ListAlertRules(ctx)
+		if err != nil {
+			return nil, err
+		}
+		compiled := make([]alertRule, len(rules))
+		for i, rule := range rules {
+			compiled[i].AlertRule = rule
+			if rule.Schedule != nil {
+				compiled[i].schedule, _ = rule.Schedule.Compile()
+			}
+		}
+		return compiled, nil
+	}
+}
+
+// condition returns the state of a rule's condition for a vehicle, creating
+// it on first use.
+func (s *AlertService) condition(key conditionKey) *conditionState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, ok := s.conditions[key]
+	if !ok {
+		st = &conditionState{}
+		s.conditions[key] = st
+	}
+	return st
+}
+
+func alertMessage(rule domain.AlertRule, status domain.VehicleStatus, held time.Duration) string {
+	switch rule.Kind {
+	case domain.AlertSpeeding:
+		return fmt.Sprintf("%.1f km/h above the %.1f km/h limit for %s", status.Speed, rule.SpeedLimit, held)
+	case domain.AlertOutsideHours:
+		return fmt.Sprintf("moving at %.1f km/h outside allowed hours", status.Speed)
+	case domain.AlertNoUpdate:
+		return fmt.Sprintf("no update for more than %s", rule.Duration())
+	}
+	return rule.Name
+}
+
+// Sweep opens no_update alerts for vehicles that have been silent for longer
+// than their rule allows at now, and resolves those whose vehicle has
+// reported since.
+func (s *AlertService) Sweep(ctx context.Context, now time.Time) {
+	rules, err := s.rules.get(ctx)
+	if err != nil {
+		s.logger.Error("Failed to load alert rules", zap.Error(err))
+		return
+	}
+
+	for _, rule := range rules {
+		if rule.Kind != domain.AlertNoUpdate || !rule.Enabled {
+			continue
+		}
+		silentSince := now.Add(-rule.Duration())
+		resolved, err := s.repo.ResolveSilenceAlerts(ctx, rule.ID, silentSince, now)
+		if err != nil {
+			s.logger.Error("Failed to resolve no_update alerts", zap.String("rule_id", rule.ID.String()), zap.Error(err))
+		}
+		s.publishAll(ctx, domain.EventAlertResolved, resolved)
+
+		opened, err := s.repo.OpenSilenceAlerts(ctx, rule.AlertRule, alertMessage(rule.AlertRule, domain.VehicleStatus{}, 0), silentSince, now)
+		if err != nil {
+			s.logger.Error("Failed to open no_update alerts", zap.String("rule_id", rule.ID.String()), zap.Error(err))
+		}
+		s.publishAll(ctx, domain.EventAlertOpened, opened)
+	}
+}
+
+// Run periodically sweeps no_update rules until ctx is cancelled.
+func (s *AlertService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			s.Sweep(ctx, now)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *AlertService) publish(ctx context.Context, eventType domain.EventType, at time.Time, alert domain.Alert) {
+	event := domain.NewEvent(eventType, alert.VehicleID, at, alert)
+	event.AlertKind = alert.Kind
+	s.events.Publish(ctx, event)
+}
+
+// publishAll publishes the resolved, respectively opened, alerts of a bulk
+// transition.
+func (s *AlertService) publishAll(ctx context.Context, eventType domain.EventType, alerts []domain.Alert) {
+	for _, alert := range alerts {
+		at := alert.OpenedAt
+		if alert.ResolvedAt != nil {
+			at = *alert.ResolvedAt
+		}
+		s.publish(ctx, eventType, at, alert)
+	}
+}
+
+// invalidate reloads the rules on next use and forgets the condition state,
+// since a changed threshold may no longer match it.
+func (s *AlertService) invalidate() {
+	s.rules.invalidate()
+
+	s.mu.Lock()
+	s.conditions = make(map[conditionKey]*conditionState)
+	s.mu.Unlock()
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/google/uuid"
+)
+
+// DeviceServiceAPI defines the interface for device registry operations.
+type DeviceServiceAPI interface {
+	CreateDevice(ctx context.Context, device domain.Device) (*domain.Device, error)
+	GetDevice(ctx context.Context, id uuid.UUID) (*domain.Device, error)
+	ListDevices(ctx context.Context) ([]domain.Device, error)
+	UpdateDevice(ctx context.Context, device domain.Device) (*domain.Device, error)
+	RotateDeviceToken(ctx context.Context, id uuid.UUID) (*domain.Device, error)
+	DeleteDevice(ctx context.Context, id uuid.UUID) error
+}
+
+// DeviceResolver maps the identifier a device reports to its registration.
+type DeviceResolver interface {
+	// ResolveDevice returns domain.ErrNotFound for unregistered devices.
+	ResolveDevice(ctx context.Context, identifier string) (*domain.Device, error)
+}
+
+// DeviceAuthenticator checks the token a device sends with its readings.
+type DeviceAuthenticator interface {
+	// AuthenticateDevice returns domain.ErrInvalidDeviceToken unless the
+	// device is registered with token.
+	AuthenticateDevice(ctx context.Context, identifier, token string) (*domain.Device, error)
+}
+
+// DeviceService manages the registry of trackers that report over device
+// protocols and resolves their identifiers to vehicles.
+type DeviceService struct {
+	repo domain.DeviceRepository
+}
+
+// NewDeviceService creates a DeviceService.
+func NewDeviceService(repo domain.DeviceRepository) *DeviceService {
+	return &DeviceService{repo: repo}
+}
+
+// CreateDevice registers a device and issues its token. The result is the
+// only response that includes the token. It returns a
+// *domain.ValidationError for an invalid device and domain.ErrDeviceExists
+// if the identifier is taken.
+func (s *DeviceService) CreateDevice(ctx context.Context, device domain.Device) (*domain.Device, error) {
+	if err := device.Validate(); err != nil {
+		return nil, err
+	}
+	token, err := newDeviceToken()
+	if err != nil {
+		return nil, err
+	}
+	device.TokenHash = hashDeviceToken(token)
+
+	created, err := s.repo.CreateDevice(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+	created.Token = token
+	return created, nil
+}
+
+// GetDevice returns domain.ErrNotFound if the device does not exist.
+func (s *DeviceService) GetDevice(ctx context.Context, id uuid.UUID) (*domain.Device, error) {
+	return s.repo.GetDevice(ctx, id)
+}
+
+// ListDevices returns every registered device.
+func (s *DeviceService) ListDevices(ctx context.Context) ([]domain.Device, error) {
+	return s.repo.ListDevices(ctx)
+}
+
+// UpdateDevice replaces the device with device.ID. It returns a
+// *domain.ValidationError for an invalid device, domain.ErrNotFound if the
+// device does not exist and domain.ErrDeviceExists if the identifier is
+// taken by another device.
+func (s *DeviceService) UpdateDevice(ctx context.Context, device domain.Device) (*domain.Device, error) {
+	if err := device.Validate(); err != nil {
+		return nil, err
+	}
+	return s.repo.UpdateDevice(ctx, device)
+}
+
+// RotateDeviceToken issues a new token for the device, replacing the
+// current one. It returns domain.ErrNotFound if the device does not exist.
+func (s *DeviceService) RotateDeviceToken(ctx context.Context, id uuid.UUID) (*domain.Device, error) {
+	token, err := newDeviceToken()
+	if err != nil {
+		return nil, err
+	}
+	device, err := s.repo.SetDeviceTokenHash(ctx, id, hashDeviceToken(token))
+	if err != nil {
+		return nil, err
+	}
+	device.Token = token
+	return device, nil
+}
+
+// DeleteDevice returns domain.ErrNotFound if the device does not exist.
+func (s *DeviceService) DeleteDevice(ctx context.Context, id uuid.UUID) error {
+	return s.repo.DeleteDevice(ctx, id)
+}
+
+// ResolveDevice returns the device registered with identifier, or
+// domain.ErrNotFound.
+func (s *DeviceService) ResolveDevice(ctx context.Context, identifier string) (*domain.Device, error) {
+	return s.repo.GetDeviceByIdentifier(ctx, identifier)
+}
+
+// AuthenticateDevice returns the device registered with identifier if token
+// is its current token, and domain.ErrInvalidDeviceToken otherwise.
+func (s *DeviceService) AuthenticateDevice(ctx context.Context, identifier, token string) (*domain.Device, error) {
+	device, err := s.repo.GetDeviceByIdentifier(ctx, identifier)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.ErrInvalidDeviceToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if device.TokenHash == nil || subtle.ConstantTimeCompare(device.TokenHash, hashDeviceToken(token)) != 1 {
+		return nil, domain.ErrInvalidDeviceToken
+	}
+	return device, nil
+}
+
+func newDeviceToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashDeviceToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/google/uuid"
+)
+
+// EventPublisher receives the fleet events emitted by the services, e.g. to
+// deliver them to webhook subscribers. Publishing must not fail the operation
+// that caused the event, so implementations handle their own errors.
+type EventPublisher interface {
+	Publish(ctx context.Context, event domain.Event)
+}
+
+// noopPublisher is the EventPublisher of services that were not given one.
+type noopPublisher struct{}
+
+func (noopPublisher) Publish(context.Context, domain.Event) {}
+
+// multiPublisher passes every event to each of its publishers in turn.
+type multiPublisher []EventPublisher
+
+func (m multiPublisher) Publish(ctx context.Context, event domain.Event) {
+	for _, p := range m {
+		p.Publish(ctx, event)
+	}
+}
+
+// MultiPublisher returns an EventPublisher that publishes to each of
+// publishers in order.
+func MultiPublisher(publishers ...EventPublisher) EventPublisher {
+	return multiPublisher(publishers)
+}
+
+// statusEvents publishes accepted readings.
+type statusEvents struct {
+	events EventPublisher
+}
+
+// StatusEvents returns a StatusProcessor that publishes every accepted reading
+// as a status.updated event to p.
+func StatusEvents(p EventPublisher) StatusProcessor {
+	return statusEvents{events: p}
+}
+
+func (s statusEvents) Process(ctx context.Context, vehicleID uuid.UUID, status domain.VehicleStatus) error {
+	s.events.Publish(ctx, domain.NewEvent(domain.EventStatusUpdated, vehicleID, status.Timestamp, status))
+	return nil
+}
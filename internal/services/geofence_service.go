@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/google/uuid"
+)
+
+const (
+	// GeofenceEventWindow is the default range of a geofence event query.
+	GeofenceEventWindow = 24 * time.Hour
+	// DefaultGeofenceEventLimit and MaxGeofenceEventLimit bound a page of
+	// geofence events.
+	DefaultGeofenceEventLimit = 100
+	MaxGeofenceEventLimit     = 1000
+)
+
+// GeofenceServiceAPI defines the interface for geofence operations.
+type GeofenceServiceAPI interface {
+	CreateGeofence(ctx context.Context, fence domain.Geofence) (*domain.Geofence, error)
+	GetGeofence(ctx context.Context, id uuid.UUID) (*domain.Geofence, error)
+	ListGeofences(ctx context.Context) ([]domain.Geofence, error)
+	UpdateGeofence(ctx context.Context, fence domain.Geofence) (*domain.Geofence, error)
+	DeleteGeofence(ctx context.Context, id uuid.UUID) error
+	ListEvents(ctx context.Context, query domain.GeofenceEventQuery) ([]domain.GeofenceEvent, error)
+}
+
+// GeofenceService manages geofences and, as a StatusProcessor, turns the
+// status stream into enter, exit and dwell events.
+//
+// Which fences a vehicle is inside is persisted, so events survive restarts
+// and are not repeated when several instances ingest for the same vehicle.
+// The fences themselves are cached in memory and reloaded after every change
+// and at least every ListCacheTTL.
+type GeofenceService struct {
+	repo   domain.GeofenceRepository
+	fences *listCache[domain.Geofence]
+	events EventPublisher
+}
+
+// GeofenceServiceOption configures optional behaviour of a GeofenceService.
+type GeofenceServiceOption func(*GeofenceService)
+
+// WithGeofenceEvents publishes an event for every recorded enter, exit and
+// dwell to p.
+func WithGeofenceEvents(p EventPublisher) GeofenceServiceOption {
+	return func(s *GeofenceService) {
+		s.events = p
+	}
+}
+
+// NewGeofenceService creates a new GeofenceService.
+func NewGeofenceService(repo domain.GeofenceRepository, opts ...GeofenceServiceOption) *GeofenceService {
+	s := &GeofenceService{
+		repo:   repo,
+		fences: newListCache(ListCacheTTL, repo.ListGeofences),
+		events: noopPublisher{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// CreateGeofence stores a new geofence. An invalid fence is rejected with a
+// *domain.ValidationError.
+func (s *GeofenceService) CreateGeofence(ctx context.Context, fence domain.Geofence) (*domain.Geofence, error) {
+	if err := fence.Validate(); err != nil {
+		return nil, err
+	}
+	created, err := s.repo.CreateGeofence(ctx, fence)
+	if err != nil {
+		return nil, err
+	}
+	s.fences.invalidate()
+	return created, nil
+}
+
+// GetGeofence returns domain.ErrNotFound if the geofence does not exist.
+func (s *GeofenceService) GetGeofence(ctx context.Context, id uuid.UUID) (*domain.Geofence, error) {
+	return s.repo.GetGeofence(ctx, id)
+}
+
+// ListGeofences returns every geofence.
+func (s *GeofenceService) ListGeofences(ctx context.Context) ([]domain.Geofence, error) {
+	return s.repo.ListGeofences(ctx)
+}
+
+// UpdateGeofence replaces the geofence with fence.ID. It returns a
+// *domain.ValidationError for an invalid fence and domain.ErrNotFound if the
+// geofence does not exist. Vehicles inside the old shape are re-evaluated on
+// their next reading.
+func (s *GeofenceService) UpdateGeofence(ctx context.Context, fence domain.Geofence) (*domain.Geofence, error) {
+	if err := fence.Validate(); err != nil {
+		return nil, err
+	}
+	updated, err := s.repo.UpdateGeofence(ctx, fence)
+	if err != nil {
+		return nil, err
+	}
+	s.fences.invalidate()
+	return updated, nil
+}
+
+// DeleteGeofence returns domain.ErrNotFound if the geofence does not exist.
+func (s *GeofenceService) DeleteGeofence(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.DeleteGeofence(ctx, id); err != nil {
+		return err
+	}
+	s.fences.invalidate()
+	return nil
+}
+
+// ListEvents retrieves a page of geofence events. A zero To defaults to now,
+// a zero From to GeofenceEventWindow before To.
+func (s *GeofenceService) ListEvents(ctx context.Context, query domain.GeofenceEventQuery) ([]domain.GeofenceEvent, error) {
+	if query.To.IsZero() {
+		query.To = time.Now()
+	}
+	if query.From.IsZero() {
+		query.From = query.To.Add(-GeofenceEventWindow)
+	}
+	if query.Limit <= 0 {
+		query.Limit = DefaultGeofenceEventLimit
+	}
+	if query.Limit > MaxGeofenceEventLimit {
+		query.Limit = MaxGeofenceEventLimit
+	}
+	return s.repo.ListGeofenceEvents(ctx, query)
+}
+
+// Process evaluates one status reading of a vehicle against every geofence
+// and records the transitions it causes.
+func (s *GeofenceService) Process(ctx context.Context, vehicleID uuid.UUID, status domain.VehicleStatus) error {
+	lon, lat, ok := status.Coordinates()
+	if !ok {
+		return nil
+	}
+
+	fences, err := s.fences.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	presence, err := s.repo.ListPresence(ctx, vehicleID)
+	if err != nil {
+		return err
+	}
+	inside := make(map[uuid.UUID]domain.GeofencePresence, len(presence))
+	for _, p := range presence {
+		inside[p.GeofenceID] = p
+	}
+
+	var errs []error
+	for _, fence := range fences {
+		p, wasInside := inside[fence.ID]
+		isInside := fence.Contains(lon, lat)
+
+		switch {
+		case isInside && !wasInside:
+			err = s.enter(ctx, vehicleID, fence, status)
+		case isInside && wasInside:
+			err = s.dwell(ctx, vehicleID, fence, p, status)
+		case !isInside && wasInside:
+			err = s.exit(ctx, vehicleID, fence.ID, status)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *GeofenceService) enter(ctx context.Context, vehicleID uuid.UUID, fence domain.Geofence, status domain.VehicleStatus) error {
+	// Only the instance that records the presence emits the event.
+	entered, err := s.repo.EnterGeofence(ctx, domain.GeofencePresence{
+		VehicleID:  vehicleID,
+		GeofenceID: fence.ID,
+		EnteredAt:  status.Timestamp,
+	})
+	if err != nil || !entered {
+		return err
+	}
+	return s.record(ctx, vehicleID, fence.ID, domain.GeofenceEnter, status)
+}
+
+func (s *GeofenceService) dwell(ctx context.Context, vehicleID uuid.UUID, fence domain.Geofence, p domain.GeofencePresence, status domain.VehicleStatus) error {
+	if fence.DwellSeconds <= 0 || p.DwellReported || status.Timestamp.Sub(p.EnteredAt) < fence.DwellTime() {
+		return nil
+	}
+	marked, err := s.repo.MarkDwellReported(ctx, vehicleID, fence.ID)
+	if err != nil || !marked {
+		return err
+	}
+	return s.record(ctx, vehicleID, fence.ID, domain.GeofenceDwell, status)
+}
+
+func (s *GeofenceService) exit(ctx context.Context, vehicleID, geofenceID uuid.UUID, status domain.VehicleStatus) error {
+	exited, err := s.repo.ExitGeofence(ctx, vehicleID, geofenceID)
+	if err != nil || !exited {
+		return err
+	}
+	return s.record(ctx, vehicleID, geofenceID, domain.GeofenceExit, status)
+}
+
+func (s *GeofenceService) record(ctx context.Context, vehicleID, geofenceID uuid.UUID, kind domain.GeofenceEventKind, status domain.VehicleStatus) error {
+	recorded, err := s.repo.InsertGeofenceEvent(ctx, domain.GeofenceEvent{
+		GeofenceID: geofenceID,
+		VehicleID:  vehicleID,
+		Kind:       kind,
+		OccurredAt: status.Timestamp,
+		Location:   status.Location,
+	})
+	if err != nil {
+		return err
+	}
+
+	event := domain.NewEvent(domain.GeofenceEventTypes[kind], vehicleID, status.Timestamp, *recorded)
+	event.GeofenceID = &geofenceID
+	s.events.Publish(ctx, event)
+	return nil
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ListCacheTTL bounds how long a change to a geofence, alert rule or
+// webhook subscription made by another instance goes unnoticed.
+const ListCacheTTL = time.Minute
+
+// listCache holds a rarely changing list, such as geofences or alert rules,
+// that is consulted on every ingest. It is reloaded after invalidate and at
+// least every ttl, which bounds how long changes made by another instance go
+// unnoticed.
+type listCache[T any] struct {
+	load func(ctx context.Context) ([]T, error)
+	ttl  time.Duration
+
+	mu       sync.RWMutex
+	items    []T
+	loadedAt time.Time
+}
+
+func newListCache[T any](ttl time.Duration, load func(ctx context.Context) ([]T, error)) *listCache[T] {
+	return &listCache[T]{load: load, ttl: ttl}
+}
+
+// get returns the cached list, reloading it once it is older than ttl.
+func (c *listCache[T]) get(ctx context.Context) ([]T, error) {
+	c.mu.RLock()
+	items, loadedAt := c.items, c.loadedAt
+	c.mu.RUnlock()
+	if !loadedAt.IsZero() && time.Since(loadedAt) < c.ttl {
+		return items, nil
+	}
+
+	items, err := c.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.items, c.loadedAt = items, time.Now()
+	c.mu.Unlock()
+	return items, nil
+}
+
+func (c *listCache[T]) invalidate() {
+	c.mu.Lock()
+	c.items, c.loadedAt = nil, time.Time{}
+	c.mu.Unlock()
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// LiveBufferSize is how many events a live subscriber may fall behind
+	// before further events are dropped for it.
+	LiveBufferSize = 64
+	// liveResubscribeDelay is the pause before a failed bus subscription is
+	// retried.
+	liveResubscribeDelay = time.Second
+)
+
+// LiveFeedAPI defines the interface for subscribing to live fleet events.
+type LiveFeedAPI interface {
+	Subscribe(filters ...LiveFilter) *LiveSubscription
+}
+
+// LiveFilter selects the events of a live subscription. Empty fields match
+// everything; GeofenceIDs and AlertKinds only match events that carry a
+// geofence, respectively an alert kind.
+type LiveFilter struct {
+	Types       []domain.EventType
+	VehicleIDs  []uuid.UUID
+	GeofenceIDs []uuid.UUID
+	AlertKinds  []domain.AlertRuleKind
+}
+
+// Matches reports whether the event passes the filter.
+func (f LiveFilter) Matches(event domain.Event) bool {
+	if len(f.Types) > 0 && !slices.Contains(f.Types, event.Type) {
+		return false
+	}
+	if len(f.VehicleIDs) > 0 && !slices.Contains(f.VehicleIDs, event.VehicleID) {
+		return false
+	}
+	if len(f.GeofenceIDs) > 0 && (event.GeofenceID == nil || !slices.Contains(f.GeofenceIDs, *event.GeofenceID)) {
+		return false
+	}
+	if len(f.AlertKinds) > 0 && !slices.Contains(f.AlertKinds, event.AlertKind) {
+		return false
+	}
+	return true
+}
+
+// LiveHub streams fleet events to the clients connected to this instance.
+//
+// As an EventPublisher it publishes events to the EventBus, and Run passes
+// everything received from the bus to the matching local subscriptions, so
+// a client sees the events of every instance behind the load balancer. A
+// subscriber that does not keep up loses events rather than slowing down the
+// others.
+type LiveHub struct {
+	bus    domain.EventBus
+	logger *zap.Logger
+
+	mu   sync.RWMutex
+	subs map[*LiveSubscription]struct{}
+}
+
+// LiveSubscription receives the events that match any of its filters until
+// it is closed.
+type LiveSubscription struct {
+	hub     *LiveHub
+	filters []LiveFilter
+	events  chan domain.Event
+	dropped atomic.Uint64
+	closed  bool
+}
+
+// NewLiveHub creates a LiveHub.
+func NewLiveHub(bus domain.EventBus, logger *zap.Logger) *LiveHub {
+	return &LiveHub{
+		bus:    bus,
+		logger: logger,
+		subs:   make(map[*LiveSubscription]struct{}),
+	}
+}
+
+// Publish sends the event to every instance. Failures are logged, since they
+// must not fail the operation that emitted the event.
+func (h *LiveHub) Publish(ctx context.Context, event domain.Event) {
+	if err := h.bus.Publish(ctx, event); err != nil {
+		h.logger.Error("Failed to publish live event",
+			zap.String("event_type", string(event.Type)),
+			zap.Error(err),
+		)
+	}
+}
+
+// Subscribe registers a subscription for the events that match any of
+// filters. Without filters it receives nothing until SetFilters is called.
+func (h *LiveHub) Subscribe(filters ...LiveFilter) *LiveSubscription {
+	sub := &LiveSubscription{
+		hub:     h,
+		filters: filters,
+		events:  make(chan domain.Event, LiveBufferSize),
+	}
+
+	h.mu.Lock()
+	h.subs[sub] = struct{}{}
+	h.mu.Unlock()
+	return sub
+}
+
+// Run relays the events of the bus to the local subscriptions until ctx is
+// cancelled, then closes every subscription.
+func (h *LiveHub) Run(ctx context.Context) {
+	defer h.closeAll()
+
+	for {
+		err := h.bus.Subscribe(ctx, h.dispatch)
+		if ctx.Err() != nil {
+			return
+		}
+		h.logger.Error("Live event subscription failed", zap.Error(err))
+
+		select {
+		case <-time.After(liveResubscribeDelay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (h *LiveHub) dispatch(event domain.Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for sub := range h.subs {
+		if !sub.matches(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			// The subscriber is too slow; newer events will follow.
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+func (h *LiveHub) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subs {
+		sub.closeLocked()
+	}
+}
+
+// Events returns the channel the subscription's events arrive on. It is
+// closed once the subscription or the hub shuts down.
+func (s *LiveSubscription) Events() <-chan domain.Event {
+	return s.events
+}
+
+// SetFilters replaces the filters of the subscription. Events already
+// buffered are still delivered.
+func (s *LiveSubscription) SetFilters(filters ...LiveFilter) {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.filters = filters
+}
+
+// TakeDropped returns the number of events dropped because the subscriber
+// fell more than LiveBufferSize events behind since the last call.
+func (s *LiveSubscription) TakeDropped() uint64 {
+	return s.dropped.Swap(0)
+}
+
+// Close unregisters the subscription.
+func (s *LiveSubscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.closeLocked()
+}
+
+// matches must be called with the hub's lock held.
+func (s *LiveSubscription) matches(event domain.Event) bool {
+	for _, f := range s.filters {
+		if f.Matches(event) {
+			return true
+		}
+	}
+	return false
+}
+
+// closeLocked must be called with the hub's lock held.
+func (s *LiveSubscription) closeLocked() {
+	if s.closed {
+		return
+	}
+	s.closed = true
+	delete(s.hub.subs, s)
+	close(s.events)
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RegistrationCacheTTL bounds how long ingest keeps accepting readings of an
+// archived vehicle, or rejecting those of a newly registered one, when only
+// registered vehicles are accepted.
+const RegistrationCacheTTL = 10 * time.Second
+
+// registrationCacheSize caps the vehicles a registrationCache remembers, so
+// that readings for random vehicle IDs cannot grow it without bound.
+const registrationCacheSize = 100000
+
+// registrationCache remembers for ttl whether vehicles are active, so that
+// ingest does not look the vehicle of every reading up in the registry.
+type registrationCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[uuid.UUID]registrationEntry
+}
+
+type registrationEntry struct {
+	active    bool
+	checkedAt time.Time
+}
+
+func newRegistrationCache(ttl time.Duration) *registrationCache {
+	return &registrationCache{ttl: ttl, entries: make(map[uuid.UUID]registrationEntry)}
+}
+
+// get returns whether the vehicle is active, and false for ok if that is not
+// known or too old.
+func (c *registrationCache) get(id uuid.UUID) (active, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[id]
+	if !ok || time.Since(entry.checkedAt) >= c.ttl {
+		return false, false
+	}
+	return entry.active, true
+}
+
+// put remembers whether the vehicle is active. A full cache first drops the
+// entries that have expired and, if that is not enough, everything.
+func (c *registrationCache) put(id uuid.UUID, active bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= registrationCacheSize {
+		for key, entry := range c.entries {
+			if time.Since(entry.checkedAt) >= c.ttl {
+				delete(c.entries, key)
+			}
+		}
+		if len(c.entries) >= registrationCacheSize {
+			c.entries = make(map[uuid.UUID]registrationEntry)
+		}
+	}
+	c.entries[id] = registrationEntry{active: active, checkedAt: time.Now()}
+}
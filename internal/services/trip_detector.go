@@ -0,0 +1,345 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/pkg/geo"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// TripDetector segments the status stream of each vehicle into trips.
+//
+// A trip opens with the first reading at or above minSpeed and closes once the
+// vehicle has been below minSpeed, or silent, for stopWindow. Mileage is the
+// sum of haversine distances between consecutive readings in kilometres and
+// the average speed is the mean of the reported speeds while the trip was
+// moving. Readings taken while the vehicle is stopped only count towards the
+// trip if it starts moving again before the window elapses.
+//
+// The readings of one vehicle are processed one at a time, while different
+// vehicles proceed in parallel. Open trips are kept in memory; Restore picks
+// up the ones a previous run left open.
+type TripDetector struct {
+	repo       domain.VehicleRepository
+	stopWindow time.Duration
+	minSpeed   float64
+	logger     *zap.Logger
+	events     EventPublisher
+
+	mu       sync.Mutex // guards vehicles, not their state
+	vehicles map[uuid.UUID]*vehicleTrips
+}
+
+// vehicleTrips is the trip detection state of a single vehicle. Its lock is
+// held across the database round trips of the vehicle's readings.
+type vehicleTrips struct {
+	mu sync.Mutex
+	// receivedAt is when the last reading arrived. Idleness is measured
+	// against it rather than against the reading's own timestamp, since
+	// buffered readings may be uploaded long after they were taken.
+	receivedAt time.Time
+	open       *tripState // nil while no trip is open
+}
+
+// tripState tracks the open trip of a single vehicle.
+type tripState struct {
+	trip      domain.Trip
+	last      domain.VehicleStatus
+	stoppedAt *time.Time
+
+	distance float64
+	speedSum float64
+	samples  int
+
+	// Totals of the readings since the vehicle stopped. They are folded into
+	// the trip when it moves again and dropped when the trip closes.
+	idleDistance float64
+	idleSpeedSum float64
+	idleSamples  int
+}
+
+// TripDetectorOption configures optional behaviour of a TripDetector.
+type TripDetectorOption func(*TripDetector)
+
+// WithTripEvents publishes trip.started and trip.ended events to p.
+func WithTripEvents(p EventPublisher) TripDetectorOption {
+	return func(d *TripDetector) {
+		d.events = p
+	}
+}
+
+// NewTripDetector creates a TripDetector.
+func NewTripDetector(repo domain.VehicleRepository, stopWindow time.Duration, minSpeed float64, logger *zap.Logger, opts ...TripDetectorOption) *TripDetector {
+	d := &TripDetector{
+		repo:       repo,
+		stopWindow: stopWindow,
+		minSpeed:   minSpeed,
+		logger:     logger,
+		events:     noopPublisher{},
+		vehicles:   make(map[uuid.UUID]*vehicleTrips),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Process feeds one status reading of a vehicle into the detector.
+func (d *TripDetector) Process(ctx context.Context, vehicleID uuid.UUID, status domain.VehicleStatus) error {
+	v := d.vehicle(vehicleID)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.receivedAt = time.Now()
+
+	moving := status.Speed >= d.minSpeed
+	st := v.open
+	if st != nil {
+		// Late readings must not rewind the trip.
+		if status.Timestamp.Before(st.last.Timestamp) {
+			return nil
+		}
+		// The vehicle went silent for longer than the window, so the previous
+		// trip is over.
+		if status.Timestamp.Sub(st.last.Timestamp) >= d.stopWindow {
+			if err := d.closeTrip(ctx, vehicleID, v, st.endTime()); err != nil {
+				return err
+			}
+			st = nil
+		}
+	}
+
+	if st == nil {
+		if !moving {
+			return nil
+		}
+		return d.openTrip(ctx, vehicleID, v, status)
+	}
+
+	if st.advance(status, moving, d.stopWindow) {
+		return d.closeTrip(ctx, vehicleID, v, *st.stoppedAt)
+	}
+	return nil
+}
+
+// Sweep closes the trips of vehicles from which no reading has arrived within
+// the stop window of now.
+func (d *TripDetector) Sweep(ctx context.Context, now time.Time) {
+	d.mu.Lock()
+	vehicles := make(map[uuid.UUID]*vehicleTrips, len(d.vehicles))
+	for vehicleID, v := range d.vehicles {
+		vehicles[vehicleID] = v
+	}
+	d.mu.Unlock()
+
+	for vehicleID, v := range vehicles {
+		v.mu.Lock()
+		if v.open != nil && now.Sub(v.receivedAt) >= d.stopWindow {
+			if err := d.closeTrip(ctx, vehicleID, v, v.open.endTime()); err != nil {
+				d.logger.Error("Failed to close idle trip",
+					zap.String("vehicle_id", vehicleID.String()),
+					zap.Error(err),
+				)
+			}
+		}
+		v.mu.Unlock()
+	}
+}
+
+// Restore reloads the trips that a previous run left open, replaying the
+// positions recorded since each started to rebuild its totals. Trips that
+// the replay finds finished are closed; the others stay open until the
+// vehicle reports again or Sweep closes them. It must run before the first
+// reading is processed.
+func (d *TripDetector) Restore(ctx context.Context) error {
+	trips, err := d.repo.ListOpenTrips(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, trip := range trips {
+		vehicleID := uuid.UUID(trip.VehicleID.Bytes)
+		v := d.vehicle(vehicleID)
+		v.mu.Lock()
+		if err := d.restoreTrip(ctx, vehicleID, v, trip, now); err != nil {
+			d.logger.Error("Failed to restore open trip",
+				zap.String("vehicle_id", vehicleID.String()),
+				zap.String("trip_id", uuid.UUID(trip.ID.Bytes).String()),
+				zap.Error(err),
+			)
+		}
+		v.mu.Unlock()
+	}
+	return nil
+}
+
+// restoreTrip must be called with v.mu held.
+func (d *TripDetector) restoreTrip(ctx context.Context, vehicleID uuid.UUID, v *vehicleTrips, trip domain.Trip, now time.Time) error {
+	// Only one trip per vehicle can be open; an older one ended when the
+	// newer one started at the latest.
+	if v.open != nil {
+		if err := d.closeTrip(ctx, vehicleID, v, v.open.endTime()); err != nil {
+			return err
+		}
+	}
+
+	st := &tripState{trip: trip, last: domain.VehicleStatus{Timestamp: trip.StartTime.Time}}
+	v.open, v.receivedAt = st, now
+
+	query := domain.PositionQuery{
+		VehicleID: vehicleID,
+		From:      trip.StartTime.Time,
+		To:        now,
+		Limit:     ExportBatchSize,
+	}
+	first := true
+	for {
+		positions, err := d.repo.ListPositions(ctx, query)
+		if err != nil {
+			return err
+		}
+		for _, p := range positions {
+			status := p.VehicleStatus
+			// The first position is the reading that opened the trip.
+			if first {
+				st.last, st.speedSum, st.samples = status, status.Speed, 1
+				first = false
+				continue
+			}
+			if status.Timestamp.Sub(st.last.Timestamp) >= d.stopWindow {
+				return d.closeTrip(ctx, vehicleID, v, st.endTime())
+			}
+			if st.advance(status, status.Speed >= d.minSpeed, d.stopWindow) {
+				return d.closeTrip(ctx, vehicleID, v, *st.stoppedAt)
+			}
+		}
+		if len(positions) < ExportBatchSize {
+			return nil
+		}
+		last := positions[len(positions)-1]
+		query.After = &domain.PositionCursor{RecordedAt: last.Timestamp, ID: last.ID}
+	}
+}
+
+// Run periodically sweeps idle trips until ctx is cancelled.
+func (d *TripDetector) Run(ctx context.Context) {
+	interval := d.stopWindow / 2
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			d.Sweep(ctx, now)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// vehicle returns the state of a vehicle, creating it on first use.
+func (d *TripDetector) vehicle(vehicleID uuid.UUID) *vehicleTrips {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	v, ok := d.vehicles[vehicleID]
+	if !ok {
+		v = &vehicleTrips{}
+		d.vehicles[vehicleID] = v
+	}
+	return v
+}
+
+// openTrip must be called with v.mu held.
+func (d *TripDetector) openTrip(ctx context.Context, vehicleID uuid.UUID, v *vehicleTrips, status domain.VehicleStatus) error {
+	trip := domain.Trip{
+		ID:        pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		VehicleID: pgtype.UUID{Bytes: vehicleID, Valid: true},
+		StartTime: pgtype.Timestamptz{Time: status.Timestamp, Valid: true},
+	}
+	if err := d.repo.CreateTrip(ctx, trip); err != nil {
+		return err
+	}
+	d.events.Publish(ctx, domain.NewEvent(domain.EventTripStarted, vehicleID, status.Timestamp, trip))
+
+	v.open = &tripState{
+		trip:     trip,
+		last:     status,
+		speedSum: status.Speed,
+		samples:  1,
+	}
+	return nil
+}
+
+// closeTrip must be called with v.mu held. The state is dropped even if the
+// update fails so a broken row does not block new trips for the vehicle.
+func (d *TripDetector) closeTrip(ctx context.Context, vehicleID uuid.UUID, v *vehicleTrips, endTime time.Time) error {
+	st := v.open
+	v.open = nil
+
+	trip := st.trip
+	trip.EndTime = &endTime
+	trip.Mileage = st.distance
+	if st.samples > 0 {
+		trip.AvgSpeed = st.speedSum / float64(st.samples)
+	}
+	if err := d.repo.CloseTrip(ctx, trip); err != nil {
+		return err
+	}
+	d.events.Publish(ctx, domain.NewEvent(domain.EventTripEnded, vehicleID, endTime, trip))
+	return nil
+}
+
+// advance folds a reading into the open trip and reports whether the vehicle
+// has now been stopped for the window, which ends the trip at stoppedAt.
+func (st *tripState) advance(status domain.VehicleStatus, moving bool, stopWindow time.Duration) bool {
+	step := stepDistance(st.last, status)
+	st.last = status
+
+	if moving {
+		st.distance += st.idleDistance + step
+		st.speedSum += st.idleSpeedSum + status.Speed
+		st.samples += st.idleSamples + 1
+		st.idleDistance, st.idleSpeedSum, st.idleSamples = 0, 0, 0
+		st.stoppedAt = nil
+		return false
+	}
+
+	if st.stoppedAt == nil {
+		// Distance covered while slowing down to a halt still belongs to the trip.
+		st.distance += step
+		stoppedAt := status.Timestamp
+		st.stoppedAt = &stoppedAt
+	} else {
+		st.idleDistance += step
+		st.idleSpeedSum += status.Speed
+		st.idleSamples++
+	}
+	return status.Timestamp.Sub(*st.stoppedAt) >= stopWindow
+}
+
+// endTime is the moment the vehicle stopped, or its last reading if it never
+// did.
+func (st *tripState) endTime() time.Time {
+	if st.stoppedAt != nil {
+		return *st.stoppedAt
+	}
+	return st.last.Timestamp
+}
+
+// stepDistance returns the distance in kilometres between two readings, or
+// zero if either lacks a location.
+func stepDistance(from, to domain.VehicleStatus) float64 {
+	lon1, lat1, ok1 := from.Coordinates()
+	lon2, lat2, ok2 := to.Coordinates()
+	if !ok1 || !ok2 {
+		return 0
+	}
+	return geo.DistanceKm(lon1, lat1, lon2, lat2)
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/google/uuid"
+)
+
+// DefaultVehicleLimit and MaxVehicleLimit bound a page of the vehicle
+// registry.
+const (
+	DefaultVehicleLimit = 100
+	MaxVehicleLimit     = 1000
+)
+
+// VehicleRegistryAPI defines the interface for vehicle registry operations.
+type VehicleRegistryAPI interface {
+	CreateVehicle(ctx context.Context, vehicle domain.Vehicle) (*domain.Vehicle, error)
+	GetVehicle(ctx context.Context, id uuid.UUID) (*domain.Vehicle, error)
+	GetVehicleByPlate(ctx context.Context, plateNumber string) (*domain.Vehicle, error)
+	ListVehicles(ctx context.Context, query domain.VehicleQuery) ([]domain.Vehicle, error)
+	UpdateVehicle(ctx context.Context, vehicle domain.Vehicle) (*domain.Vehicle, error)
+	ArchiveVehicle(ctx context.Context, id uuid.UUID) (*domain.Vehicle, error)
+}
+
+// ArchiveHandler is told about every archived vehicle, e.g. to end what is
+// still open for it.
+type ArchiveHandler interface {
+	VehicleArchived(ctx context.Context, vehicleID uuid.UUID) error
+}
+
+// VehicleRegistry manages the vehicles of the fleet. Plate numbers are unique
+// among the vehicles that are not archived.
+type VehicleRegistry struct {
+	repo     domain.VehicleRegistryRepository
+	cache    domain.VehicleCache
+	archived []ArchiveHandler
+}
+
+// VehicleRegistryOption configures optional behaviour of a VehicleRegistry.
+type VehicleRegistryOption func(*VehicleRegistry)
+
+// WithArchiveHandlers passes every archived vehicle to handlers, in order.
+func WithArchiveHandlers(handlers ...ArchiveHandler) VehicleRegistryOption {
+	return func(s *VehicleRegistry) {
+		s.archived = append(s.archived, handlers...)
+	}
+}
+
+// NewVehicleRegistry creates a VehicleRegistry. Archived vehicles are removed
+// from the fleet snapshot in cache.
+func NewVehicleRegistry(repo domain.VehicleRegistryRepository, cache domain.VehicleCache, opts ...VehicleRegistryOption) *VehicleRegistry {
+	s := &VehicleRegistry{repo: repo, cache: cache}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// CreateVehicle registers a vehicle, generating its ID unless one is given so
+// that vehicles already reporting can be registered. It returns a
+// *domain.ValidationError for an invalid vehicle and domain.ErrVehicleExists
+// if the ID or the plate number is taken.
+func (s *VehicleRegistry) CreateVehicle(ctx context.Context, vehicle domain.Vehicle) (*domain.Vehicle, error) {
+	if err := vehicle.Validate(); err != nil {
+		return nil, err
+	}
+	if vehicle.ID == uuid.Nil {
+		vehicle.ID = uuid.New()
+	}
+	if err := s.checkPlateNumber(ctx, vehicle); err != nil {
+		return nil, err
+	}
+	return s.repo.CreateVehicle(ctx, vehicle)
+}
+
+// GetVehicle returns domain.ErrNotFound if the vehicle does not exist.
+func (s *VehicleRegistry) GetVehicle(ctx context.Context, id uuid.UUID) (*domain.Vehicle, error) {
+	return s.repo.GetVehicle(ctx, id)
+}
+
+// GetVehicleByPlate returns the active vehicle with the plate number or, if
+// there is none, the one archived last. It returns domain.ErrNotFound if no
+// vehicle ever had the plate number.
+func (s *VehicleRegistry) GetVehicleByPlate(ctx context.Context, plateNumber string) (*domain.Vehicle, error) {
+	return s.repo.GetVehicleByPlate(ctx, plateNumber)
+}
+
+// ListVehicles returns a page of the registry.
+func (s *VehicleRegistry) ListVehicles(ctx context.Context, query domain.VehicleQuery) ([]domain.Vehicle, error) {
+	if query.Limit <= 0 {
+		query.Limit = DefaultVehicleLimit
+	}
+	if query.Limit > MaxVehicleLimit {
+		query.Limit = MaxVehicleLimit
+	}
+	return s.repo.ListVehicles(ctx, query)
+}
+
+// UpdateVehicle replaces the plate number and name of the vehicle with
+// vehicle.ID. It returns a *domain.ValidationError for an invalid vehicle,
+// domain.ErrNotFound if the vehicle does not exist and
+// domain.ErrVehicleExists if another active vehicle has the plate number.
+func (s *VehicleRegistry) UpdateVehicle(ctx context.Context, vehicle domain.Vehicle) (*domain.Vehicle, error) {
+	if err := vehicle.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.checkPlateNumber(ctx, vehicle); err != nil {
+		return nil, err
+	}
+	return s.repo.UpdateVehicle(ctx, vehicle)
+}
+
+// ArchiveVehicle retires a vehicle. Its history is kept, its plate number
+// becomes free for another vehicle, it leaves the fleet snapshot and the
+// archive handlers are run. It returns domain.ErrNotFound if the vehicle does
+// not exist. Archiving is repeatable, so a failure after the vehicle was
+// archived can be retried.
+func (s *VehicleRegistry) ArchiveVehicle(ctx context.Context, id uuid.UUID) (*domain.Vehicle, error) {
+	vehicle, err := s.repo.ArchiveVehicle(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cache.RemoveFromFleet(ctx, id); err != nil {
+		return nil, err
+	}
+	for _, h := range s.archived {
+		if err := h.VehicleArchived(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	return vehicle, nil
+}
+
+// checkPlateNumber returns domain.ErrVehicleExists if an active vehicle
+// other than vehicle has its plate number.
+func (s *VehicleRegistry) checkPlateNumber(ctx context.Context, vehicle domain.Vehicle) error {
+	existing, err := s.repo.GetVehicleByPlate(ctx, vehicle.PlateNumber)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID != vehicle.ID && existing.ArchivedAt == nil {
+		return domain.ErrVehicleExists
+	}
+	return nil
+}
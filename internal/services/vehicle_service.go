@@ -1,52 +1,373 @@
 package services
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
 	"time"
 
 	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
 const (
 	// CacheDuration defines how long vehicle status is cached.
 	CacheDuration = 5 * time.Minute
+
+	// PositionHistoryWindow is the default range of a position history query.
+	PositionHistoryWindow = 24 * time.Hour
+	// DefaultPositionLimit and MaxPositionLimit bound a page of positions.
+	DefaultPositionLimit = 100
+	MaxPositionLimit     = 1000
+
+	// TripHistoryWindow is the default range of a trip history query.
+	TripHistoryWindow = 24 * time.Hour
+	// DefaultTripLimit and MaxTripLimit bound a page of trips.
+	DefaultTripLimit = 100
+	MaxTripLimit     = 1000
+
+	// MaxTripPositions caps the number of positions returned for one trip,
+	// or for a page of trips. Longer routes are thinned out to it.
+	MaxTripPositions = 10000
+
+	// ExportBatchSize is the number of trips or positions an export reads
+	// from the store at a time.
+	ExportBatchSize = 1000
+
+	// MaxBatchSize caps the number of items of a single batch ingest.
+	MaxBatchSize = 5000
+
+	// DefaultFleetLimit and MaxFleetLimit bound a page of the fleet snapshot.
+	DefaultFleetLimit = 1000
+	MaxFleetLimit     = 5000
+
+	// DefaultNearbyLimit and MaxNearbyLimit bound the result of a nearby
+	// search.
+	DefaultNearbyLimit = 10
+	MaxNearbyLimit     = 1000
+	// MaxNearbySearch caps the vehicles a nearby search that leaves out
+	// stale vehicles reads from the cache. Vehicles further away than that
+	// many others are not found.
+	MaxNearbySearch = 10000
+	// nearbyOverfetch is how many times the limit such a search reads at
+	// first.
+	nearbyOverfetch = 4
 )
 
 // VehicleServiceAPI defines the interface for vehicle service operations.
 type VehicleServiceAPI interface {
 	GetVehicleStatus(ctx context.Context, vehicleID uuid.UUID) (*domain.VehicleStatus, error)
+	GetFleetStatus(ctx context.Context, query domain.FleetQuery) ([]domain.VehicleSnapshot, error)
+	FindNearbyVehicles(ctx context.Context, query domain.NearbyQuery) ([]domain.NearbyVehicle, error)
 	IngestData(ctx context.Context, data domain.IngestRequest) error
-	GetVehicleTrips(ctx context.Context, vehicleID uuid.UUID) ([]domain.Trip, error)
+	IngestBatch(ctx context.Context, items []domain.IngestRequest) ([]domain.IngestResult, error)
+	GetVehicleTrips(ctx context.Context, query domain.TripQuery) (domain.TripPage, error)
+	GetVehiclePositions(ctx context.Context, query domain.PositionQuery) ([]domain.Position, error)
+	GetTrip(ctx context.Context, tripID uuid.UUID) (*domain.Trip, error)
+	GetTripsPositions(ctx context.Context, trips []domain.Trip) ([]domain.TripPositions, error)
+	GetTripRoute(ctx context.Context, tripID uuid.UUID, maxPoints int) (*domain.Trip, []domain.Position, error)
+	ExportTrips(ctx context.Context, query domain.TripQuery, fn func(domain.Trip) error) error
+	ExportPositions(ctx context.Context, query domain.PositionQuery, fn func(domain.Position) error) error
+}
+
+// StatusProcessor consumes every status accepted by IngestData, e.g. to derive
+// trips from the stream.
+type StatusProcessor interface {
+	Process(ctx context.Context, vehicleID uuid.UUID, status domain.VehicleStatus) error
+}
+
+// Ingester stores readings for device protocol sources that acknowledge a
+// reading only once it is stored.
+type Ingester interface {
+	IngestData(ctx context.Context, data domain.IngestRequest) error
+	IngestBatch(ctx context.Context, items []domain.IngestRequest) ([]domain.IngestResult, error)
 }
 
 // VehicleService encapsulates the business logic for vehicle operations.
 type VehicleService struct {
-	repo  domain.VehicleRepository
-	cache domain.VehicleCache
+	repo        domain.VehicleRepository
+	cache       domain.VehicleCache
+	processors  []StatusProcessor
+	dedupWindow time.Duration
+	registry    domain.VehicleRegistryRepository
+	registered  *registrationCache
+	logger      *zap.Logger
+}
+
+// VehicleServiceOption configures optional behaviour of a VehicleService.
+type VehicleServiceOption func(*VehicleService)
+
+// WithStatusProcessors registers processors that run, in order, after each
+// successful ingest.
+func WithStatusProcessors(processors ...StatusProcessor) VehicleServiceOption {
+	return func(s *VehicleService) {
+		s.processors = append(s.processors, processors...)
+	}
+}
+
+// WithDedupWindow makes requests that repeat a message ID within window be
+// acknowledged without being processed again. Zero disables deduplication.
+func WithDedupWindow(window time.Duration) VehicleServiceOption {
+	return func(s *VehicleService) {
+		s.dedupWindow = window
+	}
+}
+
+// WithRegisteredVehiclesOnly rejects readings for vehicles that are not in
+// registry, or are archived, instead of registering them on their first
+// reading. The answers are cached for RegistrationCacheTTL.
+func WithRegisteredVehiclesOnly(registry domain.VehicleRegistryRepository) VehicleServiceOption {
+	return func(s *VehicleService) {
+		s.registry = registry
+		s.registered = newRegistrationCache(RegistrationCacheTTL)
+	}
+}
+
+// WithLogger logs the failures that do not fail an ingest, such as a
+// processor rejecting a reading that is already stored.
+func WithLogger(logger *zap.Logger) VehicleServiceOption {
+	return func(s *VehicleService) {
+		s.logger = logger
+	}
 }
 
 // NewVehicleService creates a new VehicleService.
-func NewVehicleService(repo domain.VehicleRepository, cache domain.VehicleCache) *VehicleService {
-	return &VehicleService{
-		repo:  repo,
-		cache: cache,
+func NewVehicleService(repo domain.VehicleRepository, cache domain.VehicleCache, opts ...VehicleServiceOption) *VehicleService {
+	s := &VehicleService{
+		repo:   repo,
+		cache:  cache,
+		logger: zap.NewNop(),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
-// IngestData processes new vehicle data, updating the database and cache.
+// IngestData processes new vehicle data, updating the database and cache. An
+// implausible reading, or one for an unregistered vehicle when only registered
+// vehicles are accepted, is rejected with a *domain.ValidationError and a
+// repeated message with domain.ErrDuplicateMessage.
 func (s *VehicleService) IngestData(ctx context.Context, data domain.IngestRequest) error {
-	// 1. Update the database (write-through)
+	if err := data.Validate(); err != nil {
+		return err
+	}
+	if err := s.checkRegistered(ctx, uuid.UUID(data.VehicleID.Bytes)); err != nil {
+		return err
+	}
+
+	first, err := s.claimMessage(ctx, data)
+	if err != nil {
+		return err
+	}
+	if !first {
+		return domain.ErrDuplicateMessage
+	}
+
+	// 1. Update the database (write-through); a late reading leaves the newer
+	// last status in place
 	vehicleUUID := uuid.UUID(data.VehicleID.Bytes)
-	if err := s.repo.UpdateVehicleStatus(ctx, vehicleUUID, data.PlateNumber, data.Status); err != nil {
+	applied, err := s.repo.UpdateVehicleStatus(ctx, vehicleUUID, data.PlateNumber, data.Status)
+	if err != nil {
+		s.releaseMessage(ctx, data)
 		return err
 	}
 
-	// 2. Update the cache
-	return s.cache.SetStatus(ctx, data.VehicleID.Bytes, &data.Status, CacheDuration)
+	// 2. Append to the position history
+	if err := s.repo.InsertPosition(ctx, vehicleUUID, data.Status); err != nil {
+		s.releaseMessage(ctx, data)
+		return err
+	}
+
+	// Late readings stop here so they cannot move the cache or the derived
+	// data backwards.
+	if !applied {
+		return nil
+	}
+
+	// 3. Update the cache. The reading is stored by now, so a failure is
+	// logged rather than returned: a retry would be dropped as a duplicate or
+	// stored twice. The cache expires and is corrected by the next reading.
+	if err := s.cache.SetStatus(ctx, data.VehicleID.Bytes, &data.Status, CacheDuration); err != nil {
+		s.logger.Error("Failed to cache status",
+			zap.String("vehicle_id", vehicleUUID.String()),
+			zap.Error(err),
+		)
+	}
+
+	// 4. Feed the derived-data processors (trips, ...). The reading is
+	// stored by now, so a retry would only duplicate it.
+	s.process(ctx, vehicleUUID, data.Status)
+	return nil
+}
+
+// IngestBatch processes readings that a device buffered while offline. Items
+// are accepted, rejected or recognised as duplicates individually and the
+// returned results line up with items. The accepted readings are written to
+// the position history in a single round trip, while each vehicle's last
+// status only moves to its newest reading of the batch. An error that comes
+// with results happened after the batch was stored.
+func (s *VehicleService) IngestBatch(ctx context.Context, items []domain.IngestRequest) ([]domain.IngestResult, error) {
+	results := make([]domain.IngestResult, len(items))
+	accepted := make([]domain.IngestRequest, 0, len(items))
+	for i, item := range items {
+		results[i] = domain.IngestResult{Index: i, Status: domain.IngestAccepted}
+		err := item.Validate()
+		if err == nil {
+			err = s.checkRegistered(ctx, uuid.UUID(item.VehicleID.Bytes))
+		}
+		var verr *domain.ValidationError
+		if errors.As(err, &verr) {
+			results[i].Status = domain.IngestRejected
+			results[i].Error = err.Error()
+			results[i].Fields = verr.Fields
+			continue
+		}
+		if err != nil {
+			s.releaseMessages(ctx, accepted)
+			return nil, err
+		}
+
+		first, err := s.claimMessage(ctx, item)
+		if err != nil {
+			s.releaseMessages(ctx, accepted)
+			return nil, err
+		}
+		if !first {
+			results[i].Status = domain.IngestDuplicate
+			continue
+		}
+		accepted = append(accepted, item)
+	}
+	if len(accepted) == 0 {
+		return results, nil
+	}
+
+	// Buffered uploads are not guaranteed to be in the order the readings
+	// were taken.
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return accepted[i].Status.Timestamp.Before(accepted[j].Status.Timestamp)
+	})
+
+	var vehicleIDs []uuid.UUID
+	latest := make(map[uuid.UUID]domain.IngestRequest)
+	positions := make([]domain.Position, 0, len(accepted))
+	for _, item := range accepted {
+		vehicleID := uuid.UUID(item.VehicleID.Bytes)
+		if _, ok := latest[vehicleID]; !ok {
+			vehicleIDs = append(vehicleIDs, vehicleID)
+		}
+		latest[vehicleID] = item
+		positions = append(positions, domain.Position{VehicleID: vehicleID, VehicleStatus: item.Status})
+	}
+
+	// 1. Update the database with the newest reading of every vehicle
+	applied := make(map[uuid.UUID]bool, len(vehicleIDs))
+	for _, vehicleID := range vehicleIDs {
+		item := latest[vehicleID]
+		ok, err := s.repo.UpdateVehicleStatus(ctx, vehicleID, item.PlateNumber, item.Status)
+		if err != nil {
+			s.releaseMessages(ctx, accepted)
+			return nil, err
+		}
+		applied[vehicleID] = ok
+	}
+
+	// 2. Append the whole batch to the position history
+	if err := s.repo.InsertPositions(ctx, positions); err != nil {
+		s.releaseMessages(ctx, accepted)
+		return nil, err
+	}
+
+	// 3. Update the cache, skipping vehicles whose stored status is newer
+	// than anything in the batch
+	for _, vehicleID := range vehicleIDs {
+		if !applied[vehicleID] {
+			continue
+		}
+		status := latest[vehicleID].Status
+		if err := s.cache.SetStatus(ctx, vehicleID, &status, CacheDuration); err != nil {
+			return results, err
+		}
+	}
+
+	// 4. Feed the derived-data processors in chronological order
+	for _, p := range positions {
+		if applied[p.VehicleID] {
+			s.process(ctx, p.VehicleID, p.VehicleStatus)
+		}
+	}
+	return results, nil
+}
+
+// checkRegistered returns a *domain.ValidationError if only registered
+// vehicles are accepted and the vehicle is not one.
+func (s *VehicleService) checkRegistered(ctx context.Context, vehicleID uuid.UUID) error {
+	if s.registry == nil {
+		return nil
+	}
+	registered, ok := s.registered.get(vehicleID)
+	if !ok {
+		var err error
+		registered, err = s.registry.IsVehicleActive(ctx, vehicleID)
+		if err != nil {
+			return err
+		}
+		s.registered.put(vehicleID, registered)
+	}
+	if !registered {
+		return domain.UnregisteredVehicleError()
+	}
+	return nil
+}
+
+// claimMessage records the message ID of a request and reports whether the
+// request should be processed. Requests without a message ID always are.
+func (s *VehicleService) claimMessage(ctx context.Context, data domain.IngestRequest) (bool, error) {
+	if s.dedupWindow <= 0 || data.MessageID == "" {
+		return true, nil
+	}
+	return s.cache.MarkMessageSeen(ctx, uuid.UUID(data.VehicleID.Bytes), data.MessageID, s.dedupWindow)
+}
+
+// releaseMessage forgets the message ID of a request that failed to persist,
+// so the device's retry is processed. It is best effort: if it fails the retry
+// is dropped as a duplicate until the window expires.
+func (s *VehicleService) releaseMessage(ctx context.Context, data domain.IngestRequest) {
+	if s.dedupWindow <= 0 || data.MessageID == "" {
+		return
+	}
+	_ = s.cache.ForgetMessage(ctx, uuid.UUID(data.VehicleID.Bytes), data.MessageID)
+}
+
+func (s *VehicleService) releaseMessages(ctx context.Context, items []domain.IngestRequest) {
+	for _, item := range items {
+		s.releaseMessage(ctx, item)
+	}
+}
+
+// process runs every registered processor, so one failing processor does not
+// starve the others of the reading. Failures are only logged: the reading is
+// already stored, and failing the ingest would make the device resend it.
+func (s *VehicleService) process(ctx context.Context, vehicleID uuid.UUID, status domain.VehicleStatus) {
+	for _, p := range s.processors {
+		if err := p.Process(ctx, vehicleID, status); err != nil {
+			s.logger.Error("Failed to process status",
+				zap.String("vehicle_id", vehicleID.String()),
+				zap.String("processor", fmt.Sprintf("%T", p)),
+				zap.Error(err),
+			)
+		}
+	}
 }
 
 // GetVehicleStatus retrieves the current status of a vehicle, trying the cache first.
+// It returns nil for a vehicle that has not reported a status yet and
+// domain.ErrNotFound for an unknown vehicle.
 func (s *VehicleService) GetVehicleStatus(ctx context.Context, vehicleID uuid.UUID) (*domain.VehicleStatus, error) {
 	status, err := s.cache.GetStatus(ctx, vehicleID)
 	if status != nil || err != nil {
@@ -54,7 +375,7 @@ func (s *VehicleService) GetVehicleStatus(ctx context.Context, vehicleID uuid.UU
 	}
 	// fallback to DB
 	status, err = s.repo.GetVehicleStatus(ctx, vehicleID)
-	if err != nil {
+	if status == nil || err != nil {
 		return nil, err
 	}
 	// optionally write back to cache
@@ -62,8 +383,345 @@ func (s *VehicleService) GetVehicleStatus(ctx context.Context, vehicleID uuid.UU
 	return status, nil
 }
 
-// GetVehicleTrips retrieves the trip history for a vehicle in the last 24 hours.
-func (s *VehicleService) GetVehicleTrips(ctx context.Context, vehicleID uuid.UUID) ([]domain.Trip, error) {
-	since := time.Now().Add(-24 * time.Hour)
-	return s.repo.FindTripsByVehicleID(ctx, vehicleID, since)
+// GetFleetStatus retrieves a page of the latest statuses of the fleet, ordered
+// by vehicle ID. A bounding box without vehicle IDs is looked up in the
+// position index rather than filtered from the whole fleet. An invalid
+// bounding box is rejected with a *domain.ValidationError.
+func (s *VehicleService) GetFleetStatus(ctx context.Context, query domain.FleetQuery) ([]domain.VehicleSnapshot, error) {
+	if query.BBox != nil {
+		if err := query.BBox.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if query.Limit <= 0 {
+		query.Limit = DefaultFleetLimit
+	}
+	if query.Limit > MaxFleetLimit {
+		query.Limit = MaxFleetLimit
+	}
+
+	var snapshots []domain.VehicleSnapshot
+	var err error
+	if query.BBox != nil && len(query.VehicleIDs) == 0 {
+		snapshots, err = s.cache.ListStatusesInBox(ctx, *query.BBox, query.UpdatedSince)
+	} else {
+		snapshots, err = s.cache.ListStatuses(ctx, query.VehicleIDs, query.UpdatedSince)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if query.BBox != nil {
+		snapshots = slices.DeleteFunc(snapshots, func(v domain.VehicleSnapshot) bool {
+			lon, lat, ok := v.Status.Coordinates()
+			return !ok || !query.BBox.Contains(lon, lat)
+		})
+	}
+
+	// Paginate in a stable order; a vehicle asked for twice is listed once.
+	slices.SortFunc(snapshots, func(a, b domain.VehicleSnapshot) int {
+		return bytes.Compare(a.VehicleID[:], b.VehicleID[:])
+	})
+	snapshots = slices.CompactFunc(snapshots, func(a, b domain.VehicleSnapshot) bool {
+		return a.VehicleID == b.VehicleID
+	})
+
+	if int(query.Offset) >= len(snapshots) {
+		return []domain.VehicleSnapshot{}, nil
+	}
+	snapshots = snapshots[query.Offset:]
+	if len(snapshots) > int(query.Limit) {
+		snapshots = snapshots[:query.Limit]
+	}
+	return snapshots, nil
+}
+
+// FindNearbyVehicles retrieves the vehicles closest to a point, nearest first,
+// with their distance and current status. With UpdatedSince, only the nearest
+// MaxNearbySearch vehicles are considered. An invalid query is rejected with a
+// *domain.ValidationError.
+func (s *VehicleService) FindNearbyVehicles(ctx context.Context, query domain.NearbyQuery) ([]domain.NearbyVehicle, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	if query.Limit <= 0 {
+		query.Limit = DefaultNearbyLimit
+	}
+	if query.Limit > MaxNearbyLimit {
+		query.Limit = MaxNearbyLimit
+	}
+
+	// Stale vehicles are dropped after the search, so it reads more than the
+	// limit then, and reads further while too few fresh vehicles are found
+	// and the index has more.
+	count := int(query.Limit)
+	if !query.UpdatedSince.IsZero() {
+		count *= nearbyOverfetch
+	}
+	for {
+		vehicles, err := s.cache.FindNearby(ctx, query.Longitude, query.Latitude, query.Radius, count)
+		if err != nil {
+			return nil, err
+		}
+		found := len(vehicles)
+		if !query.UpdatedSince.IsZero() {
+			vehicles = slices.DeleteFunc(vehicles, func(v domain.NearbyVehicle) bool {
+				return v.Status.Timestamp.Before(query.UpdatedSince)
+			})
+		}
+		if len(vehicles) < int(query.Limit) && found == count && count < MaxNearbySearch {
+			count = min(count*2, MaxNearbySearch)
+			continue
+		}
+
+		if len(vehicles) > int(query.Limit) {
+			vehicles = vehicles[:query.Limit]
+		}
+		if vehicles == nil {
+			vehicles = []domain.NearbyVehicle{}
+		}
+		return vehicles, nil
+	}
+}
+
+// GetVehicleTrips retrieves a page of a vehicle's trip history, newest first
+// unless ascending order is asked for. A zero To defaults to now, a zero From
+// to TripHistoryWindow before To.
+func (s *VehicleService) GetVehicleTrips(ctx context.Context, query domain.TripQuery) (domain.TripPage, error) {
+	if query.To.IsZero() {
+		query.To = time.Now()
+	}
+	if query.From.IsZero() {
+		query.From = query.To.Add(-TripHistoryWindow)
+	}
+	if query.Order != domain.SortAscending {
+		query.Order = domain.SortDescending
+	}
+	if query.Limit <= 0 {
+		query.Limit = DefaultTripLimit
+	}
+	if query.Limit > MaxTripLimit {
+		query.Limit = MaxTripLimit
+	}
+
+	// One extra trip tells whether another page follows.
+	limit := query.Limit
+	query.Limit++
+	trips, err := s.repo.ListTrips(ctx, query)
+	if err != nil {
+		return domain.TripPage{}, err
+	}
+
+	page := domain.TripPage{Trips: trips}
+	if len(trips) > int(limit) {
+		page.Trips = trips[:limit]
+		last := page.Trips[limit-1]
+		page.Next = &domain.TripCursor{StartTime: last.StartTime.Time, ID: last.ID.Bytes}
+	}
+	return page, nil
+}
+
+// GetVehiclePositions retrieves a page of a vehicle's position history. A zero
+// To defaults to now, a zero From to PositionHistoryWindow before To.
+func (s *VehicleService) GetVehiclePositions(ctx context.Context, query domain.PositionQuery) ([]domain.Position, error) {
+	if query.To.IsZero() {
+		query.To = time.Now()
+	}
+	if query.From.IsZero() {
+		query.From = query.To.Add(-PositionHistoryWindow)
+	}
+	if query.Limit <= 0 {
+		query.Limit = DefaultPositionLimit
+	}
+	if query.Limit > MaxPositionLimit {
+		query.Limit = MaxPositionLimit
+	}
+	return s.repo.ListPositions(ctx, query)
+}
+
+// GetTripsPositions retrieves the positions recorded during each of a page of
+// one vehicle's trips, in the order of the trips. All of them are read in a
+// single query over the page's time span, at most MaxTripPositions in
+// chronological order; the trips whose positions run past that are marked
+// truncated. An open trip extends to now.
+func (s *VehicleService) GetTripsPositions(ctx context.Context, trips []domain.Trip) ([]domain.TripPositions, error) {
+	result := make([]domain.TripPositions, len(trips))
+	if len(trips) == 0 {
+		return result, nil
+	}
+
+	queries := make([]domain.PositionQuery, len(trips))
+	for i, trip := range trips {
+		queries[i] = tripPositionQuery(trip)
+	}
+	span := queries[0]
+	for _, q := range queries[1:] {
+		if q.From.Before(span.From) {
+			span.From = q.From
+		}
+		if q.To.After(span.To) {
+			span.To = q.To
+		}
+	}
+	span.Limit = MaxTripPositions
+	positions, err := s.repo.ListPositions(ctx, span)
+	if err != nil {
+		return nil, err
+	}
+	full := len(positions) == MaxTripPositions
+
+	for i, q := range queries {
+		lo := sort.Search(len(positions), func(j int) bool { return !positions[j].Timestamp.Before(q.From) })
+		hi := sort.Search(len(positions), func(j int) bool { return positions[j].Timestamp.After(q.To) })
+		result[i].Positions = positions[lo:max(lo, hi)]
+		// Positions tied with the last one read may have been cut off too.
+		result[i].Truncated = full && !q.To.Before(positions[len(positions)-1].Timestamp)
+	}
+	return result, nil
+}
+
+// tripPositionQuery selects the positions recorded during a trip. An open
+// trip extends to now.
+func tripPositionQuery(trip domain.Trip) domain.PositionQuery {
+	to := time.Now()
+	if trip.EndTime != nil {
+		to = *trip.EndTime
+	}
+	return domain.PositionQuery{
+		VehicleID: uuid.UUID(trip.VehicleID.Bytes),
+		From:      trip.StartTime.Time,
+		To:        to,
+	}
+}
+
+// ExportTrips passes every trip matched by the query to fn in the query's
+// order, reading them ExportBatchSize at a time, so that an export does not
+// hold the whole history in memory. The query's Limit is ignored. It stops at
+// the first error, including one returned by fn.
+func (s *VehicleService) ExportTrips(ctx context.Context, query domain.TripQuery, fn func(domain.Trip) error) error {
+	// Pin the range so that every batch reads the same window.
+	if query.To.IsZero() {
+		query.To = time.Now()
+	}
+	if query.From.IsZero() {
+		query.From = query.To.Add(-TripHistoryWindow)
+	}
+	query.Limit = ExportBatchSize
+
+	for {
+		page, err := s.GetVehicleTrips(ctx, query)
+		if err != nil {
+			return err
+		}
+		for _, trip := range page.Trips {
+			if err := fn(trip); err != nil {
+				return err
+			}
+		}
+		if page.Next == nil {
+			return nil
+		}
+		query.After = page.Next
+	}
+}
+
+// ExportPositions passes every position matched by the query to fn in
+// chronological order, reading them ExportBatchSize at a time. The query's
+// Limit and Offset are ignored. It stops at the first error, including one
+// returned by fn.
+func (s *VehicleService) ExportPositions(ctx context.Context, query domain.PositionQuery, fn func(domain.Position) error) error {
+	if query.To.IsZero() {
+		query.To = time.Now()
+	}
+	if query.From.IsZero() {
+		query.From = query.To.Add(-PositionHistoryWindow)
+	}
+	query.Limit = ExportBatchSize
+	query.Offset = 0
+
+	for {
+		positions, err := s.repo.ListPositions(ctx, query)
+		if err != nil {
+			return err
+		}
+		for _, p := range positions {
+			if err := fn(p); err != nil {
+				return err
+			}
+		}
+		if len(positions) < ExportBatchSize {
+			return nil
+		}
+		last := positions[len(positions)-1]
+		query.After = &domain.PositionCursor{RecordedAt: last.Timestamp, ID: last.ID}
+	}
+}
+
+// GetTrip retrieves a single trip. It returns domain.ErrNotFound for an
+// unknown trip.
+func (s *VehicleService) GetTrip(ctx context.Context, tripID uuid.UUID) (*domain.Trip, error) {
+	return s.repo.GetTrip(ctx, tripID)
+}
+
+// GetTripRoute retrieves a trip and the positions recorded during it in
+// chronological order. The route is thinned out evenly over the whole trip to
+// at most maxPoints positions, keeping the first and last one; zero or
+// negative maxPoints, like any above it, means MaxTripPositions.
+func (s *VehicleService) GetTripRoute(ctx context.Context, tripID uuid.UUID, maxPoints int) (*domain.Trip, []domain.Position, error) {
+	trip, err := s.repo.GetTrip(ctx, tripID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if maxPoints <= 0 || maxPoints > MaxTripPositions {
+		maxPoints = MaxTripPositions
+	}
+
+	query := tripPositionQuery(*trip)
+	n, err := s.repo.CountPositions(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	positions, err := s.samplePositions(ctx, query, int(n), maxPoints)
+	if err != nil {
+		return nil, nil, err
+	}
+	return trip, positions, nil
+}
+
+// samplePositions reads the n positions matched by query and keeps at most
+// maxPoints of them, spread evenly and always including both ends, without
+// holding the others in memory. Positions stored while it reads shift the
+// picks slightly but never push out the last position.
+func (s *VehicleService) samplePositions(ctx context.Context, query domain.PositionQuery, n, maxPoints int) ([]domain.Position, error) {
+	m := min(n, maxPoints)
+	picks := make([]domain.Position, 0, m)
+	// pick returns the index of the k-th position to keep.
+	pick := func(k int) int {
+		if m <= 1 {
+			return 0
+		}
+		return k * (n - 1) / (m - 1)
+	}
+
+	i := 0
+	var last *domain.Position
+	err := s.ExportPositions(ctx, query, func(p domain.Position) error {
+		if len(picks) < m && i == pick(len(picks)) {
+			picks = append(picks, p)
+		}
+		i++
+		last = &p
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if last != nil && m > 1 && (len(picks) == 0 || picks[len(picks)-1].ID != last.ID) {
+		if len(picks) == m {
+			picks[m-1] = *last
+		} else {
+			picks = append(picks, *last)
+		}
+	}
+	return picks, nil
 }
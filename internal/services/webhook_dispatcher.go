@@ -0,0 +1,215 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/pkg/utils"
+	"github.com/AnjuRKrishnan/fleet-tracker/pkg/webhook"
+	"go.uber.org/zap"
+)
+
+const (
+	// WebhookBatchSize caps the deliveries a dispatcher attempts at once.
+	WebhookBatchSize = 50
+	// WebhookLease is how long a claimed delivery is hidden from other
+	// dispatchers. It must exceed the HTTP client timeout; a delivery whose
+	// dispatcher dies mid-attempt is retried once the lease runs out.
+	WebhookLease = 2 * time.Minute
+	// WebhookRetryBaseDelay and WebhookRetryMaxDelay bound the exponential
+	// backoff between the attempts of a delivery.
+	WebhookRetryBaseDelay = 10 * time.Second
+	WebhookRetryMaxDelay  = time.Hour
+	// WebhookPurgeInterval is how often completed deliveries past the
+	// retention are dropped from the log.
+	WebhookPurgeInterval = time.Hour
+
+	// maxWebhookErrorLength caps the response excerpt kept in the log.
+	maxWebhookErrorLength = 256
+)
+
+// WebhookDeliveryPolicy configures a WebhookDispatcher.
+type WebhookDeliveryPolicy struct {
+	// PollInterval is how often due deliveries are claimed.
+	PollInterval time.Duration
+	// MaxAttempts is how often a delivery is attempted before it fails.
+	MaxAttempts int
+	// DisableAfter is how long a subscription may keep failing, without a
+	// single successful delivery, before it is disabled.
+	DisableAfter time.Duration
+	// Retention is how long completed deliveries stay in the log.
+	Retention time.Duration
+}
+
+// Backoff returns the delay before the next attempt of a delivery that has
+// failed attempts times: WebhookRetryBaseDelay, doubling with every attempt
+// up to WebhookRetryMaxDelay.
+func (p WebhookDeliveryPolicy) Backoff(attempts int) time.Duration {
+	delay := WebhookRetryBaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= WebhookRetryMaxDelay {
+			return WebhookRetryMaxDelay
+		}
+	}
+	return delay
+}
+
+// WebhookDispatcher sends the queued webhook deliveries. Every payload is
+// signed with the subscription secret (see package webhook), a 2xx response
+// counts as delivered and anything else is retried with exponential backoff.
+// Dispatchers on several instances share the queue without sending a
+// delivery twice, except when one dies mid-attempt; receivers should
+// therefore ignore repeated delivery IDs.
+type WebhookDispatcher struct {
+	repo   domain.WebhookRepository
+	client *http.Client
+	policy WebhookDeliveryPolicy
+	logger *zap.Logger
+}
+
+// NewWebhookDispatcher creates a WebhookDispatcher. The client's timeout
+// bounds each attempt and must be shorter than WebhookLease, so that an
+// attempt still running is never claimed again; webhook.NewClient creates one
+// that only reaches public addresses. The policy must allow at least one
+// attempt.
+func NewWebhookDispatcher(repo domain.WebhookRepository, client *http.Client, policy WebhookDeliveryPolicy, logger *zap.Logger) (*WebhookDispatcher, error) {
+	if client.Timeout <= 0 || client.Timeout >= WebhookLease {
+		return nil, fmt.Errorf("webhook timeout %v must be positive and shorter than the %v delivery lease", client.Timeout, WebhookLease)
+	}
+	if policy.MaxAttempts <= 0 {
+		return nil, fmt.Errorf("webhook max attempts %d must be positive", policy.MaxAttempts)
+	}
+	return &WebhookDispatcher{
+		repo:   repo,
+		client: client,
+		policy: policy,
+		logger: logger,
+	}, nil
+}
+
+// Dispatch attempts the deliveries due at now and waits for the attempts to
+// finish. It returns the number of deliveries attempted.
+func (d *WebhookDispatcher) Dispatch(ctx context.Context, now time.Time) int {
+	deliveries, err := d.repo.ClaimDeliveries(ctx, now, now.Add(WebhookLease), WebhookBatchSize)
+	if err != nil {
+		d.logger.Error("Failed to claim webhook deliveries", zap.Error(err))
+		return 0
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		utils.SafeGo(func() {
+			defer wg.Done()
+			d.deliver(ctx, delivery)
+		}, "WebhookDelivery", delivery.ID.String())
+	}
+	wg.Wait()
+	return len(deliveries)
+}
+
+// Run dispatches due deliveries every poll interval, and purges the log once
+// per WebhookPurgeInterval, until ctx is cancelled.
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	poll := time.NewTicker(d.policy.PollInterval)
+	defer poll.Stop()
+	purge := time.NewTicker(WebhookPurgeInterval)
+	defer purge.Stop()
+
+	for {
+		select {
+		case now := <-poll.C:
+			// Keep going while full batches come back, so a backlog drains
+			// faster than one batch per interval.
+			for d.Dispatch(ctx, now) == WebhookBatchSize && ctx.Err() == nil {
+				now = time.Now()
+			}
+		case now := <-purge.C:
+			if _, err := d.repo.DeleteDeliveriesBefore(ctx, now.Add(-d.policy.Retention)); err != nil {
+				d.logger.Error("Failed to purge webhook deliveries", zap.Error(err))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (d *WebhookDispatcher) deliver(ctx context.Context, delivery domain.PendingDelivery) {
+	attempt := d.attempt(ctx, delivery)
+	log := d.logger.With(
+		zap.String("delivery_id", delivery.ID.String()),
+		zap.String("subscription_id", delivery.SubscriptionID.String()),
+	)
+
+	if attempt.Error == "" {
+		if err := d.repo.CompleteDelivery(ctx, delivery.ID, domain.WebhookDelivered, attempt); err != nil {
+			log.Error("Failed to record webhook delivery", zap.Error(err))
+		}
+		if err := d.repo.RecordWebhookSuccess(ctx, delivery.SubscriptionID); err != nil {
+			log.Error("Failed to record webhook success", zap.Error(err))
+		}
+		return
+	}
+
+	attempts := delivery.Attempts + 1
+	var err error
+	if attempts >= d.policy.MaxAttempts {
+		log.Warn("Webhook delivery failed", zap.Int("attempts", attempts), zap.String("error", attempt.Error))
+		err = d.repo.CompleteDelivery(ctx, delivery.ID, domain.WebhookFailed, attempt)
+	} else {
+		err = d.repo.RetryDelivery(ctx, delivery.ID, attempt, attempt.At.Add(d.policy.Backoff(attempts)))
+	}
+	if err != nil {
+		log.Error("Failed to record webhook attempt", zap.Error(err))
+	}
+
+	sub, err := d.repo.RecordWebhookFailure(ctx, delivery.SubscriptionID, attempt.At, attempt.At.Add(-d.policy.DisableAfter))
+	if err != nil {
+		log.Error("Failed to record webhook failure", zap.Error(err))
+		return
+	}
+	if !sub.Enabled {
+		log.Warn("Webhook subscription is disabled", zap.String("url", sub.URL), zap.Timep("failing_since", sub.FailingSince))
+	}
+}
+
+// attempt posts the payload once. A non-empty Error marks the attempt as
+// failed.
+func (d *WebhookDispatcher) attempt(ctx context.Context, delivery domain.PendingDelivery) domain.WebhookAttempt {
+	attempt := domain.WebhookAttempt{At: time.Now()}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "fleet-tracker-webhooks")
+	req.Header.Set(webhook.EventHeader, string(delivery.EventType))
+	req.Header.Set(webhook.DeliveryHeader, delivery.ID.String())
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(delivery.Secret, attempt.At, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer resp.Body.Close()
+
+	status := resp.StatusCode
+	attempt.ResponseStatus = &status
+	if status < 200 || status > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookErrorLength))
+		attempt.Error = fmt.Sprintf("unexpected status %d: %s", status, bytes.TrimSpace(body))
+	}
+	// Drain the body so the connection can be reused.
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	return attempt
+}
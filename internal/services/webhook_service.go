@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// WebhookDeliveryWindow is the default range of a delivery log query.
+	WebhookDeliveryWindow = 24 * time.Hour
+	// DefaultDeliveryLimit and MaxDeliveryLimit bound a page of deliveries.
+	DefaultDeliveryLimit = 100
+	MaxDeliveryLimit     = 1000
+)
+
+// WebhookServiceAPI defines the interface for webhook subscription operations.
+type WebhookServiceAPI interface {
+	CreateWebhook(ctx context.Context, sub domain.WebhookSubscription) (*domain.WebhookSubscription, error)
+	GetWebhook(ctx context.Context, id uuid.UUID) (*domain.WebhookSubscription, error)
+	ListWebhooks(ctx context.Context) ([]domain.WebhookSubscription, error)
+	UpdateWebhook(ctx context.Context, sub domain.WebhookSubscription) (*domain.WebhookSubscription, error)
+	DeleteWebhook(ctx context.Context, id uuid.UUID) error
+	ListDeliveries(ctx context.Context, query domain.WebhookDeliveryQuery) ([]domain.WebhookDelivery, error)
+}
+
+// WebhookService manages webhook subscriptions and, as an EventPublisher,
+// queues a delivery of every event for each subscription that wants it. The
+// deliveries are sent by a WebhookDispatcher.
+type WebhookService struct {
+	repo   domain.WebhookRepository
+	logger *zap.Logger
+	subs   *listCache[domain.WebhookSubscription]
+}
+
+// NewWebhookService creates a WebhookService.
+func NewWebhookService(repo domain.WebhookRepository, logger *zap.Logger) *WebhookService {
+	return &WebhookService{
+		repo:   repo,
+		logger: logger,
+		subs:   newListCache(ListCacheTTL, repo.ListWebhooks),
+	}
+}
+
+// CreateWebhook stores a new subscription, generating its secret if none is
+// given. The result is the only response that includes the secret. An
+// invalid subscription is rejected with a *domain.ValidationError.
+func (s *WebhookService) CreateWebhook(ctx context.Context, sub domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	if err := sub.Validate(); err != nil {
+		return nil, err
+	}
+	if sub.Secret == "" {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+		sub.Secret = secret
+	}
+
+	created, err := s.repo.CreateWebhook(ctx, sub)
+	if err != nil {
+		return nil, err
+	}
+	s.subs.invalidate()
+	return created, nil
+}
+
+// GetWebhook returns domain.ErrNotFound if the subscription does not exist.
+func (s *WebhookService) GetWebhook(ctx context.Context, id uuid.UUID) (*domain.WebhookSubscription, error) {
+	sub, err := s.repo.GetWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	sub.Secret = ""
+	return sub, nil
+}
+
+// ListWebhooks returns every subscription.
+func (s *WebhookService) ListWebhooks(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	subs, err := s.repo.ListWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range subs {
+		subs[i].Secret = ""
+	}
+	return subs, nil
+}
+
+// UpdateWebhook replaces the subscription with sub.ID, keeping its secret if
+// sub.Secret is empty. Enabling a disabled subscription resumes its pending
+// deliveries. It returns a *domain.ValidationError for an invalid
+// subscription and domain.ErrNotFound if it does not exist.
+func (s *WebhookService) UpdateWebhook(ctx context.Context, sub domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	if err := sub.Validate(); err != nil {
+		return nil, err
+	}
+	updated, err := s.repo.UpdateWebhook(ctx, sub)
+	if err != nil {
+		return nil, err
+	}
+	s.subs.invalidate()
+	updated.Secret = ""
+	return updated, nil
+}
+
+// DeleteWebhook returns domain.ErrNotFound if the subscription does not exist.
+func (s *WebhookService) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.DeleteWebhook(ctx, id); err != nil {
+		return err
+	}
+	s.subs.invalidate()
+	return nil
+}
+
+// ListDeliveries retrieves a page of the delivery log of a subscription,
+// newest first. A zero To defaults to now and a zero From to
+// WebhookDeliveryWindow before To.
+func (s *WebhookService) ListDeliveries(ctx context.Context, query domain.WebhookDeliveryQuery) ([]domain.WebhookDelivery, error) {
+	if query.To.IsZero() {
+		query.To = time.Now()
+	}
+	if query.From.IsZero() {
+		query.From = query.To.Add(-WebhookDeliveryWindow)
+	}
+	if query.Limit <= 0 {
+		query.Limit = DefaultDeliveryLimit
+	}
+	if query.Limit > MaxDeliveryLimit {
+		query.Limit = MaxDeliveryLimit
+	}
+	return s.repo.ListDeliveries(ctx, query)
+}
+
+// Publish queues the event for every enabled subscription that wants it.
+// Failures are logged, since they must not fail the operation that emitted
+// the event.
+func (s *WebhookService) Publish(ctx context.Context, event domain.Event) {
+	subs, err := s.subs.get(ctx)
+	if err != nil {
+		s.logger.Error("Failed to load webhook subscriptions", zap.Error(err))
+		return
+	}
+
+	var ids []uuid.UUID
+	for _, sub := range subs {
+		if sub.Wants(event.Type) {
+			ids = append(ids, sub.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		s.logger.Error("Failed to encode webhook event", zap.String("event_type", string(event.Type)), zap.Error(err))
+		return
+	}
+	if err := s.repo.EnqueueDeliveries(ctx, ids, event, payload); err != nil {
+		s.logger.Error("Failed to queue webhook deliveries",
+			zap.String("event_id", event.ID.String()),
+			zap.String("event_type", string(event.Type)),
+			zap.Error(err),
+		)
+	}
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
@@ -12,38 +13,46 @@ import (
 	"go.uber.org/zap"
 )
 
+// IngestQueueSize is the buffer of the channel that the simulator and the
+// NMEA server feed the WorkerPool through.
+const IngestQueueSize = 100
+
 // The vehicle ID to simulate data for.
 var simulatedVehicleID = uuid.MustParse("d9c1b442-fb2f-412a-9d2a-a3ab499cd91c")
 
-// StartDataSimulator simulates incoming sensor data every 2 seconds.
-func StartDataSimulator(ctx context.Context) <-chan domain.IngestRequest {
-	dataChannel := make(chan domain.IngestRequest, 10) // Buffered channel
-
+// StartDataSimulator simulates incoming sensor data every 2 seconds and sends
+// it to out.
+func StartDataSimulator(ctx context.Context, out chan<- domain.IngestRequest) {
 	utils.SafeGo(func() {
 		ticker := time.NewTicker(2 * time.Second)
 		defer ticker.Stop()
-		defer close(dataChannel)
 
 		for {
 			select {
 			case <-ticker.C:
+				heading, satellites, ignition := 90.0, 9, true
 				data := domain.IngestRequest{
 					VehicleID:   pgtype.UUID{Bytes: simulatedVehicleID, Valid: true},
 					PlateNumber: "KL01AB1234",
 					Status: domain.VehicleStatus{
-						Location:  []float64{55.296249, 25.276987}, // Example location
-						Speed:     60.5,
-						Timestamp: time.Now().UTC(),
+						Location:   []float64{55.296249, 25.276987}, // Example location
+						Speed:      60.5,
+						Timestamp:  time.Now().UTC(),
+						Heading:    &heading,
+						Satellites: &satellites,
+						Ignition:   &ignition,
 					},
 				}
-				dataChannel <- data
+				select {
+				case out <- data:
+				case <-ctx.Done():
+					return
+				}
 			case <-ctx.Done():
 				return
 			}
 		}
 	}, "DataSimulator")
-
-	return dataChannel
 }
 
 // WorkerPool processes ingested data from a channel.
@@ -83,6 +92,13 @@ func (wp *WorkerPool) worker(id int) {
 		)
 
 		err := wp.service.IngestData(context.Background(), data)
+		if errors.Is(err, domain.ErrDuplicateMessage) {
+			wp.logger.Info("Worker skipped duplicate message",
+				zap.Int("worker_id", id),
+				zap.String("message_id", data.MessageID),
+			)
+			continue
+		}
 		if err != nil {
 			wp.logger.Error("Worker failed to process data",
 				zap.Int("worker_id", id),
@@ -0,0 +1,330 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/db"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type AlertRepository struct {
+	q *db.Queries
+}
+
+// NewAlertRepository creates a new repository.
+func NewAlertRepository(dbtx db.DBTX) *AlertRepository {
+	return &AlertRepository{
+		q: db.New(dbtx),
+	}
+}
+
+func (r *AlertRepository) CreateAlertRule(ctx context.Context, rule domain.AlertRule) (*domain.AlertRule, error) {
+	schedule, err := json.Marshal(rule.Schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := r.q.CreateAlertRule(ctx, db.CreateAlertRuleParams{
+		Name:            rule.Name,
+		Kind:            string(rule.Kind),
+		VehicleID:       toNullableUUID(rule.VehicleID),
+		SpeedLimit:      rule.SpeedLimit,
+		DurationSeconds: int64(rule.DurationSeconds),
+		Schedule:        string(schedule),
+		Enabled:         rule.Enabled,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toDomainAlertRule(row)
+}
+
+// GetAlertRule returns domain.ErrNotFound if the rule does not exist.
+func (r *AlertRepository) GetAlertRule(ctx context.Context, id uuid.UUID) (*domain.AlertRule, error) {
+	row, err := r.q.GetAlertRule(ctx, pgtype.UUID{Bytes: id, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toDomainAlertRule(row)
+}
+
+func (r *AlertRepository) ListAlertRules(ctx context.Context) ([]domain.AlertRule, error) {
+	rows, err := r.q.ListAlertRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]domain.AlertRule, 0, len(rows))
+	for _, row := range rows {
+		rule, err := toDomainAlertRule(row)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *rule)
+	}
+	return rules, nil
+}
+
+// UpdateAlertRule returns domain.ErrNotFound if the rule does not exist.
+func (r *AlertRepository) UpdateAlertRule(ctx context.Context, rule domain.AlertRule) (*domain.AlertRule, error) {
+	schedule, err := json.Marshal(rule.Schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := r.q.UpdateAlertRule(ctx, db.UpdateAlertRuleParams{
+		ID:              pgtype.UUID{Bytes: rule.ID, Valid: true},
+		Name:            rule.Name,
+		Kind:            string(rule.Kind),
+		VehicleID:       toNullableUUID(rule.VehicleID),
+		SpeedLimit:      rule.SpeedLimit,
+		DurationSeconds: int64(rule.DurationSeconds),
+		Schedule:        string(schedule),
+		Enabled:         rule.Enabled,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toDomainAlertRule(row)
+}
+
+// DeleteAlertRule returns domain.ErrNotFound if the rule does not exist. Its
+// alerts are removed with it.
+func (r *AlertRepository) DeleteAlertRule(ctx context.Context, id uuid.UUID) error {
+	n, err := r.q.DeleteAlertRule(ctx, pgtype.UUID{Bytes: id, Valid: true})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *AlertRepository) OpenAlert(ctx context.Context, alert domain.Alert) (*domain.Alert, error) {
+	params := db.OpenAlertParams{
+		RuleID:    pgtype.UUID{Bytes: alert.RuleID, Valid: true},
+		VehicleID: pgtype.UUID{Bytes: alert.VehicleID, Valid: true},
+		Kind:      string(alert.Kind),
+		Message:   alert.Message,
+		Value:     alert.Value,
+		OpenedAt:  pgtype.Timestamptz{Time: alert.OpenedAt, Valid: true},
+	}
+	if len(alert.Location) == 2 {
+		params.Longitude = pgtype.Float8{Float64: alert.Location[0], Valid: true}
+		params.Latitude = pgtype.Float8{Float64: alert.Location[1], Valid: true}
+	}
+
+	row, err := r.q.OpenAlert(ctx, params)
+	// The insert is skipped when an unresolved alert already exists.
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	a := toDomainAlert(row)
+	return &a, nil
+}
+
+func (r *AlertRepository) ResolveOpenAlerts(ctx context.Context, ruleID, vehicleID uuid.UUID, at time.Time) ([]domain.Alert, error) {
+	rows, err := r.q.ResolveOpenAlerts(ctx, db.ResolveOpenAlertsParams{
+		RuleID:     pgtype.UUID{Bytes: ruleID, Valid: true},
+		VehicleID:  pgtype.UUID{Bytes: vehicleID, Valid: true},
+		ResolvedAt: pgtype.Timestamptz{Time: at, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toDomainAlerts(rows), nil
+}
+
+func (r *AlertRepository) ResolveRuleAlerts(ctx context.Context, ruleID uuid.UUID, at time.Time) ([]domain.Alert, error) {
+	rows, err := r.q.ResolveRuleAlerts(ctx, db.ResolveRuleAlertsParams{
+		RuleID:     pgtype.UUID{Bytes: ruleID, Valid: true},
+		ResolvedAt: pgtype.Timestamptz{Time: at, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toDomainAlerts(rows), nil
+}
+
+func (r *AlertRepository) ResolveVehicleAlerts(ctx context.Context, vehicleID uuid.UUID, at time.Time) ([]domain.Alert, error) {
+	rows, err := r.q.ResolveVehicleAlerts(ctx, db.ResolveVehicleAlertsParams{
+		VehicleID:  pgtype.UUID{Bytes: vehicleID, Valid: true},
+		ResolvedAt: pgtype.Timestamptz{Time: at, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toDomainAlerts(rows), nil
+}
+
+func (r *AlertRepository) OpenSilenceAlerts(ctx context.Context, rule domain.AlertRule, message string, silentSince, at time.Time) ([]domain.Alert, error) {
+	rows, err := r.q.OpenSilenceAlerts(ctx, db.OpenSilenceAlertsParams{
+		RuleID:      pgtype.UUID{Bytes: rule.ID, Valid: true},
+		Message:     message,
+		OpenedAt:    pgtype.Timestamptz{Time: at, Valid: true},
+		SilentSince: pgtype.Timestamptz{Time: silentSince, Valid: true},
+		VehicleID:   toNullableUUID(rule.VehicleID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toDomainAlerts(rows), nil
+}
+
+func (r *AlertRepository) ResolveSilenceAlerts(ctx context.Context, ruleID uuid.UUID, silentSince, at time.Time) ([]domain.Alert, error) {
+	rows, err := r.q.ResolveSilenceAlerts(ctx, db.ResolveSilenceAlertsParams{
+		ResolvedAt:  pgtype.Timestamptz{Time: at, Valid: true},
+		RuleID:      pgtype.UUID{Bytes: ruleID, Valid: true},
+		SilentSince: pgtype.Timestamptz{Time: silentSince, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toDomainAlerts(rows), nil
+}
+
+// GetAlert returns domain.ErrNotFound if the alert does not exist.
+func (r *AlertRepository) GetAlert(ctx context.Context, id uuid.UUID) (*domain.Alert, error) {
+	row, err := r.q.GetAlert(ctx, pgtype.UUID{Bytes: id, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	a := toDomainAlert(row)
+	return &a, nil
+}
+
+func (r *AlertRepository) ListAlerts(ctx context.Context, query domain.AlertQuery) ([]domain.Alert, error) {
+	params := db.ListAlertsParams{
+		VehicleID: toNullableUUID(query.VehicleID),
+		RuleID:    toNullableUUID(query.RuleID),
+		FromTime:  pgtype.Timestamptz{Time: query.From, Valid: true},
+		ToTime:    pgtype.Timestamptz{Time: query.To, Valid: true},
+		RowLimit:  query.Limit,
+		RowOffset: query.Offset,
+	}
+	if query.State != "" {
+		params.State = pgtype.Text{String: string(query.State), Valid: true}
+	}
+
+	rows, err := r.q.ListAlerts(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return toDomainAlerts(rows), nil
+}
+
+func (r *AlertRepository) AcknowledgeAlert(ctx context.Context, id uuid.UUID, at time.Time) (*domain.Alert, error) {
+	row, err := r.q.AcknowledgeAlert(ctx, db.AcknowledgeAlertParams{
+		ID:             pgtype.UUID{Bytes: id, Valid: true},
+		AcknowledgedAt: pgtype.Timestamptz{Time: at, Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	a := toDomainAlert(row)
+	return &a, nil
+}
+
+func (r *AlertRepository) ResolveAlert(ctx context.Context, id uuid.UUID, at time.Time) (*domain.Alert, error) {
+	row, err := r.q.ResolveAlert(ctx, db.ResolveAlertParams{
+		ID:         pgtype.UUID{Bytes: id, Valid: true},
+		ResolvedAt: pgtype.Timestamptz{Time: at, Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	a := toDomainAlert(row)
+	return &a, nil
+}
+
+func toNullableUUID(id *uuid.UUID) pgtype.UUID {
+	if id == nil {
+		return pgtype.UUID{}
+	}
+	return pgtype.UUID{Bytes: *id, Valid: true}
+}
+
+func fromNullableUUID(id pgtype.UUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	u := uuid.UUID(id.Bytes)
+	return &u
+}
+
+func fromNullableTime(t pgtype.Timestamptz) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func toDomainAlertRule(row db.AlertRule) (*domain.AlertRule, error) {
+	rule := &domain.AlertRule{
+		ID:              uuid.UUID(row.ID.Bytes),
+		Name:            row.Name,
+		Kind:            domain.AlertRuleKind(row.Kind),
+		VehicleID:       fromNullableUUID(row.VehicleID),
+		SpeedLimit:      row.SpeedLimit,
+		DurationSeconds: int(row.DurationSeconds),
+		Enabled:         row.Enabled,
+		CreatedAt:       row.CreatedAt.Time,
+		UpdatedAt:       row.UpdatedAt.Time,
+	}
+	if err := json.Unmarshal([]byte(row.Schedule), &rule.Schedule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func toDomainAlert(row db.Alert) domain.Alert {
+	alert := domain.Alert{
+		ID:             uuid.UUID(row.ID.Bytes),
+		RuleID:         uuid.UUID(row.RuleID.Bytes),
+		VehicleID:      uuid.UUID(row.VehicleID.Bytes),
+		Kind:           domain.AlertRuleKind(row.Kind),
+		State:          domain.AlertState(row.State),
+		Message:        row.Message,
+		Value:          row.Value,
+		OpenedAt:       row.OpenedAt.Time,
+		AcknowledgedAt: fromNullableTime(row.AcknowledgedAt),
+		ResolvedAt:     fromNullableTime(row.ResolvedAt),
+	}
+	if row.Longitude.Valid && row.Latitude.Valid {
+		alert.Location = []float64{row.Longitude.Float64, row.Latitude.Float64}
+	}
+	return alert
+}
+
+func toDomainAlerts(rows []db.Alert) []domain.Alert {
+	alerts := make([]domain.Alert, 0, len(rows))
+	for _, row := range rows {
+		alerts = append(alerts, toDomainAlert(row))
+	}
+	return alerts
+}
@@ -0,0 +1,154 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/db"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// pgUniqueViolation is the SQLSTATE of a unique constraint violation.
+const pgUniqueViolation = "23505"
+
+type DeviceRepository struct {
+	q *db.Queries
+}
+
+// NewDeviceRepository creates a new repository.
+func NewDeviceRepository(dbtx db.DBTX) *DeviceRepository {
+	return &DeviceRepository{
+		q: db.New(dbtx),
+	}
+}
+
+// CreateDevice returns domain.ErrDeviceExists if the identifier is taken.
+func (r *DeviceRepository) CreateDevice(ctx context.Context, device domain.Device) (*domain.Device, error) {
+	row, err := r.q.CreateDevice(ctx, db.CreateDeviceParams{
+		Identifier:  device.Identifier,
+		VehicleID:   pgtype.UUID{Bytes: device.VehicleID, Valid: true},
+		PlateNumber: device.PlateNumber,
+		Name:        device.Name,
+		Model:       device.Model,
+		TokenHash:   device.TokenHash,
+	})
+	if isUniqueViolation(err) {
+		return nil, domain.ErrDeviceExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toDomainDevice(row), nil
+}
+
+// GetDevice returns domain.ErrNotFound if the device does not exist.
+func (r *DeviceRepository) GetDevice(ctx context.Context, id uuid.UUID) (*domain.Device, error) {
+	row, err := r.q.GetDevice(ctx, pgtype.UUID{Bytes: id, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toDomainDevice(row), nil
+}
+
+// GetDeviceByIdentifier returns domain.ErrNotFound if no device has the
+// identifier.
+func (r *DeviceRepository) GetDeviceByIdentifier(ctx context.Context, identifier string) (*domain.Device, error) {
+	row, err := r.q.GetDeviceByIdentifier(ctx, identifier)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toDomainDevice(row), nil
+}
+
+func (r *DeviceRepository) ListDevices(ctx context.Context) ([]domain.Device, error) {
+	rows, err := r.q.ListDevices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	devices := make([]domain.Device, 0, len(rows))
+	for _, row := range rows {
+		devices = append(devices, *toDomainDevice(row))
+	}
+	return devices, nil
+}
+
+// UpdateDevice returns domain.ErrNotFound if the device does not exist and
+// domain.ErrDeviceExists if the identifier is taken by another device.
+func (r *DeviceRepository) UpdateDevice(ctx context.Context, device domain.Device) (*domain.Device, error) {
+	row, err := r.q.UpdateDevice(ctx, db.UpdateDeviceParams{
+		Identifier:  device.Identifier,
+		VehicleID:   pgtype.UUID{Bytes: device.VehicleID, Valid: true},
+		PlateNumber: device.PlateNumber,
+		Name:        device.Name,
+		Model:       device.Model,
+		ID:          pgtype.UUID{Bytes: device.ID, Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if isUniqueViolation(err) {
+		return nil, domain.ErrDeviceExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toDomainDevice(row), nil
+}
+
+// SetDeviceTokenHash replaces the token hash of the device. It returns
+// domain.ErrNotFound if the device does not exist.
+func (r *DeviceRepository) SetDeviceTokenHash(ctx context.Context, id uuid.UUID, tokenHash []byte) (*domain.Device, error) {
+	row, err := r.q.SetDeviceTokenHash(ctx, db.SetDeviceTokenHashParams{
+		TokenHash: tokenHash,
+		ID:        pgtype.UUID{Bytes: id, Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toDomainDevice(row), nil
+}
+
+// DeleteDevice returns domain.ErrNotFound if the device does not exist.
+func (r *DeviceRepository) DeleteDevice(ctx context.Context, id uuid.UUID) error {
+	n, err := r.q.DeleteDevice(ctx, pgtype.UUID{Bytes: id, Valid: true})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation
+}
+
+func toDomainDevice(row db.Device) *domain.Device {
+	return &domain.Device{
+		ID:          row.ID.Bytes,
+		Identifier:  row.Identifier,
+		VehicleID:   row.VehicleID.Bytes,
+		PlateNumber: row.PlateNumber,
+		Name:        row.Name,
+		Model:       row.Model,
+		CreatedAt:   row.CreatedAt.Time,
+		UpdatedAt:   row.UpdatedAt.Time,
+		TokenHash:   row.TokenHash,
+	}
+}
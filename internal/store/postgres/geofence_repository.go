@@ -0,0 +1,265 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/db"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type GeofenceRepository struct {
+	q *db.Queries
+}
+
+// NewGeofenceRepository creates a new repository.
+func NewGeofenceRepository(dbtx db.DBTX) *GeofenceRepository {
+	return &GeofenceRepository{
+		q: db.New(dbtx),
+	}
+}
+
+func (r *GeofenceRepository) CreateGeofence(ctx context.Context, fence domain.Geofence) (*domain.Geofence, error) {
+	shape, err := toGeofenceShape(fence)
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := r.q.CreateGeofence(ctx, db.CreateGeofenceParams{
+		Name:         fence.Name,
+		Kind:         string(fence.Kind),
+		CenterLon:    shape.centerLon,
+		CenterLat:    shape.centerLat,
+		Radius:       shape.radius,
+		Polygon:      shape.polygon,
+		DwellSeconds: int64(fence.DwellSeconds),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toDomainGeofence(row)
+}
+
+// GetGeofence returns domain.ErrNotFound if the geofence does not exist.
+func (r *GeofenceRepository) GetGeofence(ctx context.Context, id uuid.UUID) (*domain.Geofence, error) {
+	row, err := r.q.GetGeofence(ctx, pgtype.UUID{Bytes: id, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toDomainGeofence(row)
+}
+
+func (r *GeofenceRepository) ListGeofences(ctx context.Context) ([]domain.Geofence, error) {
+	rows, err := r.q.ListGeofences(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fences := make([]domain.Geofence, 0, len(rows))
+	for _, row := range rows {
+		fence, err := toDomainGeofence(row)
+		if err != nil {
+			return nil, err
+		}
+		fences = append(fences, *fence)
+	}
+	return fences, nil
+}
+
+// UpdateGeofence returns domain.ErrNotFound if the geofence does not exist.
+func (r *GeofenceRepository) UpdateGeofence(ctx context.Context, fence domain.Geofence) (*domain.Geofence, error) {
+	shape, err := toGeofenceShape(fence)
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := r.q.UpdateGeofence(ctx, db.UpdateGeofenceParams{
+		ID:           pgtype.UUID{Bytes: fence.ID, Valid: true},
+		Name:         fence.Name,
+		Kind:         string(fence.Kind),
+		CenterLon:    shape.centerLon,
+		CenterLat:    shape.centerLat,
+		Radius:       shape.radius,
+		Polygon:      shape.polygon,
+		DwellSeconds: int64(fence.DwellSeconds),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toDomainGeofence(row)
+}
+
+// DeleteGeofence returns domain.ErrNotFound if the geofence does not exist.
+// Its presence rows and events are removed with it.
+func (r *GeofenceRepository) DeleteGeofence(ctx context.Context, id uuid.UUID) error {
+	n, err := r.q.DeleteGeofence(ctx, pgtype.UUID{Bytes: id, Valid: true})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *GeofenceRepository) ListPresence(ctx context.Context, vehicleID uuid.UUID) ([]domain.GeofencePresence, error) {
+	rows, err := r.q.ListGeofencePresence(ctx, pgtype.UUID{Bytes: vehicleID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	presence := make([]domain.GeofencePresence, 0, len(rows))
+	for _, row := range rows {
+		presence = append(presence, domain.GeofencePresence{
+			VehicleID:     uuid.UUID(row.VehicleID.Bytes),
+			GeofenceID:    uuid.UUID(row.GeofenceID.Bytes),
+			EnteredAt:     row.EnteredAt.Time,
+			DwellReported: row.DwellReported,
+		})
+	}
+	return presence, nil
+}
+
+func (r *GeofenceRepository) EnterGeofence(ctx context.Context, presence domain.GeofencePresence) (bool, error) {
+	n, err := r.q.InsertGeofencePresence(ctx, db.InsertGeofencePresenceParams{
+		VehicleID:  pgtype.UUID{Bytes: presence.VehicleID, Valid: true},
+		GeofenceID: pgtype.UUID{Bytes: presence.GeofenceID, Valid: true},
+		EnteredAt:  pgtype.Timestamptz{Time: presence.EnteredAt, Valid: true},
+	})
+	return n > 0, err
+}
+
+func (r *GeofenceRepository) MarkDwellReported(ctx context.Context, vehicleID, geofenceID uuid.UUID) (bool, error) {
+	n, err := r.q.MarkGeofenceDwellReported(ctx, db.MarkGeofenceDwellReportedParams{
+		VehicleID:  pgtype.UUID{Bytes: vehicleID, Valid: true},
+		GeofenceID: pgtype.UUID{Bytes: geofenceID, Valid: true},
+	})
+	return n > 0, err
+}
+
+func (r *GeofenceRepository) ExitGeofence(ctx context.Context, vehicleID, geofenceID uuid.UUID) (bool, error) {
+	n, err := r.q.DeleteGeofencePresence(ctx, db.DeleteGeofencePresenceParams{
+		VehicleID:  pgtype.UUID{Bytes: vehicleID, Valid: true},
+		GeofenceID: pgtype.UUID{Bytes: geofenceID, Valid: true},
+	})
+	return n > 0, err
+}
+
+func (r *GeofenceRepository) InsertGeofenceEvent(ctx context.Context, event domain.GeofenceEvent) (*domain.GeofenceEvent, error) {
+	params := db.InsertGeofenceEventParams{
+		GeofenceID: pgtype.UUID{Bytes: event.GeofenceID, Valid: true},
+		VehicleID:  pgtype.UUID{Bytes: event.VehicleID, Valid: true},
+		Kind:       string(event.Kind),
+		OccurredAt: pgtype.Timestamptz{Time: event.OccurredAt, Valid: true},
+	}
+	if len(event.Location) == 2 {
+		params.Longitude = pgtype.Float8{Float64: event.Location[0], Valid: true}
+		params.Latitude = pgtype.Float8{Float64: event.Location[1], Valid: true}
+	}
+
+	row, err := r.q.InsertGeofenceEvent(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	e := toDomainGeofenceEvent(row)
+	return &e, nil
+}
+
+func (r *GeofenceRepository) ListGeofenceEvents(ctx context.Context, query domain.GeofenceEventQuery) ([]domain.GeofenceEvent, error) {
+	params := db.ListGeofenceEventsParams{
+		FromTime:  pgtype.Timestamptz{Time: query.From, Valid: true},
+		ToTime:    pgtype.Timestamptz{Time: query.To, Valid: true},
+		RowLimit:  query.Limit,
+		RowOffset: query.Offset,
+	}
+	if query.VehicleID != nil {
+		params.VehicleID = pgtype.UUID{Bytes: *query.VehicleID, Valid: true}
+	}
+	if query.GeofenceID != nil {
+		params.GeofenceID = pgtype.UUID{Bytes: *query.GeofenceID, Valid: true}
+	}
+
+	rows, err := r.q.ListGeofenceEvents(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]domain.GeofenceEvent, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, toDomainGeofenceEvent(row))
+	}
+	return events, nil
+}
+
+// geofenceShape holds the shape columns of a geofence row.
+type geofenceShape struct {
+	centerLon pgtype.Float8
+	centerLat pgtype.Float8
+	radius    pgtype.Float8
+	polygon   string
+}
+
+func toGeofenceShape(fence domain.Geofence) (geofenceShape, error) {
+	shape := geofenceShape{polygon: "[]"}
+	if len(fence.Center) == 2 {
+		shape.centerLon = pgtype.Float8{Float64: fence.Center[0], Valid: true}
+		shape.centerLat = pgtype.Float8{Float64: fence.Center[1], Valid: true}
+	}
+	if fence.Kind == domain.GeofenceCircle {
+		shape.radius = pgtype.Float8{Float64: fence.Radius, Valid: true}
+	}
+	if len(fence.Polygon) > 0 {
+		polygonJSON, err := json.Marshal(fence.Polygon)
+		if err != nil {
+			return geofenceShape{}, err
+		}
+		shape.polygon = string(polygonJSON)
+	}
+	return shape, nil
+}
+
+func toDomainGeofence(row db.Geofence) (*domain.Geofence, error) {
+	fence := &domain.Geofence{
+		ID:           uuid.UUID(row.ID.Bytes),
+		Name:         row.Name,
+		Kind:         domain.GeofenceKind(row.Kind),
+		Radius:       row.Radius.Float64,
+		DwellSeconds: int(row.DwellSeconds),
+		CreatedAt:    row.CreatedAt.Time,
+		UpdatedAt:    row.UpdatedAt.Time,
+	}
+	if row.CenterLon.Valid && row.CenterLat.Valid {
+		fence.Center = []float64{row.CenterLon.Float64, row.CenterLat.Float64}
+	}
+	if err := json.Unmarshal([]byte(row.Polygon), &fence.Polygon); err != nil {
+		return nil, err
+	}
+	if len(fence.Polygon) == 0 {
+		fence.Polygon = nil
+	}
+	return fence, nil
+}
+
+func toDomainGeofenceEvent(row db.GeofenceEvent) domain.GeofenceEvent {
+	event := domain.GeofenceEvent{
+		ID:         row.ID,
+		GeofenceID: uuid.UUID(row.GeofenceID.Bytes),
+		VehicleID:  uuid.UUID(row.VehicleID.Bytes),
+		Kind:       domain.GeofenceEventKind(row.Kind),
+		OccurredAt: row.OccurredAt.Time,
+	}
+	if row.Longitude.Valid && row.Latitude.Valid {
+		event.Location = []float64{row.Longitude.Float64, row.Latitude.Float64}
+	}
+	return event
+}
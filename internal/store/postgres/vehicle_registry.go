@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/db"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// CreateVehicle returns domain.ErrVehicleExists if the ID or, among active
+// vehicles, the plate number is taken.
+func (r *VehicleRepository) CreateVehicle(ctx context.Context, vehicle domain.Vehicle) (*domain.Vehicle, error) {
+	row, err := r.q.CreateVehicle(ctx, db.CreateVehicleParams{
+		ID:          pgtype.UUID{Bytes: vehicle.ID, Valid: true},
+		PlateNumber: vehicle.PlateNumber,
+		Name:        vehicle.Name,
+	})
+	if isUniqueViolation(err) {
+		return nil, domain.ErrVehicleExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toDomainVehicle(row)
+}
+
+// GetVehicle returns domain.ErrNotFound if the vehicle does not exist.
+func (r *VehicleRepository) GetVehicle(ctx context.Context, id uuid.UUID) (*domain.Vehicle, error) {
+	row, err := r.q.GetVehicle(ctx, pgtype.UUID{Bytes: id, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toDomainVehicle(row)
+}
+
+func (r *VehicleRepository) IsVehicleActive(ctx context.Context, id uuid.UUID) (bool, error) {
+	return r.q.IsVehicleActive(ctx, pgtype.UUID{Bytes: id, Valid: true})
+}
+
+// GetVehicleByPlate returns domain.ErrNotFound if no vehicle has the plate
+// number.
+func (r *VehicleRepository) GetVehicleByPlate(ctx context.Context, plateNumber string) (*domain.Vehicle, error) {
+	row, err := r.q.GetVehicleByPlate(ctx, plateNumber)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toDomainVehicle(row)
+}
+
+func (r *VehicleRepository) ListVehicles(ctx context.Context, query domain.VehicleQuery) ([]domain.Vehicle, error) {
+	rows, err := r.q.ListVehicles(ctx, db.ListVehiclesParams{
+		IncludeArchived: query.IncludeArchived,
+		Limit:           query.Limit,
+		Offset:          query.Offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	vehicles := make([]domain.Vehicle, 0, len(rows))
+	for _, row := range rows {
+		vehicle, err := toDomainVehicle(row)
+		if err != nil {
+			return nil, err
+		}
+		vehicles = append(vehicles, *vehicle)
+	}
+	return vehicles, nil
+}
+
+// UpdateVehicle returns domain.ErrNotFound if the vehicle does not exist and
+// domain.ErrVehicleExists if another active vehicle has the plate number.
+func (r *VehicleRepository) UpdateVehicle(ctx context.Context, vehicle domain.Vehicle) (*domain.Vehicle, error) {
+	row, err := r.q.UpdateVehicle(ctx, db.UpdateVehicleParams{
+		PlateNumber: vehicle.PlateNumber,
+		Name:        vehicle.Name,
+		ID:          pgtype.UUID{Bytes: vehicle.ID, Valid: true},
+	})
+	if isUniqueViolation(err) {
+		return nil, domain.ErrVehicleExists
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toDomainVehicle(row)
+}
+
+// ArchiveVehicle returns domain.ErrNotFound if the vehicle does not exist.
+func (r *VehicleRepository) ArchiveVehicle(ctx context.Context, id uuid.UUID) (*domain.Vehicle, error) {
+	row, err := r.q.ArchiveVehicle(ctx, pgtype.UUID{Bytes: id, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toDomainVehicle(row)
+}
+
+// toDomainVehicle converts a registry row. Vehicles registered ahead of their
+// first reading have no last status.
+func toDomainVehicle(row db.Vehicle) (*domain.Vehicle, error) {
+	vehicle := &domain.Vehicle{
+		ID:          row.ID.Bytes,
+		PlateNumber: row.PlateNumber,
+		Name:        row.Name,
+		CreatedAt:   row.CreatedAt.Time,
+		UpdatedAt:   row.UpdatedAt.Time,
+	}
+	if row.LastStatusAt.Valid {
+		vehicle.LastStatus = &domain.VehicleStatus{}
+		if err := json.Unmarshal([]byte(row.LastStatus), vehicle.LastStatus); err != nil {
+			return nil, err
+		}
+	}
+	if row.ArchivedAt.Valid {
+		vehicle.ArchivedAt = &row.ArchivedAt.Time
+	}
+	return vehicle, nil
+}
@@ -3,11 +3,13 @@ package postgres
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/AnjuRKrishnan/fleet-tracker/internal/db"
 	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
@@ -22,62 +24,245 @@ func NewVehicleRepository(dbtx db.DBTX) *VehicleRepository {
 	}
 }
 
-// UpdateVehicleStatus calls the generated method.
-func (r *VehicleRepository) UpdateVehicleStatus(ctx context.Context, vehicleID uuid.UUID, plateNumber string, status domain.VehicleStatus) error {
+// UpdateVehicleStatus upserts the vehicle's last status. It reports false when
+// a newer status was already stored, leaving the row untouched.
+func (r *VehicleRepository) UpdateVehicleStatus(ctx context.Context, vehicleID uuid.UUID, plateNumber string, status domain.VehicleStatus) (bool, error) {
 	statusJSON, err := json.Marshal(status)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	// 5. Use the generated parameter struct from the 'db' package
 	params := db.UpsertVehicleStatusParams{
-		Column3:     string(statusJSON),
-		PlateNumber: plateNumber,
-		ID:          pgtype.UUID{Bytes: vehicleID, Valid: true},
+		Column3:      string(statusJSON),
+		PlateNumber:  plateNumber,
+		ID:           pgtype.UUID{Bytes: vehicleID, Valid: true},
+		LastStatusAt: pgtype.Timestamptz{Time: status.Timestamp, Valid: true},
 	}
 
-	return r.q.UpsertVehicleStatus(ctx, params)
+	rows, err := r.q.UpsertVehicleStatus(ctx, params)
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
 }
 
-func (r *VehicleRepository) FindTripsByVehicleID(ctx context.Context, vehicleID uuid.UUID, since time.Time) ([]domain.Trip, error) {
-	// Use the generated ListTripsByVehicleID method
-	dbTrips, err := r.q.ListTripsByVehicle(ctx, db.ListTripsByVehicleParams{
-		VehicleID: pgtype.UUID{Bytes: vehicleID, Valid: true},
-		StartTime: pgtype.Timestamptz{Time: since, Valid: true},
-	})
+// ListTrips retrieves a page of a vehicle's trips in the order of the query.
+func (r *VehicleRepository) ListTrips(ctx context.Context, query domain.TripQuery) ([]domain.Trip, error) {
+	params := db.ListTripsByVehicleParams{
+		VehicleID:  pgtype.UUID{Bytes: query.VehicleID, Valid: true},
+		From:       pgtype.Timestamptz{Time: query.From, Valid: true},
+		To:         pgtype.Timestamptz{Time: query.To, Valid: true},
+		MinMileage: query.MinMileage,
+		Limit:      query.Limit,
+	}
+	if query.After != nil {
+		params.AfterStartTime = pgtype.Timestamptz{Time: query.After.StartTime, Valid: true}
+		params.AfterID = pgtype.UUID{Bytes: query.After.ID, Valid: true}
+	}
+
+	var dbTrips []db.Trip
+	var err error
+	if query.Order == domain.SortAscending {
+		dbTrips, err = r.q.ListTripsByVehicleAsc(ctx, db.ListTripsByVehicleAscParams(params))
+	} else {
+		dbTrips, err = r.q.ListTripsByVehicle(ctx, params)
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	// Map the database models to our domain models
-	var domainTrips []domain.Trip
-	for _, dt := range dbTrips {
-		var endTime *time.Time
-		if dt.EndTime.Valid {
-			endTime = &dt.EndTime.Time
-		}
-		domainTrips = append(domainTrips, domain.Trip{
-			ID:        dt.ID,
-			VehicleID: dt.VehicleID,
-			StartTime: dt.StartTime,
-			EndTime:   endTime,
-			Mileage:   dt.Mileage.Float64,
-			AvgSpeed:  dt.AvgSpeed.Float64,
-		})
+	domainTrips := make([]domain.Trip, len(dbTrips))
+	for i, dt := range dbTrips {
+		domainTrips[i] = toDomainTrip(dt)
 	}
-
 	return domainTrips, nil
 }
 
+// GetTrip returns domain.ErrNotFound for an unknown trip.
+func (r *VehicleRepository) GetTrip(ctx context.Context, id uuid.UUID) (*domain.Trip, error) {
+	dt, err := r.q.GetTripByID(ctx, pgtype.UUID{Bytes: id, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	trip := toDomainTrip(dt)
+	return &trip, nil
+}
+
+func toDomainTrip(dt db.Trip) domain.Trip {
+	var endTime *time.Time
+	if dt.EndTime.Valid {
+		endTime = &dt.EndTime.Time
+	}
+	return domain.Trip{
+		ID:        dt.ID,
+		VehicleID: dt.VehicleID,
+		StartTime: dt.StartTime,
+		EndTime:   endTime,
+		Mileage:   dt.Mileage.Float64,
+		AvgSpeed:  dt.AvgSpeed.Float64,
+	}
+}
+
+// GetVehicleStatus returns the vehicle's last status, or nil when it has not
+// reported one yet. It returns domain.ErrNotFound for an unknown vehicle.
 func (r *VehicleRepository) GetVehicleStatus(ctx context.Context, vehicleID uuid.UUID) (*domain.VehicleStatus, error) {
 	row, err := r.q.GetVehicleStatus(ctx, pgtype.UUID{Bytes: vehicleID, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
+	if !row.LastStatusAt.Valid {
+		return nil, nil
+	}
 	var status domain.VehicleStatus
-	if err := json.Unmarshal([]byte(row), &status); err != nil {
+	if err := json.Unmarshal([]byte(row.LastStatus), &status); err != nil {
 		return nil, err
 	}
 
 	return &status, nil
 }
+
+// CreateTrip inserts a newly opened trip. The end time, mileage and average
+// speed are filled in later by CloseTrip.
+func (r *VehicleRepository) CreateTrip(ctx context.Context, trip domain.Trip) error {
+	return r.q.InsertTrip(ctx, db.InsertTripParams{
+		ID:        trip.ID,
+		VehicleID: trip.VehicleID,
+		StartTime: trip.StartTime,
+		EndTime:   toTimestamptz(trip.EndTime),
+		Mileage:   pgtype.Float8{Float64: trip.Mileage, Valid: true},
+		AvgSpeed:  pgtype.Float8{Float64: trip.AvgSpeed, Valid: true},
+	})
+}
+
+// ListOpenTrips returns the trips that have no end time yet, oldest first.
+func (r *VehicleRepository) ListOpenTrips(ctx context.Context) ([]domain.Trip, error) {
+	dbTrips, err := r.q.ListOpenTrips(ctx)
+	if err != nil {
+		return nil, err
+	}
+	trips := make([]domain.Trip, len(dbTrips))
+	for i, dt := range dbTrips {
+		trips[i] = toDomainTrip(dt)
+	}
+	return trips, nil
+}
+
+// CloseTrip records the end time and totals of a finished trip.
+func (r *VehicleRepository) CloseTrip(ctx context.Context, trip domain.Trip) error {
+	return r.q.CloseTrip(ctx, db.CloseTripParams{
+		ID:       trip.ID,
+		EndTime:  toTimestamptz(trip.EndTime),
+		Mileage:  pgtype.Float8{Float64: trip.Mileage, Valid: true},
+		AvgSpeed: pgtype.Float8{Float64: trip.AvgSpeed, Valid: true},
+	})
+}
+
+func toTimestamptz(t *time.Time) pgtype.Timestamptz {
+	if t == nil {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: *t, Valid: true}
+}
+
+// InsertPosition appends a status reading to the vehicle's position history.
+func (r *VehicleRepository) InsertPosition(ctx context.Context, vehicleID uuid.UUID, status domain.VehicleStatus) error {
+	statusJSON, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+
+	params := db.InsertPositionParams{
+		VehicleID:  pgtype.UUID{Bytes: vehicleID, Valid: true},
+		RecordedAt: pgtype.Timestamptz{Time: status.Timestamp, Valid: true},
+		Speed:      status.Speed,
+		Column6:    string(statusJSON),
+	}
+	if lon, lat, ok := status.Coordinates(); ok {
+		params.Longitude = pgtype.Float8{Float64: lon, Valid: true}
+		params.Latitude = pgtype.Float8{Float64: lat, Valid: true}
+	}
+
+	return r.q.InsertPosition(ctx, params)
+}
+
+// InsertPositions appends a batch of readings to the position history in a
+// single statement.
+func (r *VehicleRepository) InsertPositions(ctx context.Context, positions []domain.Position) error {
+	params := db.InsertPositionsParams{
+		VehicleIds: make([]pgtype.UUID, 0, len(positions)),
+		Statuses:   make([]string, 0, len(positions)),
+	}
+	for _, p := range positions {
+		statusJSON, err := json.Marshal(p.VehicleStatus)
+		if err != nil {
+			return err
+		}
+		params.VehicleIds = append(params.VehicleIds, pgtype.UUID{Bytes: p.VehicleID, Valid: true})
+		params.Statuses = append(params.Statuses, string(statusJSON))
+	}
+
+	return r.q.InsertPositions(ctx, params)
+}
+
+// ListPositions returns a page of a vehicle's position history.
+func (r *VehicleRepository) ListPositions(ctx context.Context, query domain.PositionQuery) ([]domain.Position, error) {
+	var rows []db.VehiclePosition
+	var err error
+	if query.After != nil {
+		rows, err = r.q.ListPositionsByVehicleAfter(ctx, db.ListPositionsByVehicleAfterParams{
+			VehicleID:       pgtype.UUID{Bytes: query.VehicleID, Valid: true},
+			AfterRecordedAt: pgtype.Timestamptz{Time: query.After.RecordedAt, Valid: true},
+			To:              pgtype.Timestamptz{Time: query.To, Valid: true},
+			AfterID:         query.After.ID,
+			Limit:           query.Limit,
+		})
+	} else {
+		rows, err = r.q.ListPositionsByVehicle(ctx, db.ListPositionsByVehicleParams{
+			VehicleID:    pgtype.UUID{Bytes: query.VehicleID, Valid: true},
+			RecordedAt:   pgtype.Timestamptz{Time: query.From, Valid: true},
+			RecordedAt_2: pgtype.Timestamptz{Time: query.To, Valid: true},
+			Limit:        query.Limit,
+			Offset:       query.Offset,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make([]domain.Position, 0, len(rows))
+	for _, row := range rows {
+		position, err := toDomainPosition(row)
+		if err != nil {
+			return nil, err
+		}
+		positions = append(positions, position)
+	}
+	return positions, nil
+}
+
+func (r *VehicleRepository) CountPositions(ctx context.Context, query domain.PositionQuery) (int64, error) {
+	return r.q.CountPositionsByVehicle(ctx, db.CountPositionsByVehicleParams{
+		VehicleID:    pgtype.UUID{Bytes: query.VehicleID, Valid: true},
+		RecordedAt:   pgtype.Timestamptz{Time: query.From, Valid: true},
+		RecordedAt_2: pgtype.Timestamptz{Time: query.To, Valid: true},
+	})
+}
+
+func toDomainPosition(row db.VehiclePosition) (domain.Position, error) {
+	position := domain.Position{
+		ID:        row.ID,
+		VehicleID: uuid.UUID(row.VehicleID.Bytes),
+	}
+	if err := json.Unmarshal([]byte(row.Status), &position.VehicleStatus); err != nil {
+		return domain.Position{}, err
+	}
+	return position, nil
+}
@@ -0,0 +1,263 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/db"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type WebhookRepository struct {
+	q *db.Queries
+}
+
+// NewWebhookRepository creates a new repository.
+func NewWebhookRepository(dbtx db.DBTX) *WebhookRepository {
+	return &WebhookRepository{
+		q: db.New(dbtx),
+	}
+}
+
+func (r *WebhookRepository) CreateWebhook(ctx context.Context, sub domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	row, err := r.q.CreateWebhook(ctx, db.CreateWebhookParams{
+		Url:        sub.URL,
+		Secret:     sub.Secret,
+		EventTypes: fromEventTypes(sub.EventTypes),
+		Enabled:    sub.Enabled,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toDomainWebhook(row), nil
+}
+
+// GetWebhook returns domain.ErrNotFound if the subscription does not exist.
+func (r *WebhookRepository) GetWebhook(ctx context.Context, id uuid.UUID) (*domain.WebhookSubscription, error) {
+	row, err := r.q.GetWebhook(ctx, pgtype.UUID{Bytes: id, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toDomainWebhook(row), nil
+}
+
+func (r *WebhookRepository) ListWebhooks(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	rows, err := r.q.ListWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	subs := make([]domain.WebhookSubscription, 0, len(rows))
+	for _, row := range rows {
+		subs = append(subs, *toDomainWebhook(row))
+	}
+	return subs, nil
+}
+
+// UpdateWebhook returns domain.ErrNotFound if the subscription does not
+// exist. Re-enabling a subscription clears its failure state.
+func (r *WebhookRepository) UpdateWebhook(ctx context.Context, sub domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	row, err := r.q.UpdateWebhook(ctx, db.UpdateWebhookParams{
+		Url:        sub.URL,
+		Secret:     sub.Secret,
+		EventTypes: fromEventTypes(sub.EventTypes),
+		Enabled:    sub.Enabled,
+		ID:         pgtype.UUID{Bytes: sub.ID, Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toDomainWebhook(row), nil
+}
+
+// DeleteWebhook removes the subscription together with its deliveries. It
+// returns domain.ErrNotFound if the subscription does not exist.
+func (r *WebhookRepository) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	n, err := r.q.DeleteWebhook(ctx, pgtype.UUID{Bytes: id, Valid: true})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *WebhookRepository) EnqueueDeliveries(ctx context.Context, subscriptionIDs []uuid.UUID, event domain.Event, payload []byte) error {
+	ids := make([]pgtype.UUID, len(subscriptionIDs))
+	for i, id := range subscriptionIDs {
+		ids[i] = pgtype.UUID{Bytes: id, Valid: true}
+	}
+	return r.q.EnqueueWebhookDeliveries(ctx, db.EnqueueWebhookDeliveriesParams{
+		SubscriptionIds: ids,
+		EventID:         pgtype.UUID{Bytes: event.ID, Valid: true},
+		EventType:       string(event.Type),
+		Payload:         string(payload),
+		NextAttemptAt:   pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	})
+}
+
+func (r *WebhookRepository) ClaimDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int32) ([]domain.PendingDelivery, error) {
+	rows, err := r.q.ClaimWebhookDeliveries(ctx, db.ClaimWebhookDeliveriesParams{
+		Now:        pgtype.Timestamptz{Time: now, Valid: true},
+		RowLimit:   limit,
+		LeaseUntil: pgtype.Timestamptz{Time: leaseUntil, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]domain.PendingDelivery, 0, len(rows))
+	for _, row := range rows {
+		deliveries = append(deliveries, domain.PendingDelivery{
+			WebhookDelivery: toDomainDelivery(db.WebhookDelivery{
+				ID:             row.ID,
+				SubscriptionID: row.SubscriptionID,
+				EventID:        row.EventID,
+				EventType:      row.EventType,
+				Payload:        row.Payload,
+				State:          row.State,
+				Attempts:       row.Attempts,
+				NextAttemptAt:  row.NextAttemptAt,
+				ResponseStatus: row.ResponseStatus,
+				LastError:      row.LastError,
+				CreatedAt:      row.CreatedAt,
+				CompletedAt:    row.CompletedAt,
+			}),
+			URL:    row.Url,
+			Secret: row.Secret,
+		})
+	}
+	return deliveries, nil
+}
+
+func (r *WebhookRepository) RetryDelivery(ctx context.Context, id uuid.UUID, attempt domain.WebhookAttempt, nextAttemptAt time.Time) error {
+	return r.q.RetryWebhookDelivery(ctx, db.RetryWebhookDeliveryParams{
+		ResponseStatus: toNullableInt(attempt.ResponseStatus),
+		LastError:      attempt.Error,
+		NextAttemptAt:  pgtype.Timestamptz{Time: nextAttemptAt, Valid: true},
+		ID:             pgtype.UUID{Bytes: id, Valid: true},
+	})
+}
+
+func (r *WebhookRepository) CompleteDelivery(ctx context.Context, id uuid.UUID, state domain.WebhookDeliveryState, attempt domain.WebhookAttempt) error {
+	return r.q.CompleteWebhookDelivery(ctx, db.CompleteWebhookDeliveryParams{
+		State:          string(state),
+		ResponseStatus: toNullableInt(attempt.ResponseStatus),
+		LastError:      attempt.Error,
+		CompletedAt:    pgtype.Timestamptz{Time: attempt.At, Valid: true},
+		ID:             pgtype.UUID{Bytes: id, Valid: true},
+	})
+}
+
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, query domain.WebhookDeliveryQuery) ([]domain.WebhookDelivery, error) {
+	var state pgtype.Text
+	if query.State != "" {
+		state = pgtype.Text{String: string(query.State), Valid: true}
+	}
+
+	rows, err := r.q.ListWebhookDeliveries(ctx, db.ListWebhookDeliveriesParams{
+		SubscriptionID: pgtype.UUID{Bytes: query.SubscriptionID, Valid: true},
+		State:          state,
+		FromTime:       pgtype.Timestamptz{Time: query.From, Valid: true},
+		ToTime:         pgtype.Timestamptz{Time: query.To, Valid: true},
+		RowLimit:       query.Limit,
+		RowOffset:      query.Offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]domain.WebhookDelivery, 0, len(rows))
+	for _, row := range rows {
+		deliveries = append(deliveries, toDomainDelivery(row))
+	}
+	return deliveries, nil
+}
+
+func (r *WebhookRepository) DeleteDeliveriesBefore(ctx context.Context, t time.Time) (int64, error) {
+	return r.q.DeleteWebhookDeliveriesBefore(ctx, pgtype.Timestamptz{Time: t, Valid: true})
+}
+
+func (r *WebhookRepository) RecordWebhookSuccess(ctx context.Context, id uuid.UUID) error {
+	return r.q.RecordWebhookSuccess(ctx, pgtype.UUID{Bytes: id, Valid: true})
+}
+
+func (r *WebhookRepository) RecordWebhookFailure(ctx context.Context, id uuid.UUID, at, disableBefore time.Time) (*domain.WebhookSubscription, error) {
+	row, err := r.q.RecordWebhookFailure(ctx, db.RecordWebhookFailureParams{
+		FailedAt:      pgtype.Timestamptz{Time: at, Valid: true},
+		DisableBefore: pgtype.Timestamptz{Time: disableBefore, Valid: true},
+		ID:            pgtype.UUID{Bytes: id, Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toDomainWebhook(row), nil
+}
+
+func fromEventTypes(types []domain.EventType) []string {
+	out := make([]string, len(types))
+	for i, t := range types {
+		out[i] = string(t)
+	}
+	return out
+}
+
+func toNullableInt(n *int) pgtype.Int4 {
+	if n == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: int32(*n), Valid: true}
+}
+
+func toDomainWebhook(row db.WebhookSubscription) *domain.WebhookSubscription {
+	types := make([]domain.EventType, len(row.EventTypes))
+	for i, t := range row.EventTypes {
+		types[i] = domain.EventType(t)
+	}
+	return &domain.WebhookSubscription{
+		ID:           uuid.UUID(row.ID.Bytes),
+		URL:          row.Url,
+		Secret:       row.Secret,
+		EventTypes:   types,
+		Enabled:      row.Enabled,
+		FailingSince: fromNullableTime(row.FailingSince),
+		DisabledAt:   fromNullableTime(row.DisabledAt),
+		CreatedAt:    row.CreatedAt.Time,
+		UpdatedAt:    row.UpdatedAt.Time,
+	}
+}
+
+func toDomainDelivery(row db.WebhookDelivery) domain.WebhookDelivery {
+	d := domain.WebhookDelivery{
+		ID:             uuid.UUID(row.ID.Bytes),
+		SubscriptionID: uuid.UUID(row.SubscriptionID.Bytes),
+		EventID:        uuid.UUID(row.EventID.Bytes),
+		EventType:      domain.EventType(row.EventType),
+		Payload:        []byte(row.Payload),
+		State:          domain.WebhookDeliveryState(row.State),
+		Attempts:       int(row.Attempts),
+		NextAttemptAt:  row.NextAttemptAt.Time,
+		LastError:      row.LastError,
+		CreatedAt:      row.CreatedAt.Time,
+		CompletedAt:    fromNullableTime(row.CompletedAt),
+	}
+	if row.ResponseStatus.Valid {
+		status := int(row.ResponseStatus.Int32)
+		d.ResponseStatus = &status
+	}
+	return d
+}
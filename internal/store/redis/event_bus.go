@@ -0,0 +1,63 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+// EventChannel is the pub/sub channel fleet events are fanned out on.
+const EventChannel = "fleet:events"
+
+// EventBus fans fleet events out to every server instance through Redis
+// pub/sub. Delivery is at most once: instances that are not subscribed when
+// an event is published never see it.
+type EventBus struct {
+	client *redis.Client
+}
+
+func NewEventBus(client *redis.Client) *EventBus {
+	return &EventBus{client: client}
+}
+
+func (b *EventBus) Publish(ctx context.Context, event domain.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(ctx, EventChannel, payload).Err()
+}
+
+// Subscribe passes every event published on EventChannel to handle until ctx
+// is cancelled. The Data of received events is the raw JSON document.
+func (b *EventBus) Subscribe(ctx context.Context, handle func(domain.Event)) error {
+	sub := b.client.Subscribe(ctx, EventChannel)
+	defer sub.Close()
+
+	// Wait for the subscription to be confirmed so that no event published
+	// after Subscribe starts is missed.
+	if _, err := sub.Receive(ctx); err != nil {
+		return err
+	}
+
+	ch := sub.Channel()
+	for {
+		select {
+		case msg, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			// A non-nil pointer in the interface makes json decode into it
+			// instead of building a map.
+			event := domain.Event{Data: &json.RawMessage{}}
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				continue
+			}
+			handle(event)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
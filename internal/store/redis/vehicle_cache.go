@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
+	"strconv"
 	"time"
 
 	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/pkg/geo"
 	"github.com/redis/go-redis/v9"
 
 	"github.com/google/uuid"
@@ -36,12 +39,87 @@ func (c *VehicleCache) key(vehicleID uuid.UUID) string {
 	return fmt.Sprintf("vehicle:%s:status", vehicleID.String())
 }
 
+// tsKey holds the reading time, in Unix milliseconds, of the cached status.
+func (c *VehicleCache) tsKey(vehicleID uuid.UUID) string {
+	return fmt.Sprintf("vehicle:%s:status:ts", vehicleID.String())
+}
+
+// Fleet index keys. fleetStatusKey is a hash of the latest status JSON of
+// every vehicle, by vehicle ID, fleetUpdatedKey a sorted set of the vehicle
+// IDs scored by the reading time (ms) of that status and fleetPositionKey a
+// GEO set of its location. Unlike the per-vehicle status keys they do not
+// expire, so parked vehicles stay on the fleet snapshot.
+const (
+	fleetStatusKey   = "fleet:status"
+	fleetUpdatedKey  = "fleet:status:updated"
+	fleetPositionKey = "fleet:positions"
+)
+
+// maxGeoLatitude is the highest latitude Redis GEO sets can index. Vehicles
+// closer to the poles are left out of the nearby search.
+const maxGeoLatitude = 85.05112878
+
+// geoHashError exceeds the error, in degrees, of the locations Redis stores
+// in a GEO set.
+const geoHashError = 1e-5
+
+// worldRadius, half the Earth's circumference in metres, makes a radius
+// search cover the whole index.
+const worldRadius = 20_037_509
+
+// fleetReadChunk bounds the number of vehicles read by one HMGET of a fleet
+// snapshot.
+const fleetReadChunk = 500
+
+// setStatusScript writes the status only if no newer reading is cached.
+//
+// KEYS[1] status key, KEYS[2] timestamp key, KEYS[3] fleet status hash,
+// KEYS[4] fleet updated set, KEYS[5] fleet position set
+// ARGV[1] status JSON, ARGV[2] reading time (ms), ARGV[3] TTL (ms, 0 = none),
+// ARGV[4] vehicle ID, ARGV[5] longitude, ARGV[6] latitude (both empty if the
+// status cannot be indexed)
+var setStatusScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[2])
+if current and tonumber(current) > tonumber(ARGV[2]) then
+	return 0
+end
+local indexed = redis.call('ZSCORE', KEYS[4], ARGV[4])
+if not indexed or tonumber(indexed) <= tonumber(ARGV[2]) then
+	redis.call('HSET', KEYS[3], ARGV[4], ARGV[1])
+	redis.call('ZADD', KEYS[4], ARGV[2], ARGV[4])
+	if ARGV[5] ~= '' then
+		redis.call('GEOADD', KEYS[5], ARGV[5], ARGV[6], ARGV[4])
+	else
+		redis.call('ZREM', KEYS[5], ARGV[4])
+	end
+end
+if tonumber(ARGV[3]) > 0 then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[3])
+	redis.call('SET', KEYS[2], ARGV[2], 'PX', ARGV[3])
+else
+	redis.call('SET', KEYS[1], ARGV[1])
+	redis.call('SET', KEYS[2], ARGV[2])
+end
+return 1
+`)
+
+// SetStatus caches the status unless a status with a later timestamp is
+// already cached, so concurrent or delayed writers cannot roll it back. The
+// fleet index only ever moves forward, even after the status key expired.
 func (c *VehicleCache) SetStatus(ctx context.Context, vehicleID uuid.UUID, status *domain.VehicleStatus, expiration time.Duration) error {
 	statusJSON, err := json.Marshal(status)
 	if err != nil {
 		return err
 	}
-	return c.client.Set(ctx, c.key(vehicleID), statusJSON, expiration).Err()
+	var lonArg, latArg string
+	if lon, lat, ok := status.Coordinates(); ok && math.Abs(lat) <= maxGeoLatitude {
+		lonArg = strconv.FormatFloat(lon, 'f', -1, 64)
+		latArg = strconv.FormatFloat(lat, 'f', -1, 64)
+	}
+	keys := []string{c.key(vehicleID), c.tsKey(vehicleID), fleetStatusKey, fleetUpdatedKey, fleetPositionKey}
+	return setStatusScript.Run(ctx, c.client, keys,
+		statusJSON, status.Timestamp.UnixMilli(), expiration.Milliseconds(), vehicleID.String(), lonArg, latArg,
+	).Err()
 }
 
 func (c *VehicleCache) GetStatus(ctx context.Context, vehicleID uuid.UUID) (*domain.VehicleStatus, error) {
@@ -58,3 +136,229 @@ func (c *VehicleCache) GetStatus(ctx context.Context, vehicleID uuid.UUID) (*dom
 	}
 	return &status, nil
 }
+
+// ListStatuses returns the latest status of the given vehicles, or of the
+// whole fleet if vehicleIDs is empty, leaving out statuses taken before
+// since.
+func (c *VehicleCache) ListStatuses(ctx context.Context, vehicleIDs []uuid.UUID, since time.Time) ([]domain.VehicleSnapshot, error) {
+	ids := make([]string, len(vehicleIDs))
+	for i, id := range vehicleIDs {
+		ids[i] = id.String()
+	}
+	if len(ids) == 0 {
+		from := "-inf"
+		if !since.IsZero() {
+			from = strconv.FormatInt(since.UnixMilli(), 10)
+		}
+		var err error
+		ids, err = c.client.ZRangeByScore(ctx, fleetUpdatedKey, &redis.ZRangeBy{Min: from, Max: "+inf"}).Result()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	return c.readStatuses(ctx, ids, since)
+}
+
+// ListStatusesInBox returns the latest status of the vehicles located in or
+// right around the box, leaving out statuses taken before since. Only the
+// vehicles the position index finds there are read, so the box is searched up
+// to the latitudes the index covers.
+func (c *VehicleCache) ListStatusesInBox(ctx context.Context, box domain.BoundingBox, since time.Time) ([]domain.VehicleSnapshot, error) {
+	indexed := box
+	indexed.MinLat = max(box.MinLat, -maxGeoLatitude)
+	indexed.MaxLat = min(box.MaxLat, maxGeoLatitude)
+	if indexed.MinLat > indexed.MaxLat {
+		return nil, nil
+	}
+	lon, lat, width, height := searchBox(indexed)
+	locations, err := c.client.GeoSearchLocation(ctx, fleetPositionKey, &redis.GeoSearchLocationQuery{
+		GeoSearchQuery: redis.GeoSearchQuery{
+			Longitude: lon,
+			Latitude:  lat,
+			BoxWidth:  width,
+			BoxHeight: height,
+			BoxUnit:   "m",
+		},
+		WithCoord: true,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	// The index stores locations with some error, so the box is widened by
+	// it to keep vehicles on its edges.
+	loose := domain.BoundingBox{
+		MinLon: box.MinLon - geoHashError,
+		MinLat: box.MinLat - geoHashError,
+		MaxLon: box.MaxLon + geoHashError,
+		MaxLat: box.MaxLat + geoHashError,
+	}
+	ids := make([]string, 0, len(locations))
+	for _, loc := range locations {
+		if loose.Contains(loc.Longitude, loc.Latitude) {
+			ids = append(ids, loc.Name)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return c.readStatuses(ctx, ids, since)
+}
+
+// searchBox returns the centre and size in metres of a Redis GEOSEARCH box
+// that encloses box. Redis measures a member's longitude distance along the
+// member's own parallel, so the width is taken where the box is widest, at
+// the latitude closest to the equator. The result is padded for the
+// difference between Redis' Earth radius and ours.
+func searchBox(box domain.BoundingBox) (lon, lat, width, height float64) {
+	span := box.MaxLon - box.MinLon
+	if span < 0 {
+		span += 360 // Crosses the antimeridian
+	}
+	lon = box.MinLon + span/2
+	if lon > 180 {
+		lon -= 360
+	}
+	lat = (box.MinLat + box.MaxLat) / 2
+
+	widest := 0.0
+	if box.MinLat > 0 {
+		widest = box.MinLat
+	} else if box.MaxLat < 0 {
+		widest = box.MaxLat
+	}
+	const pad = 1.01
+	width = 2 * geo.DistanceKm(lon, widest, lon+span/2, widest) * 1000 * pad
+	height = geo.DistanceKm(lon, box.MinLat, lon, box.MaxLat) * 1000 * pad
+	return lon, lat, width, height
+}
+
+// readStatuses reads the fleet index entries of the vehicles ids, leaving out
+// vehicles that are not indexed and statuses taken before since.
+func (c *VehicleCache) readStatuses(ctx context.Context, ids []string, since time.Time) ([]domain.VehicleSnapshot, error) {
+	vals, err := c.readIndex(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]domain.VehicleSnapshot, 0, len(ids))
+	for i, val := range vals {
+		raw, ok := val.(string)
+		if !ok {
+			continue // Not in the index
+		}
+		snapshot := domain.VehicleSnapshot{}
+		if err := json.Unmarshal([]byte(raw), &snapshot.Status); err != nil {
+			return nil, err
+		}
+		if snapshot.Status.Timestamp.Before(since) {
+			continue
+		}
+		snapshot.VehicleID, _ = uuid.Parse(ids[i])
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+// readIndex returns the status JSON of each of the vehicles ids in the fleet
+// index, or nil for those not indexed, with HMGET calls of fleetReadChunk IDs
+// pipelined into one round trip.
+func (c *VehicleCache) readIndex(ctx context.Context, ids []string) ([]any, error) {
+	pipe := c.client.Pipeline()
+	cmds := make([]*redis.SliceCmd, 0, len(ids)/fleetReadChunk+1)
+	for start := 0; start < len(ids); start += fleetReadChunk {
+		end := min(start+fleetReadChunk, len(ids))
+		cmds = append(cmds, pipe.HMGet(ctx, fleetStatusKey, ids[start:end]...))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	vals := make([]any, 0, len(ids))
+	for _, cmd := range cmds {
+		vals = append(vals, cmd.Val()...)
+	}
+	return vals, nil
+}
+
+// RemoveFromFleet drops a vehicle from the fleet index, so that it no longer
+// appears on the fleet snapshot or in nearby searches.
+func (c *VehicleCache) RemoveFromFleet(ctx context.Context, vehicleID uuid.UUID) error {
+	id := vehicleID.String()
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HDel(ctx, fleetStatusKey, id)
+		pipe.ZRem(ctx, fleetUpdatedKey, id)
+		pipe.ZRem(ctx, fleetPositionKey, id)
+		return nil
+	})
+	return err
+}
+
+// FindNearby returns the vehicles whose latest position lies within radius
+// metres of the point, nearest first, with their distance and status. A zero
+// radius searches the whole index and a zero count returns every match.
+func (c *VehicleCache) FindNearby(ctx context.Context, lon, lat, radius float64, count int) ([]domain.NearbyVehicle, error) {
+	if radius <= 0 {
+		radius = worldRadius
+	}
+	locations, err := c.client.GeoSearchLocation(ctx, fleetPositionKey, &redis.GeoSearchLocationQuery{
+		GeoSearchQuery: redis.GeoSearchQuery{
+			Longitude:  lon,
+			Latitude:   lat,
+			Radius:     radius,
+			RadiusUnit: "m",
+			Sort:       "ASC",
+			Count:      count,
+		},
+		WithDist: true,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(locations) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, len(locations))
+	for i, loc := range locations {
+		ids[i] = loc.Name
+	}
+	statuses, err := c.readIndex(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	vehicles := make([]domain.NearbyVehicle, 0, len(locations))
+	for i, loc := range locations {
+		raw, ok := statuses[i].(string)
+		if !ok {
+			continue
+		}
+		vehicle := domain.NearbyVehicle{Distance: loc.Dist}
+		if err := json.Unmarshal([]byte(raw), &vehicle.Status); err != nil {
+			return nil, err
+		}
+		vehicle.VehicleID, _ = uuid.Parse(loc.Name)
+		vehicles = append(vehicles, vehicle)
+	}
+	return vehicles, nil
+}
+
+func (c *VehicleCache) messageKey(vehicleID uuid.UUID, messageID string) string {
+	return fmt.Sprintf("vehicle:%s:msg:%s", vehicleID.String(), messageID)
+}
+
+// MarkMessageSeen records the message ID for window and reports whether it
+// had not been recorded before.
+func (c *VehicleCache) MarkMessageSeen(ctx context.Context, vehicleID uuid.UUID, messageID string, window time.Duration) (bool, error) {
+	return c.client.SetNX(ctx, c.messageKey(vehicleID, messageID), 1, window).Result()
+}
+
+// ForgetMessage removes a recorded message ID.
+func (c *VehicleCache) ForgetMessage(ctx context.Context, vehicleID uuid.UUID, messageID string) error {
+	return c.client.Del(ctx, c.messageKey(vehicleID, messageID)).Err()
+}
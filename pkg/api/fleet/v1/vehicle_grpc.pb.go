@@ -0,0 +1,256 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.28.3
+// source: fleet/v1/vehicle.proto
+
+package fleetv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	VehicleService_GetVehicleStatus_FullMethodName = "/fleet.v1.VehicleService/GetVehicleStatus"
+	VehicleService_GetVehicleTrips_FullMethodName  = "/fleet.v1.VehicleService/GetVehicleTrips"
+	VehicleService_Ingest_FullMethodName           = "/fleet.v1.VehicleService/Ingest"
+	VehicleService_WatchPositions_FullMethodName   = "/fleet.v1.VehicleService/WatchPositions"
+)
+
+// VehicleServiceClient is the client API for VehicleService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// VehicleService is the gRPC counterpart of the /api/vehicle REST endpoints.
+// Every call must carry a user JWT in the "authorization" metadata as
+// "Bearer <token>".
+type VehicleServiceClient interface {
+	// GetVehicleStatus returns the last known status of a vehicle.
+	GetVehicleStatus(ctx context.Context, in *GetVehicleStatusRequest, opts ...grpc.CallOption) (*VehicleStatus, error)
+	// GetVehicleTrips returns a page of the trips of a vehicle.
+	GetVehicleTrips(ctx context.Context, in *GetVehicleTripsRequest, opts ...grpc.CallOption) (*GetVehicleTripsResponse, error)
+	// Ingest accepts a stream of readings, for gateways that forward many
+	// devices over one connection. Readings are validated and deduplicated
+	// individually; the response summarises the stream once the client closes
+	// it.
+	Ingest(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[IngestRequest, IngestResponse], error)
+	// WatchPositions streams every accepted vehicle status until the client
+	// cancels the call.
+	WatchPositions(ctx context.Context, in *WatchPositionsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PositionUpdate], error)
+}
+
+type vehicleServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewVehicleServiceClient(cc grpc.ClientConnInterface) VehicleServiceClient {
+	return &vehicleServiceClient{cc}
+}
+
+func (c *vehicleServiceClient) GetVehicleStatus(ctx context.Context, in *GetVehicleStatusRequest, opts ...grpc.CallOption) (*VehicleStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VehicleStatus)
+	err := c.cc.Invoke(ctx, VehicleService_GetVehicleStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vehicleServiceClient) GetVehicleTrips(ctx context.Context, in *GetVehicleTripsRequest, opts ...grpc.CallOption) (*GetVehicleTripsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetVehicleTripsResponse)
+	err := c.cc.Invoke(ctx, VehicleService_GetVehicleTrips_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vehicleServiceClient) Ingest(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[IngestRequest, IngestResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &VehicleService_ServiceDesc.Streams[0], VehicleService_Ingest_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[IngestRequest, IngestResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type VehicleService_IngestClient = grpc.ClientStreamingClient[IngestRequest, IngestResponse]
+
+func (c *vehicleServiceClient) WatchPositions(ctx context.Context, in *WatchPositionsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PositionUpdate], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &VehicleService_ServiceDesc.Streams[1], VehicleService_WatchPositions_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchPositionsRequest, PositionUpdate]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type VehicleService_WatchPositionsClient = grpc.ServerStreamingClient[PositionUpdate]
+
+// VehicleServiceServer is the server API for VehicleService service.
+// All implementations must embed UnimplementedVehicleServiceServer
+// for forward compatibility.
+//
+// VehicleService is the gRPC counterpart of the /api/vehicle REST endpoints.
+// Every call must carry a user JWT in the "authorization" metadata as
+// "Bearer <token>".
+type VehicleServiceServer interface {
+	// GetVehicleStatus returns the last known status of a vehicle.
+	GetVehicleStatus(context.Context, *GetVehicleStatusRequest) (*VehicleStatus, error)
+	// GetVehicleTrips returns a page of the trips of a vehicle.
+	GetVehicleTrips(context.Context, *GetVehicleTripsRequest) (*GetVehicleTripsResponse, error)
+	// Ingest accepts a stream of readings, for gateways that forward many
+	// devices over one connection. Readings are validated and deduplicated
+	// individually; the response summarises the stream once the client closes
+	// it.
+	Ingest(grpc.ClientStreamingServer[IngestRequest, IngestResponse]) error
+	// WatchPositions streams every accepted vehicle status until the client
+	// cancels the call.
+	WatchPositions(*WatchPositionsRequest, grpc.ServerStreamingServer[PositionUpdate]) error
+	mustEmbedUnimplementedVehicleServiceServer()
+}
+
+// UnimplementedVehicleServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedVehicleServiceServer struct{}
+
+func (UnimplementedVehicleServiceServer) GetVehicleStatus(context.Context, *GetVehicleStatusRequest) (*VehicleStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVehicleStatus not implemented")
+}
+func (UnimplementedVehicleServiceServer) GetVehicleTrips(context.Context, *GetVehicleTripsRequest) (*GetVehicleTripsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVehicleTrips not implemented")
+}
+func (UnimplementedVehicleServiceServer) Ingest(grpc.ClientStreamingServer[IngestRequest, IngestResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Ingest not implemented")
+}
+func (UnimplementedVehicleServiceServer) WatchPositions(*WatchPositionsRequest, grpc.ServerStreamingServer[PositionUpdate]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchPositions not implemented")
+}
+func (UnimplementedVehicleServiceServer) mustEmbedUnimplementedVehicleServiceServer() {}
+func (UnimplementedVehicleServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeVehicleServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to VehicleServiceServer will
+// result in compilation errors.
+type UnsafeVehicleServiceServer interface {
+	mustEmbedUnimplementedVehicleServiceServer()
+}
+
+func RegisterVehicleServiceServer(s grpc.ServiceRegistrar, srv VehicleServiceServer) {
+	// If the following call pancis, it indicates UnimplementedVehicleServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&VehicleService_ServiceDesc, srv)
+}
+
+func _VehicleService_GetVehicleStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetVehicleStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VehicleServiceServer).GetVehicleStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VehicleService_GetVehicleStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VehicleServiceServer).GetVehicleStatus(ctx, req.(*GetVehicleStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VehicleService_GetVehicleTrips_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetVehicleTripsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VehicleServiceServer).GetVehicleTrips(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VehicleService_GetVehicleTrips_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VehicleServiceServer).GetVehicleTrips(ctx, req.(*GetVehicleTripsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VehicleService_Ingest_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(VehicleServiceServer).Ingest(&grpc.GenericServerStream[IngestRequest, IngestResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type VehicleService_IngestServer = grpc.ClientStreamingServer[IngestRequest, IngestResponse]
+
+func _VehicleService_WatchPositions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchPositionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(VehicleServiceServer).WatchPositions(m, &grpc.GenericServerStream[WatchPositionsRequest, PositionUpdate]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type VehicleService_WatchPositionsServer = grpc.ServerStreamingServer[PositionUpdate]
+
+// VehicleService_ServiceDesc is the grpc.ServiceDesc for VehicleService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var VehicleService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "fleet.v1.VehicleService",
+	HandlerType: (*VehicleServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetVehicleStatus",
+			Handler:    _VehicleService_GetVehicleStatus_Handler,
+		},
+		{
+			MethodName: "GetVehicleTrips",
+			Handler:    _VehicleService_GetVehicleTrips_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Ingest",
+			Handler:       _VehicleService_Ingest_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "WatchPositions",
+			Handler:       _VehicleService_WatchPositions_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "fleet/v1/vehicle.proto",
+}
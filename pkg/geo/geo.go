@@ -0,0 +1,38 @@
+package geo
+
+import "math"
+
+// EarthRadiusKm is the mean radius of the Earth in kilometres.
+const EarthRadiusKm = 6371.0088
+
+// DistanceKm returns the great-circle (haversine) distance in kilometres
+// between two points given as longitude/latitude pairs in degrees.
+func DistanceKm(lon1, lat1, lon2, lat2 float64) float64 {
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+// PointInPolygon reports whether the point lies inside the polygon ring,
+// given as [longitude, latitude] vertices, using the even-odd rule. The ring
+// may or may not repeat its first vertex. It treats coordinates as planar,
+// which is accurate enough for fences that do not span the antimeridian.
+func PointInPolygon(lon, lat float64, ring [][]float64) bool {
+	inside := false
+	n := len(ring)
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+		if (yi > lat) != (yj > lat) && lon < (xj-xi)*(lat-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
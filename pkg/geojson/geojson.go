@@ -0,0 +1,52 @@
+// Package geojson holds the subset of RFC 7946 GeoJSON types the API emits.
+package geojson
+
+// MediaType is the registered media type of GeoJSON documents.
+const MediaType = "application/geo+json"
+
+// Geometry is a GeoJSON geometry object. Positions are [longitude, latitude].
+type Geometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+// Feature is a GeoJSON feature. A nil Geometry encodes as null, which GeoJSON
+// allows for features without a location.
+type Feature struct {
+	Type       string         `json:"type"`
+	ID         any            `json:"id,omitempty"`
+	Geometry   *Geometry      `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// FeatureCollection is a GeoJSON feature collection.
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// NewPoint returns a Point geometry.
+func NewPoint(lon, lat float64) *Geometry {
+	return &Geometry{Type: "Point", Coordinates: []float64{lon, lat}}
+}
+
+// NewLineString returns a LineString geometry through the given positions.
+func NewLineString(positions [][]float64) *Geometry {
+	return &Geometry{Type: "LineString", Coordinates: positions}
+}
+
+// NewFeature returns a feature with the given geometry and properties.
+func NewFeature(id any, geometry *Geometry, properties map[string]any) Feature {
+	if properties == nil {
+		properties = map[string]any{}
+	}
+	return Feature{Type: "Feature", ID: id, Geometry: geometry, Properties: properties}
+}
+
+// NewFeatureCollection returns a collection of the given features.
+func NewFeatureCollection(features []Feature) FeatureCollection {
+	if features == nil {
+		features = []Feature{}
+	}
+	return FeatureCollection{Type: "FeatureCollection", Features: features}
+}
@@ -0,0 +1,126 @@
+// Package gpx writes GPS Exchange Format 1.1 documents, the track format most
+// mapping and route analysis tools import. Tracks are written point by point,
+// so a document of any size can be streamed.
+package gpx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// MediaType is the media type of GPX documents.
+const MediaType = "application/gpx+xml"
+
+// Track describes a track of the document.
+type Track struct {
+	Name        string
+	Description string
+}
+
+// Point is a single point of a track.
+type Point struct {
+	Longitude float64
+	Latitude  float64
+	Elevation *float64 // metres above sea level
+	Time      time.Time
+}
+
+// Writer writes a GPX document. The first error is kept: once a write fails,
+// every later call returns the same error.
+type Writer struct {
+	w       io.Writer
+	creator string
+	started bool
+	inTrack bool
+	err     error
+}
+
+// NewWriter returns a Writer that writes to w. creator names the
+// application in the document header.
+func NewWriter(w io.Writer, creator string) *Writer {
+	return &Writer{w: w, creator: creator}
+}
+
+// StartTrack ends the current track, if any, and starts a new one.
+func (g *Writer) StartTrack(t Track) error {
+	g.start()
+	g.endTrack()
+	g.printf("<trk>")
+	if t.Name != "" {
+		g.element("name", t.Name)
+	}
+	if t.Description != "" {
+		g.element("desc", t.Description)
+	}
+	g.printf("<trkseg>\n")
+	g.inTrack = true
+	return g.err
+}
+
+// WritePoint adds a point to the current track, starting an unnamed one if
+// none was started.
+func (g *Writer) WritePoint(p Point) error {
+	if !g.inTrack {
+		g.StartTrack(Track{})
+	}
+	g.printf(`<trkpt lat="%s" lon="%s">`, formatFloat(p.Latitude), formatFloat(p.Longitude))
+	if p.Elevation != nil {
+		g.printf("<ele>%s</ele>", formatFloat(*p.Elevation))
+	}
+	if !p.Time.IsZero() {
+		g.printf("<time>%s</time>", p.Time.UTC().Format(time.RFC3339Nano))
+	}
+	g.printf("</trkpt>\n")
+	return g.err
+}
+
+// Close ends the current track and the document. It does not close the
+// underlying writer.
+func (g *Writer) Close() error {
+	g.start()
+	g.endTrack()
+	g.printf("</gpx>\n")
+	return g.err
+}
+
+func (g *Writer) start() {
+	if g.started {
+		return
+	}
+	g.started = true
+	g.printf("%s<gpx version=\"1.1\" creator=\"", xml.Header)
+	g.escape(g.creator)
+	g.printf("\" xmlns=\"http://www.topografix.com/GPX/1/1\">\n")
+}
+
+func (g *Writer) endTrack() {
+	if g.inTrack {
+		g.printf("</trkseg></trk>\n")
+		g.inTrack = false
+	}
+}
+
+func (g *Writer) element(name, text string) {
+	g.printf("<%s>", name)
+	g.escape(text)
+	g.printf("</%s>", name)
+}
+
+func (g *Writer) printf(format string, args ...any) {
+	if g.err == nil {
+		_, g.err = fmt.Fprintf(g.w, format, args...)
+	}
+}
+
+func (g *Writer) escape(s string) {
+	if g.err == nil {
+		g.err = xml.EscapeText(g.w, []byte(s))
+	}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,125 @@
+// Package kml writes KML 2.2 documents for Google Earth and other GIS tools.
+// Lines are written coordinate by coordinate, so a document of any size can be
+// streamed.
+package kml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// MediaType is the media type of KML documents.
+const MediaType = "application/vnd.google-earth.kml+xml"
+
+// Placemark describes a line of the document. A non-zero Begin or End puts
+// the line on the time slider of Google Earth.
+type Placemark struct {
+	Name        string
+	Description string
+	Begin       time.Time
+	End         time.Time
+}
+
+// Writer writes a KML document of LineString placemarks. The first error is
+// kept: once a write fails, every later call returns the same error.
+type Writer struct {
+	w           io.Writer
+	name        string
+	started     bool
+	inPlacemark bool
+	err         error
+}
+
+// NewWriter returns a Writer that writes to w. name names the document.
+func NewWriter(w io.Writer, name string) *Writer {
+	return &Writer{w: w, name: name}
+}
+
+// StartPlacemark ends the current placemark, if any, and starts a new line.
+func (k *Writer) StartPlacemark(p Placemark) error {
+	k.start()
+	k.endPlacemark()
+	k.printf("<Placemark>")
+	if p.Name != "" {
+		k.element("name", p.Name)
+	}
+	if p.Description != "" {
+		k.element("description", p.Description)
+	}
+	if !p.Begin.IsZero() || !p.End.IsZero() {
+		k.printf("<TimeSpan>")
+		if !p.Begin.IsZero() {
+			k.printf("<begin>%s</begin>", p.Begin.UTC().Format(time.RFC3339))
+		}
+		if !p.End.IsZero() {
+			k.printf("<end>%s</end>", p.End.UTC().Format(time.RFC3339))
+		}
+		k.printf("</TimeSpan>")
+	}
+	k.printf("<LineString><tessellate>1</tessellate><coordinates>\n")
+	k.inPlacemark = true
+	return k.err
+}
+
+// WriteCoordinate adds a point to the line of the current placemark, starting
+// an unnamed one if none was started.
+func (k *Writer) WriteCoordinate(lon, lat float64) error {
+	if !k.inPlacemark {
+		k.StartPlacemark(Placemark{})
+	}
+	k.printf("%s,%s\n", formatFloat(lon), formatFloat(lat))
+	return k.err
+}
+
+// Close ends the current placemark and the document. It does not close the
+// underlying writer.
+func (k *Writer) Close() error {
+	k.start()
+	k.endPlacemark()
+	k.printf("</Document></kml>\n")
+	return k.err
+}
+
+func (k *Writer) start() {
+	if k.started {
+		return
+	}
+	k.started = true
+	k.printf("%s<kml xmlns=\"http://www.opengis.net/kml/2.2\"><Document>", xml.Header)
+	if k.name != "" {
+		k.element("name", k.name)
+	}
+	k.printf("\n")
+}
+
+func (k *Writer) endPlacemark() {
+	if k.inPlacemark {
+		k.printf("</coordinates></LineString></Placemark>\n")
+		k.inPlacemark = false
+	}
+}
+
+func (k *Writer) element(name, text string) {
+	k.printf("<%s>", name)
+	k.escape(text)
+	k.printf("</%s>", name)
+}
+
+func (k *Writer) printf(format string, args ...any) {
+	if k.err == nil {
+		_, k.err = fmt.Fprintf(k.w, format, args...)
+	}
+}
+
+func (k *Writer) escape(s string) {
+	if k.err == nil {
+		k.err = xml.EscapeText(k.w, []byte(s))
+	}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,232 @@
+// Package nmea parses the NMEA 0183 sentences GPS receivers report fixes in.
+// Only RMC (recommended minimum) and GGA (fix data) are supported, from any
+// talker such as GP, GN or GL.
+package nmea
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KnotsToKmh converts a speed over ground in knots to km/h.
+const KnotsToKmh = 1.852
+
+var (
+	// ErrChecksum is returned when a sentence does not match its checksum.
+	ErrChecksum = errors.New("nmea: checksum mismatch")
+	// ErrUnsupported is returned for well-formed sentences of other types.
+	ErrUnsupported = errors.New("nmea: unsupported sentence")
+)
+
+// Sentence is a parsed RMC or GGA sentence.
+type Sentence interface {
+	sentence()
+}
+
+// RMC is the recommended minimum fix: position, speed, course and the full
+// UTC time.
+type RMC struct {
+	Time time.Time
+	// Valid is false for the void status the receiver reports without a fix.
+	Valid      bool
+	Latitude   float64
+	Longitude  float64
+	SpeedKnots float64
+	// Course is the track over ground in degrees, nil if not reported.
+	Course *float64
+}
+
+// GGA is the fix data: position, quality, satellites, HDOP and altitude. It
+// carries the time of day only.
+type GGA struct {
+	TimeOfDay time.Duration
+	Latitude  float64
+	Longitude float64
+	// Quality is 0 without a fix.
+	Quality    int
+	Satellites *int
+	HDOP       *float64
+	// Altitude is above mean sea level in metres.
+	Altitude *float64
+}
+
+func (RMC) sentence() {}
+func (GGA) sentence() {}
+
+// Parse parses one sentence, e.g.
+// "$GPRMC,092751.000,A,5321.6802,N,00630.3371,W,0.06,31.66,280511,,,A*45".
+// The checksum is verified if present.
+func Parse(line string) (Sentence, error) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "$") {
+		return nil, errors.New("nmea: missing $ prefix")
+	}
+	body := line[1:]
+	if i := strings.IndexByte(body, '*'); i >= 0 {
+		want, err := strconv.ParseUint(body[i+1:], 16, 8)
+		if err != nil || len(body)-i-1 != 2 {
+			return nil, errors.New("nmea: invalid checksum")
+		}
+		body = body[:i]
+		if checksum(body) != byte(want) {
+			return nil, ErrChecksum
+		}
+	}
+
+	fields := strings.Split(body, ",")
+	if len(fields[0]) != 5 {
+		return nil, fmt.Errorf("nmea: invalid address %q", fields[0])
+	}
+	switch fields[0][2:] {
+	case "RMC":
+		return parseRMC(fields)
+	case "GGA":
+		return parseGGA(fields)
+	default:
+		return nil, ErrUnsupported
+	}
+}
+
+func parseRMC(f []string) (Sentence, error) {
+	if len(f) < 10 {
+		return nil, errors.New("nmea: RMC has too few fields")
+	}
+	var rmc RMC
+	if f[2] != "A" && f[2] != "V" {
+		return nil, errors.New("nmea: invalid RMC status")
+	}
+	rmc.Valid = f[2] == "A"
+
+	tod, err := parseTimeOfDay(f[1])
+	if err != nil {
+		return nil, err
+	}
+	date, err := time.Parse("020106", f[9])
+	if err != nil {
+		return nil, errors.New("nmea: invalid date")
+	}
+	rmc.Time = date.Add(tod)
+	if !rmc.Valid && f[3] == "" {
+		return rmc, nil
+	}
+
+	if rmc.Latitude, rmc.Longitude, err = parsePosition(f[3], f[4], f[5], f[6]); err != nil {
+		return nil, err
+	}
+	if f[7] != "" {
+		if rmc.SpeedKnots, err = strconv.ParseFloat(f[7], 64); err != nil {
+			return nil, errors.New("nmea: invalid speed")
+		}
+	}
+	if rmc.Course, err = parseOptionalFloat(f[8], "course"); err != nil {
+		return nil, err
+	}
+	return rmc, nil
+}
+
+func parseGGA(f []string) (Sentence, error) {
+	if len(f) < 10 {
+		return nil, errors.New("nmea: GGA has too few fields")
+	}
+	var gga GGA
+	var err error
+	if gga.TimeOfDay, err = parseTimeOfDay(f[1]); err != nil {
+		return nil, err
+	}
+	if gga.Quality, err = strconv.Atoi(f[6]); err != nil {
+		return nil, errors.New("nmea: invalid fix quality")
+	}
+	if gga.Quality == 0 && f[2] == "" {
+		return gga, nil
+	}
+	if gga.Latitude, gga.Longitude, err = parsePosition(f[2], f[3], f[4], f[5]); err != nil {
+		return nil, err
+	}
+	if f[7] != "" {
+		n, err := strconv.Atoi(f[7])
+		if err != nil {
+			return nil, errors.New("nmea: invalid satellites")
+		}
+		gga.Satellites = &n
+	}
+	if gga.HDOP, err = parseOptionalFloat(f[8], "HDOP"); err != nil {
+		return nil, err
+	}
+	if gga.Altitude, err = parseOptionalFloat(f[9], "altitude"); err != nil {
+		return nil, err
+	}
+	return gga, nil
+}
+
+// parseTimeOfDay parses hhmmss with optional fractional seconds.
+func parseTimeOfDay(s string) (time.Duration, error) {
+	if len(s) < 6 {
+		return 0, errors.New("nmea: invalid time")
+	}
+	h, err1 := strconv.Atoi(s[0:2])
+	m, err2 := strconv.Atoi(s[2:4])
+	sec, err3 := strconv.ParseFloat(s[4:], 64)
+	if err1 != nil || err2 != nil || err3 != nil || h > 23 || m > 59 || sec >= 61 {
+		return 0, errors.New("nmea: invalid time")
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute +
+		time.Duration(sec*float64(time.Second)).Round(time.Millisecond), nil
+}
+
+// parsePosition converts ddmm.mmmm/N and dddmm.mmmm/E pairs to signed
+// decimal degrees.
+func parsePosition(lat, ns, lon, ew string) (float64, float64, error) {
+	latitude, err := parseDegrees(lat, 2)
+	if err != nil || latitude > 90 || (ns != "N" && ns != "S") {
+		return 0, 0, errors.New("nmea: invalid latitude")
+	}
+	longitude, err := parseDegrees(lon, 3)
+	if err != nil || longitude > 180 || (ew != "E" && ew != "W") {
+		return 0, 0, errors.New("nmea: invalid longitude")
+	}
+	if ns == "S" {
+		latitude = -latitude
+	}
+	if ew == "W" {
+		longitude = -longitude
+	}
+	return latitude, longitude, nil
+}
+
+func parseDegrees(s string, degreeDigits int) (float64, error) {
+	if len(s) < degreeDigits+2 {
+		return 0, errors.New("too short")
+	}
+	deg, err := strconv.Atoi(s[:degreeDigits])
+	if err != nil {
+		return 0, err
+	}
+	min, err := strconv.ParseFloat(s[degreeDigits:], 64)
+	if err != nil || min >= 60 {
+		return 0, errors.New("invalid minutes")
+	}
+	return float64(deg) + min/60, nil
+}
+
+func parseOptionalFloat(s, name string) (*float64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, errors.New("nmea: invalid " + name)
+	}
+	return &v, nil
+}
+
+// checksum is the XOR of the bytes between "$" and "*".
+func checksum(body string) byte {
+	var sum byte
+	for i := 0; i < len(body); i++ {
+		sum ^= body[i]
+	}
+	return sum
+}
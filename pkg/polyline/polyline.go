@@ -0,0 +1,42 @@
+// Package polyline implements Google's encoded polyline algorithm format, a
+// compact string form of a line that map SDKs decode natively.
+package polyline
+
+import (
+	"math"
+	"strings"
+)
+
+// Precision is the number of decimal places the coordinates are rounded to,
+// the precision map SDKs decode by default.
+const Precision = 5
+
+// Encode encodes positions given as [longitude, latitude] pairs, like GeoJSON
+// positions. The format itself stores latitude first.
+func Encode(positions [][]float64) string {
+	factor := math.Pow10(Precision)
+	var b strings.Builder
+	var prevLat, prevLon int64
+	for _, p := range positions {
+		lat := int64(math.Round(p[1] * factor))
+		lon := int64(math.Round(p[0] * factor))
+		encodeValue(&b, lat-prevLat)
+		encodeValue(&b, lon-prevLon)
+		prevLat, prevLon = lat, lon
+	}
+	return b.String()
+}
+
+// encodeValue writes one signed delta as 5-bit chunks, least significant
+// first, each offset by 63 into printable ASCII.
+func encodeValue(b *strings.Builder, v int64) {
+	u := uint64(v) << 1
+	if v < 0 {
+		u = ^u
+	}
+	for u >= 0x20 {
+		b.WriteByte(byte(0x20|u&0x1f) + 63)
+		u >>= 5
+	}
+	b.WriteByte(byte(u) + 63)
+}
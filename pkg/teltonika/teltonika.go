@@ -0,0 +1,260 @@
+// Package teltonika decodes the TCP protocol of Teltonika trackers such as the
+// FMB series: the IMEI handshake and AVL data packets in Codec 8 and Codec 8
+// Extended.
+//
+// A device opens a connection with its IMEI and waits for the server to
+// accept it. It then sends AVL packets and expects every packet to be
+// acknowledged with the number of records the server took; packets that are
+// not fully acknowledged are sent again.
+package teltonika
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Codec IDs of the supported AVL packet formats.
+const (
+	Codec8  byte = 0x08
+	Codec8E byte = 0x8E
+)
+
+// Replies to the IMEI handshake.
+const (
+	AcceptIMEI byte = 0x01
+	RejectIMEI byte = 0x00
+)
+
+const (
+	// MaxPacketSize bounds the data field of an AVL packet.
+	MaxPacketSize = 64 << 10
+	// maxIMEILength bounds the handshake; IMEIs have 15 digits.
+	maxIMEILength = 32
+)
+
+var (
+	// ErrCRC is returned when a packet does not match its checksum. The
+	// stream is still in sync, so the packet can be rejected and the next
+	// one read.
+	ErrCRC = errors.New("teltonika: CRC mismatch")
+	// ErrUnsupportedCodec is returned for packets in codecs other than 8
+	// and 8E, such as the GPRS command codecs.
+	ErrUnsupportedCodec = errors.New("teltonika: unsupported codec")
+)
+
+// Packet is a decoded AVL data packet.
+type Packet struct {
+	Codec   byte
+	Records []Record
+}
+
+// Record is one AVL record: a GPS fix and the IO elements sampled with it.
+type Record struct {
+	Timestamp time.Time
+	// Priority is 0 (low), 1 (high) or 2 (panic).
+	Priority byte
+	// Longitude and Latitude are in degrees. Both are 0 when the device has
+	// never had a fix.
+	Longitude float64
+	Latitude  float64
+	// Altitude is in metres above sea level.
+	Altitude int16
+	// Angle is the heading in degrees clockwise from north.
+	Angle      uint16
+	Satellites byte
+	// Speed is in km/h. It is 0 when the fix is invalid.
+	Speed uint16
+	// EventIOID is the IO element that triggered the record, or 0.
+	EventIOID uint16
+	// IO holds the raw big-endian value of every IO element by ID.
+	IO map[uint16][]byte
+}
+
+// IOUint returns the IO element id as an unsigned integer, and false if the
+// record does not have it or it is longer than 8 bytes.
+func (r Record) IOUint(id uint16) (uint64, bool) {
+	v, ok := r.IO[id]
+	if !ok || len(v) > 8 {
+		return 0, false
+	}
+	var n uint64
+	for _, b := range v {
+		n = n<<8 | uint64(b)
+	}
+	return n, true
+}
+
+// ReadIMEI reads the handshake a device opens its connection with: the
+// length of its IMEI as two bytes followed by the IMEI in ASCII.
+func ReadIMEI(r io.Reader) (string, error) {
+	var n uint16
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return "", err
+	}
+	if n == 0 || n > maxIMEILength {
+		return "", fmt.Errorf("teltonika: invalid IMEI length %d", n)
+	}
+	imei := make([]byte, n)
+	if _, err := io.ReadFull(r, imei); err != nil {
+		return "", err
+	}
+	for _, c := range imei {
+		if c < '0' || c > '9' {
+			return "", errors.New("teltonika: IMEI is not numeric")
+		}
+	}
+	return string(imei), nil
+}
+
+// ReadPacket reads one AVL data packet. It returns ErrCRC for a packet that
+// arrived whole but does not match its checksum, after which the next packet
+// can still be read.
+func ReadPacket(r io.Reader) (*Packet, error) {
+	var header [8]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	if binary.BigEndian.Uint32(header[:4]) != 0 {
+		return nil, errors.New("teltonika: invalid preamble")
+	}
+	length := binary.BigEndian.Uint32(header[4:])
+	if length < 3 || length > MaxPacketSize {
+		return nil, fmt.Errorf("teltonika: invalid data length %d", length)
+	}
+
+	// The data field is followed by the CRC in four bytes.
+	buf := make([]byte, length+4)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	data := buf[:length]
+	if want := binary.BigEndian.Uint32(buf[length:]); uint32(CRC16(data)) != want {
+		return nil, ErrCRC
+	}
+	return DecodeData(data)
+}
+
+// DecodeData decodes the data field of a packet, from the codec ID to the
+// second record count.
+func DecodeData(data []byte) (*Packet, error) {
+	d := decoder{buf: data}
+	p := &Packet{Codec: d.byte()}
+	if d.err == nil && p.Codec != Codec8 && p.Codec != Codec8E {
+		return nil, fmt.Errorf("%w 0x%02X", ErrUnsupportedCodec, p.Codec)
+	}
+
+	n := int(d.byte())
+	p.Records = make([]Record, 0, n)
+	for i := 0; i < n && d.err == nil; i++ {
+		p.Records = append(p.Records, d.record(p.Codec == Codec8E))
+	}
+	last := int(d.byte())
+	if d.err != nil {
+		return nil, d.err
+	}
+	if last != n {
+		return nil, errors.New("teltonika: record counts do not match")
+	}
+	if len(d.buf) != 0 {
+		return nil, errors.New("teltonika: trailing data")
+	}
+	return p, nil
+}
+
+// Ack is the reply to a packet: the number of records the server accepted.
+func Ack(records int) []byte {
+	return binary.BigEndian.AppendUint32(nil, uint32(records))
+}
+
+// CRC16 is the CRC-16/IBM checksum of a packet's data field.
+func CRC16(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b)
+		for i := 0; i < 8; i++ {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0xA001
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return crc
+}
+
+// decoder reads big-endian fields, remembering the first error so a record
+// can be decoded without checking every read.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) next(n int) []byte {
+	if d.err != nil {
+		return make([]byte, n)
+	}
+	if len(d.buf) < n {
+		d.err = errors.New("teltonika: truncated packet")
+		d.buf = nil
+		return make([]byte, n)
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *decoder) byte() byte     { return d.next(1)[0] }
+func (d *decoder) uint16() uint16 { return binary.BigEndian.Uint16(d.next(2)) }
+func (d *decoder) uint32() uint32 { return binary.BigEndian.Uint32(d.next(4)) }
+func (d *decoder) uint64() uint64 { return binary.BigEndian.Uint64(d.next(8)) }
+
+// count reads an element ID or count, one byte in Codec 8 and two in 8E.
+func (d *decoder) count(extended bool) uint16 {
+	if extended {
+		return d.uint16()
+	}
+	return uint16(d.byte())
+}
+
+func (d *decoder) record(extended bool) Record {
+	r := Record{
+		Timestamp:  time.UnixMilli(int64(d.uint64())).UTC(),
+		Priority:   d.byte(),
+		Longitude:  float64(int32(d.uint32())) / 1e7,
+		Latitude:   float64(int32(d.uint32())) / 1e7,
+		Altitude:   int16(d.uint16()),
+		Angle:      d.uint16(),
+		Satellites: d.byte(),
+		Speed:      d.uint16(),
+		EventIOID:  d.count(extended),
+	}
+
+	total := int(d.count(extended))
+	r.IO = make(map[uint16][]byte, total)
+	read := 0
+	// Elements are grouped by value size: 1, 2, 4 and 8 bytes.
+	for _, size := range []int{1, 2, 4, 8} {
+		n := int(d.count(extended))
+		for i := 0; i < n && d.err == nil; i++ {
+			id := d.count(extended)
+			r.IO[id] = d.next(size)
+		}
+		read += n
+	}
+	// Codec 8E adds a group of variable-length elements.
+	if extended {
+		n := int(d.uint16())
+		for i := 0; i < n && d.err == nil; i++ {
+			id := d.uint16()
+			r.IO[id] = d.next(int(d.uint16()))
+		}
+		read += n
+	}
+	if d.err == nil && read != total {
+		d.err = errors.New("teltonika: IO element counts do not match")
+	}
+	return r
+}
@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateAddress is returned when a webhook endpoint is on a loopback,
+// private, link-local or otherwise non-public address.
+var ErrPrivateAddress = errors.New("webhook endpoint is not a public address")
+
+// nonPublic are the ranges beyond the loopback, private, link-local and
+// multicast ones that IsPublic rejects: "this network" and the carrier-grade
+// NAT space, which cloud providers also use internally.
+var nonPublic = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// IsPublic reports whether webhooks may be sent to ip. Loopback, private,
+// link-local (including the 169.254.169.254 metadata endpoint), unspecified
+// and multicast addresses are refused.
+func IsPublic(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+	for _, p := range nonPublic {
+		if p.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsPublicHost reports whether a URL host, without its port, may receive
+// webhooks as far as can be told without resolving it: IP literals must be
+// public and localhost names are refused. Other names are checked when
+// dialing.
+func IsPublicHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if ip, err := netip.ParseAddr(strings.Trim(host, "[]")); err == nil {
+		return IsPublic(ip)
+	}
+	return true
+}
+
+// Control refuses connections to non-public addresses. Set as the Control
+// of a net.Dialer, it checks the address a host name resolved to right before
+// connecting, so that DNS cannot point a validated endpoint inside the
+// network.
+func Control(network, address string, _ syscall.RawConn) error {
+	addr, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !IsPublic(addr.Addr()) {
+		return ErrPrivateAddress
+	}
+	return nil
+}
+
+// NewClient returns an HTTP client for sending webhooks that gives up on an
+// attempt after timeout and only connects to public addresses. It does not go
+// through the environment's proxy, whose address would be checked instead of
+// the endpoint's.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   Control,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
@@ -0,0 +1,74 @@
+// Package webhook signs and verifies the payloads of outbound webhooks, and
+// keeps them from being sent to addresses inside the network.
+//
+// The signature header has the form "t=<unix seconds>,v1=<hex>", where the
+// hex digest is the HMAC-SHA256 of "<unix seconds>.<payload>" keyed with the
+// subscription secret. Including the timestamp lets receivers reject
+// replayed deliveries.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers set on every delivery.
+const (
+	SignatureHeader = "X-Fleet-Signature"
+	EventHeader     = "X-Fleet-Event"
+	DeliveryHeader  = "X-Fleet-Delivery"
+)
+
+var (
+	// ErrInvalidSignature is returned when a signature header is malformed or
+	// does not match the payload.
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrSignatureExpired is returned when a signature is older than the
+	// tolerated age.
+	ErrSignatureExpired = errors.New("webhook signature expired")
+)
+
+// Sign returns the signature header value for payload sent at timestamp.
+func Sign(secret string, timestamp time.Time, payload []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + ts + ",v1=" + digest(secret, ts, payload)
+}
+
+// Verify checks a signature header against payload. Signatures made more than
+// tolerance before now are rejected; zero disables the check.
+func Verify(secret, header string, payload []byte, now time.Time, tolerance time.Duration) error {
+	var ts, sig string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			sig = value
+		}
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || sig == "" {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(sig), []byte(digest(secret, ts, payload))) {
+		return ErrInvalidSignature
+	}
+	if tolerance > 0 && now.Sub(time.Unix(unix, 0)) > tolerance {
+		return ErrSignatureExpired
+	}
+	return nil
+}
+
+func digest(secret, ts string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,219 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	handler "github.com/AnjuRKrishnan/fleet-tracker/internal/handler"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// MockAlertService is a mock type for AlertService
+type MockAlertService struct {
+	mock.Mock
+}
+
+func (m *MockAlertService) CreateRule(ctx context.Context, rule domain.AlertRule) (*domain.AlertRule, error) {
+	args := m.Called(ctx, rule)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AlertRule), args.Error(1)
+}
+
+func (m *MockAlertService) GetRule(ctx context.Context, id uuid.UUID) (*domain.AlertRule, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AlertRule), args.Error(1)
+}
+
+func (m *MockAlertService) ListRules(ctx context.Context) ([]domain.AlertRule, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.AlertRule), args.Error(1)
+}
+
+func (m *MockAlertService) UpdateRule(ctx context.Context, rule domain.AlertRule) (*domain.AlertRule, error) {
+	args := m.Called(ctx, rule)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AlertRule), args.Error(1)
+}
+
+func (m *MockAlertService) DeleteRule(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockAlertService) GetAlert(ctx context.Context, id uuid.UUID) (*domain.Alert, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Alert), args.Error(1)
+}
+
+func (m *MockAlertService) ListAlerts(ctx context.Context, query domain.AlertQuery) ([]domain.Alert, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Alert), args.Error(1)
+}
+
+func (m *MockAlertService) AcknowledgeAlert(ctx context.Context, id uuid.UUID) (*domain.Alert, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Alert), args.Error(1)
+}
+
+func (m *MockAlertService) ResolveAlert(ctx context.Context, id uuid.UUID) (*domain.Alert, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Alert), args.Error(1)
+}
+
+func newAlertRouter(m *MockAlertService) http.Handler {
+	h := handler.NewAlertHandler(m, zap.NewNop())
+	r := chi.NewRouter()
+	r.Post("/alert-rules", h.CreateRule)
+	r.Get("/alert-rules/{id}", h.GetRule)
+	r.Get("/alerts", h.ListAlerts)
+	r.Get("/alerts/{id}", h.GetAlert)
+	r.Post("/alerts/{id}/acknowledge", h.AcknowledgeAlert)
+	r.Post("/alerts/{id}/resolve", h.ResolveAlert)
+	return r
+}
+
+func TestAlertHandler_CreateRule(t *testing.T) {
+	t.Run("Enabled by default", func(t *testing.T) {
+		m := new(MockAlertService)
+		m.On("CreateRule", mock.Anything, domain.AlertRule{
+			Name:            "City limit",
+			Kind:            domain.AlertSpeeding,
+			SpeedLimit:      80,
+			DurationSeconds: 30,
+			Enabled:         true,
+		}).Return(&domain.AlertRule{ID: uuid.New(), Name: "City limit", Enabled: true}, nil)
+
+		body := `{"name":"City limit","kind":"speeding","speed_limit":80,"duration_seconds":30}`
+		rr := httptest.NewRecorder()
+		newAlertRouter(m).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/alert-rules", strings.NewReader(body)))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("Validation Error", func(t *testing.T) {
+		m := new(MockAlertService)
+		m.On("CreateRule", mock.Anything, mock.Anything).Return(nil, &domain.ValidationError{
+			Fields: []domain.FieldError{{Field: "speed_limit", Message: "must be greater than 0"}},
+		})
+
+		rr := httptest.NewRecorder()
+		newAlertRouter(m).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/alert-rules", strings.NewReader(`{"name":"x","kind":"speeding"}`)))
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+
+	t.Run("Get Not Found", func(t *testing.T) {
+		id := uuid.New()
+		m := new(MockAlertService)
+		m.On("GetRule", mock.Anything, id).Return(nil, domain.ErrNotFound)
+
+		rr := httptest.NewRecorder()
+		newAlertRouter(m).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/alert-rules/"+id.String(), nil))
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestAlertHandler_ListAlerts(t *testing.T) {
+	vehicleID := uuid.New()
+	alerts := []domain.Alert{{ID: uuid.New(), VehicleID: vehicleID, Kind: domain.AlertSpeeding, State: domain.AlertOpen}}
+
+	t.Run("Filters", func(t *testing.T) {
+		m := new(MockAlertService)
+		m.On("ListAlerts", mock.Anything, domain.AlertQuery{
+			VehicleID: &vehicleID,
+			State:     domain.AlertOpen,
+			From:      time.Date(2025, 6, 17, 0, 0, 0, 0, time.UTC),
+			Limit:     20,
+		}).Return(alerts, nil)
+
+		url := "/alerts?vehicle_id=" + vehicleID.String() + "&state=open&from=2025-06-17T00:00:00Z&limit=20"
+		rr := httptest.NewRecorder()
+		newAlertRouter(m).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, url, nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var got []domain.Alert
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+		assert.Len(t, got, 1)
+	})
+
+	t.Run("Invalid state", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		newAlertRouter(new(MockAlertService)).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/alerts?state=closed", nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, "Invalid state\n", rr.Body.String())
+	})
+
+	t.Run("Invalid rule_id", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		newAlertRouter(new(MockAlertService)).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/alerts?rule_id=nope", nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, "Invalid rule_id\n", rr.Body.String())
+	})
+}
+
+func TestAlertHandler_Transitions(t *testing.T) {
+	id := uuid.New()
+
+	t.Run("Acknowledge", func(t *testing.T) {
+		m := new(MockAlertService)
+		m.On("AcknowledgeAlert", mock.Anything, id).Return(&domain.Alert{ID: id, State: domain.AlertAcknowledged}, nil)
+
+		rr := httptest.NewRecorder()
+		newAlertRouter(m).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/alerts/"+id.String()+"/acknowledge", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var got domain.Alert
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+		assert.Equal(t, domain.AlertAcknowledged, got.State)
+	})
+
+	t.Run("Acknowledge resolved", func(t *testing.T) {
+		m := new(MockAlertService)
+		m.On("AcknowledgeAlert", mock.Anything, id).Return(nil, domain.ErrAlertResolved)
+
+		rr := httptest.NewRecorder()
+		newAlertRouter(m).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/alerts/"+id.String()+"/acknowledge", nil))
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("Resolve unknown", func(t *testing.T) {
+		m := new(MockAlertService)
+		m.On("ResolveAlert", mock.Anything, id).Return(nil, domain.ErrNotFound)
+
+		rr := httptest.NewRecorder()
+		newAlertRouter(m).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/alerts/"+id.String()+"/resolve", nil))
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
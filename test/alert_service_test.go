@@ -0,0 +1,396 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// MockAlertRepository is a mock type for the AlertRepository
+type MockAlertRepository struct {
+	mock.Mock
+}
+
+func (m *MockAlertRepository) CreateAlertRule(ctx context.Context, rule domain.AlertRule) (*domain.AlertRule, error) {
+	args := m.Called(ctx, rule)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AlertRule), args.Error(1)
+}
+
+func (m *MockAlertRepository) GetAlertRule(ctx context.Context, id uuid.UUID) (*domain.AlertRule, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AlertRule), args.Error(1)
+}
+
+func (m *MockAlertRepository) ListAlertRules(ctx context.Context) ([]domain.AlertRule, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.AlertRule), args.Error(1)
+}
+
+func (m *MockAlertRepository) UpdateAlertRule(ctx context.Context, rule domain.AlertRule) (*domain.AlertRule, error) {
+	args := m.Called(ctx, rule)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AlertRule), args.Error(1)
+}
+
+func (m *MockAlertRepository) DeleteAlertRule(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockAlertRepository) OpenAlert(ctx context.Context, alert domain.Alert) (*domain.Alert, error) {
+	args := m.Called(ctx, alert)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Alert), args.Error(1)
+}
+
+func (m *MockAlertRepository) ResolveOpenAlerts(ctx context.Context, ruleID, vehicleID uuid.UUID, at time.Time) ([]domain.Alert, error) {
+	args := m.Called(ctx, ruleID, vehicleID, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Alert), args.Error(1)
+}
+
+func (m *MockAlertRepository) ResolveRuleAlerts(ctx context.Context, ruleID uuid.UUID, at time.Time) ([]domain.Alert, error) {
+	args := m.Called(ctx, ruleID, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Alert), args.Error(1)
+}
+
+func (m *MockAlertRepository) ResolveVehicleAlerts(ctx context.Context, vehicleID uuid.UUID, at time.Time) ([]domain.Alert, error) {
+	args := m.Called(ctx, vehicleID, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Alert), args.Error(1)
+}
+
+func (m *MockAlertRepository) OpenSilenceAlerts(ctx context.Context, rule domain.AlertRule, message string, silentSince, at time.Time) ([]domain.Alert, error) {
+	args := m.Called(ctx, rule, message, silentSince, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Alert), args.Error(1)
+}
+
+func (m *MockAlertRepository) ResolveSilenceAlerts(ctx context.Context, ruleID uuid.UUID, silentSince, at time.Time) ([]domain.Alert, error) {
+	args := m.Called(ctx, ruleID, silentSince, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Alert), args.Error(1)
+}
+
+func (m *MockAlertRepository) GetAlert(ctx context.Context, id uuid.UUID) (*domain.Alert, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Alert), args.Error(1)
+}
+
+func (m *MockAlertRepository) ListAlerts(ctx context.Context, query domain.AlertQuery) ([]domain.Alert, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Alert), args.Error(1)
+}
+
+func (m *MockAlertRepository) AcknowledgeAlert(ctx context.Context, id uuid.UUID, at time.Time) (*domain.Alert, error) {
+	args := m.Called(ctx, id, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Alert), args.Error(1)
+}
+
+func (m *MockAlertRepository) ResolveAlert(ctx context.Context, id uuid.UUID, at time.Time) (*domain.Alert, error) {
+	args := m.Called(ctx, id, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Alert), args.Error(1)
+}
+
+func TestAlertSchedule_Allows(t *testing.T) {
+	office := domain.AlertSchedule{Start: "08:00", End: "18:00", Days: []int{1, 2, 3, 4, 5}, Timezone: "Asia/Dubai"}
+	// Tuesday 2025-06-17; Dubai is UTC+4.
+	assert.True(t, office.Allows(time.Date(2025, 6, 17, 5, 0, 0, 0, time.UTC)))   // 09:00 local
+	assert.False(t, office.Allows(time.Date(2025, 6, 17, 15, 0, 0, 0, time.UTC))) // 19:00 local
+	assert.False(t, office.Allows(time.Date(2025, 6, 15, 5, 0, 0, 0, time.UTC)))  // Sunday
+
+	night := domain.AlertSchedule{Start: "22:00", End: "06:00"}
+	assert.True(t, night.Allows(time.Date(2025, 6, 17, 23, 0, 0, 0, time.UTC)))
+	assert.True(t, night.Allows(time.Date(2025, 6, 17, 5, 59, 0, 0, time.UTC)))
+	assert.False(t, night.Allows(time.Date(2025, 6, 17, 12, 0, 0, 0, time.UTC)))
+}
+
+func TestAlertSchedule_Compile(t *testing.T) {
+	office, err := domain.AlertSchedule{Start: "08:00", End: "18:00", Days: []int{1, 2, 3, 4, 5}, Timezone: "Asia/Dubai"}.Compile()
+	require.NoError(t, err)
+	assert.True(t, office.Allows(time.Date(2025, 6, 17, 5, 0, 0, 0, time.UTC)))
+	assert.False(t, office.Allows(time.Date(2025, 6, 17, 15, 0, 0, 0, time.UTC)))
+
+	_, err = domain.AlertSchedule{Start: "08:00", End: "18:00", Timezone: "Mars/Olympus"}.Compile()
+	assert.Error(t, err)
+
+	var invalid *domain.CompiledSchedule
+	assert.False(t, invalid.Allows(time.Date(2025, 6, 17, 5, 0, 0, 0, time.UTC)))
+}
+
+func TestAlertRule_Validate(t *testing.T) {
+	valid := domain.AlertRule{Name: "Highway limit", Kind: domain.AlertSpeeding, SpeedLimit: 120, DurationSeconds: 30}
+	assert.NoError(t, valid.Validate())
+
+	invalid := domain.AlertRule{
+		Name:     "After hours",
+		Kind:     domain.AlertOutsideHours,
+		Schedule: &domain.AlertSchedule{Start: "8am", End: "18:00", Days: []int{7}, Timezone: "Mars/Olympus"},
+	}
+	var verr *domain.ValidationError
+	if assert.ErrorAs(t, invalid.Validate(), &verr) {
+		var fields []string
+		for _, f := range verr.Fields {
+			fields = append(fields, f.Field)
+		}
+		assert.ElementsMatch(t, []string{"schedule.start", "schedule.days[0]", "schedule.timezone"}, fields)
+	}
+
+	var noUpdate *domain.ValidationError
+	assert.ErrorAs(t, domain.AlertRule{Name: "Silent", Kind: domain.AlertNoUpdate}.Validate(), &noUpdate)
+}
+
+func TestAlertService_Process(t *testing.T) {
+	vehicleID := uuid.New()
+	start := time.Date(2025, 6, 17, 9, 0, 0, 0, time.UTC)
+	speeding := domain.AlertRule{
+		ID:              uuid.New(),
+		Name:            "City limit",
+		Kind:            domain.AlertSpeeding,
+		SpeedLimit:      80,
+		DurationSeconds: 30,
+		Enabled:         true,
+	}
+
+	t.Run("Speeding opens after the duration and resolves", func(t *testing.T) {
+		repo := new(MockAlertRepository)
+		repo.On("ListAlertRules", mock.Anything).Return([]domain.AlertRule{speeding}, nil)
+		repo.On("ResolveOpenAlerts", mock.Anything, speeding.ID, vehicleID, start).Return([]domain.Alert{}, nil).Once()
+		repo.On("OpenAlert", mock.Anything, mock.MatchedBy(func(a domain.Alert) bool {
+			return a.RuleID == speeding.ID && a.Value == 95 && a.OpenedAt.Equal(start.Add(40*time.Second))
+		})).Return(&domain.Alert{}, nil).Once()
+		repo.On("ResolveOpenAlerts", mock.Anything, speeding.ID, vehicleID, start.Add(time.Minute)).Return([]domain.Alert{}, nil).Once()
+
+		s := services.NewAlertService(repo, time.Minute, zap.NewNop())
+		ctx := context.Background()
+		readings := []domain.VehicleStatus{
+			statusAt(start, 0, 0, 0, 50),
+			statusAt(start, 10*time.Second, 0, 0, 90),
+			statusAt(start, 20*time.Second, 0, 0, 100),
+			statusAt(start, 40*time.Second, 0, 0, 95),
+			statusAt(start, 50*time.Second, 0, 0, 99),
+			statusAt(start, time.Minute, 0, 0, 60),
+			statusAt(start, 70*time.Second, 0, 0, 40),
+		}
+		for _, r := range readings {
+			assert.NoError(t, s.Process(ctx, vehicleID, r))
+		}
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Rules of other vehicles are skipped", func(t *testing.T) {
+		other := uuid.New()
+		rule := speeding
+		rule.VehicleID = &other
+
+		repo := new(MockAlertRepository)
+		repo.On("ListAlertRules", mock.Anything).Return([]domain.AlertRule{rule}, nil)
+
+		s := services.NewAlertService(repo, time.Minute, zap.NewNop())
+		assert.NoError(t, s.Process(context.Background(), vehicleID, statusAt(start, 0, 0, 0, 150)))
+		repo.AssertNotCalled(t, "OpenAlert", mock.Anything, mock.Anything)
+		repo.AssertNotCalled(t, "ResolveOpenAlerts", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Vehicles are evaluated while another waits on the store", func(t *testing.T) {
+		slow, fast := uuid.New(), uuid.New()
+		release := make(chan struct{})
+
+		repo := new(MockAlertRepository)
+		repo.On("ListAlertRules", mock.Anything).Return([]domain.AlertRule{speeding}, nil)
+		repo.On("ResolveOpenAlerts", mock.Anything, speeding.ID, slow, start).
+			Run(func(mock.Arguments) { <-release }).Return([]domain.Alert{}, nil).Once()
+		repo.On("ResolveOpenAlerts", mock.Anything, speeding.ID, fast, start).Return([]domain.Alert{}, nil).Once()
+
+		s := services.NewAlertService(repo, time.Minute, zap.NewNop())
+		ctx := context.Background()
+		done := make(chan error)
+		go func() { done <- s.Process(ctx, slow, statusAt(start, 0, 0, 0, 50)) }()
+		assert.Eventually(t, func() bool {
+			return s.Process(ctx, fast, statusAt(start, 0, 0, 0, 50)) == nil
+		}, 5*time.Second, 10*time.Millisecond)
+
+		close(release)
+		assert.NoError(t, <-done)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Outside hours", func(t *testing.T) {
+		rule := domain.AlertRule{
+			ID:       uuid.New(),
+			Name:     "Office hours",
+			Kind:     domain.AlertOutsideHours,
+			Schedule: &domain.AlertSchedule{Start: "08:00", End: "18:00"},
+			Enabled:  true,
+		}
+		night := time.Date(2025, 6, 17, 23, 0, 0, 0, time.UTC)
+
+		repo := new(MockAlertRepository)
+		repo.On("ListAlertRules", mock.Anything).Return([]domain.AlertRule{rule}, nil)
+		repo.On("ResolveOpenAlerts", mock.Anything, rule.ID, vehicleID, night).Return([]domain.Alert{}, nil).Once()
+		repo.On("OpenAlert", mock.Anything, mock.MatchedBy(func(a domain.Alert) bool {
+			return a.Kind == domain.AlertOutsideHours
+		})).Return(&domain.Alert{}, nil).Once()
+
+		s := services.NewAlertService(repo, time.Minute, zap.NewNop())
+		ctx := context.Background()
+		assert.NoError(t, s.Process(ctx, vehicleID, statusAt(night, 0, 0, 0, 0)))
+		assert.NoError(t, s.Process(ctx, vehicleID, statusAt(night, time.Minute, 0, 0, 30)))
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestAlertService_Sweep(t *testing.T) {
+	now := time.Date(2025, 6, 17, 9, 0, 0, 0, time.UTC)
+	rule := domain.AlertRule{ID: uuid.New(), Name: "Silent", Kind: domain.AlertNoUpdate, DurationSeconds: 900, Enabled: true}
+	disabled := domain.AlertRule{ID: uuid.New(), Name: "Off", Kind: domain.AlertNoUpdate, DurationSeconds: 60}
+
+	repo := new(MockAlertRepository)
+	repo.On("ListAlertRules", mock.Anything).Return([]domain.AlertRule{rule, disabled}, nil)
+	silentSince := now.Add(-15 * time.Minute)
+	repo.On("ResolveSilenceAlerts", mock.Anything, rule.ID, silentSince, now).Return([]domain.Alert{}, nil).Once()
+	opened := domain.Alert{ID: uuid.New(), RuleID: rule.ID, VehicleID: uuid.New(), Kind: domain.AlertNoUpdate, OpenedAt: now}
+	repo.On("OpenSilenceAlerts", mock.Anything, rule, "no update for more than 15m0s", silentSince, now).Return([]domain.Alert{opened}, nil).Once()
+
+	events := &recordingPublisher{}
+	s := services.NewAlertService(repo, time.Minute, zap.NewNop(), services.WithAlertEvents(events))
+	s.Sweep(context.Background(), now)
+	repo.AssertExpectations(t)
+	assert.Equal(t, []domain.EventType{domain.EventAlertOpened}, events.types())
+	assert.Equal(t, opened.VehicleID, events.events[0].VehicleID)
+}
+
+func TestAlertService_RuleChanges(t *testing.T) {
+	rule := domain.AlertRule{ID: uuid.New(), Name: "Silent", Kind: domain.AlertNoUpdate, DurationSeconds: 900, Enabled: true}
+	open := domain.Alert{ID: uuid.New(), RuleID: rule.ID, VehicleID: uuid.New(), Kind: domain.AlertNoUpdate}
+
+	t.Run("Disabling resolves open alerts", func(t *testing.T) {
+		disabled := rule
+		disabled.Enabled = false
+
+		repo := new(MockAlertRepository)
+		repo.On("UpdateAlertRule", mock.Anything, disabled).Return(&disabled, nil).Once()
+		repo.On("ResolveRuleAlerts", mock.Anything, rule.ID, mock.Anything).Return([]domain.Alert{open}, nil).Once()
+
+		events := &recordingPublisher{}
+		s := services.NewAlertService(repo, time.Minute, zap.NewNop(), services.WithAlertEvents(events))
+		_, err := s.UpdateRule(context.Background(), disabled)
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+		assert.Equal(t, []domain.EventType{domain.EventAlertResolved}, events.types())
+	})
+
+	t.Run("Enabled rules keep their alerts", func(t *testing.T) {
+		repo := new(MockAlertRepository)
+		repo.On("UpdateAlertRule", mock.Anything, rule).Return(&rule, nil).Once()
+
+		s := services.NewAlertService(repo, time.Minute, zap.NewNop())
+		_, err := s.UpdateRule(context.Background(), rule)
+		assert.NoError(t, err)
+		repo.AssertNotCalled(t, "ResolveRuleAlerts", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Deleting resolves open alerts", func(t *testing.T) {
+		repo := new(MockAlertRepository)
+		repo.On("ResolveRuleAlerts", mock.Anything, rule.ID, mock.Anything).Return([]domain.Alert{open}, nil).Once()
+		repo.On("DeleteAlertRule", mock.Anything, rule.ID).Return(nil).Once()
+
+		events := &recordingPublisher{}
+		s := services.NewAlertService(repo, time.Minute, zap.NewNop(), services.WithAlertEvents(events))
+		assert.NoError(t, s.DeleteRule(context.Background(), rule.ID))
+		repo.AssertExpectations(t)
+		assert.Equal(t, []domain.EventType{domain.EventAlertResolved}, events.types())
+	})
+
+	t.Run("Archiving a vehicle resolves its alerts", func(t *testing.T) {
+		repo := new(MockAlertRepository)
+		repo.On("ResolveVehicleAlerts", mock.Anything, open.VehicleID, mock.Anything).Return([]domain.Alert{open}, nil).Once()
+
+		events := &recordingPublisher{}
+		s := services.NewAlertService(repo, time.Minute, zap.NewNop(), services.WithAlertEvents(events))
+		assert.NoError(t, s.VehicleArchived(context.Background(), open.VehicleID))
+		repo.AssertExpectations(t)
+		assert.Equal(t, []domain.EventType{domain.EventAlertResolved}, events.types())
+	})
+}
+
+func TestAlertService_AcknowledgeAlert(t *testing.T) {
+	id := uuid.New()
+
+	t.Run("Open alert", func(t *testing.T) {
+		repo := new(MockAlertRepository)
+		repo.On("AcknowledgeAlert", mock.Anything, id, mock.AnythingOfType("time.Time")).
+			Return(&domain.Alert{ID: id, State: domain.AlertAcknowledged}, nil)
+
+		events := &recordingPublisher{}
+		s := services.NewAlertService(repo, time.Minute, zap.NewNop(), services.WithAlertEvents(events))
+		alert, err := s.AcknowledgeAlert(context.Background(), id)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.AlertAcknowledged, alert.State)
+		assert.Equal(t, []domain.EventType{domain.EventAlertAcknowledged}, events.types())
+	})
+
+	t.Run("Resolved alert", func(t *testing.T) {
+		repo := new(MockAlertRepository)
+		repo.On("AcknowledgeAlert", mock.Anything, id, mock.AnythingOfType("time.Time")).Return(nil, domain.ErrNotFound)
+		repo.On("GetAlert", mock.Anything, id).Return(&domain.Alert{ID: id, State: domain.AlertResolved}, nil)
+
+		s := services.NewAlertService(repo, time.Minute, zap.NewNop())
+		_, err := s.AcknowledgeAlert(context.Background(), id)
+		assert.ErrorIs(t, err, domain.ErrAlertResolved)
+	})
+
+	t.Run("Unknown alert", func(t *testing.T) {
+		repo := new(MockAlertRepository)
+		repo.On("AcknowledgeAlert", mock.Anything, id, mock.AnythingOfType("time.Time")).Return(nil, domain.ErrNotFound)
+		repo.On("GetAlert", mock.Anything, id).Return(nil, domain.ErrNotFound)
+
+		s := services.NewAlertService(repo, time.Minute, zap.NewNop())
+		_, err := s.AcknowledgeAlert(context.Background(), id)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
@@ -0,0 +1,158 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	handler "github.com/AnjuRKrishnan/fleet-tracker/internal/handler"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// MockDeviceService is a mock type for DeviceService
+type MockDeviceService struct {
+	mock.Mock
+}
+
+func (m *MockDeviceService) CreateDevice(ctx context.Context, device domain.Device) (*domain.Device, error) {
+	args := m.Called(ctx, device)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Device), args.Error(1)
+}
+
+func (m *MockDeviceService) GetDevice(ctx context.Context, id uuid.UUID) (*domain.Device, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Device), args.Error(1)
+}
+
+func (m *MockDeviceService) ListDevices(ctx context.Context) ([]domain.Device, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Device), args.Error(1)
+}
+
+func (m *MockDeviceService) UpdateDevice(ctx context.Context, device domain.Device) (*domain.Device, error) {
+	args := m.Called(ctx, device)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Device), args.Error(1)
+}
+
+func (m *MockDeviceService) RotateDeviceToken(ctx context.Context, id uuid.UUID) (*domain.Device, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Device), args.Error(1)
+}
+
+func (m *MockDeviceService) DeleteDevice(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockDeviceService) ResolveDevice(ctx context.Context, identifier string) (*domain.Device, error) {
+	args := m.Called(ctx, identifier)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Device), args.Error(1)
+}
+
+func (m *MockDeviceService) AuthenticateDevice(ctx context.Context, identifier, token string) (*domain.Device, error) {
+	args := m.Called(ctx, identifier, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Device), args.Error(1)
+}
+
+func newDeviceRouter(m *MockDeviceService) http.Handler {
+	h := handler.NewDeviceHandler(m, zap.NewNop())
+	r := chi.NewRouter()
+	r.Post("/devices", h.Create)
+	r.Get("/devices/{id}", h.Get)
+	r.Put("/devices/{id}", h.Update)
+	r.Delete("/devices/{id}", h.Delete)
+	r.Post("/devices/{id}/token", h.RotateToken)
+	return r
+}
+
+func TestDeviceHandler(t *testing.T) {
+	vehicleID := uuid.New()
+	device := domain.Device{Identifier: "356307042441013", VehicleID: vehicleID, PlateNumber: "KL01AB1234"}
+
+	t.Run("Create", func(t *testing.T) {
+		m := new(MockDeviceService)
+		m.On("CreateDevice", mock.Anything, device).Return(&domain.Device{ID: uuid.New(), Identifier: device.Identifier, VehicleID: vehicleID}, nil)
+
+		body := `{"identifier":"356307042441013","vehicle_id":"` + vehicleID.String() + `","plate_number":"KL01AB1234"}`
+		rr := httptest.NewRecorder()
+		newDeviceRouter(m).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/devices", strings.NewReader(body)))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		var got domain.Device
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+		assert.Equal(t, vehicleID, got.VehicleID)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("Identifier taken", func(t *testing.T) {
+		m := new(MockDeviceService)
+		m.On("CreateDevice", mock.Anything, device).Return(nil, domain.ErrDeviceExists)
+
+		body := `{"identifier":"356307042441013","vehicle_id":"` + vehicleID.String() + `","plate_number":"KL01AB1234"}`
+		rr := httptest.NewRecorder()
+		newDeviceRouter(m).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/devices", strings.NewReader(body)))
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("Validation Error", func(t *testing.T) {
+		m := new(MockDeviceService)
+		m.On("UpdateDevice", mock.Anything, mock.Anything).Return(nil, domain.Device{}.Validate())
+
+		rr := httptest.NewRecorder()
+		newDeviceRouter(m).ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/devices/"+uuid.NewString(), strings.NewReader(`{}`)))
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"field":"identifier"`)
+		assert.Contains(t, rr.Body.String(), `"field":"vehicle_id"`)
+	})
+
+	t.Run("Delete Not Found", func(t *testing.T) {
+		id := uuid.New()
+		m := new(MockDeviceService)
+		m.On("DeleteDevice", mock.Anything, id).Return(domain.ErrNotFound)
+
+		rr := httptest.NewRecorder()
+		newDeviceRouter(m).ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/devices/"+id.String(), nil))
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Rotate token", func(t *testing.T) {
+		id := uuid.New()
+		m := new(MockDeviceService)
+		m.On("RotateDeviceToken", mock.Anything, id).Return(&domain.Device{ID: id, Token: "new-token", TokenHash: []byte{1}}, nil)
+
+		rr := httptest.NewRecorder()
+		newDeviceRouter(m).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/devices/"+id.String()+"/token", nil))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"token":"new-token"`)
+		assert.NotContains(t, rr.Body.String(), "token_hash")
+	})
+}
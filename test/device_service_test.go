@@ -0,0 +1,128 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockDeviceRepository is a mock type for DeviceRepository
+type MockDeviceRepository struct {
+	mock.Mock
+}
+
+func (m *MockDeviceRepository) CreateDevice(ctx context.Context, device domain.Device) (*domain.Device, error) {
+	args := m.Called(ctx, device)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Device), args.Error(1)
+}
+
+func (m *MockDeviceRepository) GetDevice(ctx context.Context, id uuid.UUID) (*domain.Device, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Device), args.Error(1)
+}
+
+func (m *MockDeviceRepository) GetDeviceByIdentifier(ctx context.Context, identifier string) (*domain.Device, error) {
+	args := m.Called(ctx, identifier)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Device), args.Error(1)
+}
+
+func (m *MockDeviceRepository) ListDevices(ctx context.Context) ([]domain.Device, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Device), args.Error(1)
+}
+
+func (m *MockDeviceRepository) UpdateDevice(ctx context.Context, device domain.Device) (*domain.Device, error) {
+	args := m.Called(ctx, device)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Device), args.Error(1)
+}
+
+func (m *MockDeviceRepository) SetDeviceTokenHash(ctx context.Context, id uuid.UUID, tokenHash []byte) (*domain.Device, error) {
+	args := m.Called(ctx, id, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Device), args.Error(1)
+}
+
+func (m *MockDeviceRepository) DeleteDevice(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestDeviceService_Tokens(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockDeviceRepository)
+	service := services.NewDeviceService(repo)
+
+	// The repository stores whatever hash the service hands it.
+	var stored domain.Device
+	repo.On("CreateDevice", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(domain.Device)
+		stored.ID = uuid.New()
+	}).Return(&stored, nil).Once()
+
+	created, err := service.CreateDevice(ctx, domain.Device{Identifier: "phone-7", VehicleID: uuid.New()})
+	require.NoError(t, err)
+	assert.Len(t, created.Token, 64)
+	assert.NotEmpty(t, stored.TokenHash)
+	assert.NotEqual(t, []byte(created.Token), stored.TokenHash)
+
+	repo.On("GetDeviceByIdentifier", mock.Anything, "phone-7").Return(&stored, nil)
+	repo.On("GetDeviceByIdentifier", mock.Anything, "phone-8").Return(nil, domain.ErrNotFound)
+
+	device, err := service.AuthenticateDevice(ctx, "phone-7", created.Token)
+	require.NoError(t, err)
+	assert.Equal(t, stored.ID, device.ID)
+
+	_, err = service.AuthenticateDevice(ctx, "phone-7", "wrong")
+	assert.ErrorIs(t, err, domain.ErrInvalidDeviceToken)
+	_, err = service.AuthenticateDevice(ctx, "phone-7", "")
+	assert.ErrorIs(t, err, domain.ErrInvalidDeviceToken)
+	_, err = service.AuthenticateDevice(ctx, "phone-8", created.Token)
+	assert.ErrorIs(t, err, domain.ErrInvalidDeviceToken)
+
+	t.Run("Rotate", func(t *testing.T) {
+		var newHash []byte
+		repo.On("SetDeviceTokenHash", mock.Anything, stored.ID, mock.Anything).Run(func(args mock.Arguments) {
+			newHash = args.Get(2).([]byte)
+		}).Return(&domain.Device{ID: stored.ID}, nil).Once()
+
+		rotated, err := service.RotateDeviceToken(ctx, stored.ID)
+		require.NoError(t, err)
+		assert.NotEqual(t, created.Token, rotated.Token)
+		assert.NotEqual(t, stored.TokenHash, newHash)
+
+		stored.TokenHash = newHash
+		_, err = service.AuthenticateDevice(ctx, "phone-7", created.Token)
+		assert.ErrorIs(t, err, domain.ErrInvalidDeviceToken)
+		_, err = service.AuthenticateDevice(ctx, "phone-7", rotated.Token)
+		assert.NoError(t, err)
+	})
+
+	t.Run("No token issued", func(t *testing.T) {
+		repo.On("GetDeviceByIdentifier", mock.Anything, "legacy").Return(&domain.Device{Identifier: "legacy"}, nil)
+		_, err := service.AuthenticateDevice(ctx, "legacy", "")
+		assert.ErrorIs(t, err, domain.ErrInvalidDeviceToken)
+	})
+}
@@ -0,0 +1,248 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	handler "github.com/AnjuRKrishnan/fleet-tracker/internal/handler"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// MockGeofenceService is a mock type for GeofenceService
+type MockGeofenceService struct {
+	mock.Mock
+}
+
+func (m *MockGeofenceService) CreateGeofence(ctx context.Context, fence domain.Geofence) (*domain.Geofence, error) {
+	args := m.Called(ctx, fence)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Geofence), args.Error(1)
+}
+
+func (m *MockGeofenceService) GetGeofence(ctx context.Context, id uuid.UUID) (*domain.Geofence, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Geofence), args.Error(1)
+}
+
+func (m *MockGeofenceService) ListGeofences(ctx context.Context) ([]domain.Geofence, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Geofence), args.Error(1)
+}
+
+func (m *MockGeofenceService) UpdateGeofence(ctx context.Context, fence domain.Geofence) (*domain.Geofence, error) {
+	args := m.Called(ctx, fence)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Geofence), args.Error(1)
+}
+
+func (m *MockGeofenceService) DeleteGeofence(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockGeofenceService) ListEvents(ctx context.Context, query domain.GeofenceEventQuery) ([]domain.GeofenceEvent, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.GeofenceEvent), args.Error(1)
+}
+
+func newGeofenceRouter(m *MockGeofenceService) http.Handler {
+	h := handler.NewGeofenceHandler(m, zap.NewNop())
+	r := chi.NewRouter()
+	r.Get("/vehicle/geofence-events", h.GetVehicleEvents)
+	r.Post("/geofences", h.Create)
+	r.Get("/geofences", h.List)
+	r.Get("/geofences/{id}", h.Get)
+	r.Put("/geofences/{id}", h.Update)
+	r.Delete("/geofences/{id}", h.Delete)
+	r.Get("/geofences/{id}/events", h.GetFenceEvents)
+	return r
+}
+
+func TestGeofenceHandler_Create(t *testing.T) {
+	fenceID := uuid.New()
+
+	tests := []struct {
+		name               string
+		body               string
+		setupMock          func(m *MockGeofenceService)
+		expectedStatusCode int
+	}{
+		{
+			name: "Success with default dwell",
+			body: `{"name":"Depot","kind":"circle","center":[55.27,25.20],"radius":200}`,
+			setupMock: func(m *MockGeofenceService) {
+				m.On("CreateGeofence", mock.Anything, domain.Geofence{
+					Name:         "Depot",
+					Kind:         domain.GeofenceCircle,
+					Center:       []float64{55.27, 25.20},
+					Radius:       200,
+					DwellSeconds: domain.DefaultDwellSeconds,
+				}).Return(&domain.Geofence{ID: fenceID, Name: "Depot"}, nil)
+			},
+			expectedStatusCode: http.StatusCreated,
+		},
+		{
+			name:               "Invalid Body",
+			body:               `{"name":`,
+			setupMock:          func(m *MockGeofenceService) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "Validation Error",
+			body: `{"name":"Depot","kind":"square"}`,
+			setupMock: func(m *MockGeofenceService) {
+				m.On("CreateGeofence", mock.Anything, mock.Anything).Return(nil, &domain.ValidationError{
+					Fields: []domain.FieldError{{Field: "kind", Message: "must be circle or polygon"}},
+				})
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "Internal Server Error",
+			body: `{"name":"Depot","kind":"circle","center":[55.27,25.20],"radius":200}`,
+			setupMock: func(m *MockGeofenceService) {
+				m.On("CreateGeofence", mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			m := new(MockGeofenceService)
+			tc.setupMock(m)
+
+			req := httptest.NewRequest(http.MethodPost, "/geofences", strings.NewReader(tc.body))
+			rr := httptest.NewRecorder()
+			newGeofenceRouter(m).ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatusCode, rr.Code)
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGeofenceHandler_GetUpdateDelete(t *testing.T) {
+	fenceID := uuid.New()
+	path := "/geofences/" + fenceID.String()
+
+	t.Run("Get", func(t *testing.T) {
+		m := new(MockGeofenceService)
+		m.On("GetGeofence", mock.Anything, fenceID).Return(&domain.Geofence{ID: fenceID, Name: "Depot"}, nil)
+
+		rr := httptest.NewRecorder()
+		newGeofenceRouter(m).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var got domain.Geofence
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+		assert.Equal(t, "Depot", got.Name)
+	})
+
+	t.Run("Get Not Found", func(t *testing.T) {
+		m := new(MockGeofenceService)
+		m.On("GetGeofence", mock.Anything, fenceID).Return(nil, domain.ErrNotFound)
+
+		rr := httptest.NewRecorder()
+		newGeofenceRouter(m).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Get Invalid ID", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		newGeofenceRouter(new(MockGeofenceService)).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/geofences/nope", nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		m := new(MockGeofenceService)
+		m.On("UpdateGeofence", mock.Anything, mock.MatchedBy(func(f domain.Geofence) bool {
+			return f.ID == fenceID && f.DwellSeconds == 60
+		})).Return(&domain.Geofence{ID: fenceID}, nil)
+
+		body := `{"name":"Depot","kind":"circle","center":[55.27,25.20],"radius":200,"dwell_seconds":60}`
+		rr := httptest.NewRecorder()
+		newGeofenceRouter(m).ServeHTTP(rr, httptest.NewRequest(http.MethodPut, path, strings.NewReader(body)))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		m := new(MockGeofenceService)
+		m.On("DeleteGeofence", mock.Anything, fenceID).Return(nil)
+
+		rr := httptest.NewRecorder()
+		newGeofenceRouter(m).ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, path, nil))
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+	})
+
+	t.Run("Delete Not Found", func(t *testing.T) {
+		m := new(MockGeofenceService)
+		m.On("DeleteGeofence", mock.Anything, fenceID).Return(domain.ErrNotFound)
+
+		rr := httptest.NewRecorder()
+		newGeofenceRouter(m).ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, path, nil))
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestGeofenceHandler_Events(t *testing.T) {
+	vehicleID := uuid.New()
+	fenceID := uuid.New()
+	events := []domain.GeofenceEvent{{ID: 1, GeofenceID: fenceID, VehicleID: vehicleID, Kind: domain.GeofenceEnter}}
+
+	t.Run("Per vehicle", func(t *testing.T) {
+		m := new(MockGeofenceService)
+		m.On("ListEvents", mock.Anything, domain.GeofenceEventQuery{VehicleID: &vehicleID, GeofenceID: &fenceID, Limit: 10}).Return(events, nil)
+
+		url := "/vehicle/geofence-events?vehicle_id=" + vehicleID.String() + "&geofence_id=" + fenceID.String() + "&limit=10"
+		rr := httptest.NewRecorder()
+		newGeofenceRouter(m).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, url, nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var got []domain.GeofenceEvent
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+		assert.Equal(t, events, got)
+	})
+
+	t.Run("Per fence", func(t *testing.T) {
+		m := new(MockGeofenceService)
+		m.On("ListEvents", mock.Anything, domain.GeofenceEventQuery{GeofenceID: &fenceID}).Return(events, nil)
+
+		rr := httptest.NewRecorder()
+		newGeofenceRouter(m).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/geofences/"+fenceID.String()+"/events", nil))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("Invalid vehicle_id", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		newGeofenceRouter(new(MockGeofenceService)).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/vehicle/geofence-events", nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, "Invalid vehicle_id\n", rr.Body.String())
+	})
+}
@@ -0,0 +1,244 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockGeofenceRepository is a mock type for the GeofenceRepository
+type MockGeofenceRepository struct {
+	mock.Mock
+}
+
+func (m *MockGeofenceRepository) CreateGeofence(ctx context.Context, fence domain.Geofence) (*domain.Geofence, error) {
+	args := m.Called(ctx, fence)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Geofence), args.Error(1)
+}
+
+func (m *MockGeofenceRepository) GetGeofence(ctx context.Context, id uuid.UUID) (*domain.Geofence, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Geofence), args.Error(1)
+}
+
+func (m *MockGeofenceRepository) ListGeofences(ctx context.Context) ([]domain.Geofence, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Geofence), args.Error(1)
+}
+
+func (m *MockGeofenceRepository) UpdateGeofence(ctx context.Context, fence domain.Geofence) (*domain.Geofence, error) {
+	args := m.Called(ctx, fence)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Geofence), args.Error(1)
+}
+
+func (m *MockGeofenceRepository) DeleteGeofence(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockGeofenceRepository) ListPresence(ctx context.Context, vehicleID uuid.UUID) ([]domain.GeofencePresence, error) {
+	args := m.Called(ctx, vehicleID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.GeofencePresence), args.Error(1)
+}
+
+func (m *MockGeofenceRepository) EnterGeofence(ctx context.Context, presence domain.GeofencePresence) (bool, error) {
+	args := m.Called(ctx, presence)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockGeofenceRepository) MarkDwellReported(ctx context.Context, vehicleID, geofenceID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, vehicleID, geofenceID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockGeofenceRepository) ExitGeofence(ctx context.Context, vehicleID, geofenceID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, vehicleID, geofenceID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockGeofenceRepository) InsertGeofenceEvent(ctx context.Context, event domain.GeofenceEvent) (*domain.GeofenceEvent, error) {
+	args := m.Called(ctx, event)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.GeofenceEvent), args.Error(1)
+}
+
+func (m *MockGeofenceRepository) ListGeofenceEvents(ctx context.Context, query domain.GeofenceEventQuery) ([]domain.GeofenceEvent, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.GeofenceEvent), args.Error(1)
+}
+
+func eventOfKind(kind domain.GeofenceEventKind) interface{} {
+	return mock.MatchedBy(func(e domain.GeofenceEvent) bool { return e.Kind == kind })
+}
+
+func TestGeofence_Contains(t *testing.T) {
+	circle := domain.Geofence{Kind: domain.GeofenceCircle, Center: []float64{55.27, 25.20}, Radius: 500}
+	assert.True(t, circle.Contains(55.27, 25.20))
+	assert.True(t, circle.Contains(55.273, 25.20)) // ~300 m east
+	assert.False(t, circle.Contains(55.28, 25.20)) // ~1 km east
+
+	square := domain.Geofence{Kind: domain.GeofencePolygon, Polygon: [][]float64{{0, 0}, {1, 0}, {1, 1}, {0, 1}}}
+	assert.True(t, square.Contains(0.5, 0.5))
+	assert.False(t, square.Contains(1.5, 0.5))
+}
+
+func TestGeofence_Validate(t *testing.T) {
+	valid := domain.Geofence{Name: "Depot", Kind: domain.GeofenceCircle, Center: []float64{55.27, 25.20}, Radius: 200}
+	assert.NoError(t, valid.Validate())
+
+	invalid := domain.Geofence{Kind: domain.GeofencePolygon, Polygon: [][]float64{{0, 0}, {200, 0}}, DwellSeconds: -1}
+	var verr *domain.ValidationError
+	if assert.ErrorAs(t, invalid.Validate(), &verr) {
+		var fields []string
+		for _, f := range verr.Fields {
+			fields = append(fields, f.Field)
+		}
+		assert.ElementsMatch(t, []string{"name", "polygon", "polygon[1][0]", "dwell_seconds"}, fields)
+	}
+}
+
+func TestGeofenceService_Process(t *testing.T) {
+	vehicleID := uuid.New()
+	fence := domain.Geofence{
+		ID:           uuid.New(),
+		Name:         "Depot",
+		Kind:         domain.GeofenceCircle,
+		Center:       []float64{55.27, 25.20},
+		Radius:       500,
+		DwellSeconds: 600,
+	}
+	start := time.Date(2025, 6, 17, 9, 0, 0, 0, time.UTC)
+	inside := statusAt(start, 0, 55.27, 25.20, 0)
+	outside := statusAt(start, 0, 55.30, 25.20, 40)
+
+	t.Run("Enter", func(t *testing.T) {
+		repo := new(MockGeofenceRepository)
+		repo.On("ListGeofences", mock.Anything).Return([]domain.Geofence{fence}, nil).Once()
+		repo.On("ListPresence", mock.Anything, vehicleID).Return([]domain.GeofencePresence{}, nil)
+		repo.On("EnterGeofence", mock.Anything, domain.GeofencePresence{VehicleID: vehicleID, GeofenceID: fence.ID, EnteredAt: start}).Return(true, nil)
+		repo.On("InsertGeofenceEvent", mock.Anything, eventOfKind(domain.GeofenceEnter)).Return(&domain.GeofenceEvent{}, nil).Once()
+
+		events := &recordingPublisher{}
+		s := services.NewGeofenceService(repo, services.WithGeofenceEvents(events))
+		assert.NoError(t, s.Process(context.Background(), vehicleID, inside))
+		repo.AssertExpectations(t)
+
+		assert.Equal(t, []domain.EventType{domain.EventGeofenceEntered}, events.types())
+		assert.Equal(t, &fence.ID, events.events[0].GeofenceID)
+		assert.Equal(t, vehicleID, events.events[0].VehicleID)
+	})
+
+	t.Run("Enter already recorded elsewhere", func(t *testing.T) {
+		repo := new(MockGeofenceRepository)
+		repo.On("ListGeofences", mock.Anything).Return([]domain.Geofence{fence}, nil)
+		repo.On("ListPresence", mock.Anything, vehicleID).Return([]domain.GeofencePresence{}, nil)
+		repo.On("EnterGeofence", mock.Anything, mock.Anything).Return(false, nil)
+
+		s := services.NewGeofenceService(repo)
+		assert.NoError(t, s.Process(context.Background(), vehicleID, inside))
+		repo.AssertNotCalled(t, "InsertGeofenceEvent", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Dwell fires once after the dwell time", func(t *testing.T) {
+		presence := domain.GeofencePresence{VehicleID: vehicleID, GeofenceID: fence.ID, EnteredAt: start}
+		repo := new(MockGeofenceRepository)
+		repo.On("ListGeofences", mock.Anything).Return([]domain.Geofence{fence}, nil)
+		repo.On("ListPresence", mock.Anything, vehicleID).Return([]domain.GeofencePresence{presence}, nil)
+		repo.On("MarkDwellReported", mock.Anything, vehicleID, fence.ID).Return(true, nil).Once()
+		repo.On("InsertGeofenceEvent", mock.Anything, eventOfKind(domain.GeofenceDwell)).Return(&domain.GeofenceEvent{}, nil).Once()
+
+		s := services.NewGeofenceService(repo)
+		ctx := context.Background()
+		assert.NoError(t, s.Process(ctx, vehicleID, statusAt(start, 5*time.Minute, 55.27, 25.20, 0)))
+		repo.AssertNotCalled(t, "MarkDwellReported", mock.Anything, mock.Anything, mock.Anything)
+
+		assert.NoError(t, s.Process(ctx, vehicleID, statusAt(start, 10*time.Minute, 55.27, 25.20, 0)))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Exit", func(t *testing.T) {
+		presence := domain.GeofencePresence{VehicleID: vehicleID, GeofenceID: fence.ID, EnteredAt: start, DwellReported: true}
+		repo := new(MockGeofenceRepository)
+		repo.On("ListGeofences", mock.Anything).Return([]domain.Geofence{fence}, nil)
+		repo.On("ListPresence", mock.Anything, vehicleID).Return([]domain.GeofencePresence{presence}, nil)
+		repo.On("ExitGeofence", mock.Anything, vehicleID, fence.ID).Return(true, nil).Once()
+		repo.On("InsertGeofenceEvent", mock.Anything, eventOfKind(domain.GeofenceExit)).Return(&domain.GeofenceEvent{}, nil).Once()
+
+		s := services.NewGeofenceService(repo)
+		assert.NoError(t, s.Process(context.Background(), vehicleID, outside))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Changes reload the fences", func(t *testing.T) {
+		repo := new(MockGeofenceRepository)
+		repo.On("ListGeofences", mock.Anything).Return([]domain.Geofence{}, nil).Twice()
+		repo.On("ListPresence", mock.Anything, vehicleID).Return([]domain.GeofencePresence{}, nil)
+		repo.On("DeleteGeofence", mock.Anything, fence.ID).Return(nil).Once()
+
+		s := services.NewGeofenceService(repo)
+		ctx := context.Background()
+		assert.NoError(t, s.Process(ctx, vehicleID, inside))
+		assert.NoError(t, s.Process(ctx, vehicleID, inside))
+		assert.NoError(t, s.DeleteGeofence(ctx, fence.ID))
+		assert.NoError(t, s.Process(ctx, vehicleID, inside))
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestGeofenceService_CreateGeofence(t *testing.T) {
+	repo := new(MockGeofenceRepository)
+	s := services.NewGeofenceService(repo)
+
+	var verr *domain.ValidationError
+	_, err := s.CreateGeofence(context.Background(), domain.Geofence{Name: "Depot", Kind: "square"})
+	assert.ErrorAs(t, err, &verr)
+	repo.AssertNotCalled(t, "CreateGeofence", mock.Anything, mock.Anything)
+
+	dbErr := errors.New("db error")
+	fence := domain.Geofence{Name: "Depot", Kind: domain.GeofenceCircle, Center: []float64{55.27, 25.20}, Radius: 200}
+	repo.On("CreateGeofence", mock.Anything, fence).Return(nil, dbErr).Once()
+	_, err = s.CreateGeofence(context.Background(), fence)
+	assert.Equal(t, dbErr, err)
+}
+
+func TestGeofenceService_ListEvents(t *testing.T) {
+	repo := new(MockGeofenceRepository)
+	vehicleID := uuid.New()
+	repo.On("ListGeofenceEvents", mock.Anything, mock.MatchedBy(func(q domain.GeofenceEventQuery) bool {
+		return *q.VehicleID == vehicleID &&
+			q.To.Sub(q.From) == services.GeofenceEventWindow &&
+			q.Limit == services.MaxGeofenceEventLimit
+	})).Return([]domain.GeofenceEvent{}, nil).Once()
+
+	s := services.NewGeofenceService(repo)
+	_, err := s.ListEvents(context.Background(), domain.GeofenceEventQuery{VehicleID: &vehicleID, Limit: 5000})
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,382 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/auth"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/grpcapi"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/services"
+	fleetv1 "github.com/AnjuRKrishnan/fleet-tracker/pkg/api/fleet/v1"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// startGRPCServer serves the gRPC API over an in-memory connection and
+// returns a client and a context carrying a valid token.
+func startGRPCServer(t *testing.T, vehicles services.VehicleServiceAPI, feed services.LiveFeedAPI) (fleetv1.VehicleServiceClient, context.Context) {
+	t.Helper()
+	jwtAuth := auth.NewJWTAuth("test-secret")
+	lis := bufconn.Listen(1 << 20)
+	server := grpcapi.NewServer(jwtAuth, grpcapi.NewVehicleServer(vehicles, feed, zap.NewNop()))
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	token, err := jwtAuth.GenerateToken("operator")
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return fleetv1.NewVehicleServiceClient(conn), metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
+
+// batchIngester stores batches by deciding each reading on its own with
+// ingest, however the stream was split into batches. An error fails the
+// whole batch.
+type batchIngester struct {
+	*MockVehicleService
+	ingest func(domain.IngestRequest) (domain.IngestResult, error)
+
+	mu      sync.Mutex
+	batches []int
+}
+
+func (b *batchIngester) IngestBatch(ctx context.Context, items []domain.IngestRequest) ([]domain.IngestResult, error) {
+	b.mu.Lock()
+	b.batches = append(b.batches, len(items))
+	b.mu.Unlock()
+
+	results := make([]domain.IngestResult, len(items))
+	for i, item := range items {
+		res, err := b.ingest(item)
+		if err != nil {
+			return nil, err
+		}
+		res.Index = i
+		results[i] = res
+	}
+	return results, nil
+}
+
+// sizes returns the number of readings of every batch.
+func (b *batchIngester) sizes() []int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return slices.Clone(b.batches)
+}
+
+func TestGRPCVehicleService(t *testing.T) {
+	vehicleID := uuid.New()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	t.Run("Authentication", func(t *testing.T) {
+		client, _ := startGRPCServer(t, new(MockVehicleService), nil)
+		req := &fleetv1.GetVehicleStatusRequest{VehicleId: vehicleID.String()}
+
+		_, err := client.GetVehicleStatus(context.Background(), req)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer guess")
+		_, err = client.GetVehicleStatus(ctx, req)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		stream, err := client.WatchPositions(context.Background(), &fleetv1.WatchPositionsRequest{})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("Get vehicle status", func(t *testing.T) {
+		vehicles := new(MockVehicleService)
+		heading, sats := 87.5, 9
+		vehicles.On("GetVehicleStatus", mock.Anything, vehicleID).Return(&domain.VehicleStatus{
+			Location: []float64{55.2962, 25.2769}, Speed: 42, Timestamp: now, Heading: &heading, Satellites: &sats,
+		}, nil)
+		missing := uuid.New()
+		vehicles.On("GetVehicleStatus", mock.Anything, missing).Return(nil, nil)
+		client, ctx := startGRPCServer(t, vehicles, nil)
+
+		resp, err := client.GetVehicleStatus(ctx, &fleetv1.GetVehicleStatusRequest{VehicleId: vehicleID.String()})
+		require.NoError(t, err)
+		assert.Equal(t, 55.2962, resp.GetLongitude())
+		assert.Equal(t, 25.2769, resp.GetLatitude())
+		assert.Equal(t, 42.0, resp.Speed)
+		assert.Equal(t, now, resp.Timestamp.AsTime())
+		assert.Equal(t, 87.5, resp.GetHeading())
+		assert.Equal(t, int32(9), resp.GetSatellites())
+		assert.Nil(t, resp.Altitude)
+
+		_, err = client.GetVehicleStatus(ctx, &fleetv1.GetVehicleStatusRequest{VehicleId: missing.String()})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		_, err = client.GetVehicleStatus(ctx, &fleetv1.GetVehicleStatusRequest{VehicleId: "invalid"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Get status of unknown vehicle", func(t *testing.T) {
+		client, ctx := startGRPCServer(t, unknownVehicleService(), nil)
+
+		_, err := client.GetVehicleStatus(ctx, &fleetv1.GetVehicleStatusRequest{VehicleId: uuid.NewString()})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("Get vehicle trips", func(t *testing.T) {
+		vehicles := new(MockVehicleService)
+		tripID := uuid.New()
+		next := domain.TripCursor{StartTime: now.Add(-time.Hour), ID: tripID}
+		vehicles.On("GetVehicleTrips", mock.Anything, domain.TripQuery{
+			VehicleID:  vehicleID,
+			From:       now.Add(-30 * 24 * time.Hour),
+			MinMileage: 10,
+			Order:      domain.SortAscending,
+			Limit:      1,
+		}).Return(domain.TripPage{Trips: []domain.Trip{{
+			ID:        pgtype.UUID{Bytes: tripID, Valid: true},
+			VehicleID: pgtype.UUID{Bytes: vehicleID, Valid: true},
+			StartTime: pgtype.Timestamptz{Time: now.Add(-time.Hour), Valid: true},
+			Mileage:   12.5,
+			AvgSpeed:  40,
+		}}, Next: &next}, nil)
+		client, ctx := startGRPCServer(t, vehicles, nil)
+
+		resp, err := client.GetVehicleTrips(ctx, &fleetv1.GetVehicleTripsRequest{
+			VehicleId:  vehicleID.String(),
+			From:       timestamppb.New(now.Add(-30 * 24 * time.Hour)),
+			MinMileage: 10,
+			Ascending:  true,
+			PageSize:   1,
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Trips, 1)
+		assert.Equal(t, tripID.String(), resp.Trips[0].Id)
+		assert.Equal(t, now.Add(-time.Hour), resp.Trips[0].StartTime.AsTime())
+		assert.Nil(t, resp.Trips[0].EndTime)
+		assert.Equal(t, 12.5, resp.Trips[0].Mileage)
+		assert.Equal(t, next.String(), resp.NextPageToken)
+
+		_, err = client.GetVehicleTrips(ctx, &fleetv1.GetVehicleTripsRequest{VehicleId: vehicleID.String(), PageToken: "garbage"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Ingest stream", func(t *testing.T) {
+		vehicles := &batchIngester{MockVehicleService: new(MockVehicleService), ingest: func(req domain.IngestRequest) (domain.IngestResult, error) {
+			switch {
+			case req.MessageID == "dup":
+				return domain.IngestResult{Status: domain.IngestDuplicate}, nil
+			case req.Status.Location[1] == 95:
+				verr := &domain.ValidationError{Fields: []domain.FieldError{
+					{Field: "status.location[1]", Message: "latitude must be between -90 and 90"},
+				}}
+				return domain.IngestResult{Status: domain.IngestRejected, Error: verr.Error(), Fields: verr.Fields}, nil
+			case uuid.UUID(req.VehicleID.Bytes) == vehicleID && req.PlateNumber == "KL01AB1234" &&
+				req.Status.Location[0] == 55.2962 && req.Status.Timestamp.Equal(now):
+				return domain.IngestResult{Status: domain.IngestAccepted}, nil
+			}
+			return domain.IngestResult{}, errors.New("unexpected reading")
+		}}
+		client, ctx := startGRPCServer(t, vehicles, nil)
+
+		reading := func(messageID string, lat float64) *fleetv1.IngestRequest {
+			return &fleetv1.IngestRequest{
+				VehicleId:   vehicleID.String(),
+				PlateNumber: "KL01AB1234",
+				MessageId:   messageID,
+				Status:      &fleetv1.VehicleStatus{Longitude: proto.Float64(55.2962), Latitude: proto.Float64(lat), Speed: 30, Timestamp: timestamppb.New(now)},
+			}
+		}
+		stream, err := client.Ingest(ctx)
+		require.NoError(t, err)
+		for _, msg := range []*fleetv1.IngestRequest{
+			reading("1", 25.2769),
+			reading("dup", 25.2769),
+			reading("2", 95),
+			{VehicleId: "invalid"},
+			reading("3", 25.2769),
+		} {
+			require.NoError(t, stream.Send(msg))
+		}
+		resp, err := stream.CloseAndRecv()
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(2), resp.Accepted)
+		assert.Equal(t, int64(1), resp.Duplicates)
+		assert.Equal(t, int64(2), resp.Rejected)
+		require.Len(t, resp.Rejections, 2)
+		assert.Equal(t, int64(2), resp.Rejections[0].Index)
+		assert.Equal(t, "status.location[1]", resp.Rejections[0].Fields[0].Field)
+		assert.Equal(t, int64(3), resp.Rejections[1].Index)
+		assert.Equal(t, "vehicle_id", resp.Rejections[1].Fields[0].Field)
+	})
+
+	t.Run("Ingest stream without coordinates", func(t *testing.T) {
+		vehicles := &batchIngester{MockVehicleService: new(MockVehicleService), ingest: func(req domain.IngestRequest) (domain.IngestResult, error) {
+			var verr *domain.ValidationError
+			if errors.As(req.Validate(), &verr) {
+				return domain.IngestResult{Status: domain.IngestRejected, Error: verr.Error(), Fields: verr.Fields}, nil
+			}
+			return domain.IngestResult{Status: domain.IngestAccepted}, nil
+		}}
+		client, ctx := startGRPCServer(t, vehicles, nil)
+
+		stream, err := client.Ingest(ctx)
+		require.NoError(t, err)
+		for _, s := range []*fleetv1.VehicleStatus{
+			{Speed: 30, Timestamp: timestamppb.New(now)},
+			{Longitude: proto.Float64(55.2962), Timestamp: timestamppb.New(now)},
+			{Longitude: proto.Float64(0), Latitude: proto.Float64(0), Timestamp: timestamppb.New(now)},
+		} {
+			require.NoError(t, stream.Send(&fleetv1.IngestRequest{VehicleId: vehicleID.String(), Status: s}))
+		}
+		resp, err := stream.CloseAndRecv()
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(1), resp.Accepted)
+		assert.Equal(t, int64(2), resp.Rejected)
+		require.Len(t, resp.Rejections, 2)
+		assert.Equal(t, int64(0), resp.Rejections[0].Index)
+		assert.Equal(t, "status.location", resp.Rejections[0].Fields[0].Field)
+		assert.Equal(t, int64(1), resp.Rejections[1].Index)
+		assert.Equal(t, "status.location", resp.Rejections[1].Fields[0].Field)
+	})
+
+	t.Run("Ingest stream batches readings", func(t *testing.T) {
+		// The first batch is held until every reading was sent, so the
+		// others queue up behind it.
+		sent := make(chan struct{})
+		vehicles := &batchIngester{MockVehicleService: new(MockVehicleService), ingest: func(domain.IngestRequest) (domain.IngestResult, error) {
+			<-sent
+			return domain.IngestResult{Status: domain.IngestAccepted}, nil
+		}}
+		client, ctx := startGRPCServer(t, vehicles, nil)
+
+		stream, err := client.Ingest(ctx)
+		require.NoError(t, err)
+		msg := &fleetv1.IngestRequest{
+			VehicleId: vehicleID.String(),
+			Status:    &fleetv1.VehicleStatus{Longitude: proto.Float64(55.2962), Latitude: proto.Float64(25.2769), Timestamp: timestamppb.New(now)},
+		}
+		for i := 0; i < 1200; i++ {
+			require.NoError(t, stream.Send(msg))
+		}
+		close(sent)
+		resp, err := stream.CloseAndRecv()
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(1200), resp.Accepted)
+		batches := vehicles.sizes()
+		assert.LessOrEqual(t, len(batches), 10)
+		for _, n := range batches {
+			assert.LessOrEqual(t, n, grpcapi.IngestBatchSize)
+		}
+	})
+
+	t.Run("Ingest stream failure", func(t *testing.T) {
+		stored := make(chan struct{}, 1)
+		vehicles := &batchIngester{MockVehicleService: new(MockVehicleService), ingest: func(req domain.IngestRequest) (domain.IngestResult, error) {
+			if req.MessageID == "2" {
+				return domain.IngestResult{}, errors.New("db down")
+			}
+			stored <- struct{}{}
+			return domain.IngestResult{Status: domain.IngestAccepted}, nil
+		}}
+		client, ctx := startGRPCServer(t, vehicles, nil)
+
+		stream, err := client.Ingest(ctx)
+		require.NoError(t, err)
+		reading := func(messageID string) *fleetv1.IngestRequest {
+			return &fleetv1.IngestRequest{
+				VehicleId: vehicleID.String(),
+				MessageId: messageID,
+				Status:    &fleetv1.VehicleStatus{Longitude: proto.Float64(55.2962), Latitude: proto.Float64(25.2769), Timestamp: timestamppb.New(now)},
+			}
+		}
+		require.NoError(t, stream.Send(reading("1")))
+		<-stored
+		require.NoError(t, stream.Send(reading("2")))
+		_, err = stream.CloseAndRecv()
+
+		st := status.Convert(err)
+		assert.Equal(t, codes.Internal, st.Code())
+		assert.Equal(t, "Failed to ingest readings from 1", st.Message())
+		require.Len(t, st.Details(), 1)
+		progress, ok := st.Details()[0].(*fleetv1.IngestResponse)
+		require.True(t, ok)
+		assert.Equal(t, int64(1), progress.Accepted)
+	})
+
+	t.Run("Recovers from panics", func(t *testing.T) {
+		vehicles := &batchIngester{MockVehicleService: new(MockVehicleService), ingest: func(domain.IngestRequest) (domain.IngestResult, error) {
+			panic("ingest bug")
+		}}
+		vehicles.On("GetVehicleStatus", mock.Anything, vehicleID).Run(func(mock.Arguments) { panic("status bug") })
+		client, ctx := startGRPCServer(t, vehicles, nil)
+
+		_, err := client.GetVehicleStatus(ctx, &fleetv1.GetVehicleStatusRequest{VehicleId: vehicleID.String()})
+		assert.Equal(t, codes.Internal, status.Code(err))
+
+		stream, err := client.Ingest(ctx)
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&fleetv1.IngestRequest{
+			VehicleId: vehicleID.String(),
+			Status:    &fleetv1.VehicleStatus{Timestamp: timestamppb.New(now)},
+		}))
+		_, err = stream.CloseAndRecv()
+		assert.Equal(t, codes.Internal, status.Code(err))
+
+		// The server keeps serving.
+		_, err = client.GetVehicleStatus(ctx, &fleetv1.GetVehicleStatusRequest{VehicleId: "invalid"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Watch positions", func(t *testing.T) {
+		hub := startLiveHubs(t, 1)[0]
+		client, ctx := startGRPCServer(t, new(MockVehicleService), hub)
+		other := uuid.New()
+
+		stream, err := client.WatchPositions(ctx, &fleetv1.WatchPositionsRequest{VehicleIds: []string{vehicleID.String()}})
+		require.NoError(t, err)
+		// The subscription is in place once the response headers arrive.
+		_, err = stream.Header()
+		require.NoError(t, err)
+
+		hub.Publish(ctx, domain.NewEvent(domain.EventStatusUpdated, other, now, domain.VehicleStatus{Location: []float64{1, 2}, Timestamp: now}))
+		hub.Publish(ctx, domain.NewEvent(domain.EventTripStarted, vehicleID, now, domain.Trip{}))
+		hub.Publish(ctx, domain.NewEvent(domain.EventStatusUpdated, vehicleID, now, domain.VehicleStatus{Location: []float64{55.2962, 25.2769}, Speed: 42, Timestamp: now}))
+
+		update, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, vehicleID.String(), update.VehicleId)
+		assert.Equal(t, 25.2769, update.Status.GetLatitude())
+		assert.Equal(t, 42.0, update.Status.Speed)
+		assert.Equal(t, now, update.Status.Timestamp.AsTime())
+		assert.Zero(t, update.Dropped)
+
+		invalid, err := client.WatchPositions(ctx, &fleetv1.WatchPositionsRequest{VehicleIds: []string{"invalid"}})
+		require.NoError(t, err)
+		_, err = invalid.Recv()
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -0,0 +1,162 @@
+package test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	handler "github.com/AnjuRKrishnan/fleet-tracker/internal/handler"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/services"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/store/redis"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// startLiveHubs runs n hubs that share one Redis, as separate instances
+// would, and waits until all of them are subscribed.
+func startLiveHubs(t *testing.T, n int) []*services.LiveHub {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	hubs := make([]*services.LiveHub, n)
+	for i := range hubs {
+		client, err := redis.NewRedisCache("redis://" + mr.Addr())
+		require.NoError(t, err)
+		t.Cleanup(func() { client.Close() })
+
+		hubs[i] = services.NewLiveHub(redis.NewEventBus(client), zap.NewNop())
+		go hubs[i].Run(ctx)
+	}
+	require.Eventually(t, func() bool {
+		return mr.PubSubNumSub(redis.EventChannel)[redis.EventChannel] == n
+	}, time.Second, 10*time.Millisecond)
+	return hubs
+}
+
+func receiveEvent(t *testing.T, sub *services.LiveSubscription) domain.Event {
+	t.Helper()
+	select {
+	case event := <-sub.Events():
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		return domain.Event{}
+	}
+}
+
+func TestLiveHub_FansOutAcrossInstances(t *testing.T) {
+	hubs := startLiveHubs(t, 2)
+	watched, other := uuid.New(), uuid.New()
+	ctx := context.Background()
+
+	sub := hubs[1].Subscribe(services.LiveFilter{
+		Types:      []domain.EventType{domain.EventStatusUpdated},
+		VehicleIDs: []uuid.UUID{watched},
+	})
+	defer sub.Close()
+
+	now := time.Date(2025, 6, 17, 9, 0, 0, 0, time.UTC)
+	hubs[0].Publish(ctx, domain.NewEvent(domain.EventStatusUpdated, other, now, domain.VehicleStatus{Speed: 10}))
+	hubs[0].Publish(ctx, domain.NewEvent(domain.EventTripStarted, watched, now, domain.Trip{}))
+	assert.NoError(t, services.StatusEvents(hubs[0]).Process(ctx, watched, domain.VehicleStatus{
+		Location:  []float64{55.29, 25.27},
+		Speed:     42,
+		Timestamp: now,
+	}))
+
+	event := receiveEvent(t, sub)
+	assert.Equal(t, domain.EventStatusUpdated, event.Type)
+	assert.Equal(t, watched, event.VehicleID)
+	assert.True(t, now.Equal(event.OccurredAt))
+
+	data, err := json.Marshal(event.Data)
+	require.NoError(t, err)
+	var status domain.VehicleStatus
+	require.NoError(t, json.Unmarshal(data, &status))
+	assert.Equal(t, 42.0, status.Speed)
+
+	select {
+	case extra := <-sub.Events():
+		t.Fatalf("unexpected event %s for %s", extra.Type, extra.VehicleID)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestLiveHub_ReportsDroppedEvents(t *testing.T) {
+	hub := startLiveHubs(t, 1)[0]
+	vehicleID := uuid.New()
+	sub := hub.Subscribe(services.LiveFilter{VehicleIDs: []uuid.UUID{vehicleID}})
+	defer sub.Close()
+
+	now := time.Now()
+	for range services.LiveBufferSize + 5 {
+		hub.Publish(context.Background(), domain.NewEvent(domain.EventStatusUpdated, vehicleID, now, domain.VehicleStatus{}))
+	}
+	var dropped uint64
+	require.Eventually(t, func() bool {
+		dropped += sub.TakeDropped()
+		return dropped == 5
+	}, time.Second, 10*time.Millisecond)
+	assert.Len(t, sub.Events(), services.LiveBufferSize)
+	assert.Zero(t, sub.TakeDropped())
+}
+
+func TestStreamHandler_StreamPositions(t *testing.T) {
+	hubs := startLiveHubs(t, 1)
+	vehicleID := uuid.New()
+
+	h := handler.NewStreamHandler(hubs[0], zap.NewNop())
+	server := httptest.NewServer(http.HandlerFunc(h.StreamPositions))
+	defer server.Close()
+
+	t.Run("Streams matching statuses", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"?vehicle_id="+vehicleID.String(), nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		reader := bufio.NewReader(resp.Body)
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, ": connected\n", line)
+
+		event := domain.NewEvent(domain.EventStatusUpdated, vehicleID, time.Now(), domain.VehicleStatus{Speed: 12})
+		hubs[0].Publish(ctx, domain.NewEvent(domain.EventStatusUpdated, uuid.New(), time.Now(), domain.VehicleStatus{}))
+		hubs[0].Publish(ctx, event)
+
+		var frame []string
+		for len(frame) < 3 {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			if line = strings.TrimSuffix(line, "\n"); line != "" {
+				frame = append(frame, line)
+			}
+		}
+		assert.Equal(t, "id: "+event.ID.String(), frame[0])
+		assert.Equal(t, "event: status.updated", frame[1])
+		assert.Contains(t, frame[2], `"vehicle_id":"`+vehicleID.String()+`"`)
+	})
+
+	t.Run("Invalid vehicle_id", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "?vehicle_id=" + vehicleID.String() + ",nope")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
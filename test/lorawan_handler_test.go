@@ -0,0 +1,205 @@
+package test
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	handler "github.com/AnjuRKrishnan/fleet-tracker/internal/handler"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/ingest"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// lppGPS is the GPS example of the Cayenne LPP specification after a
+// temperature reading: 42.3519°N, 87.9094°W at 10 m.
+const lppGPS = "0167FFD7" + "0188" + "06765FF2960A0003E8"
+
+func lppUplink(t *testing.T, payload string) ingest.LoRaWANUplink {
+	t.Helper()
+	b, err := hex.DecodeString(payload)
+	require.NoError(t, err)
+	return ingest.LoRaWANUplink{Payload: b}
+}
+
+func TestLoRaWANDecoders(t *testing.T) {
+	t.Run("Cayenne LPP", func(t *testing.T) {
+		status, err := ingest.DecodeCayenneLPP(lppUplink(t, lppGPS))
+		require.NoError(t, err)
+		assert.InDelta(t, -87.9094, status.Location[0], 1e-9)
+		assert.InDelta(t, 42.3519, status.Location[1], 1e-9)
+		assert.InDelta(t, 10, *status.Altitude, 1e-9)
+
+		_, err = ingest.DecodeCayenneLPP(lppUplink(t, "0167FFD7"))
+		assert.ErrorIs(t, err, ingest.ErrNoPosition)
+		_, err = ingest.DecodeCayenneLPP(lppUplink(t, "01FF00"))
+		assert.EqualError(t, err, "unknown Cayenne LPP type 0xFF")
+		_, err = ingest.DecodeCayenneLPP(lppUplink(t, "018806765F"))
+		assert.EqualError(t, err, "truncated Cayenne LPP payload")
+	})
+
+	t.Run("Network server payload", func(t *testing.T) {
+		status, err := ingest.DecodeNetworkServerPayload(ingest.LoRaWANUplink{
+			Decoded: []byte(`{"lat":25.2769,"lng":55.2962,"speed":42,"course":87.5,"sats":9,"battery":3.6}`),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []float64{55.2962, 25.2769}, status.Location)
+		assert.Equal(t, 42.0, status.Speed)
+		assert.Equal(t, 87.5, *status.Heading)
+		assert.Equal(t, 9, *status.Satellites)
+		assert.Nil(t, status.Altitude)
+
+		_, err = ingest.DecodeNetworkServerPayload(ingest.LoRaWANUplink{Decoded: []byte(`{"battery":3.6}`)})
+		assert.ErrorIs(t, err, ingest.ErrNoPosition)
+		_, err = ingest.DecodeNetworkServerPayload(ingest.LoRaWANUplink{})
+		assert.ErrorIs(t, err, ingest.ErrNoPosition)
+	})
+}
+
+func TestLoRaWANHandler(t *testing.T) {
+	trailer := &domain.Device{Identifier: "70B3D57ED005A4B2", VehicleID: uuid.New(), PlateNumber: "TR-001", Model: "cayenne-lpp"}
+	tracker := &domain.Device{Identifier: "0101010101010101", VehicleID: uuid.New(), PlateNumber: "TR-002"}
+	custom := &domain.Device{Identifier: "0202020202020202", VehicleID: uuid.New(), Model: "acme-t1"}
+	unknownModel := &domain.Device{Identifier: "0303030303030303", VehicleID: uuid.New(), Model: "unknown"}
+
+	newRouter := func() (*MockVehicleService, http.Handler) {
+		devices, vehicles := new(MockDeviceService), new(MockVehicleService)
+		for _, d := range []*domain.Device{trailer, tracker, custom, unknownModel} {
+			devices.On("ResolveDevice", mock.Anything, d.Identifier).Return(d, nil)
+		}
+		devices.On("ResolveDevice", mock.Anything, mock.Anything).Return(nil, domain.ErrNotFound)
+
+		decoders := ingest.NewLoRaWANDecoders()
+		decoders.Register("acme-t1", ingest.LoRaWANDecoderFunc(func(up ingest.LoRaWANUplink) (domain.VehicleStatus, error) {
+			return domain.VehicleStatus{Location: []float64{float64(up.Payload[0]), float64(up.Payload[1])}}, nil
+		}))
+		h := handler.NewLoRaWANHandler(devices, vehicles, decoders, zap.NewNop())
+
+		r := chi.NewRouter()
+		r.Use(middleware.StaticToken("webhook-secret"))
+		r.Post("/lorawan/tts", h.TTSUplink)
+		r.Post("/lorawan/chirpstack", h.ChirpStackUplink)
+		return vehicles, r
+	}
+	post := func(router http.Handler, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer webhook-secret")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	lpp, _ := hex.DecodeString(lppGPS)
+	ttsBody := `{
+		"end_device_ids": {"device_id": "trailer-1", "application_ids": {"application_id": "fleet"}, "dev_eui": "70B3D57ED005A4B2"},
+		"received_at": "2025-06-17T08:18:37.2Z",
+		"uplink_message": {
+			"f_port": 1, "f_cnt": 42, "frm_payload": "` + base64.StdEncoding.EncodeToString(lpp) + `",
+			"rx_metadata": [{"gateway_ids": {"gateway_id": "gw-1"}, "rssi": -97, "snr": 7.5}],
+			"received_at": "2025-06-17T08:18:36.9Z"
+		}
+	}`
+
+	t.Run("The Things Stack", func(t *testing.T) {
+		vehicles, router := newRouter()
+		vehicles.On("IngestData", mock.Anything, mock.MatchedBy(func(req domain.IngestRequest) bool {
+			return uuid.UUID(req.VehicleID.Bytes) == trailer.VehicleID &&
+				req.PlateNumber == "TR-001" &&
+				req.Status.Timestamp.Equal(time.Date(2025, 6, 17, 8, 18, 36, 900_000_000, time.UTC)) &&
+				req.Status.Location[1] == 42.3519 &&
+				req.MessageID == "lorawan:70B3D57ED005A4B2:42"
+		})).Return(nil).Once()
+
+		rr := post(router, "/lorawan/tts", ttsBody)
+		assert.Equal(t, http.StatusAccepted, rr.Code)
+		vehicles.AssertExpectations(t)
+	})
+
+	t.Run("ChirpStack", func(t *testing.T) {
+		vehicles, router := newRouter()
+		vehicles.On("IngestData", mock.Anything, mock.MatchedBy(func(req domain.IngestRequest) bool {
+			return uuid.UUID(req.VehicleID.Bytes) == tracker.VehicleID &&
+				req.Status.Location[0] == 55.2962 && req.Status.Speed == 12
+		})).Return(nil).Once()
+
+		body := `{
+			"deduplicationId": "3ac7e3c4-4401-4b8d-9386-a5c902f9202d",
+			"time": "2025-06-17T08:18:36.9Z",
+			"deviceInfo": {"deviceName": "tracker-2", "devEui": "0101010101010101", "deviceProfileName": "gps"},
+			"fCnt": 7, "fPort": 2, "data": "AQ==",
+			"object": {"latitude": 25.2769, "longitude": 55.2962, "speed": 12}
+		}`
+		rr := post(router, "/lorawan/chirpstack?event=up", body)
+		assert.Equal(t, http.StatusAccepted, rr.Code)
+		vehicles.AssertExpectations(t)
+
+		rr = post(router, "/lorawan/chirpstack?event=join", `{"deviceInfo": {"devEui": "0101010101010101"}}`)
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+	})
+
+	t.Run("Registered decoder", func(t *testing.T) {
+		vehicles, router := newRouter()
+		vehicles.On("IngestData", mock.Anything, mock.MatchedBy(func(req domain.IngestRequest) bool {
+			return req.Status.Location[0] == 10 && req.Status.Location[1] == 20
+		})).Return(nil).Once()
+
+		rr := post(router, "/lorawan/chirpstack", `{"deviceInfo": {"devEui": "0202020202020202"}, "fCnt": 1, "data": "ChQ="}`)
+		assert.Equal(t, http.StatusAccepted, rr.Code)
+		vehicles.AssertExpectations(t)
+	})
+
+	t.Run("Retried uplink without receive time", func(t *testing.T) {
+		vehicles, router := newRouter()
+		var ids []string
+		vehicles.On("IngestData", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			ids = append(ids, args.Get(1).(domain.IngestRequest).MessageID)
+		}).Return(nil).Twice()
+
+		body := `{"deviceInfo": {"devEui": "0202020202020202"}, "fCnt": 9, "data": "ChQ="}`
+		assert.Equal(t, http.StatusAccepted, post(router, "/lorawan/chirpstack", body).Code)
+		time.Sleep(2 * time.Millisecond)
+		assert.Equal(t, http.StatusAccepted, post(router, "/lorawan/chirpstack", body).Code)
+		assert.Equal(t, []string{"lorawan:0202020202020202:9", "lorawan:0202020202020202:9"}, ids)
+	})
+
+	t.Run("Rejected uplinks", func(t *testing.T) {
+		vehicles, router := newRouter()
+
+		rr := post(router, "/lorawan/chirpstack", `{"deviceInfo": {"devEui": "0404040404040404"}, "data": "AQ=="}`)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+
+		rr = post(router, "/lorawan/chirpstack", `{"deviceInfo": {"devEui": "0303030303030303"}, "data": "AQ=="}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+		rr = post(router, "/lorawan/chirpstack", `{"deviceInfo": {"devEui": "not-an-eui"}}`)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+		rr = post(router, "/lorawan/tts", `{"end_device_ids": {"dev_eui": "70B3D57ED005A4B2"}, "join_accept": {}}`)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+		// A status report without a position.
+		rr = post(router, "/lorawan/chirpstack", `{"deviceInfo": {"devEui": "0101010101010101"}, "object": {"battery": 3.6}}`)
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+
+		vehicles.AssertNotCalled(t, "IngestData", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Token required", func(t *testing.T) {
+		_, router := newRouter()
+		req := httptest.NewRequest(http.MethodPost, "/lorawan/tts", strings.NewReader(ttsBody))
+		req.Header.Set("Authorization", "Bearer guess")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
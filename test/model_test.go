@@ -0,0 +1,50 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVehicleStatus_JSON(t *testing.T) {
+	t.Run("Legacy payload", func(t *testing.T) {
+		var status domain.VehicleStatus
+		err := json.Unmarshal([]byte(`{"location":[55.29,25.27],"speed":60.5,"timestamp":"2025-06-17T09:12:00Z"}`), &status)
+		require.NoError(t, err)
+
+		assert.Equal(t, 60.5, status.Speed)
+		assert.Nil(t, status.Heading)
+		assert.Nil(t, status.Ignition)
+		assert.Nil(t, status.Satellites)
+
+		out, err := json.Marshal(status)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"location":[55.29,25.27],"speed":60.5,"timestamp":"2025-06-17T09:12:00Z"}`, string(out))
+	})
+
+	t.Run("Full telemetry", func(t *testing.T) {
+		payload := `{
+			"location":[55.29,25.27],"speed":60.5,"timestamp":"2025-06-17T09:12:00Z",
+			"heading":87.5,"altitude":12.3,"accuracy":4.5,"hdop":0.9,"satellites":11,
+			"ignition":false,"odometer":120345.6,"fuel_level":42,"engine_hours":3120.25
+		}`
+		var status domain.VehicleStatus
+		require.NoError(t, json.Unmarshal([]byte(payload), &status))
+
+		if assert.NotNil(t, status.Ignition) {
+			assert.False(t, *status.Ignition)
+		}
+		if assert.NotNil(t, status.Satellites) {
+			assert.Equal(t, 11, *status.Satellites)
+		}
+		assert.Equal(t, time.Date(2025, 6, 17, 9, 12, 0, 0, time.UTC), status.Timestamp)
+
+		out, err := json.Marshal(status)
+		require.NoError(t, err)
+		assert.JSONEq(t, payload, string(out))
+	})
+}
@@ -0,0 +1,187 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/ingest"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/services"
+	"github.com/google/uuid"
+	mochi "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// startBroker runs an in-process MQTT broker and returns it with its URL.
+func startBroker(t *testing.T) (*mochi.Server, string) {
+	t.Helper()
+	broker := mochi.New(&mochi.Options{InlineClient: true})
+	require.NoError(t, broker.AddHook(new(auth.AllowHook), nil))
+
+	tcp := listeners.NewTCP(listeners.Config{ID: "tcp", Address: "127.0.0.1:0"})
+	require.NoError(t, broker.AddListener(tcp))
+	go broker.Serve()
+	t.Cleanup(func() { broker.Close() })
+
+	return broker, "tcp://" + tcp.Address()
+}
+
+// runMQTTSource runs a source until the returned stop function is called and
+// waits until it has subscribed to topic.
+func runMQTTSource(t *testing.T, broker *mochi.Server, cfg ingest.MQTTConfig, ingester services.Ingester, topic string) (stop func()) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ingest.NewMQTTSource(cfg, ingester, zap.NewNop()).Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		return len(broker.Topics.Subscribers(topic).Subscriptions) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	stop = func() {
+		cancel()
+		<-done
+	}
+	t.Cleanup(stop)
+	return stop
+}
+
+// ingestTo returns a service whose IngestData sends each reading to out.
+func ingestTo(out chan<- domain.IngestRequest) *MockVehicleService {
+	service := new(MockVehicleService)
+	service.On("IngestData", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		out <- args.Get(1).(domain.IngestRequest)
+	}).Return(nil)
+	return service
+}
+
+// waitInflight waits until the broker has n unacknowledged messages for the
+// client.
+func waitInflight(t *testing.T, broker *mochi.Server, clientID string, n int) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		cl, ok := broker.Clients.Get(clientID)
+		return ok && cl.State.Inflight.Len() == n
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func receiveIngest(t *testing.T, out <-chan domain.IngestRequest) domain.IngestRequest {
+	t.Helper()
+	select {
+	case req := <-out:
+		return req
+	case <-time.After(5 * time.Second):
+		t.Fatal("no ingest request received")
+		return domain.IngestRequest{}
+	}
+}
+
+func TestParseMQTTMessage(t *testing.T) {
+	vehicleID := uuid.New()
+	topic := "fleet/" + vehicleID.String() + "/telemetry"
+	status := `{"location":[55.29,25.27],"speed":42,"timestamp":"2025-06-17T09:00:00Z"}`
+
+	t.Run("Bare status", func(t *testing.T) {
+		req, err := ingest.ParseMQTTMessage("fleet/+/telemetry", topic, []byte(status))
+		require.NoError(t, err)
+		assert.Equal(t, vehicleID, uuid.UUID(req.VehicleID.Bytes))
+		assert.True(t, req.VehicleID.Valid)
+		assert.Equal(t, 42.0, req.Status.Speed)
+	})
+
+	t.Run("Ingest request from a shared subscription", func(t *testing.T) {
+		payload := `{"plate_number":"KL01AB1234","message_id":"m-1","status":` + status + `}`
+		req, err := ingest.ParseMQTTMessage("$share/trackers/fleet/+/telemetry", topic, []byte(payload))
+		require.NoError(t, err)
+		assert.Equal(t, vehicleID, uuid.UUID(req.VehicleID.Bytes))
+		assert.Equal(t, "KL01AB1234", req.PlateNumber)
+		assert.Equal(t, "m-1", req.MessageID)
+		assert.Equal(t, []float64{55.29, 25.27}, req.Status.Location)
+	})
+
+	t.Run("Vehicle from the payload", func(t *testing.T) {
+		payload := `{"vehicle_id":"` + vehicleID.String() + `","status":` + status + `}`
+		req, err := ingest.ParseMQTTMessage("fleet/telemetry", "fleet/telemetry", []byte(payload))
+		require.NoError(t, err)
+		assert.Equal(t, vehicleID, uuid.UUID(req.VehicleID.Bytes))
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := ingest.ParseMQTTMessage("fleet/+/telemetry", "fleet/nope/telemetry", []byte(status))
+		assert.EqualError(t, err, "invalid vehicle ID in topic")
+
+		payload := `{"vehicle_id":"` + uuid.NewString() + `","status":` + status + `}`
+		_, err = ingest.ParseMQTTMessage("fleet/+/telemetry", topic, []byte(payload))
+		assert.EqualError(t, err, "vehicle_id does not match the topic")
+
+		_, err = ingest.ParseMQTTMessage("fleet/+/telemetry", topic, []byte(`{"speed":`))
+		assert.EqualError(t, err, "invalid payload")
+	})
+}
+
+func TestMQTTSource(t *testing.T) {
+	broker, url := startBroker(t)
+	vehicleID := uuid.New()
+	topic := "fleet/" + vehicleID.String() + "/telemetry"
+	payload := []byte(`{"location":[55.29,25.27],"speed":42,"timestamp":"2025-06-17T09:00:00Z"}`)
+	cfg := ingest.MQTTConfig{
+		BrokerURL: url,
+		ClientID:  "fleet-tracker-test",
+		Topics:    []string{"fleet/+/telemetry"},
+		QoS:       1,
+	}
+
+	t.Run("Ingests readings", func(t *testing.T) {
+		out := make(chan domain.IngestRequest, 1)
+		stop := runMQTTSource(t, broker, cfg, ingestTo(out), topic)
+		defer stop()
+
+		require.NoError(t, broker.Publish(topic, []byte(`not json`), false, 1))
+		require.NoError(t, broker.Publish(topic, payload, false, 1))
+
+		req := receiveIngest(t, out)
+		assert.Equal(t, vehicleID, uuid.UUID(req.VehicleID.Bytes))
+		assert.Equal(t, 42.0, req.Status.Speed)
+		waitInflight(t, broker, cfg.ClientID, 0)
+	})
+
+	t.Run("Acknowledges rejected readings", func(t *testing.T) {
+		out := make(chan domain.IngestRequest, 1)
+		service := new(MockVehicleService)
+		service.On("IngestData", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			out <- args.Get(1).(domain.IngestRequest)
+		}).Return(&domain.ValidationError{Fields: []domain.FieldError{{Field: "status.speed", Message: "must not be negative"}}}).Once()
+		stop := runMQTTSource(t, broker, cfg, service, topic)
+		defer stop()
+
+		require.NoError(t, broker.Publish(topic, payload, false, 1))
+		receiveIngest(t, out)
+		waitInflight(t, broker, cfg.ClientID, 0)
+	})
+
+	t.Run("Redelivers messages that were not stored after reconnecting", func(t *testing.T) {
+		// The store fails, so the message is never acknowledged.
+		failing := new(MockVehicleService)
+		failing.On("IngestData", mock.Anything, mock.Anything).Return(errors.New("database unavailable"))
+		stop := runMQTTSource(t, broker, cfg, failing, topic)
+		require.NoError(t, broker.Publish(topic, payload, false, 1))
+		waitInflight(t, broker, cfg.ClientID, 1)
+		stop()
+
+		out := make(chan domain.IngestRequest, 1)
+		runMQTTSource(t, broker, cfg, ingestTo(out), topic)
+
+		req := receiveIngest(t, out)
+		assert.Equal(t, vehicleID, uuid.UUID(req.VehicleID.Bytes))
+	})
+}
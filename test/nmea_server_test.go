@@ -0,0 +1,161 @@
+package test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/ingest"
+	"github.com/AnjuRKrishnan/fleet-tracker/pkg/nmea"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const (
+	rmcDubai = "$GNRMC,081836.00,A,2516.6192,N,05517.7749,E,32.40,87.5,170625,,,A*7B"
+	ggaDubai = "$GNGGA,081836.00,2516.6192,N,05517.7749,E,1,11,0.9,12.4,M,-27.1,M,,*66"
+	rmcVoid  = "$GPRMC,081837.00,V,,,,,,,170625,,,N*7F"
+	gsv      = "$GPGSV,3,1,11,03,03,111,00,04,15,270,00,06,01,010,00,13,06,292,00*74"
+)
+
+func TestNMEAParse(t *testing.T) {
+	t.Run("RMC", func(t *testing.T) {
+		s, err := nmea.Parse("$GPRMC,092751.000,A,5321.6802,N,00630.3371,W,0.06,31.66,280511,,,A*45\r\n")
+		require.NoError(t, err)
+		rmc := s.(nmea.RMC)
+		assert.True(t, rmc.Valid)
+		assert.Equal(t, time.Date(2011, 5, 28, 9, 27, 51, 0, time.UTC), rmc.Time)
+		assert.InDelta(t, 53.36134, rmc.Latitude, 1e-5)
+		assert.InDelta(t, -6.50562, rmc.Longitude, 1e-5)
+		assert.Equal(t, 0.06, rmc.SpeedKnots)
+		assert.Equal(t, 31.66, *rmc.Course)
+	})
+
+	t.Run("GGA", func(t *testing.T) {
+		s, err := nmea.Parse(ggaDubai)
+		require.NoError(t, err)
+		gga := s.(nmea.GGA)
+		assert.Equal(t, 8*time.Hour+18*time.Minute+36*time.Second, gga.TimeOfDay)
+		assert.Equal(t, 1, gga.Quality)
+		assert.Equal(t, 11, *gga.Satellites)
+		assert.Equal(t, 0.9, *gga.HDOP)
+		assert.Equal(t, 12.4, *gga.Altitude)
+	})
+
+	t.Run("Void RMC", func(t *testing.T) {
+		s, err := nmea.Parse(rmcVoid)
+		require.NoError(t, err)
+		assert.False(t, s.(nmea.RMC).Valid)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := nmea.Parse(gsv)
+		assert.ErrorIs(t, err, nmea.ErrUnsupported)
+
+		_, err = nmea.Parse("$GNRMC,081836.00,A,2516.6192,N,05517.7749,E,32.40,87.5,170625,,,A*7C")
+		assert.ErrorIs(t, err, nmea.ErrChecksum)
+
+		_, err = nmea.Parse("$GNRMC,081836.00,A,2516.6192,X,05517.7749,E,32.40,87.5,170625,,,A")
+		assert.EqualError(t, err, "nmea: invalid latitude")
+
+		_, err = nmea.Parse("GNRMC,081836.00,A")
+		assert.Error(t, err)
+	})
+}
+
+// startNMEAServer serves NMEA on a local port and returns its address and
+// the queue it feeds.
+func startNMEAServer(t *testing.T, devices *MockDeviceService, idleTimeout time.Duration) (string, chan domain.IngestRequest) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	out := make(chan domain.IngestRequest, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	server := ingest.NewNMEAServer("", idleTimeout, devices, out, zap.NewNop())
+	go func() { done <- server.Serve(ctx, ln) }()
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done)
+	})
+	return ln.Addr().String(), out
+}
+
+func dialNMEA(t *testing.T, addr string, lines ...string) net.Conn {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	for _, line := range lines {
+		_, err := io.WriteString(conn, line+"\r\n")
+		require.NoError(t, err)
+	}
+	return conn
+}
+
+// assertClosed checks that the server closes the connection.
+func assertClosed(t *testing.T, conn net.Conn) {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	_, err := bufio.NewReader(conn).ReadByte()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestNMEAServer(t *testing.T) {
+	vehicleID := uuid.New()
+	devices := new(MockDeviceService)
+	devices.On("ResolveDevice", mock.Anything, "GPS-0042").Return(&domain.Device{
+		Identifier:  "GPS-0042",
+		VehicleID:   vehicleID,
+		PlateNumber: "KL01AB1234",
+	}, nil)
+	devices.On("ResolveDevice", mock.Anything, "GPS-9999").Return(nil, domain.ErrNotFound)
+
+	addr, out := startNMEAServer(t, devices, time.Second)
+
+	t.Run("Ingests fixes", func(t *testing.T) {
+		dialNMEA(t, addr, "GPS-0042", gsv, ggaDubai, rmcVoid, "$GPRMC,garbage", rmcDubai)
+
+		req := receiveIngest(t, out)
+		assert.Equal(t, vehicleID, uuid.UUID(req.VehicleID.Bytes))
+		assert.Equal(t, "KL01AB1234", req.PlateNumber)
+		assert.NoError(t, req.Validate())
+
+		status := req.Status
+		assert.Equal(t, time.Date(2025, 6, 17, 8, 18, 36, 0, time.UTC), status.Timestamp)
+		assert.InDelta(t, 55.29625, status.Location[0], 1e-5)
+		assert.InDelta(t, 25.27699, status.Location[1], 1e-5)
+		assert.InDelta(t, 60.0048, status.Speed, 1e-4)
+		assert.Equal(t, 87.5, *status.Heading)
+		assert.Equal(t, 11, *status.Satellites)
+		assert.Equal(t, 12.4, *status.Altitude)
+
+		select {
+		case extra := <-out:
+			t.Fatalf("unexpected reading %+v", extra)
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	t.Run("Unregistered device", func(t *testing.T) {
+		assertClosed(t, dialNMEA(t, addr, "GPS-9999", rmcDubai))
+	})
+
+	t.Run("Sentences before the identifier", func(t *testing.T) {
+		assertClosed(t, dialNMEA(t, addr, rmcDubai))
+	})
+
+	t.Run("Idle timeout", func(t *testing.T) {
+		start := time.Now()
+		assertClosed(t, dialNMEA(t, addr, "GPS-0042"))
+		assert.GreaterOrEqual(t, time.Since(start), 900*time.Millisecond)
+	})
+}
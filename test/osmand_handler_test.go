@@ -0,0 +1,154 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	handler "github.com/AnjuRKrishnan/fleet-tracker/internal/handler"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/ingest"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestParseOsmAnd(t *testing.T) {
+	t.Run("Full", func(t *testing.T) {
+		form, _ := url.ParseQuery("id=phone-7&lat=25.2769&lon=55.2962&timestamp=1750148316&speed=10&bearing=87.5&altitude=12.4&accuracy=4.5&hdop=0.9&ignition=true&batt=80")
+		reading, err := ingest.ParseOsmAnd(form)
+		require.NoError(t, err)
+		assert.Equal(t, "phone-7", reading.DeviceID)
+		assert.True(t, reading.Valid)
+		assert.Equal(t, []float64{55.2962, 25.2769}, reading.Status.Location)
+		assert.Equal(t, time.Date(2025, 6, 17, 8, 18, 36, 0, time.UTC), reading.Status.Timestamp)
+		assert.InDelta(t, 18.52, reading.Status.Speed, 1e-9)
+		assert.Equal(t, 87.5, *reading.Status.Heading)
+		assert.Equal(t, 12.4, *reading.Status.Altitude)
+		assert.Equal(t, 4.5, *reading.Status.Accuracy)
+		assert.Equal(t, 0.9, *reading.Status.HDOP)
+		assert.True(t, *reading.Status.Ignition)
+	})
+
+	t.Run("Alternative names and formats", func(t *testing.T) {
+		want := time.Date(2025, 6, 17, 8, 18, 36, 0, time.UTC)
+		for _, query := range []string{
+			"deviceid=phone-7&location=25.2769,55.2962&timestamp=1750148316000&heading=87.5",
+			"id=phone-7&lat=25.2769&lon=55.2962&timestamp=2025-06-17T12:18:36%2B04:00",
+			"id=phone-7&lat=25.2769&lon=55.2962&timestamp=2025-06-17+08:18:36",
+		} {
+			form, _ := url.ParseQuery(query)
+			reading, err := ingest.ParseOsmAnd(form)
+			require.NoError(t, err, query)
+			assert.Equal(t, "phone-7", reading.DeviceID)
+			assert.Equal(t, []float64{55.2962, 25.2769}, reading.Status.Location)
+			assert.Equal(t, want, reading.Status.Timestamp, query)
+		}
+	})
+
+	t.Run("Defaults", func(t *testing.T) {
+		form, _ := url.ParseQuery("id=phone-7&lat=25.2769&lon=55.2962&valid=false")
+		reading, err := ingest.ParseOsmAnd(form)
+		require.NoError(t, err)
+		assert.False(t, reading.Valid)
+		assert.WithinDuration(t, time.Now(), reading.Status.Timestamp, time.Minute)
+		assert.Nil(t, reading.Status.Heading)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		for query, want := range map[string]string{
+			"lat=25.2769&lon=55.2962":                        "missing id",
+			"id=phone-7&lon=55.2962":                         "invalid lat",
+			"id=phone-7&lat=25.2769&lon=NaN":                 "invalid lon",
+			"id=phone-7&lat=25.2769&lon=55.2962&timestamp=x": "invalid timestamp",
+			"id=phone-7&lat=25.2769&lon=55.2962&speed=fast":  "invalid speed",
+		} {
+			form, _ := url.ParseQuery(query)
+			_, err := ingest.ParseOsmAnd(form)
+			assert.EqualError(t, err, want, query)
+		}
+	})
+}
+
+func TestOsmAndHandler(t *testing.T) {
+	device := &domain.Device{Identifier: "phone-7", VehicleID: uuid.New(), PlateNumber: "KL01AB1234"}
+	const query = "id=phone-7&lat=25.2769&lon=55.2962&timestamp=1750148316&speed=10"
+
+	newHandler := func() (*MockDeviceService, *MockVehicleService, http.HandlerFunc) {
+		devices, vehicles := new(MockDeviceService), new(MockVehicleService)
+		devices.On("AuthenticateDevice", mock.Anything, "phone-7", "secret").Return(device, nil)
+		devices.On("AuthenticateDevice", mock.Anything, "phone-7", mock.Anything).Return(nil, domain.ErrInvalidDeviceToken)
+		return devices, vehicles, handler.NewOsmAndHandler(devices, vehicles, zap.NewNop()).Ingest
+	}
+	matchesReading := mock.MatchedBy(func(req domain.IngestRequest) bool {
+		return uuid.UUID(req.VehicleID.Bytes) == device.VehicleID &&
+			req.PlateNumber == "KL01AB1234" &&
+			req.MessageID == "osmand:1750148316000" &&
+			req.Status.Location[1] == 25.2769
+	})
+
+	t.Run("GET with token parameter", func(t *testing.T) {
+		_, vehicles, h := newHandler()
+		vehicles.On("IngestData", mock.Anything, matchesReading).Return(nil).Once()
+
+		rr := httptest.NewRecorder()
+		h(rr, httptest.NewRequest(http.MethodGet, "/api/osmand?"+query+"&token=secret", nil))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		vehicles.AssertExpectations(t)
+	})
+
+	t.Run("POST form with bearer token", func(t *testing.T) {
+		_, vehicles, h := newHandler()
+		vehicles.On("IngestData", mock.Anything, matchesReading).Return(nil).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/osmand", strings.NewReader(query))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Authorization", "Bearer secret")
+		rr := httptest.NewRecorder()
+		h(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		vehicles.AssertExpectations(t)
+	})
+
+	t.Run("Invalid token", func(t *testing.T) {
+		_, vehicles, h := newHandler()
+
+		rr := httptest.NewRecorder()
+		h(rr, httptest.NewRequest(http.MethodGet, "/api/osmand?"+query+"&token=guess", nil))
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		vehicles.AssertNotCalled(t, "IngestData", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Duplicate", func(t *testing.T) {
+		_, vehicles, h := newHandler()
+		vehicles.On("IngestData", mock.Anything, matchesReading).Return(domain.ErrDuplicateMessage).Once()
+
+		rr := httptest.NewRecorder()
+		h(rr, httptest.NewRequest(http.MethodGet, "/api/osmand?"+query+"&token=secret", nil))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("No fix", func(t *testing.T) {
+		_, vehicles, h := newHandler()
+
+		rr := httptest.NewRecorder()
+		h(rr, httptest.NewRequest(http.MethodGet, "/api/osmand?"+query+"&valid=false&token=secret", nil))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		vehicles.AssertNotCalled(t, "IngestData", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Bad request", func(t *testing.T) {
+		devices, _, h := newHandler()
+
+		rr := httptest.NewRecorder()
+		h(rr, httptest.NewRequest(http.MethodGet, "/api/osmand?id=phone-7&lat=north&token=secret", nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "invalid lat")
+		devices.AssertNotCalled(t, "AuthenticateDevice", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
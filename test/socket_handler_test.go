@@ -0,0 +1,140 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/auth"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	handler "github.com/AnjuRKrishnan/fleet-tracker/internal/handler"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/middleware"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/services"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// socketMessage mirrors the messages of the live socket.
+type socketMessage struct {
+	Type       string                 `json:"type"`
+	Event      *domain.Event          `json:"event"`
+	Vehicles   []uuid.UUID            `json:"vehicles"`
+	Geofences  []uuid.UUID            `json:"geofences"`
+	AlertTypes []domain.AlertRuleKind `json:"alert_types"`
+	Count      uint64                 `json:"count"`
+	Error      string                 `json:"error"`
+}
+
+// dialLiveSocket serves the socket behind the same middleware as the server
+// and connects to it with a valid token.
+func dialLiveSocket(t *testing.T, hub *services.LiveHub) *websocket.Conn {
+	t.Helper()
+	jwtAuth := auth.NewJWTAuth("test-secret")
+	h := handler.NewSocketHandler(hub, zap.NewNop())
+
+	r := chi.NewRouter()
+	r.Use(middleware.RequestLogger(zap.NewNop()))
+	r.Use(middleware.TokenFromQuery("access_token"))
+	r.Use(middleware.JWTAuthenticator(jwtAuth))
+	r.Get("/ws", h.Subscribe)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	token, err := jwtAuth.GenerateToken("operator")
+	require.NoError(t, err)
+	conn, _, err := websocket.DefaultDialer.Dial(url+"?access_token="+token, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func readSocket(t *testing.T, conn *websocket.Conn) socketMessage {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	var msg socketMessage
+	require.NoError(t, conn.ReadJSON(&msg))
+	return msg
+}
+
+func TestSocketHandler_Subscribe(t *testing.T) {
+	hub := startLiveHubs(t, 1)[0]
+	conn := dialLiveSocket(t, hub)
+	ctx := context.Background()
+
+	vehicleID, otherID, fenceID := uuid.New(), uuid.New(), uuid.New()
+	now := time.Date(2025, 6, 17, 9, 0, 0, 0, time.UTC)
+
+	require.NoError(t, conn.WriteJSON(map[string]any{
+		"action":      "subscribe",
+		"vehicles":    []uuid.UUID{vehicleID},
+		"geofences":   []uuid.UUID{fenceID},
+		"alert_types": []string{"speeding"},
+	}))
+	msg := readSocket(t, conn)
+	assert.Equal(t, "subscriptions", msg.Type)
+	assert.Equal(t, []uuid.UUID{vehicleID}, msg.Vehicles)
+	assert.Equal(t, []uuid.UUID{fenceID}, msg.Geofences)
+	assert.Equal(t, []domain.AlertRuleKind{domain.AlertSpeeding}, msg.AlertTypes)
+
+	fenceEvent := domain.NewEvent(domain.EventGeofenceEntered, otherID, now, domain.GeofenceEvent{GeofenceID: fenceID})
+	fenceEvent.GeofenceID = &fenceID
+	noUpdate := domain.NewEvent(domain.EventAlertOpened, otherID, now, domain.Alert{Kind: domain.AlertNoUpdate})
+	noUpdate.AlertKind = domain.AlertNoUpdate
+	speeding := domain.NewEvent(domain.EventAlertOpened, otherID, now, domain.Alert{Kind: domain.AlertSpeeding})
+	speeding.AlertKind = domain.AlertSpeeding
+
+	hub.Publish(ctx, domain.NewEvent(domain.EventStatusUpdated, otherID, now, domain.VehicleStatus{}))
+	hub.Publish(ctx, domain.NewEvent(domain.EventTripStarted, vehicleID, now, domain.Trip{}))
+	hub.Publish(ctx, fenceEvent)
+	hub.Publish(ctx, noUpdate)
+	hub.Publish(ctx, speeding)
+
+	var got []domain.EventType
+	for range 3 {
+		msg := readSocket(t, conn)
+		require.Equal(t, "event", msg.Type)
+		got = append(got, msg.Event.Type)
+	}
+	assert.Equal(t, []domain.EventType{domain.EventTripStarted, domain.EventGeofenceEntered, domain.EventAlertOpened}, got)
+
+	t.Run("Unsubscribe", func(t *testing.T) {
+		require.NoError(t, conn.WriteJSON(map[string]any{
+			"action":    "unsubscribe",
+			"geofences": []uuid.UUID{fenceID},
+		}))
+		msg := readSocket(t, conn)
+		assert.Equal(t, "subscriptions", msg.Type)
+		assert.Empty(t, msg.Geofences)
+
+		hub.Publish(ctx, fenceEvent)
+		hub.Publish(ctx, domain.NewEvent(domain.EventStatusUpdated, vehicleID, now, domain.VehicleStatus{Speed: 30}))
+
+		msg = readSocket(t, conn)
+		require.Equal(t, "event", msg.Type)
+		assert.Equal(t, domain.EventStatusUpdated, msg.Event.Type)
+		assert.Equal(t, vehicleID, msg.Event.VehicleID)
+	})
+
+	t.Run("Invalid requests", func(t *testing.T) {
+		require.NoError(t, conn.WriteJSON(map[string]any{"action": "watch"}))
+		assert.Equal(t, socketMessage{Type: "error", Error: "Invalid action"}, readSocket(t, conn))
+
+		require.NoError(t, conn.WriteJSON(map[string]any{"action": "subscribe", "alert_types": []string{"parking"}}))
+		assert.Equal(t, socketMessage{Type: "error", Error: "Invalid alert_types"}, readSocket(t, conn))
+
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"action":"subscribe","vehicles":["nope"]}`)))
+		assert.Equal(t, socketMessage{Type: "error", Error: "Invalid message"}, readSocket(t, conn))
+	})
+}
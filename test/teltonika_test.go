@@ -0,0 +1,285 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/ingest"
+	"github.com/AnjuRKrishnan/fleet-tracker/pkg/teltonika"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// teltonikaFixture returns a packet from testdata/teltonika. The fixtures
+// without GPS are the examples of the Teltonika protocol documentation; the
+// *_gps ones follow the same format with a fix and common FMB IO elements.
+func teltonikaFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join("testdata", "teltonika", name+".hex"))
+	require.NoError(t, err)
+	packet, err := hex.DecodeString(strings.TrimSpace(string(raw)))
+	require.NoError(t, err)
+	return packet
+}
+
+func decodeTeltonikaFixture(t *testing.T, name string) *teltonika.Packet {
+	t.Helper()
+	packet, err := teltonika.ReadPacket(bytes.NewReader(teltonikaFixture(t, name)))
+	require.NoError(t, err)
+	return packet
+}
+
+func ioUint(t *testing.T, record teltonika.Record, id uint16) uint64 {
+	t.Helper()
+	v, ok := record.IOUint(id)
+	require.True(t, ok, "IO element %d", id)
+	return v
+}
+
+func TestTeltonikaDecode(t *testing.T) {
+	t.Run("Codec 8", func(t *testing.T) {
+		packet := decodeTeltonikaFixture(t, "codec8_single")
+		assert.Equal(t, teltonika.Codec8, packet.Codec)
+		require.Len(t, packet.Records, 1)
+
+		record := packet.Records[0]
+		assert.Equal(t, time.Date(2019, 6, 10, 10, 4, 46, 0, time.UTC), record.Timestamp)
+		assert.Equal(t, byte(1), record.Priority)
+		assert.Equal(t, uint16(1), record.EventIOID)
+		assert.Len(t, record.IO, 5)
+		assert.Equal(t, uint64(3), ioUint(t, record, 21))
+		assert.Equal(t, uint64(1), ioUint(t, record, 1))
+		assert.Equal(t, uint64(0x5E0F), ioUint(t, record, 66))
+		assert.Equal(t, uint64(0x601A), ioUint(t, record, 241))
+		assert.Equal(t, uint64(0), ioUint(t, record, 78))
+	})
+
+	t.Run("Codec 8 with two records", func(t *testing.T) {
+		packet := decodeTeltonikaFixture(t, "codec8_two_records")
+		require.Len(t, packet.Records, 2)
+		assert.Equal(t, time.Date(2019, 6, 10, 10, 1, 1, 0, time.UTC), packet.Records[0].Timestamp)
+		assert.Equal(t, time.Date(2019, 6, 10, 10, 1, 19, 0, time.UTC), packet.Records[1].Timestamp)
+		assert.Equal(t, uint64(0), ioUint(t, packet.Records[0], 1))
+		assert.Equal(t, uint64(1), ioUint(t, packet.Records[1], 1))
+	})
+
+	t.Run("Codec 8 with GPS", func(t *testing.T) {
+		packet := decodeTeltonikaFixture(t, "codec8_gps")
+		require.Len(t, packet.Records, 2)
+
+		moving := packet.Records[0]
+		assert.Equal(t, time.Date(2025, 6, 17, 8, 18, 36, 0, time.UTC), moving.Timestamp)
+		assert.InDelta(t, 55.2962, moving.Longitude, 1e-7)
+		assert.InDelta(t, 25.2769, moving.Latitude, 1e-7)
+		assert.Equal(t, int16(12), moving.Altitude)
+		assert.Equal(t, uint16(87), moving.Angle)
+		assert.Equal(t, byte(11), moving.Satellites)
+		assert.Equal(t, uint16(60), moving.Speed)
+		assert.Equal(t, uint64(123456), ioUint(t, moving, 16))
+
+		parked := packet.Records[1]
+		assert.Zero(t, parked.Longitude)
+		assert.Equal(t, uint16(239), parked.EventIOID)
+		assert.Equal(t, uint64(0), ioUint(t, parked, 239))
+	})
+
+	t.Run("Codec 8 Extended", func(t *testing.T) {
+		packet := decodeTeltonikaFixture(t, "codec8e_single")
+		assert.Equal(t, teltonika.Codec8E, packet.Codec)
+		require.Len(t, packet.Records, 1)
+
+		record := packet.Records[0]
+		assert.Equal(t, time.Date(2019, 6, 10, 11, 36, 32, 0, time.UTC), record.Timestamp)
+		assert.Len(t, record.IO, 5)
+		assert.Equal(t, uint64(1), ioUint(t, record, 1))
+		assert.Equal(t, uint64(0x1D), ioUint(t, record, 17))
+		assert.Equal(t, uint64(0x015E2C88), ioUint(t, record, 16))
+		assert.Equal(t, uint64(0x3544C87A), ioUint(t, record, 11))
+		assert.Equal(t, uint64(0x1DD7E06A), ioUint(t, record, 14))
+	})
+
+	t.Run("Codec 8 Extended with GPS and variable-length IO", func(t *testing.T) {
+		packet := decodeTeltonikaFixture(t, "codec8e_gps")
+		require.Len(t, packet.Records, 1)
+
+		record := packet.Records[0]
+		assert.InDelta(t, -6.50562, record.Longitude, 1e-7)
+		assert.InDelta(t, 53.36134, record.Latitude, 1e-7)
+		assert.Equal(t, int16(-3), record.Altitude)
+		assert.Equal(t, uint16(385), record.EventIOID)
+		assert.Equal(t, uint64(893910000000001), ioUint(t, record, 11))
+		assert.Equal(t, []byte{0x01, 0x10, 0xE7}, record.IO[385])
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		corrupt := teltonikaFixture(t, "codec8_gps")
+		corrupt[20] ^= 0xFF
+		_, err := teltonika.ReadPacket(bytes.NewReader(corrupt))
+		assert.ErrorIs(t, err, teltonika.ErrCRC)
+
+		truncated := teltonikaFixture(t, "codec8_gps")
+		_, err = teltonika.ReadPacket(bytes.NewReader(truncated[:len(truncated)-10]))
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+		// A GPRS command packet in Codec 12.
+		_, err = teltonika.DecodeData([]byte{0x0C, 0x01, 0x05, 0x00, 0x00, 0x00, 0x07, 'g', 'e', 't', 'i', 'n', 'f', 'o', 0x01})
+		assert.ErrorIs(t, err, teltonika.ErrUnsupportedCodec)
+
+		_, err = teltonika.DecodeData([]byte{0x08, 0x02, 0x01})
+		assert.EqualError(t, err, "teltonika: truncated packet")
+	})
+
+	t.Run("IMEI", func(t *testing.T) {
+		imei, err := teltonika.ReadIMEI(bytes.NewReader(append([]byte{0x00, 0x0F}, "356307042441013"...)))
+		require.NoError(t, err)
+		assert.Equal(t, "356307042441013", imei)
+
+		_, err = teltonika.ReadIMEI(bytes.NewReader([]byte{0x10, 0x00}))
+		assert.Error(t, err)
+		_, err = teltonika.ReadIMEI(bytes.NewReader(append([]byte{0x00, 0x03}, "abc"...)))
+		assert.Error(t, err)
+	})
+}
+
+func TestTeltonikaServer(t *testing.T) {
+	vehicleID := uuid.New()
+	devices := new(MockDeviceService)
+	devices.On("ResolveDevice", mock.Anything, "356307042441013").Return(&domain.Device{
+		Identifier:  "356307042441013",
+		VehicleID:   vehicleID,
+		PlateNumber: "KL01AB1234",
+	}, nil)
+	devices.On("ResolveDevice", mock.Anything, "356307042441014").Return(nil, domain.ErrNotFound)
+	devices.On("ResolveDevice", mock.Anything, "356307042441015").Return(&domain.Device{
+		Identifier:  "356307042441015",
+		VehicleID:   uuid.New(),
+		PlateNumber: "KL01AB5678",
+	}, nil)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	out := make(chan domain.IngestRequest, 10)
+	service := new(MockVehicleService)
+	service.On("IngestBatch", mock.Anything, mock.MatchedBy(func(items []domain.IngestRequest) bool {
+		return uuid.UUID(items[0].VehicleID.Bytes) == vehicleID
+	})).Run(func(args mock.Arguments) {
+		for _, item := range args.Get(1).([]domain.IngestRequest) {
+			out <- item
+		}
+	}).Return([]domain.IngestResult{}, nil)
+	service.On("IngestBatch", mock.Anything, mock.Anything).Return(nil, errors.New("database unavailable"))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	server := ingest.NewTeltonikaServer("", time.Minute, devices, service, zap.NewNop())
+	go func() { done <- server.Serve(ctx, ln) }()
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done)
+	})
+
+	// handshake connects as imei and returns the server's reply.
+	handshake := func(t *testing.T, imei string) (net.Conn, byte) {
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+		_, err = conn.Write(append([]byte{0x00, byte(len(imei))}, imei...))
+		require.NoError(t, err)
+		reply := make([]byte, 1)
+		_, err = io.ReadFull(conn, reply)
+		require.NoError(t, err)
+		return conn, reply[0]
+	}
+	readAck := func(t *testing.T, conn net.Conn) []byte {
+		ack := make([]byte, 4)
+		_, err := io.ReadFull(conn, ack)
+		require.NoError(t, err)
+		return ack
+	}
+
+	t.Run("Ingests records", func(t *testing.T) {
+		conn, reply := handshake(t, "356307042441013")
+		require.Equal(t, teltonika.AcceptIMEI, reply)
+
+		_, err := conn.Write(teltonikaFixture(t, "codec8_gps"))
+		require.NoError(t, err)
+		assert.Equal(t, teltonika.Ack(2), readAck(t, conn))
+
+		// The parked record has no fix and is acknowledged but skipped.
+		req := receiveIngest(t, out)
+		assert.Equal(t, vehicleID, uuid.UUID(req.VehicleID.Bytes))
+		assert.Equal(t, "KL01AB1234", req.PlateNumber)
+		assert.Equal(t, "teltonika:1750148316000", req.MessageID)
+		assert.NoError(t, req.Validate())
+
+		status := req.Status
+		assert.Equal(t, time.Date(2025, 6, 17, 8, 18, 36, 0, time.UTC), status.Timestamp)
+		assert.InDelta(t, 55.2962, status.Location[0], 1e-7)
+		assert.InDelta(t, 25.2769, status.Location[1], 1e-7)
+		assert.Equal(t, 60.0, status.Speed)
+		assert.Equal(t, 87.0, *status.Heading)
+		assert.Equal(t, 12.0, *status.Altitude)
+		assert.Equal(t, 11, *status.Satellites)
+		assert.True(t, *status.Ignition)
+		assert.Equal(t, 123.456, *status.Odometer)
+		assert.InDelta(t, 0.9, *status.HDOP, 1e-9)
+
+		select {
+		case extra := <-out:
+			t.Fatalf("unexpected reading %+v", extra)
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		// The connection stays open for the next packet.
+		_, err = conn.Write(teltonikaFixture(t, "codec8e_gps"))
+		require.NoError(t, err)
+		assert.Equal(t, teltonika.Ack(1), readAck(t, conn))
+		assert.InDelta(t, 53.36134, receiveIngest(t, out).Status.Location[1], 1e-7)
+	})
+
+	t.Run("Bad CRC", func(t *testing.T) {
+		conn, reply := handshake(t, "356307042441013")
+		require.Equal(t, teltonika.AcceptIMEI, reply)
+
+		corrupt := teltonikaFixture(t, "codec8_gps")
+		corrupt[20] ^= 0xFF
+		_, err := conn.Write(corrupt)
+		require.NoError(t, err)
+		assert.Equal(t, teltonika.Ack(0), readAck(t, conn))
+
+		// The device resends the packet.
+		_, err = conn.Write(teltonikaFixture(t, "codec8_gps"))
+		require.NoError(t, err)
+		assert.Equal(t, teltonika.Ack(2), readAck(t, conn))
+		receiveIngest(t, out)
+	})
+
+	t.Run("Records that cannot be stored", func(t *testing.T) {
+		conn, reply := handshake(t, "356307042441015")
+		require.Equal(t, teltonika.AcceptIMEI, reply)
+
+		// The packet is not acknowledged, so the device sends it again.
+		_, err := conn.Write(teltonikaFixture(t, "codec8_gps"))
+		require.NoError(t, err)
+		assertClosed(t, conn)
+	})
+
+	t.Run("Unregistered IMEI", func(t *testing.T) {
+		conn, reply := handshake(t, "356307042441014")
+		assert.Equal(t, teltonika.RejectIMEI, reply)
+		assertClosed(t, conn)
+	})
+}
@@ -0,0 +1,222 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/services"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func statusAt(start time.Time, offset time.Duration, lon, lat, speed float64) domain.VehicleStatus {
+	return domain.VehicleStatus{
+		Location:  []float64{lon, lat},
+		Speed:     speed,
+		Timestamp: start.Add(offset),
+	}
+}
+
+// --- Tests for TripDetector ---
+func TestTripDetector_Process(t *testing.T) {
+	vehicleID := uuid.New()
+	start := time.Date(2025, 6, 17, 9, 0, 0, 0, time.UTC)
+
+	t.Run("Opens and closes a trip", func(t *testing.T) {
+		repo := new(MockVehicleRepository)
+		var closed domain.Trip
+		repo.On("CreateTrip", mock.Anything, mock.AnythingOfType("domain.Trip")).Return(nil).Once()
+		repo.On("CloseTrip", mock.Anything, mock.AnythingOfType("domain.Trip")).
+			Run(func(args mock.Arguments) { closed = args.Get(1).(domain.Trip) }).
+			Return(nil).Once()
+
+		d := services.NewTripDetector(repo, 5*time.Minute, 5, zap.NewNop())
+		ctx := context.Background()
+
+		// Parked, moving east along the equator for two minutes, then parked.
+		readings := []domain.VehicleStatus{
+			statusAt(start, 0, 0, 0, 0),
+			statusAt(start, time.Minute, 0, 0, 40),
+			statusAt(start, 2*time.Minute, 0.01, 0, 60),
+			statusAt(start, 3*time.Minute, 0.02, 0, 20),
+			statusAt(start, 4*time.Minute, 0.02, 0, 0),
+			statusAt(start, 6*time.Minute, 0.02, 0, 0),
+			statusAt(start, 9*time.Minute, 0.02, 0, 0),
+		}
+		for _, r := range readings {
+			assert.NoError(t, d.Process(ctx, vehicleID, r))
+		}
+
+		repo.AssertExpectations(t)
+		assert.Equal(t, start.Add(time.Minute), closed.StartTime.Time)
+		if assert.NotNil(t, closed.EndTime) {
+			assert.Equal(t, start.Add(4*time.Minute), *closed.EndTime)
+		}
+		assert.InDelta(t, 2.22, closed.Mileage, 0.01)
+		assert.InDelta(t, 40, closed.AvgSpeed, 0.001)
+	})
+
+	t.Run("Publishes trip events", func(t *testing.T) {
+		repo := new(MockVehicleRepository)
+		repo.On("CreateTrip", mock.Anything, mock.AnythingOfType("domain.Trip")).Return(nil).Once()
+		repo.On("CloseTrip", mock.Anything, mock.AnythingOfType("domain.Trip")).Return(nil).Once()
+
+		events := &recordingPublisher{}
+		d := services.NewTripDetector(repo, 5*time.Minute, 5, zap.NewNop(), services.WithTripEvents(events))
+		ctx := context.Background()
+
+		assert.NoError(t, d.Process(ctx, vehicleID, statusAt(start, 0, 0, 0, 50)))
+		assert.NoError(t, d.Process(ctx, vehicleID, statusAt(start, time.Minute, 0, 0, 0)))
+		assert.NoError(t, d.Process(ctx, vehicleID, statusAt(start, 7*time.Minute, 0, 0, 0)))
+
+		assert.Equal(t, []domain.EventType{domain.EventTripStarted, domain.EventTripEnded}, events.types())
+		assert.Equal(t, vehicleID, events.events[1].VehicleID)
+		assert.Equal(t, start.Add(time.Minute), events.events[1].OccurredAt)
+	})
+
+	t.Run("Short stop keeps the trip open", func(t *testing.T) {
+		repo := new(MockVehicleRepository)
+		repo.On("CreateTrip", mock.Anything, mock.AnythingOfType("domain.Trip")).Return(nil).Once()
+
+		d := services.NewTripDetector(repo, 5*time.Minute, 5, zap.NewNop())
+		ctx := context.Background()
+
+		assert.NoError(t, d.Process(ctx, vehicleID, statusAt(start, 0, 0, 0, 50)))
+		assert.NoError(t, d.Process(ctx, vehicleID, statusAt(start, time.Minute, 0, 0, 0)))
+		assert.NoError(t, d.Process(ctx, vehicleID, statusAt(start, 3*time.Minute, 0, 0, 0)))
+		assert.NoError(t, d.Process(ctx, vehicleID, statusAt(start, 4*time.Minute, 0, 0, 30)))
+
+		repo.AssertExpectations(t)
+		repo.AssertNotCalled(t, "CloseTrip", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Ignores out-of-order readings", func(t *testing.T) {
+		repo := new(MockVehicleRepository)
+		repo.On("CreateTrip", mock.Anything, mock.AnythingOfType("domain.Trip")).Return(nil).Once()
+
+		d := services.NewTripDetector(repo, 5*time.Minute, 5, zap.NewNop())
+		ctx := context.Background()
+
+		assert.NoError(t, d.Process(ctx, vehicleID, statusAt(start, time.Minute, 0, 0, 50)))
+		assert.NoError(t, d.Process(ctx, vehicleID, statusAt(start, 0, 1, 1, 0)))
+
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestTripDetector_Sweep(t *testing.T) {
+	vehicleID := uuid.New()
+	// Readings uploaded long after they were taken, as from a device that
+	// buffered them while offline.
+	start := time.Date(2025, 6, 17, 9, 0, 0, 0, time.UTC)
+
+	repo := new(MockVehicleRepository)
+	repo.On("CreateTrip", mock.Anything, mock.AnythingOfType("domain.Trip")).Return(nil).Once()
+	repo.On("CloseTrip", mock.Anything, mock.MatchedBy(func(trip domain.Trip) bool {
+		return trip.EndTime != nil && trip.EndTime.Equal(start.Add(time.Minute))
+	})).Return(nil).Once()
+
+	d := services.NewTripDetector(repo, 5*time.Minute, 5, zap.NewNop())
+	ctx := context.Background()
+
+	assert.NoError(t, d.Process(ctx, vehicleID, statusAt(start, 0, 0, 0, 50)))
+	assert.NoError(t, d.Process(ctx, vehicleID, statusAt(start, time.Minute, 0.01, 0, 50)))
+
+	// Idleness is measured from when the readings arrived, not when they
+	// were taken.
+	d.Sweep(ctx, time.Now().Add(3*time.Minute))
+	repo.AssertNotCalled(t, "CloseTrip", mock.Anything, mock.Anything)
+
+	d.Sweep(ctx, time.Now().Add(6*time.Minute))
+	repo.AssertExpectations(t)
+}
+
+func TestTripDetector_Restore(t *testing.T) {
+	vehicleID := uuid.New()
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	openTrip := func(startTime time.Time) domain.Trip {
+		return domain.Trip{
+			ID:        pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			VehicleID: pgtype.UUID{Bytes: vehicleID, Valid: true},
+			StartTime: pgtype.Timestamptz{Time: startTime, Valid: true},
+		}
+	}
+	positions := func(readings ...domain.VehicleStatus) []domain.Position {
+		out := make([]domain.Position, len(readings))
+		for i, r := range readings {
+			out[i] = domain.Position{ID: int64(i + 1), VehicleID: vehicleID, VehicleStatus: r}
+		}
+		return out
+	}
+	byTrip := func(trip domain.Trip) interface{} {
+		return mock.MatchedBy(func(q domain.PositionQuery) bool {
+			return q.VehicleID == vehicleID && q.From.Equal(trip.StartTime.Time) && q.After == nil
+		})
+	}
+
+	t.Run("Continues an open trip", func(t *testing.T) {
+		trip := openTrip(start.Add(55 * time.Minute))
+		repo := new(MockVehicleRepository)
+		repo.On("ListOpenTrips", mock.Anything).Return([]domain.Trip{trip}, nil)
+		repo.On("ListPositions", mock.Anything, byTrip(trip)).Return(positions(
+			statusAt(start, 55*time.Minute, 0, 0, 40),
+			statusAt(start, 56*time.Minute, 0.01, 0, 60),
+		), nil)
+		var closed domain.Trip
+		repo.On("CloseTrip", mock.Anything, mock.AnythingOfType("domain.Trip")).
+			Run(func(args mock.Arguments) { closed = args.Get(1).(domain.Trip) }).
+			Return(nil).Once()
+
+		d := services.NewTripDetector(repo, 5*time.Minute, 5, zap.NewNop())
+		ctx := context.Background()
+		assert.NoError(t, d.Restore(ctx))
+		repo.AssertNotCalled(t, "CloseTrip", mock.Anything, mock.Anything)
+
+		// The next readings extend the restored trip instead of opening one.
+		assert.NoError(t, d.Process(ctx, vehicleID, statusAt(start, 57*time.Minute, 0.02, 0, 20)))
+		assert.NoError(t, d.Process(ctx, vehicleID, statusAt(start, 58*time.Minute, 0.02, 0, 0)))
+		d.Sweep(ctx, time.Now().Add(10*time.Minute))
+
+		repo.AssertNotCalled(t, "CreateTrip", mock.Anything, mock.Anything)
+		assert.Equal(t, trip.ID, closed.ID)
+		if assert.NotNil(t, closed.EndTime) {
+			assert.Equal(t, start.Add(58*time.Minute), *closed.EndTime)
+		}
+		assert.InDelta(t, 2.22, closed.Mileage, 0.01)
+		assert.InDelta(t, 40, closed.AvgSpeed, 0.001)
+	})
+
+	t.Run("Closes trips that ended while it was down", func(t *testing.T) {
+		older, newer := openTrip(start), openTrip(start.Add(20*time.Minute))
+		repo := new(MockVehicleRepository)
+		repo.On("ListOpenTrips", mock.Anything).Return([]domain.Trip{older, newer}, nil)
+		repo.On("ListPositions", mock.Anything, byTrip(older)).Return(positions(
+			statusAt(start, 0, 0, 0, 50),
+			statusAt(start, time.Minute, 0.01, 0, 50),
+		), nil)
+		repo.On("ListPositions", mock.Anything, byTrip(newer)).Return(positions(
+			statusAt(start, 20*time.Minute, 0, 0, 50),
+			statusAt(start, 21*time.Minute, 0, 0, 0),
+			statusAt(start, 30*time.Minute, 0, 0, 0),
+		), nil)
+		var closed []domain.Trip
+		repo.On("CloseTrip", mock.Anything, mock.AnythingOfType("domain.Trip")).
+			Run(func(args mock.Arguments) { closed = append(closed, args.Get(1).(domain.Trip)) }).
+			Return(nil)
+
+		d := services.NewTripDetector(repo, 5*time.Minute, 5, zap.NewNop())
+		assert.NoError(t, d.Restore(context.Background()))
+
+		if assert.Len(t, closed, 2) {
+			assert.Equal(t, older.ID, closed[0].ID)
+			assert.Equal(t, start.Add(time.Minute), *closed[0].EndTime)
+			assert.Equal(t, newer.ID, closed[1].ID)
+			assert.Equal(t, start.Add(21*time.Minute), *closed[1].EndTime)
+		}
+	})
+}
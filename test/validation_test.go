@@ -0,0 +1,126 @@
+package test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIngestRequest_Validate(t *testing.T) {
+	valid := func() domain.IngestRequest {
+		return domain.IngestRequest{
+			VehicleID: pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			Status: domain.VehicleStatus{
+				Location:  []float64{55.296249, 25.276987},
+				Speed:     60.5,
+				Timestamp: time.Now().UTC(),
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		mutate     func(r *domain.IngestRequest)
+		wantFields []string
+	}{
+		{
+			name:   "Valid",
+			mutate: func(r *domain.IngestRequest) {},
+		},
+		{
+			name:       "Missing vehicle_id",
+			mutate:     func(r *domain.IngestRequest) { r.VehicleID = pgtype.UUID{} },
+			wantFields: []string{"vehicle_id"},
+		},
+		{
+			name:       "Zero vehicle_id",
+			mutate:     func(r *domain.IngestRequest) { r.VehicleID = pgtype.UUID{Valid: true} },
+			wantFields: []string{"vehicle_id"},
+		},
+		{
+			name:       "Empty location",
+			mutate:     func(r *domain.IngestRequest) { r.Status.Location = nil },
+			wantFields: []string{"status.location"},
+		},
+		{
+			name:       "Out of range coordinates",
+			mutate:     func(r *domain.IngestRequest) { r.Status.Location = []float64{200, 400} },
+			wantFields: []string{"status.location[0]", "status.location[1]"},
+		},
+		{
+			name:       "NaN speed",
+			mutate:     func(r *domain.IngestRequest) { r.Status.Speed = math.NaN() },
+			wantFields: []string{"status.speed"},
+		},
+		{
+			name:       "Negative speed",
+			mutate:     func(r *domain.IngestRequest) { r.Status.Speed = -1 },
+			wantFields: []string{"status.speed"},
+		},
+		{
+			name:       "Zero timestamp",
+			mutate:     func(r *domain.IngestRequest) { r.Status.Timestamp = time.Time{} },
+			wantFields: []string{"status.timestamp"},
+		},
+		{
+			name:       "Future timestamp",
+			mutate:     func(r *domain.IngestRequest) { r.Status.Timestamp = time.Now().Add(time.Hour) },
+			wantFields: []string{"status.timestamp"},
+		},
+		{
+			name: "Valid telemetry",
+			mutate: func(r *domain.IngestRequest) {
+				heading, sats, ignition, fuel := 359.5, 9, true, 100.0
+				r.Status.Heading = &heading
+				r.Status.Satellites = &sats
+				r.Status.Ignition = &ignition
+				r.Status.FuelLevel = &fuel
+			},
+		},
+		{
+			name: "Out of range telemetry",
+			mutate: func(r *domain.IngestRequest) {
+				heading, hdop, sats, fuel, hours := 360.0, -0.5, -1, 120.0, -3.0
+				r.Status.Heading = &heading
+				r.Status.HDOP = &hdop
+				r.Status.Satellites = &sats
+				r.Status.FuelLevel = &fuel
+				r.Status.EngineHours = &hours
+			},
+			wantFields: []string{"status.heading", "status.hdop", "status.satellites", "status.fuel_level", "status.engine_hours"},
+		},
+		{
+			name:       "Empty request",
+			mutate:     func(r *domain.IngestRequest) { *r = domain.IngestRequest{} },
+			wantFields: []string{"vehicle_id", "status.location", "status.timestamp"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := valid()
+			tc.mutate(&req)
+
+			err := req.Validate()
+			if tc.wantFields == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var verr *domain.ValidationError
+			if assert.True(t, errors.As(err, &verr)) {
+				var fields []string
+				for _, f := range verr.Fields {
+					fields = append(fields, f.Field)
+				}
+				assert.Equal(t, tc.wantFields, fields)
+			}
+		})
+	}
+}
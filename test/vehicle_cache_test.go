@@ -0,0 +1,343 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/store/redis"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestVehicleCache(t *testing.T) (*redis.VehicleCache, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client, err := redis.NewRedisCache("redis://" + mr.Addr())
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	client.AddHook(geoSearchLatitudeCheck{})
+	return redis.NewVehicleCache(client), mr
+}
+
+// geoSearchLatitudeCheck refuses GEOSEARCH centres beyond the latitudes GEO
+// sets index, as Redis does and miniredis does not.
+type geoSearchLatitudeCheck struct{}
+
+func (geoSearchLatitudeCheck) DialHook(next goredis.DialHook) goredis.DialHook { return next }
+
+func (geoSearchLatitudeCheck) ProcessHook(next goredis.ProcessHook) goredis.ProcessHook {
+	return func(ctx context.Context, cmd goredis.Cmder) error {
+		args := cmd.Args()
+		for i, arg := range args {
+			if s, ok := arg.(string); ok && strings.EqualFold(s, "fromlonlat") && i+2 < len(args) {
+				lat, _ := strconv.ParseFloat(fmt.Sprint(args[i+2]), 64)
+				if math.Abs(lat) > 85.05112878 {
+					err := fmt.Errorf("ERR invalid longitude,latitude pair %v,%.6f", args[i+1], lat)
+					cmd.SetErr(err)
+					return err
+				}
+			}
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (geoSearchLatitudeCheck) ProcessPipelineHook(next goredis.ProcessPipelineHook) goredis.ProcessPipelineHook {
+	return next
+}
+
+// --- Tests for VehicleCache ---
+func TestVehicleCache_SetStatus(t *testing.T) {
+	ctx := context.Background()
+	vehicleID := uuid.New()
+	now := time.Date(2025, 6, 17, 9, 0, 0, 0, time.UTC)
+
+	newer := &domain.VehicleStatus{Location: []float64{55.3, 25.3}, Speed: 50, Timestamp: now}
+	older := &domain.VehicleStatus{Location: []float64{55.1, 25.1}, Speed: 10, Timestamp: now.Add(-time.Minute)}
+
+	t.Run("Older reading does not overwrite newer", func(t *testing.T) {
+		cache, _ := newTestVehicleCache(t)
+
+		require.NoError(t, cache.SetStatus(ctx, vehicleID, newer, time.Minute))
+		require.NoError(t, cache.SetStatus(ctx, vehicleID, older, time.Minute))
+
+		got, err := cache.GetStatus(ctx, vehicleID)
+		require.NoError(t, err)
+		assert.Equal(t, newer.Location, got.Location)
+		assert.Equal(t, newer.Speed, got.Speed)
+	})
+
+	t.Run("Newer reading overwrites older", func(t *testing.T) {
+		cache, _ := newTestVehicleCache(t)
+
+		require.NoError(t, cache.SetStatus(ctx, vehicleID, older, time.Minute))
+		require.NoError(t, cache.SetStatus(ctx, vehicleID, newer, time.Minute))
+
+		got, err := cache.GetStatus(ctx, vehicleID)
+		require.NoError(t, err)
+		assert.Equal(t, newer.Speed, got.Speed)
+	})
+
+	t.Run("Expires with the status", func(t *testing.T) {
+		cache, mr := newTestVehicleCache(t)
+
+		require.NoError(t, cache.SetStatus(ctx, vehicleID, newer, time.Minute))
+		mr.FastForward(2 * time.Minute)
+		require.NoError(t, cache.SetStatus(ctx, vehicleID, older, time.Minute))
+
+		got, err := cache.GetStatus(ctx, vehicleID)
+		require.NoError(t, err)
+		assert.Equal(t, older.Speed, got.Speed)
+	})
+}
+
+func TestVehicleCache_MarkMessageSeen(t *testing.T) {
+	ctx := context.Background()
+	vehicleID := uuid.New()
+	cache, mr := newTestVehicleCache(t)
+
+	first, err := cache.MarkMessageSeen(ctx, vehicleID, "msg-1", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, first)
+
+	again, err := cache.MarkMessageSeen(ctx, vehicleID, "msg-1", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, again)
+
+	other, err := cache.MarkMessageSeen(ctx, uuid.New(), "msg-1", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, other)
+
+	require.NoError(t, cache.ForgetMessage(ctx, vehicleID, "msg-1"))
+	retried, err := cache.MarkMessageSeen(ctx, vehicleID, "msg-1", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, retried)
+
+	mr.FastForward(2 * time.Minute)
+	expired, err := cache.MarkMessageSeen(ctx, vehicleID, "msg-1", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, expired)
+}
+
+func TestVehicleCache_ListStatuses(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 6, 17, 9, 0, 0, 0, time.UTC)
+	moving, parked, unknown := uuid.New(), uuid.New(), uuid.New()
+
+	cache, mr := newTestVehicleCache(t)
+	require.NoError(t, cache.SetStatus(ctx, moving, &domain.VehicleStatus{Speed: 50, Timestamp: now}, time.Minute))
+	require.NoError(t, cache.SetStatus(ctx, moving, &domain.VehicleStatus{Speed: 10, Timestamp: now.Add(-time.Minute)}, time.Minute))
+	require.NoError(t, cache.SetStatus(ctx, parked, &domain.VehicleStatus{Timestamp: now.Add(-time.Hour)}, time.Minute))
+	// The fleet index outlives the per-vehicle status keys.
+	mr.FastForward(2 * time.Minute)
+
+	speeds := func(got []domain.VehicleSnapshot) map[uuid.UUID]float64 {
+		out := map[uuid.UUID]float64{}
+		for _, v := range got {
+			out[v.VehicleID] = v.Status.Speed
+		}
+		return out
+	}
+
+	t.Run("Whole fleet", func(t *testing.T) {
+		got, err := cache.ListStatuses(ctx, nil, time.Time{})
+		require.NoError(t, err)
+		assert.Equal(t, map[uuid.UUID]float64{moving: 50, parked: 0}, speeds(got))
+	})
+
+	t.Run("Updated since", func(t *testing.T) {
+		got, err := cache.ListStatuses(ctx, nil, now.Add(-time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, map[uuid.UUID]float64{moving: 50}, speeds(got))
+	})
+
+	t.Run("Selected vehicles", func(t *testing.T) {
+		got, err := cache.ListStatuses(ctx, []uuid.UUID{parked, unknown}, time.Time{})
+		require.NoError(t, err)
+		assert.Equal(t, map[uuid.UUID]float64{parked: 0}, speeds(got))
+	})
+}
+
+func TestVehicleCache_FindNearby(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 6, 17, 9, 0, 0, 0, time.UTC)
+	// Around Dubai: the Burj Khalifa, Dubai Marina (~18 km) and Sharjah (~20 km).
+	downtown, marina, sharjah, polar := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	cache, _ := newTestVehicleCache(t)
+	set := func(id uuid.UUID, lon, lat float64, at time.Time) {
+		t.Helper()
+		status := &domain.VehicleStatus{Location: []float64{lon, lat}, Timestamp: at}
+		require.NoError(t, cache.SetStatus(ctx, id, status, time.Minute))
+	}
+	set(downtown, 55.2744, 25.1972, now)
+	set(marina, 55.1403, 25.0805, now)
+	set(sharjah, 55.4033, 25.3463, now)
+	// Out of reach of Redis GEO, but still cached.
+	set(polar, 15.6, 88.2, now)
+	// The marina vehicle drove into town, then a late reading arrived.
+	set(marina, 55.2800, 25.2000, now.Add(time.Minute))
+	set(marina, 55.1403, 25.0805, now.Add(-time.Minute))
+
+	ids := func(got []domain.NearbyVehicle) []uuid.UUID {
+		out := make([]uuid.UUID, len(got))
+		for i, v := range got {
+			out[i] = v.VehicleID
+		}
+		return out
+	}
+
+	t.Run("Radius", func(t *testing.T) {
+		got, err := cache.FindNearby(ctx, 55.2744, 25.1972, 5000, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{downtown, marina}, ids(got))
+		assert.InDelta(t, 0, got[0].Distance, 1)
+		assert.InDelta(t, 660, got[1].Distance, 50)
+		assert.Equal(t, now.Add(time.Minute), got[1].Status.Timestamp.UTC())
+	})
+
+	t.Run("Nearest", func(t *testing.T) {
+		got, err := cache.FindNearby(ctx, 55.40, 25.34, 0, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{sharjah, marina}, ids(got))
+	})
+
+	t.Run("Skips unindexable positions", func(t *testing.T) {
+		got, err := cache.FindNearby(ctx, 15.6, 85, 0, 0)
+		require.NoError(t, err)
+		assert.NotContains(t, ids(got), polar)
+
+		status, err := cache.GetStatus(ctx, polar)
+		require.NoError(t, err)
+		assert.NotNil(t, status)
+	})
+
+	t.Run("Reads statuses in chunks", func(t *testing.T) {
+		cache, _ := newTestVehicleCache(t)
+		for i := 0; i < 1200; i++ {
+			status := &domain.VehicleStatus{Location: []float64{55, 25 + float64(i)*1e-4}, Speed: float64(i), Timestamp: now}
+			require.NoError(t, cache.SetStatus(ctx, uuid.New(), status, time.Minute))
+		}
+
+		got, err := cache.FindNearby(ctx, 55, 25, 0, 0)
+		require.NoError(t, err)
+		require.Len(t, got, 1200)
+		for i, v := range got {
+			assert.Equal(t, float64(i), v.Status.Speed)
+		}
+	})
+}
+
+func TestVehicleCache_ListStatusesInBox(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 6, 17, 9, 0, 0, 0, time.UTC)
+	dubai, sharjah, fiji, samoa, oslo, baltic, svalbard, unlocated := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	cache, _ := newTestVehicleCache(t)
+	set := func(id uuid.UUID, location []float64, at time.Time) {
+		t.Helper()
+		require.NoError(t, cache.SetStatus(ctx, id, &domain.VehicleStatus{Location: location, Timestamp: at}, time.Minute))
+	}
+	set(dubai, []float64{55.2744, 25.1972}, now)
+	set(sharjah, []float64{55.4033, 25.3463}, now.Add(-time.Hour))
+	set(fiji, []float64{179.5, -17}, now)
+	set(samoa, []float64{-179.5, -17}, now)
+	set(oslo, []float64{10.75, 59.91}, now)
+	set(baltic, []float64{18.9, 55.2}, now)
+	set(svalbard, []float64{20, 84.5}, now)
+	set(unlocated, nil, now)
+
+	ids := func(got []domain.VehicleSnapshot) []uuid.UUID {
+		out := []uuid.UUID{}
+		for _, v := range got {
+			out = append(out, v.VehicleID)
+		}
+		return out
+	}
+
+	t.Run("Box", func(t *testing.T) {
+		got, err := cache.ListStatusesInBox(ctx, domain.BoundingBox{MinLon: 55, MinLat: 25, MaxLon: 56, MaxLat: 26}, time.Time{})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []uuid.UUID{dubai, sharjah}, ids(got))
+	})
+
+	t.Run("Updated since", func(t *testing.T) {
+		got, err := cache.ListStatusesInBox(ctx, domain.BoundingBox{MinLon: 55, MinLat: 25, MaxLon: 56, MaxLat: 26}, now.Add(-time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{dubai}, ids(got))
+	})
+
+	t.Run("Vehicles on the edges", func(t *testing.T) {
+		got, err := cache.ListStatusesInBox(ctx, domain.BoundingBox{MinLon: 55.2744, MinLat: 25.1972, MaxLon: 55.4033, MaxLat: 25.3463}, time.Time{})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []uuid.UUID{dubai, sharjah}, ids(got))
+	})
+
+	t.Run("Across the antimeridian", func(t *testing.T) {
+		got, err := cache.ListStatusesInBox(ctx, domain.BoundingBox{MinLon: 179, MinLat: -18, MaxLon: -179, MaxLat: -16}, time.Time{})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []uuid.UUID{fiji, samoa}, ids(got))
+	})
+
+	t.Run("Wide box far from the equator", func(t *testing.T) {
+		// Longitudes are furthest apart on the box's southern edge, where
+		// the Baltic vehicle lies in its corner.
+		got, err := cache.ListStatusesInBox(ctx, domain.BoundingBox{MinLon: 0, MinLat: 55, MaxLon: 19, MaxLat: 70}, time.Time{})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []uuid.UUID{oslo, baltic}, ids(got))
+	})
+
+	t.Run("Whole world", func(t *testing.T) {
+		got, err := cache.ListStatusesInBox(ctx, domain.BoundingBox{MinLon: -180, MinLat: -85, MaxLon: 180, MaxLat: 85}, time.Time{})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []uuid.UUID{dubai, sharjah, fiji, samoa, oslo, baltic, svalbard}, ids(got))
+	})
+
+	t.Run("Boxes reaching the poles", func(t *testing.T) {
+		got, err := cache.ListStatusesInBox(ctx, domain.BoundingBox{MinLon: -180, MinLat: -90, MaxLon: 180, MaxLat: 90}, time.Time{})
+		require.NoError(t, err)
+		assert.Len(t, got, 7)
+
+		got, err = cache.ListStatusesInBox(ctx, domain.BoundingBox{MinLon: 0, MinLat: 80, MaxLon: 40, MaxLat: 90}, time.Time{})
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{svalbard}, ids(got))
+
+		got, err = cache.ListStatusesInBox(ctx, domain.BoundingBox{MinLon: 0, MinLat: 86, MaxLon: 40, MaxLat: 90}, time.Time{})
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+}
+
+func TestVehicleCache_RemoveFromFleet(t *testing.T) {
+	ctx := context.Background()
+	archived, active := uuid.New(), uuid.New()
+	status := &domain.VehicleStatus{Location: []float64{55.2744, 25.1972}, Timestamp: time.Now()}
+
+	cache, _ := newTestVehicleCache(t)
+	require.NoError(t, cache.SetStatus(ctx, archived, status, time.Minute))
+	require.NoError(t, cache.SetStatus(ctx, active, status, time.Minute))
+	require.NoError(t, cache.RemoveFromFleet(ctx, archived))
+
+	fleet, err := cache.ListStatuses(ctx, nil, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, fleet, 1)
+	assert.Equal(t, active, fleet[0].VehicleID)
+
+	fleet, err = cache.ListStatuses(ctx, []uuid.UUID{archived}, time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, fleet)
+
+	nearby, err := cache.FindNearby(ctx, 55.2744, 25.1972, 1000, 0)
+	require.NoError(t, err)
+	require.Len(t, nearby, 1)
+	assert.Equal(t, active, nearby[0].VehicleID)
+}
@@ -7,11 +7,13 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
 	handler "github.com/AnjuRKrishnan/fleet-tracker/internal/handler"
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/assert"
@@ -32,17 +34,98 @@ func (m *MockVehicleService) GetVehicleStatus(ctx context.Context, vehicleID uui
 	return args.Get(0).(*domain.VehicleStatus), args.Error(1)
 }
 
+func (m *MockVehicleService) GetFleetStatus(ctx context.Context, query domain.FleetQuery) ([]domain.VehicleSnapshot, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.VehicleSnapshot), args.Error(1)
+}
+
+func (m *MockVehicleService) FindNearbyVehicles(ctx context.Context, query domain.NearbyQuery) ([]domain.NearbyVehicle, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.NearbyVehicle), args.Error(1)
+}
+
 func (m *MockVehicleService) IngestData(ctx context.Context, data domain.IngestRequest) error {
 	args := m.Called(ctx, data)
 	return args.Error(0)
 }
 
-func (m *MockVehicleService) GetVehicleTrips(ctx context.Context, vehicleID uuid.UUID) ([]domain.Trip, error) {
-	args := m.Called(ctx, vehicleID)
+func (m *MockVehicleService) IngestBatch(ctx context.Context, items []domain.IngestRequest) ([]domain.IngestResult, error) {
+	args := m.Called(ctx, items)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]domain.Trip), args.Error(1)
+	return args.Get(0).([]domain.IngestResult), args.Error(1)
+}
+
+func (m *MockVehicleService) GetVehicleTrips(ctx context.Context, query domain.TripQuery) (domain.TripPage, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).(domain.TripPage), args.Error(1)
+}
+
+func (m *MockVehicleService) GetVehiclePositions(ctx context.Context, query domain.PositionQuery) ([]domain.Position, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Position), args.Error(1)
+}
+
+func (m *MockVehicleService) GetTrip(ctx context.Context, tripID uuid.UUID) (*domain.Trip, error) {
+	args := m.Called(ctx, tripID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Trip), args.Error(1)
+}
+
+func (m *MockVehicleService) GetTripRoute(ctx context.Context, tripID uuid.UUID, maxPoints int) (*domain.Trip, []domain.Position, error) {
+	args := m.Called(ctx, tripID, maxPoints)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*domain.Trip), args.Get(1).([]domain.Position), args.Error(2)
+}
+
+func (m *MockVehicleService) GetTripsPositions(ctx context.Context, trips []domain.Trip) ([]domain.TripPositions, error) {
+	args := m.Called(ctx, trips)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.TripPositions), args.Error(1)
+}
+
+// ExportTrips passes the trips given to Return to fn before returning the
+// error given to Return.
+func (m *MockVehicleService) ExportTrips(ctx context.Context, query domain.TripQuery, fn func(domain.Trip) error) error {
+	args := m.Called(ctx, query)
+	if trips, ok := args.Get(0).([]domain.Trip); ok {
+		for _, trip := range trips {
+			if err := fn(trip); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+// ExportPositions passes the positions given to Return to fn before returning
+// the error given to Return.
+func (m *MockVehicleService) ExportPositions(ctx context.Context, query domain.PositionQuery, fn func(domain.Position) error) error {
+	args := m.Called(ctx, query)
+	if positions, ok := args.Get(0).([]domain.Position); ok {
+		for _, p := range positions {
+			if err := fn(p); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
 }
 
 // helper to get pointer to time
@@ -66,18 +149,25 @@ func TestVehicleHandler_GetTrips(t *testing.T) {
 		},
 	}
 
+	from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	cursor := domain.TripCursor{StartTime: from.Add(48 * time.Hour), ID: uuid.New()}
+	next := domain.TripCursor{StartTime: now, ID: testVehicleUUID}
+
 	tests := []struct {
 		name               string
-		vehicleID          string
+		query              string
 		setupMock          func(m *MockVehicleService)
 		expectedStatusCode int
+		expectedCursor     string
 		validateBody       func(body []byte)
 	}{
 		{
-			name:      "Success",
-			vehicleID: testVehicleUUID.String(),
+			name:  "Success",
+			query: "vehicle_id=" + testVehicleUUID.String(),
 			setupMock: func(m *MockVehicleService) {
-				m.On("GetVehicleTrips", mock.Anything, testVehicleUUID).Return(testTrips, nil)
+				m.On("GetVehicleTrips", mock.Anything, domain.TripQuery{VehicleID: testVehicleUUID}).
+					Return(domain.TripPage{Trips: testTrips}, nil)
 			},
 			expectedStatusCode: http.StatusOK,
 			validateBody: func(body []byte) {
@@ -89,9 +179,32 @@ func TestVehicleHandler_GetTrips(t *testing.T) {
 				assert.Equal(t, testTrips[0].AvgSpeed, got[0].AvgSpeed)
 			},
 		},
+		{
+			name: "Filters And Cursor",
+			query: "vehicle_id=" + testVehicleUUID.String() + "&from=2025-06-01T00:00:00Z&to=2025-07-01T00:00:00Z" +
+				"&min_mileage=5&sort=asc&limit=1&cursor=" + cursor.String(),
+			setupMock: func(m *MockVehicleService) {
+				m.On("GetVehicleTrips", mock.Anything, domain.TripQuery{
+					VehicleID:  testVehicleUUID,
+					From:       from,
+					To:         to,
+					MinMileage: 5,
+					Order:      domain.SortAscending,
+					After:      &cursor,
+					Limit:      1,
+				}).Return(domain.TripPage{Trips: testTrips, Next: &next}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedCursor:     next.String(),
+			validateBody: func(body []byte) {
+				var got []domain.Trip
+				assert.NoError(t, json.Unmarshal(body, &got))
+				assert.Len(t, got, 1)
+			},
+		},
 		{
 			name:               "Invalid UUID",
-			vehicleID:          "invalid-uuid",
+			query:              "vehicle_id=invalid-uuid",
 			setupMock:          func(m *MockVehicleService) {},
 			expectedStatusCode: http.StatusBadRequest,
 			validateBody: func(body []byte) {
@@ -99,10 +212,46 @@ func TestVehicleHandler_GetTrips(t *testing.T) {
 			},
 		},
 		{
-			name:      "Internal Server Error",
-			vehicleID: testVehicleUUID.String(),
+			name:               "Invalid Sort",
+			query:              "vehicle_id=" + testVehicleUUID.String() + "&sort=newest",
+			setupMock:          func(m *MockVehicleService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			validateBody: func(body []byte) {
+				assert.Equal(t, "Invalid sort\n", string(body))
+			},
+		},
+		{
+			name:               "Invalid Cursor",
+			query:              "vehicle_id=" + testVehicleUUID.String() + "&cursor=not-a-cursor",
+			setupMock:          func(m *MockVehicleService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			validateBody: func(body []byte) {
+				assert.Equal(t, "Invalid cursor\n", string(body))
+			},
+		},
+		{
+			name:               "Negative Min Mileage",
+			query:              "vehicle_id=" + testVehicleUUID.String() + "&min_mileage=-1",
+			setupMock:          func(m *MockVehicleService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			validateBody: func(body []byte) {
+				assert.Equal(t, "Invalid min_mileage\n", string(body))
+			},
+		},
+		{
+			name:               "Invalid Range",
+			query:              "vehicle_id=" + testVehicleUUID.String() + "&from=2025-07-01T00:00:00Z&to=2025-06-01T00:00:00Z",
+			setupMock:          func(m *MockVehicleService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			validateBody: func(body []byte) {
+				assert.Equal(t, "to must not be before from\n", string(body))
+			},
+		},
+		{
+			name:  "Internal Server Error",
+			query: "vehicle_id=" + testVehicleUUID.String(),
 			setupMock: func(m *MockVehicleService) {
-				m.On("GetVehicleTrips", mock.Anything, testVehicleUUID).Return(nil, errors.New("db error"))
+				m.On("GetVehicleTrips", mock.Anything, mock.AnythingOfType("domain.TripQuery")).Return(domain.TripPage{}, errors.New("db error"))
 			},
 			expectedStatusCode: http.StatusInternalServerError,
 			validateBody: func(body []byte) {
@@ -117,17 +266,155 @@ func TestVehicleHandler_GetTrips(t *testing.T) {
 			tc.setupMock(mockService)
 
 			h := handler.NewVehicleHandler(mockService, zap.NewNop())
-			req := httptest.NewRequest("GET", "/trips?vehicle_id="+tc.vehicleID, nil)
+			req := httptest.NewRequest("GET", "/trips?"+tc.query, nil)
 			rr := httptest.NewRecorder()
 			h.GetTrips(rr, req)
 
 			assert.Equal(t, tc.expectedStatusCode, rr.Code)
+			assert.Equal(t, tc.expectedCursor, rr.Header().Get("X-Next-Cursor"))
 			tc.validateBody(rr.Body.Bytes())
 			mockService.AssertExpectations(t)
 		})
 	}
 }
 
+func TestVehicleHandler_GetTrips_GeoJSON(t *testing.T) {
+	testVehicleUUID := uuid.New()
+	testTripUUID := uuid.New()
+	now := time.Date(2025, 6, 17, 9, 0, 0, 0, time.UTC)
+	trip := domain.Trip{
+		ID:        pgtype.UUID{Bytes: testTripUUID, Valid: true},
+		VehicleID: pgtype.UUID{Bytes: testVehicleUUID, Valid: true},
+		StartTime: pgtype.Timestamptz{Time: now, Valid: true},
+		EndTime:   ptrTime(now.Add(10 * time.Minute)),
+		Mileage:   3.2,
+		AvgSpeed:  42,
+	}
+	positions := []domain.Position{
+		{VehicleStatus: domain.VehicleStatus{Location: []float64{55.1, 25.1}}},
+		{VehicleStatus: domain.VehicleStatus{Location: []float64{55.2, 25.2}}},
+	}
+
+	mockService := new(MockVehicleService)
+	mockService.On("GetVehicleTrips", mock.Anything, domain.TripQuery{VehicleID: testVehicleUUID}).
+		Return(domain.TripPage{Trips: []domain.Trip{trip}}, nil)
+	mockService.On("GetTripsPositions", mock.Anything, []domain.Trip{trip}).
+		Return([]domain.TripPositions{{Positions: positions, Truncated: true}}, nil)
+
+	h := handler.NewVehicleHandler(mockService, zap.NewNop())
+	req := httptest.NewRequest("GET", "/trips?vehicle_id="+testVehicleUUID.String(), nil)
+	req.Header.Set("Accept", "application/geo+json")
+	rr := httptest.NewRecorder()
+	h.GetTrips(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/geo+json", rr.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"type": "FeatureCollection",
+		"features": [{
+			"type": "Feature",
+			"id": "`+testTripUUID.String()+`",
+			"geometry": {"type": "LineString", "coordinates": [[55.1, 25.1], [55.2, 25.2]]},
+			"properties": {
+				"vehicle_id": "`+testVehicleUUID.String()+`",
+				"start_time": "2025-06-17T09:00:00Z",
+				"end_time": "2025-06-17T09:10:00Z",
+				"mileage": 3.2,
+				"avg_speed": 42,
+				"truncated": true
+			}
+		}]
+	}`, rr.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestVehicleHandler_GetStatus(t *testing.T) {
+	testVehicleUUID := uuid.New()
+	heading := 90.0
+	status := &domain.VehicleStatus{
+		Location:  []float64{55.296249, 25.276987},
+		Speed:     60.5,
+		Timestamp: time.Date(2025, 6, 17, 9, 12, 0, 0, time.UTC),
+		Heading:   &heading,
+	}
+
+	tests := []struct {
+		name               string
+		query              string
+		setupMock          func(m *MockVehicleService)
+		expectedStatusCode int
+		expectedType       string
+		expectedBody       string
+	}{
+		{
+			name:  "JSON",
+			query: "vehicle_id=" + testVehicleUUID.String(),
+			setupMock: func(m *MockVehicleService) {
+				m.On("GetVehicleStatus", mock.Anything, testVehicleUUID).Return(status, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedType:       "application/json",
+			expectedBody:       `{"location":[55.296249,25.276987],"speed":60.5,"timestamp":"2025-06-17T09:12:00Z","heading":90}`,
+		},
+		{
+			name:  "GeoJSON",
+			query: "vehicle_id=" + testVehicleUUID.String() + "&format=geojson",
+			setupMock: func(m *MockVehicleService) {
+				m.On("GetVehicleStatus", mock.Anything, testVehicleUUID).Return(status, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedType:       "application/geo+json",
+			expectedBody: `{
+				"type": "Feature",
+				"id": "` + testVehicleUUID.String() + `",
+				"geometry": {"type": "Point", "coordinates": [55.296249, 25.276987]},
+				"properties": {
+					"vehicle_id": "` + testVehicleUUID.String() + `",
+					"speed": 60.5,
+					"timestamp": "2025-06-17T09:12:00Z",
+					"heading": 90
+				}
+			}`,
+		},
+		{
+			name:  "Not Found",
+			query: "vehicle_id=" + testVehicleUUID.String(),
+			setupMock: func(m *MockVehicleService) {
+				m.On("GetVehicleStatus", mock.Anything, testVehicleUUID).Return(nil, nil)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockVehicleService)
+			tc.setupMock(mockService)
+
+			h := handler.NewVehicleHandler(mockService, zap.NewNop())
+			req := httptest.NewRequest("GET", "/status?"+tc.query, nil)
+			rr := httptest.NewRecorder()
+			h.GetStatus(rr, req)
+
+			assert.Equal(t, tc.expectedStatusCode, rr.Code)
+			if tc.expectedBody != "" {
+				assert.Equal(t, tc.expectedType, rr.Header().Get("Content-Type"))
+				assert.JSONEq(t, tc.expectedBody, rr.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+
+	t.Run("Unknown vehicle", func(t *testing.T) {
+		h := handler.NewVehicleHandler(unknownVehicleService(), zap.NewNop())
+		req := httptest.NewRequest("GET", "/status?vehicle_id="+uuid.NewString(), nil)
+		rr := httptest.NewRecorder()
+		h.GetStatus(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
 func TestVehicleHandler_IngestData(t *testing.T) {
 	testVehicleUUID := uuid.New()
 	pgVehicleUUID := pgtype.UUID{Bytes: testVehicleUUID, Valid: true}
@@ -164,6 +451,42 @@ func TestVehicleHandler_IngestData(t *testing.T) {
 			expectedStatusCode: http.StatusBadRequest,
 			expectedBody:       "Invalid request body\n",
 		},
+		{
+			name: "Duplicate Message",
+			body: func() []byte {
+				req := domain.IngestRequest{
+					VehicleID: pgVehicleUUID,
+					MessageID: "msg-1",
+				}
+				b, _ := json.Marshal(req)
+				return b
+			}(),
+			setupMock: func(m *MockVehicleService) {
+				m.On("IngestData", mock.Anything, mock.AnythingOfType("domain.IngestRequest")).Return(domain.ErrDuplicateMessage)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "",
+		},
+		{
+			name: "Validation Error",
+			body: func() []byte {
+				req := domain.IngestRequest{
+					VehicleID: pgVehicleUUID,
+					Status: domain.VehicleStatus{
+						Speed: -5,
+					},
+				}
+				b, _ := json.Marshal(req)
+				return b
+			}(),
+			setupMock: func(m *MockVehicleService) {
+				m.On("IngestData", mock.Anything, mock.AnythingOfType("domain.IngestRequest")).Return(&domain.ValidationError{
+					Fields: []domain.FieldError{{Field: "status.speed", Message: "must not be negative"}},
+				})
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedBody:       `{"error":"validation failed","fields":[{"field":"status.speed","message":"must not be negative"}]}` + "\n",
+		},
 		{
 			name: "Service Error",
 			body: func() []byte {
@@ -200,3 +523,698 @@ func TestVehicleHandler_IngestData(t *testing.T) {
 		})
 	}
 }
+
+func TestVehicleHandler_IngestBatch(t *testing.T) {
+	testVehicleUUID := uuid.New()
+	item := `{"vehicle_id":"` + testVehicleUUID.String() + `","status":{"location":[55.29,25.27],"speed":40,"timestamp":"2025-06-17T09:00:00Z"}}`
+
+	tests := []struct {
+		name               string
+		body               string
+		setupMock          func(m *MockVehicleService)
+		expectedStatusCode int
+		validateBody       func(body []byte)
+	}{
+		{
+			name: "JSON Array",
+			body: "[" + item + "," + item + "]",
+			setupMock: func(m *MockVehicleService) {
+				m.On("IngestBatch", mock.Anything, mock.MatchedBy(func(items []domain.IngestRequest) bool {
+					return len(items) == 2 && items[0].VehicleID.Bytes == testVehicleUUID
+				})).Return([]domain.IngestResult{
+					{Index: 0, Status: domain.IngestAccepted},
+					{Index: 1, Status: domain.IngestAccepted},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			validateBody: func(body []byte) {
+				assert.JSONEq(t, `{"accepted":2,"rejected":0,"duplicate":0,"results":[
+					{"index":0,"status":"accepted"},
+					{"index":1,"status":"accepted"}]}`, string(body))
+			},
+		},
+		{
+			name: "NDJSON With Bad Line",
+			body: item + "\n{not json}\n\n" + item + "\n",
+			setupMock: func(m *MockVehicleService) {
+				m.On("IngestBatch", mock.Anything, mock.MatchedBy(func(items []domain.IngestRequest) bool {
+					return len(items) == 2
+				})).Return([]domain.IngestResult{
+					{Index: 0, Status: domain.IngestAccepted},
+					{Index: 1, Status: domain.IngestDuplicate},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			validateBody: func(body []byte) {
+				assert.JSONEq(t, `{"accepted":1,"rejected":1,"duplicate":1,"results":[
+					{"index":0,"status":"accepted"},
+					{"index":1,"status":"rejected","error":"invalid JSON"},
+					{"index":2,"status":"duplicate"}]}`, string(body))
+			},
+		},
+		{
+			name:               "Malformed Array",
+			body:               "[" + item,
+			setupMock:          func(m *MockVehicleService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			validateBody: func(body []byte) {
+				assert.Equal(t, "Invalid request body\n", string(body))
+			},
+		},
+		{
+			name:               "Empty Batch",
+			body:               "  \n",
+			setupMock:          func(m *MockVehicleService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			validateBody: func(body []byte) {
+				assert.Equal(t, "Empty batch\n", string(body))
+			},
+		},
+		{
+			name: "Error After The Batch Was Stored",
+			body: item,
+			setupMock: func(m *MockVehicleService) {
+				m.On("IngestBatch", mock.Anything, mock.Anything).Return([]domain.IngestResult{
+					{Index: 0, Status: domain.IngestAccepted},
+				}, errors.New("cache error"))
+			},
+			expectedStatusCode: http.StatusOK,
+			validateBody: func(body []byte) {
+				assert.JSONEq(t, `{"accepted":1,"rejected":0,"duplicate":0,"results":[
+					{"index":0,"status":"accepted"}]}`, string(body))
+			},
+		},
+		{
+			name: "Service Error",
+			body: item,
+			setupMock: func(m *MockVehicleService) {
+				m.On("IngestBatch", mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			validateBody: func(body []byte) {
+				assert.Equal(t, "Failed to ingest data\n", string(body))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockVehicleService)
+			tc.setupMock(mockService)
+
+			h := handler.NewVehicleHandler(mockService, zap.NewNop())
+			req := httptest.NewRequest("POST", "/ingest/batch", strings.NewReader(tc.body))
+			rr := httptest.NewRecorder()
+			h.IngestBatch(rr, req)
+
+			assert.Equal(t, tc.expectedStatusCode, rr.Code)
+			tc.validateBody(rr.Body.Bytes())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestVehicleHandler_GetPositions(t *testing.T) {
+	testVehicleUUID := uuid.New()
+	from := time.Date(2025, 6, 17, 0, 0, 0, 0, time.UTC)
+	to := from.Add(6 * time.Hour)
+	testPositions := []domain.Position{
+		{
+			ID:        7,
+			VehicleID: testVehicleUUID,
+			VehicleStatus: domain.VehicleStatus{
+				Location:  []float64{55.296249, 25.276987},
+				Speed:     60.5,
+				Timestamp: from.Add(time.Hour),
+			},
+		},
+	}
+
+	tests := []struct {
+		name               string
+		query              string
+		setupMock          func(m *MockVehicleService)
+		expectedStatusCode int
+		validateBody       func(body []byte)
+	}{
+		{
+			name:  "Success",
+			query: "vehicle_id=" + testVehicleUUID.String() + "&from=2025-06-17T00:00:00Z&to=2025-06-17T06:00:00Z&limit=10&offset=20",
+			setupMock: func(m *MockVehicleService) {
+				m.On("GetVehiclePositions", mock.Anything, domain.PositionQuery{
+					VehicleID: testVehicleUUID,
+					From:      from,
+					To:        to,
+					Limit:     10,
+					Offset:    20,
+				}).Return(testPositions, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			validateBody: func(body []byte) {
+				var got []domain.Position
+				err := json.Unmarshal(body, &got)
+				assert.NoError(t, err)
+				assert.Len(t, got, 1)
+				assert.Equal(t, testPositions[0].Location, got[0].Location)
+				assert.Equal(t, testPositions[0].Speed, got[0].Speed)
+			},
+		},
+		{
+			name:               "Invalid UUID",
+			query:              "vehicle_id=invalid-uuid",
+			setupMock:          func(m *MockVehicleService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			validateBody: func(body []byte) {
+				assert.Equal(t, "Invalid vehicle_id\n", string(body))
+			},
+		},
+		{
+			name:               "Invalid Range",
+			query:              "vehicle_id=" + testVehicleUUID.String() + "&from=2025-06-17T06:00:00Z&to=2025-06-17T00:00:00Z",
+			setupMock:          func(m *MockVehicleService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			validateBody: func(body []byte) {
+				assert.Equal(t, "to must not be before from\n", string(body))
+			},
+		},
+		{
+			name:               "Invalid Limit",
+			query:              "vehicle_id=" + testVehicleUUID.String() + "&limit=-1",
+			setupMock:          func(m *MockVehicleService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			validateBody: func(body []byte) {
+				assert.Equal(t, "Invalid limit\n", string(body))
+			},
+		},
+		{
+			name:  "Internal Server Error",
+			query: "vehicle_id=" + testVehicleUUID.String(),
+			setupMock: func(m *MockVehicleService) {
+				m.On("GetVehiclePositions", mock.Anything, mock.AnythingOfType("domain.PositionQuery")).Return(nil, errors.New("db error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			validateBody: func(body []byte) {
+				assert.Equal(t, "Failed to retrieve positions\n", string(body))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockVehicleService)
+			tc.setupMock(mockService)
+
+			h := handler.NewVehicleHandler(mockService, zap.NewNop())
+			req := httptest.NewRequest("GET", "/positions?"+tc.query, nil)
+			rr := httptest.NewRecorder()
+			h.GetPositions(rr, req)
+
+			assert.Equal(t, tc.expectedStatusCode, rr.Code)
+			tc.validateBody(rr.Body.Bytes())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestVehicleHandler_GetFleetStatus(t *testing.T) {
+	vehicleA, vehicleB := uuid.New(), uuid.New()
+	since := time.Date(2025, 6, 17, 9, 0, 0, 0, time.UTC)
+	snapshots := []domain.VehicleSnapshot{
+		{VehicleID: vehicleA, Status: domain.VehicleStatus{Location: []float64{55.3, 25.2}, Speed: 40, Timestamp: since}},
+	}
+
+	tests := []struct {
+		name               string
+		query              string
+		accept             string
+		setupMock          func(m *MockVehicleService)
+		expectedStatusCode int
+		validateBody       func(body []byte)
+	}{
+		{
+			name:  "Success",
+			query: "vehicle_id=" + vehicleA.String() + "," + vehicleB.String() + "&bbox=55,25,56,26&updated_since=2025-06-17T09:00:00Z&limit=10&offset=20",
+			setupMock: func(m *MockVehicleService) {
+				m.On("GetFleetStatus", mock.Anything, domain.FleetQuery{
+					VehicleIDs:   []uuid.UUID{vehicleA, vehicleB},
+					BBox:         &domain.BoundingBox{MinLon: 55, MinLat: 25, MaxLon: 56, MaxLat: 26},
+					UpdatedSince: since,
+					Limit:        10,
+					Offset:       20,
+				}).Return(snapshots, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			validateBody: func(body []byte) {
+				var got []domain.VehicleSnapshot
+				assert.NoError(t, json.Unmarshal(body, &got))
+				if assert.Len(t, got, 1) {
+					assert.Equal(t, vehicleA, got[0].VehicleID)
+					assert.Equal(t, 40.0, got[0].Status.Speed)
+				}
+			},
+		},
+		{
+			name:   "GeoJSON",
+			accept: "application/geo+json",
+			setupMock: func(m *MockVehicleService) {
+				m.On("GetFleetStatus", mock.Anything, domain.FleetQuery{}).Return(snapshots, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			validateBody: func(body []byte) {
+				assert.Contains(t, string(body), `"type":"FeatureCollection"`)
+				assert.Contains(t, string(body), `"vehicle_id":"`+vehicleA.String()+`"`)
+			},
+		},
+		{
+			name:               "Malformed Bounding Box",
+			query:              "bbox=55,25,56",
+			setupMock:          func(m *MockVehicleService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			validateBody: func(body []byte) {
+				assert.Equal(t, "Invalid bbox\n", string(body))
+			},
+		},
+		{
+			name:               "Invalid Updated Since",
+			query:              "updated_since=yesterday",
+			setupMock:          func(m *MockVehicleService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			validateBody: func(body []byte) {
+				assert.Equal(t, "Invalid updated_since\n", string(body))
+			},
+		},
+		{
+			name:  "Out Of Range Bounding Box",
+			query: "bbox=55,95,56,96",
+			setupMock: func(m *MockVehicleService) {
+				m.On("GetFleetStatus", mock.Anything, mock.AnythingOfType("domain.FleetQuery")).
+					Return(nil, domain.BoundingBox{MinLon: 55, MinLat: 95, MaxLon: 56, MaxLat: 96}.Validate())
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			validateBody: func(body []byte) {
+				assert.Contains(t, string(body), `"field":"bbox"`)
+			},
+		},
+		{
+			name: "Internal Server Error",
+			setupMock: func(m *MockVehicleService) {
+				m.On("GetFleetStatus", mock.Anything, mock.AnythingOfType("domain.FleetQuery")).Return(nil, errors.New("redis down"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			validateBody: func(body []byte) {
+				assert.Equal(t, "Failed to retrieve fleet status\n", string(body))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockVehicleService)
+			tc.setupMock(mockService)
+
+			h := handler.NewVehicleHandler(mockService, zap.NewNop())
+			req := httptest.NewRequest("GET", "/fleet/status?"+tc.query, nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			rr := httptest.NewRecorder()
+			h.GetFleetStatus(rr, req)
+
+			assert.Equal(t, tc.expectedStatusCode, rr.Code)
+			tc.validateBody(rr.Body.Bytes())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestVehicleHandler_FindNearby(t *testing.T) {
+	vehicleID := uuid.New()
+	since := time.Date(2025, 6, 17, 9, 0, 0, 0, time.UTC)
+	nearby := []domain.NearbyVehicle{
+		{VehicleID: vehicleID, Distance: 1234.5, Status: domain.VehicleStatus{Location: []float64{55.3, 25.2}, Speed: 40, Timestamp: since}},
+	}
+
+	tests := []struct {
+		name               string
+		query              string
+		setupMock          func(m *MockVehicleService)
+		expectedStatusCode int
+		validateBody       func(body []byte)
+	}{
+		{
+			name:  "Success",
+			query: "lon=55.27&lat=25.2&radius=5000&limit=3&updated_since=2025-06-17T09:00:00Z",
+			setupMock: func(m *MockVehicleService) {
+				m.On("FindNearbyVehicles", mock.Anything, domain.NearbyQuery{
+					Longitude:    55.27,
+					Latitude:     25.2,
+					Radius:       5000,
+					Limit:        3,
+					UpdatedSince: since,
+				}).Return(nearby, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			validateBody: func(body []byte) {
+				var got []domain.NearbyVehicle
+				assert.NoError(t, json.Unmarshal(body, &got))
+				if assert.Len(t, got, 1) {
+					assert.Equal(t, vehicleID, got[0].VehicleID)
+					assert.Equal(t, 1234.5, got[0].Distance)
+					assert.Equal(t, 40.0, got[0].Status.Speed)
+				}
+			},
+		},
+		{
+			name:               "Missing Point",
+			query:              "lon=55.27",
+			setupMock:          func(m *MockVehicleService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			validateBody: func(body []byte) {
+				assert.Equal(t, "lon and lat are required\n", string(body))
+			},
+		},
+		{
+			name:               "Invalid Radius",
+			query:              "lon=55.27&lat=25.2&radius=far",
+			setupMock:          func(m *MockVehicleService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			validateBody: func(body []byte) {
+				assert.Equal(t, "Invalid radius\n", string(body))
+			},
+		},
+		{
+			name:  "Out Of Range Point",
+			query: "lon=55.27&lat=95",
+			setupMock: func(m *MockVehicleService) {
+				m.On("FindNearbyVehicles", mock.Anything, mock.AnythingOfType("domain.NearbyQuery")).
+					Return(nil, domain.NearbyQuery{Longitude: 55.27, Latitude: 95}.Validate())
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			validateBody: func(body []byte) {
+				assert.Contains(t, string(body), `"field":"lat"`)
+			},
+		},
+		{
+			name:  "Internal Server Error",
+			query: "lon=55.27&lat=25.2",
+			setupMock: func(m *MockVehicleService) {
+				m.On("FindNearbyVehicles", mock.Anything, mock.AnythingOfType("domain.NearbyQuery")).Return(nil, errors.New("redis down"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			validateBody: func(body []byte) {
+				assert.Equal(t, "Failed to find nearby vehicles\n", string(body))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockVehicleService)
+			tc.setupMock(mockService)
+
+			h := handler.NewVehicleHandler(mockService, zap.NewNop())
+			req := httptest.NewRequest("GET", "/fleet/nearby?"+tc.query, nil)
+			rr := httptest.NewRecorder()
+			h.FindNearby(rr, req)
+
+			assert.Equal(t, tc.expectedStatusCode, rr.Code)
+			tc.validateBody(rr.Body.Bytes())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestVehicleHandler_GetTripRoute(t *testing.T) {
+	tripID := uuid.New()
+	start := time.Date(2025, 6, 17, 9, 0, 0, 0, time.UTC)
+	trip := &domain.Trip{
+		ID:        pgtype.UUID{Bytes: tripID, Valid: true},
+		StartTime: pgtype.Timestamptz{Time: start, Valid: true},
+		EndTime:   ptrTime(start.Add(time.Hour)),
+		Mileage:   880,
+	}
+	// The example route of the encoded polyline format documentation.
+	positions := []domain.Position{
+		{ID: 1, VehicleStatus: domain.VehicleStatus{Location: []float64{-120.2, 38.5}, Timestamp: start}},
+		{ID: 2, VehicleStatus: domain.VehicleStatus{Location: []float64{-120.95, 40.7}, Timestamp: start.Add(20 * time.Minute)}},
+		{ID: 3, VehicleStatus: domain.VehicleStatus{Location: []float64{-126.453, 43.252}, Timestamp: start.Add(time.Hour)}},
+	}
+
+	serve := func(m *MockVehicleService, target string) *httptest.ResponseRecorder {
+		h := handler.NewVehicleHandler(m, zap.NewNop())
+		r := chi.NewRouter()
+		r.Get("/vehicle/trips/{id}", h.GetTrip)
+		r.Get("/vehicle/trips/{id}/route", h.GetTripRoute)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest("GET", target, nil))
+		return rr
+	}
+	route := "/vehicle/trips/" + tripID.String() + "/route"
+
+	t.Run("Trip", func(t *testing.T) {
+		m := new(MockVehicleService)
+		m.On("GetTrip", mock.Anything, tripID).Return(trip, nil)
+
+		rr := serve(m, "/vehicle/trips/"+tripID.String())
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"mileage":880`)
+	})
+
+	t.Run("Unknown trip", func(t *testing.T) {
+		m := new(MockVehicleService)
+		m.On("GetTrip", mock.Anything, tripID).Return(nil, domain.ErrNotFound)
+		m.On("GetTripRoute", mock.Anything, tripID, 0).Return(nil, nil, domain.ErrNotFound)
+
+		assert.Equal(t, http.StatusNotFound, serve(m, "/vehicle/trips/"+tripID.String()).Code)
+		assert.Equal(t, http.StatusNotFound, serve(m, route).Code)
+	})
+
+	t.Run("Positions", func(t *testing.T) {
+		m := new(MockVehicleService)
+		m.On("GetTripRoute", mock.Anything, tripID, 500).Return(trip, positions, nil)
+
+		rr := serve(m, route+"?max_points=500")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var got struct {
+			Trip      domain.Trip       `json:"trip"`
+			Positions []domain.Position `json:"positions"`
+		}
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+		assert.Equal(t, trip.Mileage, got.Trip.Mileage)
+		assert.Len(t, got.Positions, 3)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("Encoded polyline", func(t *testing.T) {
+		m := new(MockVehicleService)
+		m.On("GetTripRoute", mock.Anything, tripID, 0).Return(trip, positions, nil)
+
+		rr := serve(m, route+"?format=polyline")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var got struct {
+			Polyline   string      `json:"polyline"`
+			Timestamps []time.Time `json:"timestamps"`
+		}
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+		assert.Equal(t, "_p~iF~ps|U_ulLnnqC_mqNvxq`@", got.Polyline)
+		assert.Equal(t, []time.Time{start, start.Add(20 * time.Minute), start.Add(time.Hour)}, got.Timestamps)
+	})
+
+	t.Run("GeoJSON", func(t *testing.T) {
+		m := new(MockVehicleService)
+		m.On("GetTripRoute", mock.Anything, tripID, 0).Return(trip, positions, nil)
+
+		rr := serve(m, route+"?format=geojson")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"type":"LineString"`)
+	})
+
+	t.Run("Invalid parameters", func(t *testing.T) {
+		m := new(MockVehicleService)
+		assert.Equal(t, http.StatusBadRequest, serve(m, "/vehicle/trips/abc/route").Code)
+		assert.Equal(t, http.StatusBadRequest, serve(m, route+"?max_points=1").Code)
+		assert.Equal(t, http.StatusBadRequest, serve(m, route+"?max_points=-5").Code)
+		m.AssertNotCalled(t, "GetTripRoute", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Internal Server Error", func(t *testing.T) {
+		m := new(MockVehicleService)
+		m.On("GetTripRoute", mock.Anything, tripID, 0).Return(nil, nil, errors.New("db error"))
+
+		rr := serve(m, route)
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Equal(t, "Failed to retrieve trip route\n", rr.Body.String())
+	})
+}
+
+func TestVehicleHandler_ExportTrips(t *testing.T) {
+	vehicleID, tripID := uuid.New(), uuid.New()
+	start := time.Date(2025, 6, 17, 9, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	trips := []domain.Trip{
+		{
+			ID:        pgtype.UUID{Bytes: tripID, Valid: true},
+			VehicleID: pgtype.UUID{Bytes: vehicleID, Valid: true},
+			StartTime: pgtype.Timestamptz{Time: start, Valid: true},
+			EndTime:   &end,
+			Mileage:   42.5,
+			AvgSpeed:  38,
+		},
+	}
+	positions := []domain.Position{
+		{ID: 1, VehicleID: vehicleID, VehicleStatus: domain.VehicleStatus{Location: []float64{13.4, 52.5}, Timestamp: start}},
+		{ID: 2, VehicleID: vehicleID, VehicleStatus: domain.VehicleStatus{Timestamp: start.Add(time.Minute)}},
+		{ID: 3, VehicleID: vehicleID, VehicleStatus: domain.VehicleStatus{Location: []float64{13.5, 52.6}, Timestamp: end}},
+	}
+	tripQuery := domain.TripQuery{VehicleID: vehicleID, From: start.Add(-24 * time.Hour), To: end}
+	positionQuery := domain.PositionQuery{VehicleID: vehicleID, From: start, To: end}
+	target := "/vehicle/trips/export?vehicle_id=" + vehicleID.String() +
+		"&from=" + start.Add(-24*time.Hour).Format(time.RFC3339) + "&to=" + end.Format(time.RFC3339)
+
+	serve := func(m *MockVehicleService, target string) *httptest.ResponseRecorder {
+		h := handler.NewVehicleHandler(m, zap.NewNop())
+		r := chi.NewRouter()
+		r.Get("/vehicle/trips/export", h.ExportTrips)
+		r.Get("/vehicle/trips/{id}", h.GetTrip)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest("GET", target, nil))
+		return rr
+	}
+
+	t.Run("CSV", func(t *testing.T) {
+		m := new(MockVehicleService)
+		m.On("ExportTrips", mock.Anything, tripQuery).Return(trips, nil)
+
+		rr := serve(m, target)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="trips-`+vehicleID.String()+`.csv"`, rr.Header().Get("Content-Disposition"))
+		assert.Equal(t, "id,vehicle_id,start_time,end_time,mileage,avg_speed\n"+
+			tripID.String()+","+vehicleID.String()+",2025-06-17T09:00:00Z,2025-06-17T10:00:00Z,42.5,38\n", rr.Body.String())
+		m.AssertNotCalled(t, "ExportPositions", mock.Anything, mock.Anything)
+	})
+
+	t.Run("GPX", func(t *testing.T) {
+		m := new(MockVehicleService)
+		m.On("ExportTrips", mock.Anything, tripQuery).Return(trips, nil)
+		m.On("ExportPositions", mock.Anything, positionQuery).Return(positions, nil)
+
+		rr := serve(m, target+"&format=gpx")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/gpx+xml", rr.Header().Get("Content-Type"))
+		body := rr.Body.String()
+		assert.Contains(t, body, "<name>Trip "+tripID.String()+"</name>")
+		assert.Contains(t, body, `<trkpt lat="52.5" lon="13.4"><time>2025-06-17T09:00:00Z</time></trkpt>`)
+		assert.Equal(t, 2, strings.Count(body, "<trkpt "))
+		assert.True(t, strings.HasSuffix(body, "</trkseg></trk>\n</gpx>\n"))
+	})
+
+	t.Run("KML", func(t *testing.T) {
+		m := new(MockVehicleService)
+		m.On("ExportTrips", mock.Anything, tripQuery).Return(trips, nil)
+		m.On("ExportPositions", mock.Anything, positionQuery).Return(positions, nil)
+
+		rr := serve(m, target+"&format=kml")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/vnd.google-earth.kml+xml", rr.Header().Get("Content-Type"))
+		body := rr.Body.String()
+		assert.Contains(t, body, "<TimeSpan><begin>2025-06-17T09:00:00Z</begin><end>2025-06-17T10:00:00Z</end></TimeSpan>")
+		assert.Contains(t, body, "<coordinates>\n13.4,52.5\n13.5,52.6\n</coordinates>")
+	})
+
+	t.Run("No trips", func(t *testing.T) {
+		m := new(MockVehicleService)
+		m.On("ExportTrips", mock.Anything, tripQuery).Return(nil, nil)
+
+		rr := serve(m, target+"&format=gpx")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `<gpx version="1.1" creator="fleet-tracker"`)
+		assert.NotContains(t, rr.Body.String(), "<trk>")
+	})
+
+	t.Run("Invalid parameters", func(t *testing.T) {
+		m := new(MockVehicleService)
+		assert.Equal(t, http.StatusBadRequest, serve(m, "/vehicle/trips/export").Code)
+		assert.Equal(t, http.StatusBadRequest, serve(m, target+"&format=xlsx").Code)
+		assert.Equal(t, http.StatusBadRequest, serve(m, target+"&min_mileage=-1").Code)
+		m.AssertNotCalled(t, "ExportTrips", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Internal Server Error", func(t *testing.T) {
+		m := new(MockVehicleService)
+		m.On("ExportTrips", mock.Anything, tripQuery).Return(nil, errors.New("db error"))
+
+		rr := serve(m, target)
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Empty(t, rr.Header().Get("Content-Disposition"))
+		assert.Equal(t, "Failed to export trips\n", rr.Body.String())
+	})
+}
+
+func TestVehicleHandler_ExportPositions(t *testing.T) {
+	vehicleID := uuid.New()
+	start := time.Date(2025, 6, 17, 9, 0, 0, 0, time.UTC)
+	heading, satellites, ignition := 90.0, 7, true
+	positions := []domain.Position{
+		{ID: 1, VehicleID: vehicleID, VehicleStatus: domain.VehicleStatus{
+			Location: []float64{13.4, 52.5}, Speed: 51.5, Timestamp: start,
+			Heading: &heading, Satellites: &satellites, Ignition: &ignition,
+		}},
+		{ID: 2, VehicleID: vehicleID, VehicleStatus: domain.VehicleStatus{Timestamp: start.Add(time.Second)}},
+	}
+	query := domain.PositionQuery{VehicleID: vehicleID, From: start}
+	target := "/vehicle/positions/export?vehicle_id=" + vehicleID.String() + "&from=" + start.Format(time.RFC3339)
+
+	serve := func(m *MockVehicleService, target string) *httptest.ResponseRecorder {
+		h := handler.NewVehicleHandler(m, zap.NewNop())
+		rr := httptest.NewRecorder()
+		h.ExportPositions(rr, httptest.NewRequest("GET", target, nil))
+		return rr
+	}
+
+	t.Run("CSV", func(t *testing.T) {
+		m := new(MockVehicleService)
+		m.On("ExportPositions", mock.Anything, query).Return(positions, nil)
+
+		rr := serve(m, target+"&format=csv")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `attachment; filename="positions-`+vehicleID.String()+`.csv"`, rr.Header().Get("Content-Disposition"))
+		lines := strings.Split(strings.TrimSuffix(rr.Body.String(), "\n"), "\n")
+		assert.Equal(t, []string{
+			"id,vehicle_id,timestamp,longitude,latitude,speed,heading,altitude,accuracy,hdop,satellites,ignition,odometer,fuel_level,engine_hours",
+			"1," + vehicleID.String() + ",2025-06-17T09:00:00Z,13.4,52.5,51.5,90,,,,7,true,,,",
+			"2," + vehicleID.String() + ",2025-06-17T09:00:01Z,,,0,,,,,,,,,",
+		}, lines)
+	})
+
+	t.Run("GPX", func(t *testing.T) {
+		m := new(MockVehicleService)
+		m.On("ExportPositions", mock.Anything, query).Return(positions, nil)
+
+		rr := serve(m, target+"&format=gpx")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), "<trk><name>"+vehicleID.String()+"</name><trkseg>\n"+
+			`<trkpt lat="52.5" lon="13.4"><time>2025-06-17T09:00:00Z</time></trkpt>`+"\n</trkseg></trk>")
+	})
+
+	t.Run("Invalid parameters", func(t *testing.T) {
+		m := new(MockVehicleService)
+		assert.Equal(t, http.StatusBadRequest, serve(m, "/vehicle/positions/export?vehicle_id=abc").Code)
+		assert.Equal(t, http.StatusBadRequest, serve(m, target+"&to=2025-01-01T00:00:00Z").Code)
+		assert.Equal(t, http.StatusBadRequest, serve(m, target+"&format=geojson").Code)
+		m.AssertNotCalled(t, "ExportPositions", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fails after the download started", func(t *testing.T) {
+		// More than the export buffer holds reaches the client before the
+		// error, so the response can only be aborted.
+		many := make([]domain.Position, 200)
+		for i := range many {
+			many[i] = positions[0]
+		}
+		m := new(MockVehicleService)
+		m.On("ExportPositions", mock.Anything, query).Return(many, errors.New("db error"))
+
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() { serve(m, target) })
+	})
+}
@@ -0,0 +1,163 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	handler "github.com/AnjuRKrishnan/fleet-tracker/internal/handler"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+type MockVehicleRegistry struct {
+	mock.Mock
+}
+
+func (m *MockVehicleRegistry) CreateVehicle(ctx context.Context, vehicle domain.Vehicle) (*domain.Vehicle, error) {
+	args := m.Called(ctx, vehicle)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Vehicle), args.Error(1)
+}
+
+func (m *MockVehicleRegistry) GetVehicle(ctx context.Context, id uuid.UUID) (*domain.Vehicle, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Vehicle), args.Error(1)
+}
+
+func (m *MockVehicleRegistry) GetVehicleByPlate(ctx context.Context, plateNumber string) (*domain.Vehicle, error) {
+	args := m.Called(ctx, plateNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Vehicle), args.Error(1)
+}
+
+func (m *MockVehicleRegistry) ListVehicles(ctx context.Context, query domain.VehicleQuery) ([]domain.Vehicle, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Vehicle), args.Error(1)
+}
+
+func (m *MockVehicleRegistry) UpdateVehicle(ctx context.Context, vehicle domain.Vehicle) (*domain.Vehicle, error) {
+	args := m.Called(ctx, vehicle)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Vehicle), args.Error(1)
+}
+
+func (m *MockVehicleRegistry) ArchiveVehicle(ctx context.Context, id uuid.UUID) (*domain.Vehicle, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Vehicle), args.Error(1)
+}
+
+func newVehicleRegistryRouter(m *MockVehicleRegistry) http.Handler {
+	h := handler.NewVehicleRegistryHandler(m, zap.NewNop())
+	r := chi.NewRouter()
+	r.Post("/vehicles", h.Create)
+	r.Get("/vehicles", h.List)
+	r.Get("/vehicles/plate/{plate}", h.GetByPlate)
+	r.Get("/vehicles/{id}", h.Get)
+	r.Put("/vehicles/{id}", h.Update)
+	r.Post("/vehicles/{id}/archive", h.Archive)
+	return r
+}
+
+func TestVehicleRegistryHandler(t *testing.T) {
+	id := uuid.New()
+	serve := func(m *MockVehicleRegistry, method, target, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		newVehicleRegistryRouter(m).ServeHTTP(rr, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return rr
+	}
+
+	t.Run("Create", func(t *testing.T) {
+		m := new(MockVehicleRegistry)
+		m.On("CreateVehicle", mock.Anything, domain.Vehicle{ID: id, PlateNumber: "KL01AB1234", Name: "Truck 7"}).
+			Return(&domain.Vehicle{ID: id, PlateNumber: "KL01AB1234", Name: "Truck 7"}, nil)
+
+		rr := serve(m, http.MethodPost, "/vehicles", `{"id":"`+id.String()+`","plate_number":"KL01AB1234","name":"Truck 7"}`)
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		var got domain.Vehicle
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+		assert.Equal(t, id, got.ID)
+		assert.NotContains(t, rr.Body.String(), "last_status")
+		m.AssertExpectations(t)
+	})
+
+	t.Run("Plate number taken", func(t *testing.T) {
+		m := new(MockVehicleRegistry)
+		m.On("CreateVehicle", mock.Anything, mock.Anything).Return(nil, domain.ErrVehicleExists)
+
+		rr := serve(m, http.MethodPost, "/vehicles", `{"plate_number":"KL01AB1234"}`)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("Validation Error", func(t *testing.T) {
+		m := new(MockVehicleRegistry)
+		m.On("UpdateVehicle", mock.Anything, domain.Vehicle{ID: id}).Return(nil, domain.Vehicle{}.Validate())
+
+		rr := serve(m, http.MethodPut, "/vehicles/"+id.String(), `{}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"field":"plate_number"`)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		m := new(MockVehicleRegistry)
+		m.On("ListVehicles", mock.Anything, domain.VehicleQuery{IncludeArchived: true, Limit: 20, Offset: 40}).
+			Return([]domain.Vehicle{{ID: id, PlateNumber: "KL01AB1234"}}, nil)
+
+		rr := serve(m, http.MethodGet, "/vehicles?limit=20&offset=40&include_archived=true", "")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), id.String())
+
+		rr = serve(m, http.MethodGet, "/vehicles?include_archived=maybe", "")
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("Get by plate", func(t *testing.T) {
+		m := new(MockVehicleRegistry)
+		m.On("GetVehicleByPlate", mock.Anything, "KL 01 AB 1234").Return(&domain.Vehicle{ID: id, PlateNumber: "KL 01 AB 1234"}, nil)
+		m.On("GetVehicleByPlate", mock.Anything, "UNKNOWN").Return(nil, domain.ErrNotFound)
+
+		rr := serve(m, http.MethodGet, "/vehicles/plate/KL%2001%20AB%201234", "")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), id.String())
+
+		rr = serve(m, http.MethodGet, "/vehicles/plate/UNKNOWN", "")
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Archive", func(t *testing.T) {
+		archivedAt := time.Date(2025, 6, 17, 8, 0, 0, 0, time.UTC)
+		m := new(MockVehicleRegistry)
+		m.On("ArchiveVehicle", mock.Anything, id).Return(&domain.Vehicle{ID: id, ArchivedAt: &archivedAt}, nil)
+
+		rr := serve(m, http.MethodPost, "/vehicles/"+id.String()+"/archive", "")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"archived_at":"2025-06-17T08:00:00Z"`)
+
+		rr = serve(m, http.MethodPost, "/vehicles/not-a-uuid/archive", "")
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
@@ -0,0 +1,244 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/services"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type MockVehicleRegistryRepository struct {
+	mock.Mock
+}
+
+func (m *MockVehicleRegistryRepository) CreateVehicle(ctx context.Context, vehicle domain.Vehicle) (*domain.Vehicle, error) {
+	args := m.Called(ctx, vehicle)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Vehicle), args.Error(1)
+}
+
+func (m *MockVehicleRegistryRepository) GetVehicle(ctx context.Context, id uuid.UUID) (*domain.Vehicle, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Vehicle), args.Error(1)
+}
+
+func (m *MockVehicleRegistryRepository) GetVehicleByPlate(ctx context.Context, plateNumber string) (*domain.Vehicle, error) {
+	args := m.Called(ctx, plateNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Vehicle), args.Error(1)
+}
+
+func (m *MockVehicleRegistryRepository) IsVehicleActive(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockVehicleRegistryRepository) ListVehicles(ctx context.Context, query domain.VehicleQuery) ([]domain.Vehicle, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Vehicle), args.Error(1)
+}
+
+func (m *MockVehicleRegistryRepository) UpdateVehicle(ctx context.Context, vehicle domain.Vehicle) (*domain.Vehicle, error) {
+	args := m.Called(ctx, vehicle)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Vehicle), args.Error(1)
+}
+
+func (m *MockVehicleRegistryRepository) ArchiveVehicle(ctx context.Context, id uuid.UUID) (*domain.Vehicle, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Vehicle), args.Error(1)
+}
+
+func TestVehicleRegistry(t *testing.T) {
+	ctx := context.Background()
+	archivedAt := time.Now()
+
+	t.Run("Create generates an ID", func(t *testing.T) {
+		repo := new(MockVehicleRegistryRepository)
+		repo.On("GetVehicleByPlate", mock.Anything, "KL01AB1234").Return(nil, domain.ErrNotFound)
+		repo.On("CreateVehicle", mock.Anything, mock.MatchedBy(func(v domain.Vehicle) bool {
+			return v.ID != uuid.Nil && v.PlateNumber == "KL01AB1234"
+		})).Return(&domain.Vehicle{ID: uuid.New(), PlateNumber: "KL01AB1234"}, nil)
+
+		_, err := services.NewVehicleRegistry(repo, new(MockVehicleCache)).CreateVehicle(ctx, domain.Vehicle{PlateNumber: "KL01AB1234"})
+		require.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Plate numbers are unique among active vehicles", func(t *testing.T) {
+		id := uuid.New()
+		repo := new(MockVehicleRegistryRepository)
+		repo.On("GetVehicleByPlate", mock.Anything, "KL01AB1234").Return(&domain.Vehicle{ID: id, PlateNumber: "KL01AB1234"}, nil)
+		repo.On("GetVehicleByPlate", mock.Anything, "KL01AB9999").Return(&domain.Vehicle{ID: uuid.New(), ArchivedAt: &archivedAt}, nil)
+		repo.On("CreateVehicle", mock.Anything, mock.Anything).Return(&domain.Vehicle{}, nil).Once()
+		repo.On("UpdateVehicle", mock.Anything, mock.Anything).Return(&domain.Vehicle{}, nil).Once()
+		registry := services.NewVehicleRegistry(repo, new(MockVehicleCache))
+
+		_, err := registry.CreateVehicle(ctx, domain.Vehicle{PlateNumber: "KL01AB1234"})
+		assert.ErrorIs(t, err, domain.ErrVehicleExists)
+
+		// The plate number of an archived vehicle can be reused.
+		_, err = registry.CreateVehicle(ctx, domain.Vehicle{PlateNumber: "KL01AB9999"})
+		assert.NoError(t, err)
+
+		// A vehicle keeps its own plate number on update.
+		_, err = registry.UpdateVehicle(ctx, domain.Vehicle{ID: id, PlateNumber: "KL01AB1234", Name: "Truck 7"})
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		repo := new(MockVehicleRegistryRepository)
+		_, err := services.NewVehicleRegistry(repo, new(MockVehicleCache)).CreateVehicle(ctx, domain.Vehicle{PlateNumber: "KL01AB1234KL01AB1234KL01AB1234KL01"})
+		var verr *domain.ValidationError
+		require.ErrorAs(t, err, &verr)
+		assert.Equal(t, "plate_number", verr.Fields[0].Field)
+		repo.AssertNotCalled(t, "CreateVehicle", mock.Anything, mock.Anything)
+	})
+
+	t.Run("List clamps the page size", func(t *testing.T) {
+		repo := new(MockVehicleRegistryRepository)
+		repo.On("ListVehicles", mock.Anything, domain.VehicleQuery{Limit: services.DefaultVehicleLimit}).Return([]domain.Vehicle{}, nil)
+		repo.On("ListVehicles", mock.Anything, domain.VehicleQuery{IncludeArchived: true, Limit: services.MaxVehicleLimit, Offset: 50}).Return([]domain.Vehicle{}, nil)
+		registry := services.NewVehicleRegistry(repo, new(MockVehicleCache))
+
+		_, err := registry.ListVehicles(ctx, domain.VehicleQuery{})
+		require.NoError(t, err)
+		_, err = registry.ListVehicles(ctx, domain.VehicleQuery{IncludeArchived: true, Limit: 5000, Offset: 50})
+		require.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Archive removes the vehicle from the fleet snapshot", func(t *testing.T) {
+		id := uuid.New()
+		repo, cache := new(MockVehicleRegistryRepository), new(MockVehicleCache)
+		repo.On("ArchiveVehicle", mock.Anything, id).Return(&domain.Vehicle{ID: id, ArchivedAt: &archivedAt}, nil).Twice()
+		cache.On("RemoveFromFleet", mock.Anything, id).Return(errors.New("redis down")).Once()
+		cache.On("RemoveFromFleet", mock.Anything, id).Return(nil).Once()
+		registry := services.NewVehicleRegistry(repo, cache)
+
+		_, err := registry.ArchiveVehicle(ctx, id)
+		assert.EqualError(t, err, "redis down")
+
+		// A retry archives again and clears the snapshot.
+		vehicle, err := registry.ArchiveVehicle(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, &archivedAt, vehicle.ArchivedAt)
+		repo.AssertExpectations(t)
+		cache.AssertExpectations(t)
+	})
+
+	t.Run("Archive runs the archive handlers", func(t *testing.T) {
+		id := uuid.New()
+		repo, cache, alerts := new(MockVehicleRegistryRepository), new(MockVehicleCache), new(MockAlertRepository)
+		repo.On("ArchiveVehicle", mock.Anything, id).Return(&domain.Vehicle{ID: id, ArchivedAt: &archivedAt}, nil).Twice()
+		cache.On("RemoveFromFleet", mock.Anything, id).Return(nil).Twice()
+		alerts.On("ResolveVehicleAlerts", mock.Anything, id, mock.Anything).Return(nil, errors.New("db error")).Once()
+		alerts.On("ResolveVehicleAlerts", mock.Anything, id, mock.Anything).Return([]domain.Alert{}, nil).Once()
+		registry := services.NewVehicleRegistry(repo, cache,
+			services.WithArchiveHandlers(services.NewAlertService(alerts, time.Minute, zap.NewNop())),
+		)
+
+		_, err := registry.ArchiveVehicle(ctx, id)
+		assert.EqualError(t, err, "db error")
+		_, err = registry.ArchiveVehicle(ctx, id)
+		require.NoError(t, err)
+		alerts.AssertExpectations(t)
+	})
+
+	t.Run("Archiving an unknown vehicle leaves the cache alone", func(t *testing.T) {
+		repo, cache := new(MockVehicleRegistryRepository), new(MockVehicleCache)
+		repo.On("ArchiveVehicle", mock.Anything, mock.Anything).Return(nil, domain.ErrNotFound)
+
+		_, err := services.NewVehicleRegistry(repo, cache).ArchiveVehicle(ctx, uuid.New())
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		cache.AssertNotCalled(t, "RemoveFromFleet", mock.Anything, mock.Anything)
+	})
+}
+
+func TestVehicleService_RegisteredVehiclesOnly(t *testing.T) {
+	registered, archived, unknown := uuid.New(), uuid.New(), uuid.New()
+	status := domain.VehicleStatus{Location: []float64{55.2962, 25.2769}, Speed: 60, Timestamp: time.Now().UTC()}
+	request := func(id uuid.UUID) domain.IngestRequest {
+		return domain.IngestRequest{VehicleID: pgtype.UUID{Bytes: id, Valid: true}, Status: status}
+	}
+
+	newService := func() (*services.VehicleService, *MockVehicleRepository, *MockVehicleRegistryRepository) {
+		repo, cache, registry := new(MockVehicleRepository), new(MockVehicleCache), new(MockVehicleRegistryRepository)
+		registry.On("IsVehicleActive", mock.Anything, registered).Return(true, nil)
+		registry.On("IsVehicleActive", mock.Anything, archived).Return(false, nil)
+		registry.On("IsVehicleActive", mock.Anything, unknown).Return(false, nil)
+		repo.On("UpdateVehicleStatus", mock.Anything, registered, "", status).Return(false, nil)
+		repo.On("InsertPosition", mock.Anything, registered, status).Return(nil)
+		repo.On("InsertPositions", mock.Anything, mock.Anything).Return(nil)
+		return services.NewVehicleService(repo, cache, services.WithRegisteredVehiclesOnly(registry)), repo, registry
+	}
+
+	t.Run("IngestData", func(t *testing.T) {
+		svc, repo, registry := newService()
+		assert.NoError(t, svc.IngestData(context.Background(), request(registered)))
+
+		for _, id := range []uuid.UUID{archived, unknown} {
+			err := svc.IngestData(context.Background(), request(id))
+			var verr *domain.ValidationError
+			require.ErrorAs(t, err, &verr)
+			assert.Equal(t, []domain.FieldError{{Field: "vehicle_id", Message: "is not a registered vehicle"}}, verr.Fields)
+		}
+		repo.AssertNumberOfCalls(t, "InsertPosition", 1)
+
+		// The answers are cached.
+		assert.NoError(t, svc.IngestData(context.Background(), request(registered)))
+		registry.AssertNumberOfCalls(t, "IsVehicleActive", 3)
+	})
+
+	t.Run("IngestBatch", func(t *testing.T) {
+		svc, _, registry := newService()
+		results, err := svc.IngestBatch(context.Background(), []domain.IngestRequest{
+			request(unknown), request(registered), request(unknown), request(registered),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, domain.IngestRejected, results[0].Status)
+		assert.Equal(t, "vehicle_id", results[0].Fields[0].Field)
+		assert.Equal(t, domain.IngestAccepted, results[1].Status)
+		assert.Equal(t, domain.IngestRejected, results[2].Status)
+		assert.Equal(t, domain.IngestAccepted, results[3].Status)
+
+		// Every vehicle is looked up once.
+		registry.AssertNumberOfCalls(t, "IsVehicleActive", 2)
+	})
+
+	t.Run("Lookup error", func(t *testing.T) {
+		repo, cache, registry := new(MockVehicleRepository), new(MockVehicleCache), new(MockVehicleRegistryRepository)
+		registry.On("IsVehicleActive", mock.Anything, registered).Return(false, errors.New("db error"))
+		svc := services.NewVehicleService(repo, cache, services.WithRegisteredVehiclesOnly(registry))
+
+		err := svc.IngestData(context.Background(), request(registered))
+		assert.EqualError(t, err, "db error")
+		_, err = svc.IngestBatch(context.Background(), []domain.IngestRequest{request(registered)})
+		assert.EqualError(t, err, "db error")
+	})
+}
@@ -0,0 +1,88 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/services"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/store/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// rowDB is a db.DBTX whose single-row queries scan into their destinations
+// with scan, standing in for PostgreSQL in repository tests.
+type rowDB struct {
+	scan func(dest ...any) error
+}
+
+func (d rowDB) Exec(context.Context, string, ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, errors.New("unexpected Exec")
+}
+
+func (d rowDB) Query(context.Context, string, ...any) (pgx.Rows, error) {
+	return nil, errors.New("unexpected Query")
+}
+
+func (d rowDB) QueryRow(context.Context, string, ...any) pgx.Row {
+	return scanRow(d.scan)
+}
+
+type scanRow func(dest ...any) error
+
+func (f scanRow) Scan(dest ...any) error { return f(dest...) }
+
+func TestVehicleRepository_GetVehicleStatus(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Stored status", func(t *testing.T) {
+		ts := time.Date(2025, 6, 17, 8, 18, 36, 0, time.UTC)
+		repo := postgres.NewVehicleRepository(rowDB{scan: func(dest ...any) error {
+			*dest[0].(*string) = `{"location":[55.2962,25.2769],"speed":42,"timestamp":"2025-06-17T08:18:36Z"}`
+			*dest[1].(*pgtype.Timestamptz) = pgtype.Timestamptz{Time: ts, Valid: true}
+			return nil
+		}})
+
+		status, err := repo.GetVehicleStatus(ctx, uuid.New())
+		require.NoError(t, err)
+		require.NotNil(t, status)
+		assert.Equal(t, []float64{55.2962, 25.2769}, status.Location)
+		assert.True(t, status.Timestamp.Equal(ts))
+	})
+
+	t.Run("Registered vehicle that has not reported", func(t *testing.T) {
+		repo := postgres.NewVehicleRepository(rowDB{scan: func(dest ...any) error {
+			*dest[0].(*string) = `{}`
+			*dest[1].(*pgtype.Timestamptz) = pgtype.Timestamptz{}
+			return nil
+		}})
+
+		status, err := repo.GetVehicleStatus(ctx, uuid.New())
+		assert.NoError(t, err)
+		assert.Nil(t, status)
+	})
+
+	t.Run("Unknown vehicle", func(t *testing.T) {
+		repo := postgres.NewVehicleRepository(rowDB{scan: func(...any) error { return pgx.ErrNoRows }})
+
+		_, err := repo.GetVehicleStatus(ctx, uuid.New())
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
+
+// unknownVehicleService is a VehicleService over a database without
+// vehicles and an empty cache.
+func unknownVehicleService() *services.VehicleService {
+	cache := new(MockVehicleCache)
+	cache.On("GetStatus", mock.Anything, mock.Anything).Return(nil, nil)
+	repo := postgres.NewVehicleRepository(rowDB{scan: func(...any) error { return pgx.ErrNoRows }})
+	return services.NewVehicleService(repo, cache)
+}
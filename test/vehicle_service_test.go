@@ -3,6 +3,7 @@ package test
 import (
 	"context"
 	"errors"
+	"slices"
 	"testing"
 	"time"
 
@@ -19,9 +20,9 @@ type MockVehicleRepository struct {
 	mock.Mock
 }
 
-func (m *MockVehicleRepository) UpdateVehicleStatus(ctx context.Context, vehicleID uuid.UUID, plate string, status domain.VehicleStatus) error {
+func (m *MockVehicleRepository) UpdateVehicleStatus(ctx context.Context, vehicleID uuid.UUID, plate string, status domain.VehicleStatus) (bool, error) {
 	args := m.Called(ctx, vehicleID, plate, status)
-	return args.Error(0)
+	return args.Bool(0), args.Error(1)
 }
 
 func (m *MockVehicleRepository) GetVehicleStatus(ctx context.Context, vehicleID uuid.UUID) (*domain.VehicleStatus, error) {
@@ -32,14 +33,63 @@ func (m *MockVehicleRepository) GetVehicleStatus(ctx context.Context, vehicleID
 	return args.Get(0).(*domain.VehicleStatus), args.Error(1)
 }
 
-func (m *MockVehicleRepository) FindTripsByVehicleID(ctx context.Context, vehicleID uuid.UUID, since time.Time) ([]domain.Trip, error) {
-	args := m.Called(ctx, vehicleID, since)
+func (m *MockVehicleRepository) ListTrips(ctx context.Context, query domain.TripQuery) ([]domain.Trip, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Trip), args.Error(1)
+}
+
+func (m *MockVehicleRepository) GetTrip(ctx context.Context, id uuid.UUID) (*domain.Trip, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Trip), args.Error(1)
+}
+
+func (m *MockVehicleRepository) CreateTrip(ctx context.Context, trip domain.Trip) error {
+	args := m.Called(ctx, trip)
+	return args.Error(0)
+}
+
+func (m *MockVehicleRepository) CloseTrip(ctx context.Context, trip domain.Trip) error {
+	args := m.Called(ctx, trip)
+	return args.Error(0)
+}
+
+func (m *MockVehicleRepository) ListOpenTrips(ctx context.Context) ([]domain.Trip, error) {
+	args := m.Called(ctx)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]domain.Trip), args.Error(1)
 }
 
+func (m *MockVehicleRepository) InsertPosition(ctx context.Context, vehicleID uuid.UUID, status domain.VehicleStatus) error {
+	args := m.Called(ctx, vehicleID, status)
+	return args.Error(0)
+}
+
+func (m *MockVehicleRepository) InsertPositions(ctx context.Context, positions []domain.Position) error {
+	args := m.Called(ctx, positions)
+	return args.Error(0)
+}
+
+func (m *MockVehicleRepository) ListPositions(ctx context.Context, query domain.PositionQuery) ([]domain.Position, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Position), args.Error(1)
+}
+
+func (m *MockVehicleRepository) CountPositions(ctx context.Context, query domain.PositionQuery) (int64, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 // --- Mock Cache ---
 type MockVehicleCache struct {
 	mock.Mock
@@ -58,38 +108,106 @@ func (m *MockVehicleCache) GetStatus(ctx context.Context, vehicleID uuid.UUID) (
 	return args.Get(0).(*domain.VehicleStatus), args.Error(1)
 }
 
+func (m *MockVehicleCache) ListStatuses(ctx context.Context, vehicleIDs []uuid.UUID, since time.Time) ([]domain.VehicleSnapshot, error) {
+	args := m.Called(ctx, vehicleIDs, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.VehicleSnapshot), args.Error(1)
+}
+
+func (m *MockVehicleCache) ListStatusesInBox(ctx context.Context, box domain.BoundingBox, since time.Time) ([]domain.VehicleSnapshot, error) {
+	args := m.Called(ctx, box, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.VehicleSnapshot), args.Error(1)
+}
+
+func (m *MockVehicleCache) RemoveFromFleet(ctx context.Context, vehicleID uuid.UUID) error {
+	args := m.Called(ctx, vehicleID)
+	return args.Error(0)
+}
+
+func (m *MockVehicleCache) FindNearby(ctx context.Context, lon, lat, radius float64, count int) ([]domain.NearbyVehicle, error) {
+	args := m.Called(ctx, lon, lat, radius, count)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.NearbyVehicle), args.Error(1)
+}
+
+func (m *MockVehicleCache) MarkMessageSeen(ctx context.Context, vehicleID uuid.UUID, messageID string, window time.Duration) (bool, error) {
+	args := m.Called(ctx, vehicleID, messageID, window)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockVehicleCache) ForgetMessage(ctx context.Context, vehicleID uuid.UUID, messageID string) error {
+	args := m.Called(ctx, vehicleID, messageID)
+	return args.Error(0)
+}
+
 // --- Tests for IngestData ---
 func TestVehicleService_IngestData(t *testing.T) {
 	vehicleID := uuid.New()
-	status := domain.VehicleStatus{Speed: 60}
+	status := domain.VehicleStatus{
+		Location:  []float64{55.296249, 25.276987},
+		Speed:     60,
+		Timestamp: time.Now().UTC(),
+	}
 
 	tests := []struct {
 		name       string
+		status     *domain.VehicleStatus
 		setupMocks func(repo *MockVehicleRepository, cache *MockVehicleCache)
 		wantErr    bool
 	}{
 		{
 			name: "Success",
 			setupMocks: func(repo *MockVehicleRepository, cache *MockVehicleCache) {
-				repo.On("UpdateVehicleStatus", mock.Anything, vehicleID, "", status).Return(nil)
+				repo.On("UpdateVehicleStatus", mock.Anything, vehicleID, "", status).Return(true, nil)
+				repo.On("InsertPosition", mock.Anything, vehicleID, status).Return(nil)
 				cache.On("SetStatus", mock.Anything, vehicleID, &status, services.CacheDuration).Return(nil)
 			},
 			wantErr: false,
 		},
+		{
+			name:       "Validation Error",
+			status:     &domain.VehicleStatus{Speed: -1},
+			setupMocks: func(repo *MockVehicleRepository, cache *MockVehicleCache) {},
+			wantErr:    true,
+		},
 		{
 			name: "Repo Error",
 			setupMocks: func(repo *MockVehicleRepository, cache *MockVehicleCache) {
-				repo.On("UpdateVehicleStatus", mock.Anything, vehicleID, "", status).Return(errors.New("db error"))
+				repo.On("UpdateVehicleStatus", mock.Anything, vehicleID, "", status).Return(false, errors.New("db error"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "Stale Reading",
+			setupMocks: func(repo *MockVehicleRepository, cache *MockVehicleCache) {
+				repo.On("UpdateVehicleStatus", mock.Anything, vehicleID, "", status).Return(false, nil)
+				repo.On("InsertPosition", mock.Anything, vehicleID, status).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "History Error",
+			setupMocks: func(repo *MockVehicleRepository, cache *MockVehicleCache) {
+				repo.On("UpdateVehicleStatus", mock.Anything, vehicleID, "", status).Return(true, nil)
+				repo.On("InsertPosition", mock.Anything, vehicleID, status).Return(errors.New("db error"))
 			},
 			wantErr: true,
 		},
 		{
 			name: "Cache Error",
 			setupMocks: func(repo *MockVehicleRepository, cache *MockVehicleCache) {
-				repo.On("UpdateVehicleStatus", mock.Anything, vehicleID, "", status).Return(nil)
+				repo.On("UpdateVehicleStatus", mock.Anything, vehicleID, "", status).Return(true, nil)
+				repo.On("InsertPosition", mock.Anything, vehicleID, status).Return(nil)
 				cache.On("SetStatus", mock.Anything, vehicleID, &status, services.CacheDuration).Return(errors.New("cache error"))
 			},
-			wantErr: true,
+			wantErr: false,
 		},
 	}
 
@@ -99,11 +217,16 @@ func TestVehicleService_IngestData(t *testing.T) {
 			mockCache := new(MockVehicleCache)
 			tt.setupMocks(mockRepo, mockCache)
 
-			svc := services.NewVehicleService(mockRepo, mockCache)
-			err := svc.IngestData(context.Background(), domain.IngestRequest{
+			req := domain.IngestRequest{
 				VehicleID: pgtype.UUID{Bytes: vehicleID, Valid: true},
 				Status:    status,
-			})
+			}
+			if tt.status != nil {
+				req.Status = *tt.status
+			}
+
+			svc := services.NewVehicleService(mockRepo, mockCache)
+			err := svc.IngestData(context.Background(), req)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -117,53 +240,878 @@ func TestVehicleService_IngestData(t *testing.T) {
 	}
 }
 
+func TestVehicleService_IngestData_Dedup(t *testing.T) {
+	vehicleID := uuid.New()
+	window := 10 * time.Minute
+	req := domain.IngestRequest{
+		VehicleID: pgtype.UUID{Bytes: vehicleID, Valid: true},
+		MessageID: "msg-42",
+		Status: domain.VehicleStatus{
+			Location:  []float64{55.296249, 25.276987},
+			Speed:     60,
+			Timestamp: time.Now().UTC(),
+		},
+	}
+
+	t.Run("First delivery is processed", func(t *testing.T) {
+		mockRepo := new(MockVehicleRepository)
+		mockCache := new(MockVehicleCache)
+		mockCache.On("MarkMessageSeen", mock.Anything, vehicleID, "msg-42", window).Return(true, nil)
+		mockRepo.On("UpdateVehicleStatus", mock.Anything, vehicleID, "", req.Status).Return(true, nil)
+		mockRepo.On("InsertPosition", mock.Anything, vehicleID, req.Status).Return(nil)
+		mockCache.On("SetStatus", mock.Anything, vehicleID, &req.Status, services.CacheDuration).Return(nil)
+
+		svc := services.NewVehicleService(mockRepo, mockCache, services.WithDedupWindow(window))
+		assert.NoError(t, svc.IngestData(context.Background(), req))
+
+		mockRepo.AssertExpectations(t)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("Retry is acknowledged but not processed", func(t *testing.T) {
+		mockRepo := new(MockVehicleRepository)
+		mockCache := new(MockVehicleCache)
+		mockCache.On("MarkMessageSeen", mock.Anything, vehicleID, "msg-42", window).Return(false, nil)
+
+		svc := services.NewVehicleService(mockRepo, mockCache, services.WithDedupWindow(window))
+		err := svc.IngestData(context.Background(), req)
+
+		assert.ErrorIs(t, err, domain.ErrDuplicateMessage)
+		mockRepo.AssertExpectations(t)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("Failed delivery can be retried", func(t *testing.T) {
+		mockRepo := new(MockVehicleRepository)
+		mockCache := new(MockVehicleCache)
+		mockCache.On("MarkMessageSeen", mock.Anything, vehicleID, "msg-42", window).Return(true, nil)
+		mockRepo.On("UpdateVehicleStatus", mock.Anything, vehicleID, "", req.Status).Return(false, errors.New("db error"))
+		mockCache.On("ForgetMessage", mock.Anything, vehicleID, "msg-42").Return(nil)
+
+		svc := services.NewVehicleService(mockRepo, mockCache, services.WithDedupWindow(window))
+		assert.Error(t, svc.IngestData(context.Background(), req))
+
+		mockRepo.AssertExpectations(t)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("Disabled without a window", func(t *testing.T) {
+		mockRepo := new(MockVehicleRepository)
+		mockCache := new(MockVehicleCache)
+		mockRepo.On("UpdateVehicleStatus", mock.Anything, vehicleID, "", req.Status).Return(true, nil)
+		mockRepo.On("InsertPosition", mock.Anything, vehicleID, req.Status).Return(nil)
+		mockCache.On("SetStatus", mock.Anything, vehicleID, &req.Status, services.CacheDuration).Return(nil)
+
+		svc := services.NewVehicleService(mockRepo, mockCache)
+		assert.NoError(t, svc.IngestData(context.Background(), req))
+
+		mockCache.AssertNotCalled(t, "MarkMessageSeen", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// failingProcessor counts the readings it is fed and rejects each of them.
+type failingProcessor struct {
+	calls int
+}
+
+func (p *failingProcessor) Process(ctx context.Context, vehicleID uuid.UUID, status domain.VehicleStatus) error {
+	p.calls++
+	return errors.New("processor error")
+}
+
+func TestVehicleService_IngestData_ProcessorError(t *testing.T) {
+	vehicleID := uuid.New()
+	status := domain.VehicleStatus{Location: []float64{55.29, 25.27}, Speed: 10, Timestamp: time.Now().UTC()}
+	req := domain.IngestRequest{VehicleID: pgtype.UUID{Bytes: vehicleID, Valid: true}, Status: status}
+
+	mockRepo := new(MockVehicleRepository)
+	mockCache := new(MockVehicleCache)
+	mockRepo.On("UpdateVehicleStatus", mock.Anything, vehicleID, "", status).Return(true, nil)
+	mockRepo.On("InsertPosition", mock.Anything, vehicleID, status).Return(nil)
+	mockCache.On("SetStatus", mock.Anything, vehicleID, &status, services.CacheDuration).Return(nil)
+	mockRepo.On("InsertPositions", mock.Anything, []domain.Position{{VehicleID: vehicleID, VehicleStatus: status}}).Return(nil)
+
+	first, second := &failingProcessor{}, &failingProcessor{}
+	svc := services.NewVehicleService(mockRepo, mockCache, services.WithStatusProcessors(first, second))
+
+	// The reading is stored, so the failure must not make the device resend it.
+	assert.NoError(t, svc.IngestData(context.Background(), req))
+	results, err := svc.IngestBatch(context.Background(), []domain.IngestRequest{req})
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.IngestResult{{Index: 0, Status: domain.IngestAccepted}}, results)
+
+	// One failing processor does not starve the next one.
+	assert.Equal(t, 2, first.calls)
+	assert.Equal(t, 2, second.calls)
+}
+
+func TestVehicleService_IngestData_CacheError(t *testing.T) {
+	vehicleID := uuid.New()
+	status := domain.VehicleStatus{Location: []float64{55.29, 25.27}, Speed: 10, Timestamp: time.Now().UTC()}
+	req := domain.IngestRequest{VehicleID: pgtype.UUID{Bytes: vehicleID, Valid: true}, Status: status, MessageID: "m-1"}
+
+	mockRepo := new(MockVehicleRepository)
+	mockCache := new(MockVehicleCache)
+	mockCache.On("MarkMessageSeen", mock.Anything, vehicleID, "m-1", time.Hour).Return(true, nil)
+	mockRepo.On("UpdateVehicleStatus", mock.Anything, vehicleID, "", status).Return(true, nil)
+	mockRepo.On("InsertPosition", mock.Anything, vehicleID, status).Return(nil)
+	mockCache.On("SetStatus", mock.Anything, vehicleID, &status, services.CacheDuration).Return(errors.New("cache error"))
+
+	processor := &failingProcessor{}
+	svc := services.NewVehicleService(mockRepo, mockCache,
+		services.WithDedupWindow(time.Hour),
+		services.WithStatusProcessors(processor),
+	)
+
+	// The reading is stored and its message ID kept, so it is neither
+	// resent nor left out of the derived data.
+	assert.NoError(t, svc.IngestData(context.Background(), req))
+	assert.Equal(t, 1, processor.calls)
+	mockCache.AssertNotCalled(t, "ForgetMessage", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// --- Tests for IngestBatch ---
+func TestVehicleService_IngestBatch(t *testing.T) {
+	vehicleA := uuid.New()
+	vehicleB := uuid.New()
+	start := time.Date(2025, 6, 17, 9, 0, 0, 0, time.UTC)
+
+	a1 := domain.VehicleStatus{Location: []float64{55.29, 25.27}, Speed: 10, Timestamp: start}
+	a2 := domain.VehicleStatus{Location: []float64{55.30, 25.28}, Speed: 20, Timestamp: start.Add(time.Minute)}
+	b1 := domain.VehicleStatus{Location: []float64{55.31, 25.29}, Speed: 30, Timestamp: start.Add(30 * time.Second)}
+
+	items := []domain.IngestRequest{
+		{VehicleID: pgtype.UUID{Bytes: vehicleA, Valid: true}, PlateNumber: "A", Status: a2},
+		{Status: b1},
+		{VehicleID: pgtype.UUID{Bytes: vehicleB, Valid: true}, PlateNumber: "B", Status: b1},
+		{VehicleID: pgtype.UUID{Bytes: vehicleA, Valid: true}, PlateNumber: "A", Status: a1},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockVehicleRepository)
+		mockCache := new(MockVehicleCache)
+		mockRepo.On("UpdateVehicleStatus", mock.Anything, vehicleA, "A", a2).Return(true, nil).Once()
+		mockRepo.On("UpdateVehicleStatus", mock.Anything, vehicleB, "B", b1).Return(true, nil).Once()
+		mockRepo.On("InsertPositions", mock.Anything, []domain.Position{
+			{VehicleID: vehicleA, VehicleStatus: a1},
+			{VehicleID: vehicleB, VehicleStatus: b1},
+			{VehicleID: vehicleA, VehicleStatus: a2},
+		}).Return(nil).Once()
+		mockCache.On("SetStatus", mock.Anything, vehicleA, &a2, services.CacheDuration).Return(nil).Once()
+		mockCache.On("SetStatus", mock.Anything, vehicleB, &b1, services.CacheDuration).Return(nil).Once()
+
+		svc := services.NewVehicleService(mockRepo, mockCache)
+		results, err := svc.IngestBatch(context.Background(), items)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.IngestResult{
+			{Index: 0, Status: domain.IngestAccepted},
+			{
+				Index:  1,
+				Status: domain.IngestRejected,
+				Error:  "validation failed: vehicle_id: is required",
+				Fields: []domain.FieldError{{Field: "vehicle_id", Message: "is required"}},
+			},
+			{Index: 2, Status: domain.IngestAccepted},
+			{Index: 3, Status: domain.IngestAccepted},
+		}, results)
+		mockRepo.AssertExpectations(t)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("History Error", func(t *testing.T) {
+		mockRepo := new(MockVehicleRepository)
+		mockCache := new(MockVehicleCache)
+		mockRepo.On("UpdateVehicleStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+		mockRepo.On("InsertPositions", mock.Anything, mock.Anything).Return(errors.New("db error"))
+
+		svc := services.NewVehicleService(mockRepo, mockCache)
+		results, err := svc.IngestBatch(context.Background(), items)
+
+		assert.Error(t, err)
+		assert.Nil(t, results)
+		mockCache.AssertNotCalled(t, "SetStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Cache Error After The Batch Was Stored", func(t *testing.T) {
+		mockRepo := new(MockVehicleRepository)
+		mockCache := new(MockVehicleCache)
+		mockRepo.On("UpdateVehicleStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+		mockRepo.On("InsertPositions", mock.Anything, mock.Anything).Return(nil)
+		mockCache.On("SetStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("cache error"))
+
+		svc := services.NewVehicleService(mockRepo, mockCache)
+		results, err := svc.IngestBatch(context.Background(), items)
+
+		assert.Error(t, err)
+		assert.Len(t, results, len(items))
+	})
+}
+
 // --- Tests for GetVehicleTrips ---
+func TestVehicleService_GetVehicleStatus(t *testing.T) {
+	vehicleID := uuid.New()
+
+	t.Run("Writes the stored status back to the cache", func(t *testing.T) {
+		status := &domain.VehicleStatus{Location: []float64{55.2962, 25.2769}, Timestamp: time.Now()}
+		mockRepo, mockCache := new(MockVehicleRepository), new(MockVehicleCache)
+		mockCache.On("GetStatus", mock.Anything, vehicleID).Return(nil, nil)
+		mockRepo.On("GetVehicleStatus", mock.Anything, vehicleID).Return(status, nil)
+		mockCache.On("SetStatus", mock.Anything, vehicleID, status, time.Hour).Return(nil)
+
+		got, err := services.NewVehicleService(mockRepo, mockCache).GetVehicleStatus(context.Background(), vehicleID)
+		assert.NoError(t, err)
+		assert.Equal(t, status, got)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("Vehicle without a status", func(t *testing.T) {
+		mockRepo, mockCache := new(MockVehicleRepository), new(MockVehicleCache)
+		mockCache.On("GetStatus", mock.Anything, vehicleID).Return(nil, nil)
+		mockRepo.On("GetVehicleStatus", mock.Anything, vehicleID).Return(nil, nil)
+
+		got, err := services.NewVehicleService(mockRepo, mockCache).GetVehicleStatus(context.Background(), vehicleID)
+		assert.NoError(t, err)
+		assert.Nil(t, got)
+		mockCache.AssertNotCalled(t, "SetStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
 func TestVehicleService_GetVehicleTrips(t *testing.T) {
 	vehicleID := uuid.New()
-	trips := []domain.Trip{
-		{VehicleID: pgtype.UUID{Bytes: vehicleID, Valid: true}, Mileage: 100},
+	start := time.Date(2025, 6, 17, 9, 0, 0, 0, time.UTC)
+	trips := make([]domain.Trip, 3)
+	for i := range trips {
+		trips[i] = domain.Trip{
+			ID:        pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			VehicleID: pgtype.UUID{Bytes: vehicleID, Valid: true},
+			StartTime: pgtype.Timestamptz{Time: start.Add(-time.Duration(i) * time.Hour), Valid: true},
+			Mileage:   100,
+		}
 	}
 
-	tests := []struct {
-		name       string
-		setupMocks func(repo *MockVehicleRepository)
-		wantTrips  []domain.Trip
-		wantErr    bool
-	}{
-		{
-			name: "Success",
-			setupMocks: func(repo *MockVehicleRepository) {
-				repo.On("FindTripsByVehicleID", mock.Anything, vehicleID, mock.Anything).Return(trips, nil)
-			},
-			wantTrips: trips,
-			wantErr:   false,
-		},
-		{
-			name: "Repo Error",
-			setupMocks: func(repo *MockVehicleRepository) {
-				repo.On("FindTripsByVehicleID", mock.Anything, vehicleID, mock.Anything).Return(nil, errors.New("db error"))
-			},
-			wantTrips: nil,
-			wantErr:   true,
-		},
+	t.Run("Applies defaults", func(t *testing.T) {
+		mockRepo := new(MockVehicleRepository)
+		mockRepo.On("ListTrips", mock.Anything, mock.MatchedBy(func(q domain.TripQuery) bool {
+			return q.VehicleID == vehicleID &&
+				q.To.Sub(q.From) == services.TripHistoryWindow &&
+				q.Order == domain.SortDescending &&
+				q.Limit == services.DefaultTripLimit+1
+		})).Return(trips, nil)
+
+		svc := services.NewVehicleService(mockRepo, nil)
+		page, err := svc.GetVehicleTrips(context.Background(), domain.TripQuery{VehicleID: vehicleID})
+
+		assert.NoError(t, err)
+		assert.Equal(t, trips, page.Trips)
+		assert.Nil(t, page.Next)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Returns the cursor of the next page", func(t *testing.T) {
+		from := start.Add(-30 * 24 * time.Hour)
+		after := &domain.TripCursor{StartTime: start.Add(time.Hour), ID: uuid.New()}
+		mockRepo := new(MockVehicleRepository)
+		mockRepo.On("ListTrips", mock.Anything, domain.TripQuery{
+			VehicleID:  vehicleID,
+			From:       from,
+			To:         start,
+			MinMileage: 50,
+			Order:      domain.SortAscending,
+			After:      after,
+			Limit:      3,
+		}).Return(trips, nil)
+
+		svc := services.NewVehicleService(mockRepo, nil)
+		page, err := svc.GetVehicleTrips(context.Background(), domain.TripQuery{
+			VehicleID:  vehicleID,
+			From:       from,
+			To:         start,
+			MinMileage: 50,
+			Order:      domain.SortAscending,
+			After:      after,
+			Limit:      2,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, trips[:2], page.Trips)
+		if assert.NotNil(t, page.Next) {
+			assert.Equal(t, domain.TripCursor{StartTime: trips[1].StartTime.Time, ID: trips[1].ID.Bytes}, *page.Next)
+		}
+	})
+
+	t.Run("Caps the limit", func(t *testing.T) {
+		mockRepo := new(MockVehicleRepository)
+		mockRepo.On("ListTrips", mock.Anything, mock.MatchedBy(func(q domain.TripQuery) bool {
+			return q.Limit == services.MaxTripLimit+1
+		})).Return(nil, errors.New("db error"))
+
+		svc := services.NewVehicleService(mockRepo, nil)
+		_, err := svc.GetVehicleTrips(context.Background(), domain.TripQuery{VehicleID: vehicleID, Limit: 50000})
+
+		assert.Error(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestTripCursor(t *testing.T) {
+	cursor := domain.TripCursor{StartTime: time.Date(2025, 6, 17, 9, 0, 0, 123456000, time.UTC), ID: uuid.New()}
+
+	got, err := domain.ParseTripCursor(cursor.String())
+	assert.NoError(t, err)
+	assert.Equal(t, cursor, *got)
+
+	for _, token := range []string{"", "!!", "bm8tc2VwYXJhdG9y", "eWVzdGVyZGF5fGFiYw"} {
+		_, err := domain.ParseTripCursor(token)
+		assert.Error(t, err, token)
 	}
+}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			mockRepo := new(MockVehicleRepository)
-			tt.setupMocks(mockRepo)
+// --- Tests for GetVehiclePositions ---
+func TestVehicleService_GetVehiclePositions(t *testing.T) {
+	vehicleID := uuid.New()
+	positions := []domain.Position{
+		{ID: 1, VehicleID: vehicleID, VehicleStatus: domain.VehicleStatus{Speed: 42}},
+	}
 
-			svc := services.NewVehicleService(mockRepo, nil)
-			got, err := svc.GetVehicleTrips(context.Background(), vehicleID)
+	t.Run("Applies defaults", func(t *testing.T) {
+		mockRepo := new(MockVehicleRepository)
+		mockRepo.On("ListPositions", mock.Anything, mock.MatchedBy(func(q domain.PositionQuery) bool {
+			return q.VehicleID == vehicleID &&
+				q.To.Sub(q.From) == services.PositionHistoryWindow &&
+				q.Limit == services.DefaultPositionLimit
+		})).Return(positions, nil)
 
-			if tt.wantErr {
-				assert.Error(t, err)
-			} else {
-				assert.NoError(t, err)
-				assert.Equal(t, tt.wantTrips, got)
-			}
+		svc := services.NewVehicleService(mockRepo, nil)
+		got, err := svc.GetVehiclePositions(context.Background(), domain.PositionQuery{VehicleID: vehicleID})
 
-			mockRepo.AssertExpectations(t)
+		assert.NoError(t, err)
+		assert.Equal(t, positions, got)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Caps the limit", func(t *testing.T) {
+		from := time.Now().Add(-time.Hour)
+		to := time.Now()
+		mockRepo := new(MockVehicleRepository)
+		mockRepo.On("ListPositions", mock.Anything, domain.PositionQuery{
+			VehicleID: vehicleID,
+			From:      from,
+			To:        to,
+			Limit:     services.MaxPositionLimit,
+			Offset:    50,
+		}).Return(positions, nil)
+
+		svc := services.NewVehicleService(mockRepo, nil)
+		_, err := svc.GetVehiclePositions(context.Background(), domain.PositionQuery{
+			VehicleID: vehicleID,
+			From:      from,
+			To:        to,
+			Limit:     5000,
+			Offset:    50,
 		})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+// --- Tests for GetTripsPositions ---
+func TestVehicleService_GetTripsPositions(t *testing.T) {
+	vehicleID := uuid.New()
+	start := time.Date(2025, 6, 17, 9, 0, 0, 0, time.UTC)
+	trip := func(from, to time.Duration) domain.Trip {
+		end := start.Add(to)
+		return domain.Trip{
+			VehicleID: pgtype.UUID{Bytes: vehicleID, Valid: true},
+			StartTime: pgtype.Timestamptz{Time: start.Add(from), Valid: true},
+			EndTime:   &end,
+		}
+	}
+	at := func(id int64, offset time.Duration) domain.Position {
+		return domain.Position{ID: id, VehicleID: vehicleID, VehicleStatus: domain.VehicleStatus{Timestamp: start.Add(offset)}}
+	}
+	ids := func(got []domain.Position) []int64 {
+		out := []int64{}
+		for _, p := range got {
+			out = append(out, p.ID)
+		}
+		return out
+	}
+
+	t.Run("Splits one query over the page between the trips", func(t *testing.T) {
+		// A page in the default newest-first order.
+		trips := []domain.Trip{trip(2*time.Hour, 3*time.Hour), trip(0, time.Hour)}
+
+		mockRepo := new(MockVehicleRepository)
+		mockRepo.On("ListPositions", mock.Anything, domain.PositionQuery{
+			VehicleID: vehicleID,
+			From:      start,
+			To:        start.Add(3 * time.Hour),
+			Limit:     services.MaxTripPositions,
+		}).Return([]domain.Position{
+			at(1, 0), at(2, time.Hour), at(3, 90*time.Minute), at(4, 2*time.Hour), at(5, 3*time.Hour),
+		}, nil).Once()
+
+		svc := services.NewVehicleService(mockRepo, nil)
+		routes, err := svc.GetTripsPositions(context.Background(), trips)
+
+		assert.NoError(t, err)
+		assert.Len(t, routes, 2)
+		assert.Equal(t, []int64{4, 5}, ids(routes[0].Positions))
+		assert.Equal(t, []int64{1, 2}, ids(routes[1].Positions))
+		assert.False(t, routes[0].Truncated)
+		assert.False(t, routes[1].Truncated)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Flags the trips cut off at MaxTripPositions", func(t *testing.T) {
+		trips := []domain.Trip{trip(0, time.Hour), trip(2*time.Hour, 3*time.Hour), trip(4*time.Hour, 5*time.Hour)}
+		positions := make([]domain.Position, services.MaxTripPositions)
+		for i := range positions {
+			positions[i] = at(int64(i), time.Duration(i)*900*time.Millisecond)
+		}
+		// The last position read lies in the second trip.
+		cutoff := positions[len(positions)-1].Timestamp
+
+		mockRepo := new(MockVehicleRepository)
+		mockRepo.On("ListPositions", mock.Anything, mock.Anything).Return(positions, nil).Once()
+
+		svc := services.NewVehicleService(mockRepo, nil)
+		routes, err := svc.GetTripsPositions(context.Background(), trips)
+
+		assert.NoError(t, err)
+		assert.False(t, routes[0].Truncated)
+		assert.True(t, routes[1].Truncated)
+		assert.True(t, routes[2].Truncated)
+		assert.Equal(t, cutoff, routes[1].Positions[len(routes[1].Positions)-1].Timestamp)
+		assert.Empty(t, routes[2].Positions)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Empty page", func(t *testing.T) {
+		mockRepo := new(MockVehicleRepository)
+		svc := services.NewVehicleService(mockRepo, nil)
+		routes, err := svc.GetTripsPositions(context.Background(), nil)
+
+		assert.NoError(t, err)
+		assert.Empty(t, routes)
+		mockRepo.AssertNotCalled(t, "ListPositions", mock.Anything, mock.Anything)
+	})
+}
+
+func TestVehicleService_GetTripRoute(t *testing.T) {
+	tripID, vehicleID := uuid.New(), uuid.New()
+	start := time.Date(2025, 6, 17, 9, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	trip := &domain.Trip{
+		ID:        pgtype.UUID{Bytes: tripID, Valid: true},
+		VehicleID: pgtype.UUID{Bytes: vehicleID, Valid: true},
+		StartTime: pgtype.Timestamptz{Time: start, Valid: true},
+		EndTime:   &end,
+	}
+	positions := make([]domain.Position, 10)
+	for i := range positions {
+		positions[i] = domain.Position{ID: int64(i), VehicleID: vehicleID}
+	}
+	ids := func(got []domain.Position) []int64 {
+		out := make([]int64, len(got))
+		for i, p := range got {
+			out[i] = p.ID
+		}
+		return out
 	}
+	query := domain.PositionQuery{VehicleID: vehicleID, From: start, To: end}
+	batch := query
+	batch.Limit = services.ExportBatchSize
+	newService := func() *services.VehicleService {
+		mockRepo := new(MockVehicleRepository)
+		mockRepo.On("GetTrip", mock.Anything, tripID).Return(trip, nil)
+		mockRepo.On("CountPositions", mock.Anything, query).Return(int64(len(positions)), nil)
+		mockRepo.On("ListPositions", mock.Anything, batch).Return(positions, nil)
+		return services.NewVehicleService(mockRepo, nil)
+	}
+
+	t.Run("Full route", func(t *testing.T) {
+		got, route, err := newService().GetTripRoute(context.Background(), tripID, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, trip, got)
+		assert.Equal(t, positions, route)
+	})
+
+	t.Run("Downsampled keeping both ends", func(t *testing.T) {
+		_, route, err := newService().GetTripRoute(context.Background(), tripID, 4)
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{0, 3, 6, 9}, ids(route))
+	})
+
+	t.Run("Fewer positions than asked for", func(t *testing.T) {
+		_, route, err := newService().GetTripRoute(context.Background(), tripID, 50)
+		assert.NoError(t, err)
+		assert.Len(t, route, 10)
+	})
+
+	t.Run("Downsampled over the whole trip", func(t *testing.T) {
+		long := make([]domain.Position, 2*services.ExportBatchSize+500)
+		for i := range long {
+			long[i] = domain.Position{ID: int64(i), VehicleID: vehicleID, VehicleStatus: domain.VehicleStatus{Timestamp: start}}
+		}
+		second, third := batch, batch
+		second.After = &domain.PositionCursor{RecordedAt: start, ID: services.ExportBatchSize - 1}
+		third.After = &domain.PositionCursor{RecordedAt: start, ID: 2*services.ExportBatchSize - 1}
+
+		mockRepo := new(MockVehicleRepository)
+		mockRepo.On("GetTrip", mock.Anything, tripID).Return(trip, nil)
+		mockRepo.On("CountPositions", mock.Anything, query).Return(int64(len(long)), nil)
+		mockRepo.On("ListPositions", mock.Anything, batch).Return(long[:services.ExportBatchSize], nil)
+		mockRepo.On("ListPositions", mock.Anything, second).Return(long[services.ExportBatchSize:2*services.ExportBatchSize], nil)
+		mockRepo.On("ListPositions", mock.Anything, third).Return(long[2*services.ExportBatchSize:], nil)
+
+		_, route, err := services.NewVehicleService(mockRepo, nil).GetTripRoute(context.Background(), tripID, 5)
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{0, 624, 1249, 1874, 2499}, ids(route))
+	})
+
+	t.Run("Unknown trip", func(t *testing.T) {
+		mockRepo := new(MockVehicleRepository)
+		mockRepo.On("GetTrip", mock.Anything, tripID).Return(nil, domain.ErrNotFound)
+
+		_, _, err := services.NewVehicleService(mockRepo, nil).GetTripRoute(context.Background(), tripID, 0)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		mockRepo.AssertNotCalled(t, "ListPositions", mock.Anything, mock.Anything)
+	})
+}
+
+func TestVehicleService_ExportTrips(t *testing.T) {
+	vehicleID := uuid.New()
+	start := time.Date(2025, 6, 17, 9, 0, 0, 0, time.UTC)
+	from := start.Add(-30 * 24 * time.Hour)
+	trips := make([]domain.Trip, services.ExportBatchSize+1)
+	for i := range trips {
+		trips[i] = domain.Trip{
+			ID:        pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			VehicleID: pgtype.UUID{Bytes: vehicleID, Valid: true},
+			StartTime: pgtype.Timestamptz{Time: start.Add(-time.Duration(i) * time.Minute), Valid: true},
+		}
+	}
+	last := trips[services.ExportBatchSize-1]
+	query := domain.TripQuery{VehicleID: vehicleID, From: from, To: start, Order: domain.SortDescending, Limit: services.ExportBatchSize + 1}
+	next := query
+	next.After = &domain.TripCursor{StartTime: last.StartTime.Time, ID: last.ID.Bytes}
+
+	t.Run("Reads every batch", func(t *testing.T) {
+		mockRepo := new(MockVehicleRepository)
+		mockRepo.On("ListTrips", mock.Anything, query).Return(trips, nil).Once()
+		mockRepo.On("ListTrips", mock.Anything, next).Return(trips[services.ExportBatchSize:], nil).Once()
+
+		var got []domain.Trip
+		svc := services.NewVehicleService(mockRepo, nil)
+		err := svc.ExportTrips(context.Background(), domain.TripQuery{VehicleID: vehicleID, From: from, To: start, Limit: 5}, func(trip domain.Trip) error {
+			got = append(got, trip)
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, trips, got)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Stops at the first error", func(t *testing.T) {
+		mockRepo := new(MockVehicleRepository)
+		mockRepo.On("ListTrips", mock.Anything, query).Return(trips, nil).Once()
+
+		calls := 0
+		writeErr := errors.New("client gone")
+		svc := services.NewVehicleService(mockRepo, nil)
+		err := svc.ExportTrips(context.Background(), domain.TripQuery{VehicleID: vehicleID, From: from, To: start}, func(domain.Trip) error {
+			calls++
+			return writeErr
+		})
+
+		assert.ErrorIs(t, err, writeErr)
+		assert.Equal(t, 1, calls)
+		mockRepo.AssertNumberOfCalls(t, "ListTrips", 1)
+	})
+}
+
+func TestVehicleService_ExportPositions(t *testing.T) {
+	vehicleID := uuid.New()
+	start := time.Date(2025, 6, 17, 9, 0, 0, 0, time.UTC)
+	positions := make([]domain.Position, services.ExportBatchSize+1)
+	for i := range positions {
+		positions[i] = domain.Position{
+			ID:            int64(i + 1),
+			VehicleID:     vehicleID,
+			VehicleStatus: domain.VehicleStatus{Timestamp: start.Add(time.Duration(i) * time.Second)},
+		}
+	}
+	last := positions[services.ExportBatchSize-1]
+
+	t.Run("Continues after the last position of each batch", func(t *testing.T) {
+		mockRepo := new(MockVehicleRepository)
+		mockRepo.On("ListPositions", mock.Anything, domain.PositionQuery{
+			VehicleID: vehicleID,
+			From:      start,
+			To:        start.Add(time.Hour),
+			Limit:     services.ExportBatchSize,
+		}).Return(positions[:services.ExportBatchSize], nil).Once()
+		mockRepo.On("ListPositions", mock.Anything, domain.PositionQuery{
+			VehicleID: vehicleID,
+			From:      start,
+			To:        start.Add(time.Hour),
+			After:     &domain.PositionCursor{RecordedAt: last.Timestamp, ID: last.ID},
+			Limit:     services.ExportBatchSize,
+		}).Return(positions[services.ExportBatchSize:], nil).Once()
+
+		var got []domain.Position
+		svc := services.NewVehicleService(mockRepo, nil)
+		err := svc.ExportPositions(context.Background(), domain.PositionQuery{
+			VehicleID: vehicleID,
+			From:      start,
+			To:        start.Add(time.Hour),
+			Limit:     10,
+			Offset:    20,
+		}, func(p domain.Position) error {
+			got = append(got, p)
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, positions, got)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Applies the default window", func(t *testing.T) {
+		mockRepo := new(MockVehicleRepository)
+		mockRepo.On("ListPositions", mock.Anything, mock.MatchedBy(func(q domain.PositionQuery) bool {
+			return q.To.Sub(q.From) == services.PositionHistoryWindow && q.After == nil
+		})).Return(nil, errors.New("db error"))
+
+		svc := services.NewVehicleService(mockRepo, nil)
+		err := svc.ExportPositions(context.Background(), domain.PositionQuery{VehicleID: vehicleID}, func(domain.Position) error {
+			return nil
+		})
+
+		assert.EqualError(t, err, "db error")
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestVehicleService_GetFleetStatus(t *testing.T) {
+	now := time.Now().UTC()
+	ids := []uuid.UUID{
+		uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+		uuid.MustParse("00000000-0000-0000-0000-000000000002"),
+		uuid.MustParse("00000000-0000-0000-0000-000000000003"),
+	}
+	// Listed out of order, with one vehicle twice and one without a location.
+	snapshots := func() []domain.VehicleSnapshot {
+		return []domain.VehicleSnapshot{
+			{VehicleID: ids[2], Status: domain.VehicleStatus{Location: []float64{55.3, 25.2}, Timestamp: now}},
+			{VehicleID: ids[0], Status: domain.VehicleStatus{Location: []float64{2.35, 48.85}, Timestamp: now}},
+			{VehicleID: ids[1], Status: domain.VehicleStatus{Timestamp: now}},
+			{VehicleID: ids[2], Status: domain.VehicleStatus{Location: []float64{55.3, 25.2}, Timestamp: now}},
+		}
+	}
+	vehicleIDs := func(got []domain.VehicleSnapshot) []uuid.UUID {
+		out := make([]uuid.UUID, len(got))
+		for i, v := range got {
+			out[i] = v.VehicleID
+		}
+		return out
+	}
+
+	t.Run("Sorts and deduplicates", func(t *testing.T) {
+		mockCache := new(MockVehicleCache)
+		mockCache.On("ListStatuses", mock.Anything, []uuid.UUID(nil), time.Time{}).Return(snapshots(), nil)
+
+		svc := services.NewVehicleService(nil, mockCache)
+		got, err := svc.GetFleetStatus(context.Background(), domain.FleetQuery{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, ids, vehicleIDs(got))
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("Looks up a bounding box in the position index", func(t *testing.T) {
+		since := now.Add(-time.Hour)
+		box := domain.BoundingBox{MinLon: 50, MinLat: 20, MaxLon: 60, MaxLat: 30}
+		mockCache := new(MockVehicleCache)
+		mockCache.On("ListStatusesInBox", mock.Anything, box, since).Return(snapshots(), nil)
+
+		svc := services.NewVehicleService(nil, mockCache)
+		got, err := svc.GetFleetStatus(context.Background(), domain.FleetQuery{BBox: &box, UpdatedSince: since})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{ids[2]}, vehicleIDs(got))
+		mockCache.AssertNotCalled(t, "ListStatuses", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Filters selected vehicles by bounding box", func(t *testing.T) {
+		mockCache := new(MockVehicleCache)
+		mockCache.On("ListStatuses", mock.Anything, ids, time.Time{}).Return([]domain.VehicleSnapshot{
+			{VehicleID: ids[0], Status: domain.VehicleStatus{Location: []float64{179.5, -17}}},
+			{VehicleID: ids[1], Status: domain.VehicleStatus{Location: []float64{-179.5, -17}}},
+			{VehicleID: ids[2], Status: domain.VehicleStatus{Location: []float64{0, -17}}},
+		}, nil)
+
+		svc := services.NewVehicleService(nil, mockCache)
+		got, err := svc.GetFleetStatus(context.Background(), domain.FleetQuery{
+			VehicleIDs: ids,
+			BBox:       &domain.BoundingBox{MinLon: 179, MinLat: -18, MaxLon: -179, MaxLat: -16},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, ids[:2], vehicleIDs(got))
+		mockCache.AssertNotCalled(t, "ListStatusesInBox", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Paginates", func(t *testing.T) {
+		mockCache := new(MockVehicleCache)
+		mockCache.On("ListStatuses", mock.Anything, ids, time.Time{}).Return(snapshots(), nil).Once()
+		mockCache.On("ListStatuses", mock.Anything, ids, time.Time{}).Return(snapshots(), nil).Once()
+
+		svc := services.NewVehicleService(nil, mockCache)
+		got, err := svc.GetFleetStatus(context.Background(), domain.FleetQuery{VehicleIDs: ids, Limit: 1, Offset: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{ids[1]}, vehicleIDs(got))
+
+		got, err = svc.GetFleetStatus(context.Background(), domain.FleetQuery{VehicleIDs: ids, Offset: 3})
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+		assert.NotNil(t, got)
+	})
+
+	t.Run("Rejects an invalid bounding box", func(t *testing.T) {
+		svc := services.NewVehicleService(nil, new(MockVehicleCache))
+		_, err := svc.GetFleetStatus(context.Background(), domain.FleetQuery{
+			BBox: &domain.BoundingBox{MinLon: 0, MinLat: 10, MaxLon: 1, MaxLat: 5},
+		})
+
+		var verr *domain.ValidationError
+		assert.ErrorAs(t, err, &verr)
+	})
+}
+
+func TestVehicleService_FindNearbyVehicles(t *testing.T) {
+	now := time.Now().UTC()
+	fresh, stale, other := uuid.New(), uuid.New(), uuid.New()
+	found := []domain.NearbyVehicle{
+		{VehicleID: stale, Distance: 100, Status: domain.VehicleStatus{Timestamp: now.Add(-2 * time.Hour)}},
+		{VehicleID: fresh, Distance: 200, Status: domain.VehicleStatus{Timestamp: now}},
+		{VehicleID: other, Distance: 300, Status: domain.VehicleStatus{Timestamp: now}},
+	}
+
+	t.Run("Applies the default limit", func(t *testing.T) {
+		mockCache := new(MockVehicleCache)
+		mockCache.On("FindNearby", mock.Anything, 55.27, 25.2, 5000.0, services.DefaultNearbyLimit).Return(found, nil)
+
+		svc := services.NewVehicleService(nil, mockCache)
+		got, err := svc.FindNearbyVehicles(context.Background(), domain.NearbyQuery{Longitude: 55.27, Latitude: 25.2, Radius: 5000})
+
+		assert.NoError(t, err)
+		assert.Equal(t, found, got)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("Drops stale vehicles before applying the limit", func(t *testing.T) {
+		mockCache := new(MockVehicleCache)
+		mockCache.On("FindNearby", mock.Anything, 55.27, 25.2, 0.0, 4).Return(slices.Clone(found), nil).Once()
+
+		svc := services.NewVehicleService(nil, mockCache)
+		got, err := svc.FindNearbyVehicles(context.Background(), domain.NearbyQuery{
+			Longitude:    55.27,
+			Latitude:     25.2,
+			Limit:        1,
+			UpdatedSince: now.Add(-time.Hour),
+		})
+
+		assert.NoError(t, err)
+		if assert.Len(t, got, 1) {
+			assert.Equal(t, fresh, got[0].VehicleID)
+		}
+	})
+
+	t.Run("Widens the search until enough fresh vehicles are found", func(t *testing.T) {
+		near := func(n int, at time.Time) []domain.NearbyVehicle {
+			out := make([]domain.NearbyVehicle, n)
+			for i := range out {
+				out[i] = domain.NearbyVehicle{VehicleID: uuid.New(), Distance: float64(i), Status: domain.VehicleStatus{Timestamp: at}}
+			}
+			return out
+		}
+		// The 60 nearest vehicles are parked, then fresh ones follow.
+		fleet := append(near(60, now.Add(-2*time.Hour)), near(200, now)...)
+		mockCache := new(MockVehicleCache)
+		for _, count := range []int{40, 80} {
+			mockCache.On("FindNearby", mock.Anything, 55.27, 25.2, 0.0, count).Return(slices.Clone(fleet[:count]), nil).Once()
+		}
+
+		svc := services.NewVehicleService(nil, mockCache)
+		got, err := svc.FindNearbyVehicles(context.Background(), domain.NearbyQuery{
+			Longitude:    55.27,
+			Latitude:     25.2,
+			UpdatedSince: now.Add(-time.Hour),
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, fleet[60:70], got)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("Stops widening at the end of the index or MaxNearbySearch", func(t *testing.T) {
+		stale := make([]domain.NearbyVehicle, services.MaxNearbySearch)
+		for i := range stale {
+			stale[i] = domain.NearbyVehicle{VehicleID: uuid.New(), Status: domain.VehicleStatus{Timestamp: now.Add(-2 * time.Hour)}}
+		}
+		mockCache := new(MockVehicleCache)
+		for _, count := range []int{40, 80, 160, 320, 640, 1280, 2560, 5120, services.MaxNearbySearch} {
+			mockCache.On("FindNearby", mock.Anything, 0.0, 0.0, 0.0, count).Return(stale[:count], nil).Once()
+		}
+		mockCache.On("FindNearby", mock.Anything, 1.0, 1.0, 0.0, 40).Return(stale[:3], nil).Once()
+
+		svc := services.NewVehicleService(nil, mockCache)
+		got, err := svc.FindNearbyVehicles(context.Background(), domain.NearbyQuery{UpdatedSince: now.Add(-time.Hour)})
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+
+		got, err = svc.FindNearbyVehicles(context.Background(), domain.NearbyQuery{Longitude: 1, Latitude: 1, UpdatedSince: now.Add(-time.Hour)})
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("Returns an empty list", func(t *testing.T) {
+		mockCache := new(MockVehicleCache)
+		mockCache.On("FindNearby", mock.Anything, 0.0, 0.0, 10.0, services.DefaultNearbyLimit).Return(nil, nil)
+
+		svc := services.NewVehicleService(nil, mockCache)
+		got, err := svc.FindNearbyVehicles(context.Background(), domain.NearbyQuery{Radius: 10})
+
+		assert.NoError(t, err)
+		assert.NotNil(t, got)
+		assert.Empty(t, got)
+	})
+
+	t.Run("Rejects an invalid query", func(t *testing.T) {
+		svc := services.NewVehicleService(nil, new(MockVehicleCache))
+		_, err := svc.FindNearbyVehicles(context.Background(), domain.NearbyQuery{Longitude: 200, Radius: -1})
+
+		var verr *domain.ValidationError
+		if assert.ErrorAs(t, err, &verr) {
+			assert.Len(t, verr.Fields, 2)
+		}
+	})
+
+	t.Run("Rejects a point beyond the position index", func(t *testing.T) {
+		mockCache := new(MockVehicleCache)
+		svc := services.NewVehicleService(nil, mockCache)
+		_, err := svc.FindNearbyVehicles(context.Background(), domain.NearbyQuery{Longitude: 10, Latitude: 89, Radius: 500000})
+
+		var verr *domain.ValidationError
+		if assert.ErrorAs(t, err, &verr) {
+			assert.Equal(t, "lat", verr.Fields[0].Field)
+		}
+		mockCache.AssertNotCalled(t, "FindNearby", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
 }
@@ -0,0 +1,145 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	handler "github.com/AnjuRKrishnan/fleet-tracker/internal/handler"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// MockWebhookService is a mock type for WebhookService
+type MockWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockWebhookService) CreateWebhook(ctx context.Context, sub domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	args := m.Called(ctx, sub)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookService) GetWebhook(ctx context.Context, id uuid.UUID) (*domain.WebhookSubscription, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookService) ListWebhooks(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookService) UpdateWebhook(ctx context.Context, sub domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	args := m.Called(ctx, sub)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookService) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) ListDeliveries(ctx context.Context, query domain.WebhookDeliveryQuery) ([]domain.WebhookDelivery, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.WebhookDelivery), args.Error(1)
+}
+
+func newWebhookRouter(m *MockWebhookService) http.Handler {
+	h := handler.NewWebhookHandler(m, zap.NewNop())
+	r := chi.NewRouter()
+	r.Post("/webhooks", h.Create)
+	r.Put("/webhooks/{id}", h.Update)
+	r.Get("/webhooks/{id}/deliveries", h.ListDeliveries)
+	return r
+}
+
+func TestWebhookHandler_Create(t *testing.T) {
+	t.Run("Enabled by default", func(t *testing.T) {
+		m := new(MockWebhookService)
+		m.On("CreateWebhook", mock.Anything, domain.WebhookSubscription{
+			URL:        "https://tms.example.com/hooks",
+			EventTypes: []domain.EventType{domain.EventTripEnded},
+			Enabled:    true,
+		}).Return(&domain.WebhookSubscription{ID: uuid.New(), Secret: "s3cr3t-s3cr3t-s3cr3t"}, nil)
+
+		body := `{"url":"https://tms.example.com/hooks","event_types":["trip.ended"]}`
+		rr := httptest.NewRecorder()
+		newWebhookRouter(m).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body)))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"secret":"s3cr3t-s3cr3t-s3cr3t"`)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("Validation Error", func(t *testing.T) {
+		m := new(MockWebhookService)
+		m.On("CreateWebhook", mock.Anything, mock.Anything).Return(nil, &domain.ValidationError{
+			Fields: []domain.FieldError{{Field: "url", Message: "must be an absolute http or https URL"}},
+		})
+
+		rr := httptest.NewRecorder()
+		newWebhookRouter(m).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(`{"url":"tms"}`)))
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+
+	t.Run("Update Not Found", func(t *testing.T) {
+		id := uuid.New()
+		m := new(MockWebhookService)
+		m.On("UpdateWebhook", mock.Anything, mock.Anything).Return(nil, domain.ErrNotFound)
+
+		rr := httptest.NewRecorder()
+		newWebhookRouter(m).ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/webhooks/"+id.String(), strings.NewReader(`{"url":"https://tms.example.com"}`)))
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestWebhookHandler_ListDeliveries(t *testing.T) {
+	id := uuid.New()
+
+	t.Run("Filters", func(t *testing.T) {
+		m := new(MockWebhookService)
+		m.On("ListDeliveries", mock.Anything, domain.WebhookDeliveryQuery{
+			SubscriptionID: id,
+			State:          domain.WebhookFailed,
+			From:           time.Date(2025, 6, 17, 0, 0, 0, 0, time.UTC),
+			Limit:          10,
+		}).Return([]domain.WebhookDelivery{{ID: uuid.New(), State: domain.WebhookFailed}}, nil)
+
+		url := "/webhooks/" + id.String() + "/deliveries?state=failed&from=2025-06-17T00:00:00Z&limit=10"
+		rr := httptest.NewRecorder()
+		newWebhookRouter(m).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, url, nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("Invalid state", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		newWebhookRouter(new(MockWebhookService)).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/webhooks/"+id.String()+"/deliveries?state=lost", nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, "Invalid state\n", rr.Body.String())
+	})
+}
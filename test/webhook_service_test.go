@@ -0,0 +1,379 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/domain"
+	"github.com/AnjuRKrishnan/fleet-tracker/internal/services"
+	"github.com/AnjuRKrishnan/fleet-tracker/pkg/webhook"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// MockWebhookRepository is a mock type for WebhookRepository
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) CreateWebhook(ctx context.Context, sub domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	args := m.Called(ctx, sub)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetWebhook(ctx context.Context, id uuid.UUID) (*domain.WebhookSubscription, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ListWebhooks(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) UpdateWebhook(ctx context.Context, sub domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	args := m.Called(ctx, sub)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) EnqueueDeliveries(ctx context.Context, subscriptionIDs []uuid.UUID, event domain.Event, payload []byte) error {
+	args := m.Called(ctx, subscriptionIDs, event, payload)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) ClaimDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int32) ([]domain.PendingDelivery, error) {
+	args := m.Called(ctx, now, leaseUntil, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.PendingDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) RetryDelivery(ctx context.Context, id uuid.UUID, attempt domain.WebhookAttempt, nextAttemptAt time.Time) error {
+	args := m.Called(ctx, id, attempt, nextAttemptAt)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) CompleteDelivery(ctx context.Context, id uuid.UUID, state domain.WebhookDeliveryState, attempt domain.WebhookAttempt) error {
+	args := m.Called(ctx, id, state, attempt)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) ListDeliveries(ctx context.Context, query domain.WebhookDeliveryQuery) ([]domain.WebhookDelivery, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) DeleteDeliveriesBefore(ctx context.Context, t time.Time) (int64, error) {
+	args := m.Called(ctx, t)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWebhookRepository) RecordWebhookSuccess(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) RecordWebhookFailure(ctx context.Context, id uuid.UUID, at, disableBefore time.Time) (*domain.WebhookSubscription, error) {
+	args := m.Called(ctx, id, at, disableBefore)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WebhookSubscription), args.Error(1)
+}
+
+// recordingPublisher collects published events.
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []domain.Event
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, event domain.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+}
+
+func (p *recordingPublisher) types() []domain.EventType {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	types := make([]domain.EventType, len(p.events))
+	for i, e := range p.events {
+		types[i] = e.Type
+	}
+	return types
+}
+
+func TestWebhookSignature(t *testing.T) {
+	payload := []byte(`{"type":"trip.started"}`)
+	now := time.Date(2025, 6, 17, 9, 0, 0, 0, time.UTC)
+	header := webhook.Sign("0123456789abcdef", now, payload)
+
+	assert.NoError(t, webhook.Verify("0123456789abcdef", header, payload, now.Add(time.Minute), 5*time.Minute))
+	assert.ErrorIs(t, webhook.Verify("another-secret-value", header, payload, now, 0), webhook.ErrInvalidSignature)
+	assert.ErrorIs(t, webhook.Verify("0123456789abcdef", header, []byte(`{"type":"trip.ended"}`), now, 0), webhook.ErrInvalidSignature)
+	assert.ErrorIs(t, webhook.Verify("0123456789abcdef", "v1=abc", payload, now, 0), webhook.ErrInvalidSignature)
+	assert.ErrorIs(t, webhook.Verify("0123456789abcdef", header, payload, now.Add(time.Hour), 5*time.Minute), webhook.ErrSignatureExpired)
+}
+
+func TestWebhookSubscription_Validate(t *testing.T) {
+	valid := domain.WebhookSubscription{URL: "https://tms.example.com/hooks", EventTypes: []domain.EventType{domain.EventTripEnded}}
+	assert.NoError(t, valid.Validate())
+
+	invalid := domain.WebhookSubscription{
+		URL:        "ftp://tms.example.com",
+		Secret:     "short",
+		EventTypes: []domain.EventType{"trip.paused"},
+	}
+	err := invalid.Validate()
+	var verr *domain.ValidationError
+	if assert.ErrorAs(t, err, &verr) {
+		assert.Equal(t, []domain.FieldError{
+			{Field: "url", Message: "must be an absolute http or https URL"},
+			{Field: "secret", Message: "must be at least 16 characters"},
+			{Field: "event_types[0]", Message: "is not a known event type"},
+		}, verr.Fields)
+	}
+
+	for _, url := range []string{
+		"http://169.254.169.254/latest/meta-data",
+		"http://127.0.0.1:8080/hooks",
+		"https://[::1]/hooks",
+		"http://10.0.0.5/hooks",
+		"http://localhost:9000/hooks",
+		"http://api.localhost/hooks",
+	} {
+		err := domain.WebhookSubscription{URL: url}.Validate()
+		if assert.ErrorAs(t, err, &verr, url) {
+			assert.Equal(t, []domain.FieldError{
+				{Field: "url", Message: "must not point to a loopback, private or link-local address"},
+			}, verr.Fields, url)
+		}
+	}
+}
+
+func TestWebhookService_Publish(t *testing.T) {
+	tms := domain.WebhookSubscription{ID: uuid.New(), Enabled: true, EventTypes: []domain.EventType{domain.EventTripStarted, domain.EventTripEnded}}
+	erp := domain.WebhookSubscription{ID: uuid.New(), Enabled: true}
+	disabled := domain.WebhookSubscription{ID: uuid.New(), Enabled: false}
+	vehicleID := uuid.New()
+	ctx := context.Background()
+
+	t.Run("Queues matching subscriptions", func(t *testing.T) {
+		repo := new(MockWebhookRepository)
+		repo.On("ListWebhooks", mock.Anything).Return([]domain.WebhookSubscription{tms, erp, disabled}, nil).Once()
+
+		event := domain.NewEvent(domain.EventTripStarted, vehicleID, time.Now(), domain.Trip{})
+		repo.On("EnqueueDeliveries", mock.Anything, []uuid.UUID{tms.ID, erp.ID}, event, mock.MatchedBy(func(payload []byte) bool {
+			var got map[string]any
+			return json.Unmarshal(payload, &got) == nil && got["type"] == "trip.started" && got["vehicle_id"] == vehicleID.String()
+		})).Return(nil).Once()
+
+		services.NewWebhookService(repo, zap.NewNop()).Publish(ctx, event)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Skips events nobody wants", func(t *testing.T) {
+		repo := new(MockWebhookRepository)
+		repo.On("ListWebhooks", mock.Anything).Return([]domain.WebhookSubscription{tms, disabled}, nil).Once()
+
+		s := services.NewWebhookService(repo, zap.NewNop())
+		assert.NoError(t, services.StatusEvents(s).Process(ctx, vehicleID, domain.VehicleStatus{Timestamp: time.Now()}))
+		repo.AssertNotCalled(t, "EnqueueDeliveries", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestWebhookService_CreateWebhook(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	repo.On("CreateWebhook", mock.Anything, mock.MatchedBy(func(sub domain.WebhookSubscription) bool {
+		return len(sub.Secret) == 64
+	})).Return(&domain.WebhookSubscription{ID: uuid.New(), Secret: "generated"}, nil).Once()
+
+	created, err := services.NewWebhookService(repo, zap.NewNop()).CreateWebhook(context.Background(), domain.WebhookSubscription{
+		URL:     "https://erp.example.com/fleet",
+		Enabled: true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "generated", created.Secret)
+	repo.AssertExpectations(t)
+}
+
+func TestWebhookDeliveryPolicy_Backoff(t *testing.T) {
+	var p services.WebhookDeliveryPolicy
+	assert.Equal(t, 10*time.Second, p.Backoff(1))
+	assert.Equal(t, 20*time.Second, p.Backoff(2))
+	assert.Equal(t, 80*time.Second, p.Backoff(4))
+	assert.Equal(t, time.Hour, p.Backoff(20))
+}
+
+// newDispatcher creates a dispatcher whose attempts time out after a second.
+func newDispatcher(t *testing.T, repo domain.WebhookRepository, client *http.Client, policy services.WebhookDeliveryPolicy) *services.WebhookDispatcher {
+	client.Timeout = time.Second
+	d, err := services.NewWebhookDispatcher(repo, client, policy, zap.NewNop())
+	require.NoError(t, err)
+	return d
+}
+
+func TestNewWebhookDispatcher(t *testing.T) {
+	policy := services.WebhookDeliveryPolicy{MaxAttempts: 3}
+	repo := new(MockWebhookRepository)
+
+	_, err := services.NewWebhookDispatcher(repo, &http.Client{Timeout: 10 * time.Second}, policy, zap.NewNop())
+	assert.NoError(t, err)
+
+	for _, timeout := range []time.Duration{0, services.WebhookLease, time.Hour} {
+		_, err := services.NewWebhookDispatcher(repo, &http.Client{Timeout: timeout}, policy, zap.NewNop())
+		assert.Error(t, err, timeout)
+	}
+	_, err = services.NewWebhookDispatcher(repo, &http.Client{Timeout: time.Second}, services.WebhookDeliveryPolicy{}, zap.NewNop())
+	assert.Error(t, err)
+}
+
+func TestWebhookClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	// Names are only resolved, and refused, when dialing.
+	_, err := webhook.NewClient(time.Second).Post(strings.Replace(server.URL, "127.0.0.1", "localhost", 1), "application/json", nil)
+	assert.ErrorIs(t, err, webhook.ErrPrivateAddress)
+
+	for addr, public := range map[string]bool{
+		"203.0.113.7":     true,
+		"2001:db8::1":     true,
+		"127.0.0.1":       false,
+		"::1":             false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"fe80::1":         false,
+		"fd00::1":         false,
+		"100.64.0.1":      false,
+		"0.0.0.0":         false,
+		"::ffff:10.0.0.1": false,
+		"224.0.0.1":       false,
+	} {
+		assert.Equal(t, public, webhook.IsPublic(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestWebhookDispatcher_Dispatch(t *testing.T) {
+	const secret = "0123456789abcdef"
+	policy := services.WebhookDeliveryPolicy{MaxAttempts: 3, DisableAfter: 24 * time.Hour}
+	now := time.Now()
+	ctx := context.Background()
+
+	pending := func(url string, attempts int) domain.PendingDelivery {
+		return domain.PendingDelivery{
+			WebhookDelivery: domain.WebhookDelivery{
+				ID:             uuid.New(),
+				SubscriptionID: uuid.New(),
+				EventType:      domain.EventAlertOpened,
+				Payload:        json.RawMessage(`{"type":"alert.opened"}`),
+				Attempts:       attempts,
+			},
+			URL:    url,
+			Secret: secret,
+		}
+	}
+
+	t.Run("Signed delivery succeeds", func(t *testing.T) {
+		var verified error
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			verified = webhook.Verify(secret, r.Header.Get(webhook.SignatureHeader), body, time.Now(), time.Minute)
+			assert.Equal(t, "alert.opened", r.Header.Get(webhook.EventHeader))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		delivery := pending(server.URL, 0)
+		repo := new(MockWebhookRepository)
+		repo.On("ClaimDeliveries", mock.Anything, now, now.Add(services.WebhookLease), int32(services.WebhookBatchSize)).
+			Return([]domain.PendingDelivery{delivery}, nil).Once()
+		repo.On("CompleteDelivery", mock.Anything, delivery.ID, domain.WebhookDelivered, mock.MatchedBy(func(a domain.WebhookAttempt) bool {
+			return a.Error == "" && a.ResponseStatus != nil && *a.ResponseStatus == http.StatusNoContent
+		})).Return(nil).Once()
+		repo.On("RecordWebhookSuccess", mock.Anything, delivery.SubscriptionID).Return(nil).Once()
+
+		n := newDispatcher(t, repo, server.Client(), policy).Dispatch(ctx, now)
+		assert.Equal(t, 1, n)
+		assert.NoError(t, verified)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Failed attempt is retried with backoff", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "maintenance", http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		delivery := pending(server.URL, 1)
+		repo := new(MockWebhookRepository)
+		repo.On("ClaimDeliveries", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return([]domain.PendingDelivery{delivery}, nil).Once()
+		var attempt domain.WebhookAttempt
+		var next time.Time
+		repo.On("RetryDelivery", mock.Anything, delivery.ID, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				attempt = args.Get(2).(domain.WebhookAttempt)
+				next = args.Get(3).(time.Time)
+			}).Return(nil).Once()
+		repo.On("RecordWebhookFailure", mock.Anything, delivery.SubscriptionID, mock.Anything, mock.Anything).
+			Return(&domain.WebhookSubscription{Enabled: true}, nil).Once()
+
+		newDispatcher(t, repo, server.Client(), policy).Dispatch(ctx, now)
+		repo.AssertExpectations(t)
+		assert.Equal(t, "unexpected status 503: maintenance", attempt.Error)
+		assert.Equal(t, 20*time.Second, next.Sub(attempt.At))
+	})
+
+	t.Run("Last attempt fails the delivery", func(t *testing.T) {
+		delivery := pending("http://127.0.0.1:1", 2)
+		repo := new(MockWebhookRepository)
+		repo.On("ClaimDeliveries", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return([]domain.PendingDelivery{delivery}, nil).Once()
+		repo.On("CompleteDelivery", mock.Anything, delivery.ID, domain.WebhookFailed, mock.MatchedBy(func(a domain.WebhookAttempt) bool {
+			return a.Error != "" && a.ResponseStatus == nil
+		})).Return(nil).Once()
+		repo.On("RecordWebhookFailure", mock.Anything, delivery.SubscriptionID, mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+			return time.Since(before) >= 24*time.Hour
+		})).Return(&domain.WebhookSubscription{Enabled: false}, nil).Once()
+
+		newDispatcher(t, repo, &http.Client{}, policy).Dispatch(ctx, now)
+		repo.AssertExpectations(t)
+	})
+}